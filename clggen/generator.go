@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Data represents the information the templates are rendered with for a single
+// CLG.
+type Data struct {
+	// Inputs lists the argument types of the calculate method of the CLG,
+	// excluding the context.
+	Inputs []string
+	// Outputs lists the output types of the calculate method of the CLG,
+	// excluding a trailing error.
+	Outputs []string
+	// PackageName is the name of the package of the CLG.
+	PackageName string
+	// ReturnsError tells whether the calculate method of the CLG returns an
+	// error.
+	ReturnsError bool
+}
+
+// Generate renders the templates of the given template directory for each CLG
+// package found in the given CLG directory.
+func Generate(clgDir, templateDir string) error {
+	templates, err := filepath.Glob(filepath.Join(templateDir, "clg", "*.tmpl"))
+	if err != nil {
+		return maskAny(err)
+	}
+
+	infos, err := ioutil.ReadDir(clgDir)
+	if err != nil {
+		return maskAny(err)
+	}
+	for _, info := range infos {
+		if !info.IsDir() {
+			continue
+		}
+		packageDir := filepath.Join(clgDir, info.Name())
+		data, err := ParseCLG(packageDir)
+		if err != nil {
+			return maskAny(err)
+		}
+		for _, t := range templates {
+			err := render(t, packageDir, data)
+			if err != nil {
+				return maskAny(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ParseCLG parses the CLG package within the given directory and returns the
+// information the templates are rendered with. Generated files and tests are
+// ignored.
+func ParseCLG(packageDir string) (Data, error) {
+	filter := func(info os.FileInfo) bool {
+		return !strings.HasPrefix(info.Name(), "generated_") && !strings.HasSuffix(info.Name(), "_test.go")
+	}
+	packages, err := parser.ParseDir(token.NewFileSet(), packageDir, filter, 0)
+	if err != nil {
+		return Data{}, maskAny(err)
+	}
+	if len(packages) != 1 {
+		return Data{}, maskAnyf(invalidCalculateError, "expected 1 package in '%s', got %d", packageDir, len(packages))
+	}
+
+	for name, p := range packages {
+		for _, f := range p.Files {
+			for _, d := range f.Decls {
+				funcDecl, ok := d.(*ast.FuncDecl)
+				if !ok || funcDecl.Recv == nil || funcDecl.Name.Name != "calculate" {
+					continue
+				}
+
+				data, err := newData(name, funcDecl.Type)
+				if err != nil {
+					return Data{}, maskAnyf(err, "package '%s'", name)
+				}
+
+				return data, nil
+			}
+		}
+	}
+
+	return Data{}, maskAnyf(invalidCalculateError, "no calculate method in '%s'", packageDir)
+}
+
+// fieldTypes returns the type of each parameter or result declared by the
+// given field list. Fields declaring multiple names, like a, b float64, are
+// expanded.
+func fieldTypes(fieldList *ast.FieldList) []string {
+	var fieldTypes []string
+	if fieldList == nil {
+		return fieldTypes
+	}
+	for _, f := range fieldList.List {
+		n := len(f.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			fieldTypes = append(fieldTypes, types.ExprString(f.Type))
+		}
+	}
+
+	return fieldTypes
+}
+
+// newData creates the information the templates are rendered with using the
+// given package name and signature of the calculate method.
+func newData(packageName string, funcType *ast.FuncType) (Data, error) {
+	inputs := fieldTypes(funcType.Params)
+	if len(inputs) == 0 || inputs[0] != "objectspec.Context" {
+		return Data{}, maskAnyf(invalidCalculateError, "first argument must be objectspec.Context")
+	}
+
+	outputs := fieldTypes(funcType.Results)
+	var returnsError bool
+	if len(outputs) > 0 && outputs[len(outputs)-1] == "error" {
+		outputs = outputs[:len(outputs)-1]
+		returnsError = true
+	}
+
+	newData := Data{
+		Inputs:       inputs[1:],
+		Outputs:      outputs,
+		PackageName:  packageName,
+		ReturnsError: returnsError,
+	}
+
+	return newData, nil
+}
+
+// render renders the given template using the given data. The formatted source
+// code is written into the given package directory.
+func render(templatePath, packageDir string, data Data) error {
+	t, err := template.ParseFiles(templatePath)
+	if err != nil {
+		return maskAny(err)
+	}
+	var b bytes.Buffer
+	err = t.Execute(&b, data)
+	if err != nil {
+		return maskAny(err)
+	}
+	source, err := format.Source(b.Bytes())
+	if err != nil {
+		return maskAnyf(err, "template '%s'", templatePath)
+	}
+
+	fileName := strings.TrimSuffix(filepath.Base(templatePath), ".tmpl") + ".go"
+	err = ioutil.WriteFile(filepath.Join(packageDir, fileName), source, 0644)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func testMustWriteFile(t *testing.T, path, content string) {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	err = ioutil.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+}
+
+func Test_Generator_ParseCLG(t *testing.T) {
+	testCases := []struct {
+		Source        string
+		Expected      Data
+		ErrorExpected bool
+	}{
+		{
+			Source:   "func (s *service) calculate(ctx objectspec.Context, a, b float64) float64 { return a + b }",
+			Expected: Data{Inputs: []string{"float64", "float64"}, Outputs: []string{"float64"}, PackageName: "test"},
+		},
+		{
+			Source:   "func (s *service) calculate(ctx objectspec.Context, f float64, p int) (float64, error) { return f, nil }",
+			Expected: Data{Inputs: []string{"float64", "int"}, Outputs: []string{"float64"}, PackageName: "test", ReturnsError: true},
+		},
+		{
+			Source:   "func (s *service) calculate(ctx objectspec.Context, i string) error { return nil }",
+			Expected: Data{Inputs: []string{"string"}, Outputs: []string{}, PackageName: "test", ReturnsError: true},
+		},
+		{
+			Source:   "func (s *service) calculate(ctx objectspec.Context) []string { return nil }",
+			Expected: Data{Inputs: []string{}, Outputs: []string{"[]string"}, PackageName: "test"},
+		},
+		// The calculate method must receive the context first.
+		{
+			Source:        "func (s *service) calculate(a float64) float64 { return a }",
+			ErrorExpected: true,
+		},
+		// The calculate method must be a method.
+		{
+			Source:        "func calculate(ctx objectspec.Context) {}",
+			ErrorExpected: true,
+		},
+	}
+
+	for i, testCase := range testCases {
+		packageDir, err := ioutil.TempDir("", "clggen")
+		if err != nil {
+			t.Fatal("case", i+1, "expected", nil, "got", err)
+		}
+		defer os.RemoveAll(packageDir)
+
+		testMustWriteFile(t, filepath.Join(packageDir, "service.go"), "package test\n\n"+testCase.Source+"\n")
+		// Generated files and tests are ignored.
+		testMustWriteFile(t, filepath.Join(packageDir, "generated_service.go"), "package test\n\nfunc (s *service) calculate() {}\n")
+		testMustWriteFile(t, filepath.Join(packageDir, "service_test.go"), "package test\n\nfunc (s *service) calculate() {}\n")
+
+		output, err := ParseCLG(packageDir)
+		if testCase.ErrorExpected {
+			if !IsInvalidCalculate(err) {
+				t.Fatal("case", i+1, "expected", true, "got", false)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal("case", i+1, "expected", nil, "got", err)
+		}
+		if !reflect.DeepEqual(output, testCase.Expected) {
+			t.Fatal("case", i+1, "expected", testCase.Expected, "got", output)
+		}
+	}
+}
+
+func Test_Generator_Generate(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "clggen")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	defer os.RemoveAll(rootDir)
+
+	clgDir := filepath.Join(rootDir, "clg")
+	templateDir := filepath.Join(rootDir, "template")
+	testMustWriteFile(t, filepath.Join(clgDir, "sum", "service.go"), "package sum\n\nfunc (s *service) calculate(ctx objectspec.Context, a, b float64) float64 { return a + b }\n")
+	testMustWriteFile(t, filepath.Join(templateDir, "clg", "generated_types.tmpl"), "package {{.PackageName}}\n\nvar inputTypes = []string{ {{- range $i, $t := .Inputs}}{{if $i}}, {{end}}\"{{$t}}\"{{end -}} }\n")
+
+	err = Generate(clgDir, templateDir)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(clgDir, "sum", "generated_types.go"))
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	expected := "package sum\n\nvar inputTypes = []string{\"float64\", \"float64\"}\n"
+	if string(b) != expected {
+		t.Fatal("expected", expected, "got", string(b))
+	}
+}
@@ -0,0 +1,36 @@
+// Package main implements the CLG generator. It renders the templates of the
+// template directory for each CLG found in the CLG directory. The clg package
+// invokes it using go generate.
+//
+//	go run ../../clggen generate --clg-dir=. --template-dir=../../template
+//
+// Each CLG is a package within its own subdirectory of the CLG directory. The
+// templates of the clg subdirectory of the template directory are rendered
+// into each of these packages. A template named generated_foo.tmpl is rendered
+// into generated_foo.go. See Data for the information the templates are
+// rendered with.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "generate" {
+		fmt.Fprintln(os.Stderr, "usage: clggen generate --clg-dir=<dir> --template-dir=<dir>")
+		os.Exit(2)
+	}
+
+	flagSet := flag.NewFlagSet("generate", flag.ExitOnError)
+	clgDir := flagSet.String("clg-dir", ".", "directory holding one subdirectory for each CLG package")
+	templateDir := flagSet.String("template-dir", "template", "directory holding the clg subdirectory of templates")
+	flagSet.Parse(os.Args[2:])
+
+	err := Generate(*clgDir, *templateDir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -3,7 +3,7 @@ package collection
 import (
 	"sync"
 
-	servicespec "github.com/the-anna-project/spec/service"
+	servicespec "github.com/the-anna-project/annad/spec/service"
 )
 
 // New creates a new service collection.
@@ -15,6 +15,8 @@ type collection struct {
 	// Dependencies.
 
 	activatorService    servicespec.ActivatorService
+	behaviourService    servicespec.BehaviourService
+	busService          servicespec.BusService
 	connectionService   servicespec.ConnectionService
 	endpointCollection  servicespec.EndpointCollection
 	featureService      servicespec.FeatureService
@@ -44,8 +46,18 @@ func (c *collection) Activator() servicespec.ActivatorService {
 	return c.activatorService
 }
 
+func (c *collection) Behaviour() servicespec.BehaviourService {
+	return c.behaviourService
+}
+
+func (c *collection) Bus() servicespec.BusService {
+	return c.busService
+}
+
 func (c *collection) Boot() {
 	go c.Activator().Boot()
+	go c.Behaviour().Boot()
+	go c.Bus().Boot()
 	go c.Connection().Boot()
 	go c.Endpoint().Boot()
 	go c.Feature().Boot()
@@ -133,6 +145,14 @@ func (c *collection) SetActivatorService(activator servicespec.ActivatorService)
 	c.activatorService = activator
 }
 
+func (c *collection) SetBehaviourService(behaviourService servicespec.BehaviourService) {
+	c.behaviourService = behaviourService
+}
+
+func (c *collection) SetBusService(busService servicespec.BusService) {
+	c.busService = busService
+}
+
 func (c *collection) SetConnectionService(connectionService servicespec.ConnectionService) {
 	c.connectionService = connectionService
 }
@@ -213,6 +233,18 @@ func (c *collection) Shutdown() {
 	c.shutdownOnce.Do(func() {
 		var wg sync.WaitGroup
 
+		wg.Add(1)
+		go func() {
+			c.Activator().Shutdown()
+			wg.Done()
+		}()
+
+		wg.Add(1)
+		go func() {
+			c.Bus().Shutdown()
+			wg.Done()
+		}()
+
 		wg.Add(1)
 		go func() {
 			c.Endpoint().Shutdown()
@@ -4,11 +4,14 @@ import (
 	"os"
 	"os/signal"
 	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"github.com/the-anna-project/annad/object/config"
-	servicespec "github.com/the-anna-project/spec/service"
+	"github.com/the-anna-project/annad/service/journal"
+	servicespec "github.com/the-anna-project/annad/spec/service"
 )
 
 // New creates a new boot command.
@@ -24,7 +27,10 @@ func New() *Command {
 type Command struct {
 	// Dependencies.
 
-	configCollection  *config.Collection
+	configCollection *config.Collection
+	// recorder records a journal of the network sessions, if enabled using the
+	// journal.record flag.
+	recorder          *journal.Recorder
 	serviceCollection servicespec.ServiceCollection
 
 	// Settings.
@@ -42,16 +48,38 @@ type Command struct {
 func (c *Command) Boot() {
 	go c.ListenToSignal()
 
-	c.serviceCollection = c.newServiceCollection()
-	go c.serviceCollection.Boot()
+	c.serviceCollection = c.NewServiceCollection()
+	c.BootServiceCollection(c.serviceCollection)
+
+	c.serviceCollection.Endpoint().Boot()
 
 	// Block the main goroutine forever. The process is only supposed to be ended
 	// by a call to Shutdown or ForceShutdown.
 	select {}
 }
 
+// ConfigCollection returns the config collection of the boot command. It holds
+// the values of the flags of the cobra command created by New.
+func (c *Command) ConfigCollection() *config.Collection {
+	return c.configCollection
+}
+
 // Execute represents the cobra run method.
 func (c *Command) Execute(cmd *cobra.Command, args []string) {
+	// Merge the parsed flags with their counterparts of the config file and the
+	// process environment before booting.
+	err := c.configCollection.Merge(cmd.Flags())
+	if err != nil {
+		panic(err)
+	}
+
+	if c.configCollection.Journal().Record() != "" {
+		c.recorder, err = c.newRecorder(cmd.Flags())
+		if err != nil {
+			panic(err)
+		}
+	}
+
 	c.Boot()
 }
 
@@ -72,9 +100,47 @@ func (c *Command) New() *cobra.Command {
 	c.configCollection.Config().SetDir(newCmd.PersistentFlags().String("config.dir", ".", "directory where to find the config file"))
 	c.configCollection.Config().SetName(newCmd.PersistentFlags().String("config.name", "config", "name of the config file without extension"))
 
+	c.configCollection.Activator().Lookup().SetStrategies(newCmd.PersistentFlags().String("activator.lookup.strategies", "configuration,random", "comma separated list of lookup strategies the activator executes in order (e.g. configuration,connection-weight,first-come,random,same-clg-tree)"))
+	c.configCollection.Activator().Queue().SetDeadLetter(newCmd.PersistentFlags().Bool("activator.queue.deadletter", false, "whether to move expired network payloads of activation queues to the dead letter queue instead of dropping them"))
+	c.configCollection.Activator().Queue().SetSweepInterval(newCmd.PersistentFlags().Duration("activator.queue.sweep", time.Minute, "interval in which activation queues of abandoned CLG trees are cleaned up"))
+	c.configCollection.Activator().Queue().SetTTL(newCmd.PersistentFlags().Duration("activator.queue.ttl", time.Minute, "duration a network payload is allowed to wait within an activation queue"))
+
+	c.configCollection.Bus().SetBuffer(newCmd.PersistentFlags().Int("bus.buffer", 1000, "number of network events buffered for each subscriber of the internal event bus"))
+	c.configCollection.Bus().SetTracing(newCmd.PersistentFlags().Bool("bus.tracing", false, "whether to log each network event published on the internal event bus"))
+
+	c.configCollection.Deterministic().SetSeed(newCmd.PersistentFlags().Int64("deterministic.seed", 0, "seed making random numbers and IDs reproducible across runs using a single input and event worker, 0 disables the deterministic mode"))
+
+	c.configCollection.Journal().SetRecord(newCmd.PersistentFlags().String("journal.record", "", "file to record a journal of input, random numbers, IDs and output to using a single input and event worker, empty disables recording"))
+
 	c.configCollection.Endpoint().Text().SetAddress(newCmd.PersistentFlags().String("endpoint.text.address", "127.0.0.1:9119", "host:port to bind the text endpoint to"))
 	c.configCollection.Endpoint().Metric().SetAddress(newCmd.PersistentFlags().String("endpoint.metric.address", "127.0.0.1:9120", "host:port to bind the metric endpoint to"))
 
+	c.configCollection.Network().Budget().SetDeadline(newCmd.PersistentFlags().Duration("network.budget.deadline", 30*time.Second, "duration a CLG tree is allowed to calculate an answer for its input"))
+	c.configCollection.Network().Budget().SetDepth(newCmd.PersistentFlags().Int("network.budget.depth", 100, "maximum number of hops a network payload is allowed to travel within its CLG tree"))
+	c.configCollection.Network().Budget().SetEvents(newCmd.PersistentFlags().Int("network.budget.events", 10000, "maximum number of network events a CLG tree is allowed to cause"))
+	c.configCollection.Network().Candidates().SetLimit(newCmd.PersistentFlags().Int("network.candidates.limit", 10, "maximum number of ranked output candidates sent to the client per CLG tree"))
+	c.configCollection.Network().Candidates().SetWindow(newCmd.PersistentFlags().Duration("network.candidates.window", 100*time.Millisecond, "duration the outputs of a CLG tree are gathered before being sent to the client as ranked candidates"))
+	c.configCollection.Network().CLG().SetCacheSize(newCmd.PersistentFlags().Int("network.clg.cache.size", 10000, "maximum number of pure CLG results being memoized, 0 disables memoization"))
+	c.configCollection.Network().CLG().SetQuarantineDuration(newCmd.PersistentFlags().Duration("network.clg.quarantine.duration", 5*time.Minute, "duration a misbehaving CLG is taken out of the network"))
+	c.configCollection.Network().CLG().SetQuarantineThreshold(newCmd.PersistentFlags().Int("network.clg.quarantine.threshold", 5, "number of consecutive panics or timeouts after which a CLG is quarantined"))
+	c.configCollection.Network().CLG().SetTimeout(newCmd.PersistentFlags().Duration("network.clg.timeout", 10*time.Second, "duration a CLG execution is allowed to take"))
+	c.configCollection.Network().Mode().SetFrozen(newCmd.PersistentFlags().Bool("network.mode.frozen", false, "whether the neural network only uses what it already learned, without creating new configurations or tracking connections"))
+	c.configCollection.Network().Priority().SetAging(newCmd.PersistentFlags().Int("network.priority.aging", 100, "number of scheduled network events after which a waiting network event gains one priority point"))
+	c.configCollection.Network().Priority().SetCLG(newCmd.PersistentFlags().String("network.priority.clg", "output:10", "comma separated list of CLG names and the priority added to their network events (e.g. output:10,input:5)"))
+	c.configCollection.Network().Priority().SetDepth(newCmd.PersistentFlags().Int("network.priority.depth", 1, "priority added to a network event for each hop within its CLG tree"))
+	c.configCollection.Network().Priority().SetInteractive(newCmd.PersistentFlags().Int("network.priority.interactive", 10, "priority of network events belonging to interactive sessions"))
+	c.configCollection.Network().Priority().SetTraining(newCmd.PersistentFlags().Int("network.priority.training", 0, "priority of network events belonging to training sessions"))
+	c.configCollection.Network().Retry().SetBackoff(newCmd.PersistentFlags().Duration("network.retry.backoff", 10*time.Millisecond, "delay before the first retry of a CLG tree whose output did not meet the expectation, doubling with each further retry"))
+	c.configCollection.Network().Retry().SetBackoffMax(newCmd.PersistentFlags().Duration("network.retry.backoff.max", time.Second, "maximum delay between two retries of a CLG tree"))
+	c.configCollection.Network().Retry().SetLimit(newCmd.PersistentFlags().Int("network.retry.limit", 100, "maximum number of retries of a CLG tree before the client receives the output coming closest to the expectation"))
+	c.configCollection.Network().Workers().SetAutoscale(newCmd.PersistentFlags().Bool("network.workers.autoscale", false, "whether to scale the number of event workers automatically"))
+	c.configCollection.Network().Workers().SetAutoscaleInterval(newCmd.PersistentFlags().Duration("network.workers.autoscale.interval", 5*time.Second, "interval in which the autoscaler decides about the number of event workers"))
+	c.configCollection.Network().Workers().SetAutoscaleLatency(newCmd.PersistentFlags().Duration("network.workers.autoscale.latency", 100*time.Millisecond, "average event handler latency above which the autoscaler grows event workers cautiously"))
+	c.configCollection.Network().Workers().SetAutoscaleMax(newCmd.PersistentFlags().Int("network.workers.autoscale.max", 100, "maximum number of event workers the autoscaler scales up to"))
+	c.configCollection.Network().Workers().SetAutoscaleMin(newCmd.PersistentFlags().Int("network.workers.autoscale.min", 1, "minimum number of event workers the autoscaler scales down to"))
+	c.configCollection.Network().Workers().SetEvent(newCmd.PersistentFlags().Int("network.workers.event", 10, "number of workers handling network events"))
+	c.configCollection.Network().Workers().SetInput(newCmd.PersistentFlags().Int("network.workers.input", 1, "number of workers handling input"))
+
 	c.configCollection.Space().Connection().SetWeight(newCmd.PersistentFlags().Int("space.connection.weight", 0, "default weight of new connections within the connection space"))
 	c.configCollection.Space().Dimension().SetCount(newCmd.PersistentFlags().Int("space.dimension.count", 3, "default number of directional coordinates within the connection space"))
 	c.configCollection.Space().Dimension().SetDepth(newCmd.PersistentFlags().Int("space.dimension.depth", 1000000, "default size of each directional coordinate within the connection space"))
@@ -96,9 +162,42 @@ func (c *Command) New() *cobra.Command {
 	c.configCollection.Storage().Peer().SetKind(newCmd.PersistentFlags().String("storage.peer.kind", "memory", "storage kind to use for persistency (e.g. redis)"))
 	c.configCollection.Storage().Peer().SetPrefix(newCmd.PersistentFlags().String("storage.peer.prefix", "anna", "prefix used to prepend to peer storage keys"))
 
+	c.configCollection.Tracker().Pattern().SetLength(newCmd.PersistentFlags().Int("tracker.pattern.length", 3, "maximum number of CLG kinds a path pattern mined by the tracker is made of"))
+
 	return newCmd
 }
 
+// newRecorder creates a new recorder writing a journal to the file configured
+// by the journal.record flag. The values of the given flags are recorded, so
+// the network can be rebuilt the same way when replaying the journal. Flags
+// only affecting the boot command itself are not recorded.
+func (c *Command) newRecorder(flagSet *pflag.FlagSet) (*journal.Recorder, error) {
+	flags := map[string]string{}
+	flagSet.VisitAll(func(flag *pflag.Flag) {
+		switch flag.Name {
+		case "config.dir", "config.name", "help", "journal.record":
+			return
+		}
+		flags[flag.Name] = flag.Value.String()
+	})
+
+	f, err := os.Create(c.configCollection.Journal().Record())
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	newRecorderConfig := journal.DefaultRecorderConfig()
+	newRecorderConfig.Flags = flags
+	newRecorderConfig.Writer = f
+	newRecorder, err := journal.NewRecorder(newRecorderConfig)
+	if err != nil {
+		f.Close()
+		return nil, maskAny(err)
+	}
+
+	return newRecorder, nil
+}
+
 // ListenToSignal listens to OS signals to be catched and processed if desired.
 func (c *Command) ListenToSignal() {
 	listener := make(chan os.Signal, 2)
@@ -152,6 +251,12 @@ func (c *Command) Shutdown() {
 		wg.Add(1)
 		go func() {
 			c.serviceCollection.Shutdown()
+			if c.recorder != nil {
+				err := c.recorder.Close()
+				if err != nil {
+					c.serviceCollection.Log().Line("msg", maskAny(err))
+				}
+			}
 			wg.Done()
 		}()
 
@@ -28,3 +28,10 @@ var invalidStorageKindError = errgo.New("invalid storage kind")
 func IsInvalidStorageKind(err error) bool {
 	return errgo.Cause(err) == invalidStorageKindError
 }
+
+var invalidCLGPriorityError = errgo.New("invalid CLG priority")
+
+// IsInvalidCLGPriority asserts invalidCLGPriorityError.
+func IsInvalidCLGPriority(err error) bool {
+	return errgo.Cause(err) == invalidCLGPriorityError
+}
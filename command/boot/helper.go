@@ -0,0 +1,51 @@
+package boot
+
+import (
+	"strconv"
+	"strings"
+
+	servicespec "github.com/the-anna-project/annad/spec/service"
+)
+
+// parseCLGPriorities parses the given comma separated list of CLG names and
+// priorities. Each CLG name is separated from its priority by a colon. E.g.
+// output:10,input:5 results in the following mapping.
+//
+//	map[string]int{
+//	  "input":  5,
+//	  "output": 10,
+//	}
+func parseCLGPriorities(s string) (map[string]int, error) {
+	priorities := map[string]int{}
+
+	if s == "" {
+		return priorities, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		split := strings.Split(pair, ":")
+		if len(split) != 2 || split[0] == "" {
+			return nil, maskAnyf(invalidCLGPriorityError, "%s", pair)
+		}
+		priority, err := strconv.Atoi(split[1])
+		if err != nil {
+			return nil, maskAnyf(invalidCLGPriorityError, "%s", pair)
+		}
+		priorities[split[0]] = priority
+	}
+
+	return priorities, nil
+}
+
+// streamCollection is a service collection providing its own random service.
+// All other services are the ones of the wrapped service collection. See
+// Command.newStreamCollection.
+type streamCollection struct {
+	servicespec.ServiceCollection
+
+	randomService servicespec.RandomService
+}
+
+func (c *streamCollection) Random() servicespec.RandomService {
+	return c.randomService
+}
@@ -0,0 +1,372 @@
+package boot
+
+import (
+	"net"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/the-anna-project/annad/object/networkevent"
+	textendpoint "github.com/the-anna-project/annad/server/service/text"
+	apispec "github.com/the-anna-project/annad/spec/api"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+)
+
+const (
+	// testBudgetEvents is the number of network events each CLG tree booted by
+	// the integration harness is allowed to cause.
+	testBudgetEvents = 10
+
+	// testTimeout is the duration the integration harness waits for the
+	// booted service collection to respond.
+	testTimeout = 10 * time.Second
+)
+
+// testHarness boots the service collection the boot command creates, using
+// memory storage, ephemeral ports and a deterministic seed. The text endpoint
+// is driven using a real gRPC client.
+type testHarness struct {
+	client     textendpoint.TextEndpointClient
+	collection servicespec.ServiceCollection
+	conn       *grpc.ClientConn
+}
+
+// newTestHarness boots a new service collection and connects a gRPC client to
+// its text endpoint.
+func newTestHarness(t *testing.T) *testHarness {
+	textAddress := testFreeAddress(t)
+
+	command := New()
+	newCmd := command.New()
+	for name, value := range map[string]string{
+		"endpoint.metric.address": testFreeAddress(t),
+		"endpoint.text.address":   textAddress,
+		"deterministic.seed":      "1",
+		"network.budget.events":   strconv.Itoa(testBudgetEvents),
+		"network.retry.backoff":   "0s",
+		"space.connection.weight": "1",
+		"storage.connection.kind": "memory",
+		"storage.feature.kind":    "memory",
+		"storage.general.kind":    "memory",
+		"storage.peer.kind":       "memory",
+	} {
+		err := newCmd.PersistentFlags().Set(name, value)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	collection := command.NewServiceCollection()
+	collection.Log().SetRootLogger(kitlog.NewNopLogger())
+	command.BootServiceCollection(collection)
+	collection.Endpoint().Boot()
+
+	conn, err := grpc.Dial(textAddress, grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(testTimeout))
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	h := &testHarness{
+		client:     textendpoint.NewTextEndpointClient(conn),
+		collection: collection,
+		conn:       conn,
+	}
+
+	return h
+}
+
+// Shutdown closes the gRPC client connection and shuts the service collection
+// down.
+func (h *testHarness) Shutdown() {
+	h.conn.Close()
+	h.collection.Shutdown()
+}
+
+// StreamText sends the given request through a new text stream and returns
+// the first output streamed back.
+func (h *testHarness) StreamText(t *testing.T, request *textendpoint.StreamTextRequest) string {
+	return h.StreamTextResponse(t, request).Data.Output
+}
+
+// StreamTextResponse works like StreamText, but returns the whole response.
+func (h *testHarness) StreamTextResponse(t *testing.T, request *textendpoint.StreamTextRequest) *textendpoint.StreamTextResponse {
+	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
+	defer cancel()
+
+	stream, err := h.client.StreamText(ctx)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	err = stream.Send(request)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	response, err := stream.Recv()
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	err = stream.CloseSend()
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	return response
+}
+
+// testFreeAddress returns a local address having an ephemeral port nobody
+// listens on.
+func testFreeAddress(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	defer l.Close()
+
+	return l.Addr().String()
+}
+
+// testLeakedGoroutines waits until there is no goroutine left executing code
+// of Anna's services, or the given timeout passed. The stacks of the remaining
+// goroutines executing code of Anna's services are returned. Goroutines of
+// third party packages which are known to not end, like the signal handler of
+// the HTTP server used by the metric endpoint or blocking commands of the
+// in-memory redis instance, are not considered.
+func testLeakedGoroutines(timeout time.Duration) []string {
+	deadline := time.Now().Add(timeout)
+	for {
+		buf := make([]byte, 1<<20)
+		buf = buf[:runtime.Stack(buf, true)]
+
+		var leaked []string
+		for _, g := range strings.Split(string(buf), "\n\n") {
+			if strings.Contains(g, "annad/service/") || strings.Contains(g, "vendor/github.com/the-anna-project/") {
+				leaked = append(leaked, g)
+			}
+		}
+
+		if len(leaked) == 0 || time.Now().After(deadline) {
+			return leaked
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func Test_Boot_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	h := newTestHarness(t)
+
+	t.Run("Echo", func(t *testing.T) {
+		output := h.StreamText(t, &textendpoint.StreamTextRequest{
+			Echo:      true,
+			Input:     "hello world",
+			SessionID: "integration-echo",
+		})
+		if output != "hello world" {
+			t.Fatal("expected", "hello world", "got", output)
+		}
+	})
+
+	t.Run("Candidates", func(t *testing.T) {
+		// The output of the CLG tree is streamed back as ranked candidate. Echo
+		// requests are calculated by the output CLG only, which is the only
+		// behaviour of the path. A path without hops and without history is rated
+		// by the success rate of unknown path patterns.
+		response := h.StreamTextResponse(t, &textendpoint.StreamTextRequest{
+			Echo:      true,
+			Input:     "hello world",
+			SessionID: "integration-candidates",
+		})
+		if response.Code != apispec.CodeData {
+			t.Fatal("expected", apispec.CodeData, "got", response.Code)
+		}
+		if response.Data.CLGTreeID == "" {
+			t.Fatal("expected", "CLG tree ID", "got", "")
+		}
+		if len(response.Data.Candidates) != 1 {
+			t.Fatal("expected", 1, "got", len(response.Data.Candidates))
+		}
+		candidate := response.Data.Candidates[0]
+		if candidate.Output != "hello world" {
+			t.Fatal("expected", "hello world", "got", candidate.Output)
+		}
+		if candidate.Score != 0.5 {
+			t.Fatal("expected", 0.5, "got", candidate.Score)
+		}
+		if len(candidate.BehaviourIDs) != 1 {
+			t.Fatal("expected", 1, "got", len(candidate.BehaviourIDs))
+		}
+	})
+
+	t.Run("Expectation_Met", func(t *testing.T) {
+		output := h.StreamText(t, &textendpoint.StreamTextRequest{
+			Echo:        true,
+			Expectation: "hello world",
+			Input:       "hello world",
+			SessionID:   "integration-expectation-met",
+		})
+		if output != "hello world" {
+			t.Fatal("expected", "hello world", "got", output)
+		}
+	})
+
+	t.Run("Expectation_Retries", func(t *testing.T) {
+		events, err := h.collection.Bus().Subscribe("integration")
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		defer h.collection.Bus().Unsubscribe("integration")
+
+		// The echoed input never meets the expectation. The output CLG retries
+		// by re-entering the CLG tree until its budget is exhausted. Then the
+		// terminal response is streamed back, which is not data calculated by the
+		// neural network.
+		response := h.StreamTextResponse(t, &textendpoint.StreamTextRequest{
+			Echo:        true,
+			Expectation: "goodbye world",
+			Input:       "hello world",
+			SessionID:   "integration-expectation-retries",
+		})
+		if response.Code != apispec.CodeBudgetExhausted {
+			t.Fatal("expected", apispec.CodeBudgetExhausted, "got", response.Code)
+		}
+		if response.Text != apispec.TextBudgetExhausted {
+			t.Fatal("expected", apispec.TextBudgetExhausted, "got", response.Text)
+		}
+		if response.Data.Output != "no answer within budget" {
+			t.Fatal("expected", "no answer within budget", "got", response.Data.Output)
+		}
+
+		var retries int
+		timeout := time.After(testTimeout)
+		for {
+			select {
+			case e := <-events:
+				if e.GetSessionID() != "integration-expectation-retries" {
+					continue
+				}
+				if e.GetKind() == networkevent.KindCalculated && e.GetCLGName() == "output" && e.GetError() != "" {
+					retries++
+				}
+				if e.GetKind() != networkevent.KindTreeFinished {
+					continue
+				}
+				if retries != testBudgetEvents {
+					t.Fatal("expected", testBudgetEvents, "got", retries)
+				}
+				return
+			case <-timeout:
+				t.Fatal("expected", "tree to finish", "got", "timeout")
+			}
+		}
+	})
+
+	t.Run("Expectation_Retries_Exhausted", func(t *testing.T) {
+		// The echoed input never meets the expectation. As soon as the retry limit
+		// of the request is exceeded, the output coming closest to the expectation
+		// is streamed back.
+		response := h.StreamTextResponse(t, &textendpoint.StreamTextRequest{
+			Backoff:            "1ms",
+			Echo:               true,
+			Expectation:        "hello world!!!!!",
+			ExpectationMatcher: "edit-distance",
+			Input:              "hello world",
+			Retries:            2,
+			SessionID:          "integration-expectation-retries-exhausted",
+		})
+		if response.Code != apispec.CodeRetriesExhausted {
+			t.Fatal("expected", apispec.CodeRetriesExhausted, "got", response.Code)
+		}
+		if response.Text != apispec.TextRetriesExhausted {
+			t.Fatal("expected", apispec.TextRetriesExhausted, "got", response.Text)
+		}
+		if response.Data.Output != "hello world" {
+			t.Fatal("expected", "hello world", "got", response.Data.Output)
+		}
+		if response.Data.Attempts != 3 {
+			t.Fatal("expected", 3, "got", response.Data.Attempts)
+		}
+		if response.Data.Score != 0.6875 {
+			t.Fatal("expected", 0.6875, "got", response.Data.Score)
+		}
+	})
+
+	h.Shutdown()
+
+	leaked := testLeakedGoroutines(testTimeout)
+	if len(leaked) != 0 {
+		t.Fatal("expected", 0, "got", len(leaked), "leaked goroutines:\n", strings.Join(leaked, "\n\n"))
+	}
+}
+
+func Test_Boot_Integration_Deterministic(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	// The requests cover the input and event workers, the deadline and retry
+	// timers and the candidates window, as well as the tracker, which creates
+	// peers at random positions while it handles network events published by
+	// the bus.
+	requests := []*textendpoint.StreamTextRequest{
+		{
+			Echo:      true,
+			Input:     "hello world",
+			SessionID: "integration-deterministic-echo",
+		},
+		{
+			Deadline:  "100ms",
+			Input:     "hello world",
+			SessionID: "integration-deterministic-input",
+		},
+		{
+			Backoff:            "1ms",
+			Echo:               true,
+			Expectation:        "hello world!!!!!",
+			ExpectationMatcher: "edit-distance",
+			Input:              "hello world",
+			Retries:            2,
+			SessionID:          "integration-deterministic-retries",
+		},
+		{
+			Echo:      true,
+			Input:     "hello world",
+			SessionID: "integration-deterministic-echo",
+		},
+	}
+
+	newResponses := func() []*textendpoint.StreamTextResponse {
+		h := newTestHarness(t)
+		defer h.Shutdown()
+
+		var responses []*textendpoint.StreamTextResponse
+		for _, request := range requests {
+			responses = append(responses, h.StreamTextResponse(t, request))
+		}
+
+		return responses
+	}
+
+	// Booting the neural network twice using the same seed results in the same
+	// responses, including the IDs of the CLG trees and the behaviours having
+	// calculated the candidates.
+	responses1 := newResponses()
+	responses2 := newResponses()
+	for i := range requests {
+		if len(responses1[i].Data.Candidates) != 0 && responses1[i].Data.CLGTreeID == "" {
+			t.Fatal("case", i+1, "expected", "CLG tree ID", "got", "")
+		}
+		if !reflect.DeepEqual(responses1[i], responses2[i]) {
+			t.Fatal("case", i+1, "expected", responses1[i], "got", responses2[i])
+		}
+	}
+}
@@ -2,48 +2,58 @@ package boot
 
 import (
 	"os"
+	"strings"
 
 	"github.com/cenk/backoff"
 	"github.com/garyburd/redigo/redis"
 	kitlog "github.com/go-kit/kit/log"
 
+	servicecollection "github.com/the-anna-project/annad/collection/collection"
+	connectionservice "github.com/the-anna-project/annad/connection/service"
+	memoryfs "github.com/the-anna-project/annad/fs/memory"
+	"github.com/the-anna-project/annad/id"
+	inputcollection "github.com/the-anna-project/annad/input/collection"
+	textinputservice "github.com/the-anna-project/annad/input/service/text"
+	"github.com/the-anna-project/annad/instrumentor/prometheus"
+	layercollection "github.com/the-anna-project/annad/layer/collection"
+	layerservice "github.com/the-anna-project/annad/layer/service"
+	"github.com/the-anna-project/annad/log"
+	outputcollection "github.com/the-anna-project/annad/output/collection"
+	textoutputservice "github.com/the-anna-project/annad/output/service/text"
+	peerservice "github.com/the-anna-project/annad/peer/service"
+	"github.com/the-anna-project/annad/permutation/service"
+	positionservice "github.com/the-anna-project/annad/position/service"
+	"github.com/the-anna-project/annad/random"
+	endpointcollection "github.com/the-anna-project/annad/server/collection"
+	metricendpoint "github.com/the-anna-project/annad/server/service/metric"
+	textendpoint "github.com/the-anna-project/annad/server/service/text"
 	"github.com/the-anna-project/annad/service/activator"
+	"github.com/the-anna-project/annad/service/behaviour"
+	"github.com/the-anna-project/annad/service/bus"
 	"github.com/the-anna-project/annad/service/feature"
 	"github.com/the-anna-project/annad/service/forwarder"
+	hashid "github.com/the-anna-project/annad/service/id"
 	"github.com/the-anna-project/annad/service/network"
+	seededrandom "github.com/the-anna-project/annad/service/random"
 	"github.com/the-anna-project/annad/service/tracker"
-	servicecollection "github.com/the-anna-project/collection/collection"
-	connectionservice "github.com/the-anna-project/connection/service"
-	memoryfs "github.com/the-anna-project/fs/memory"
-	"github.com/the-anna-project/id"
-	inputcollection "github.com/the-anna-project/input/collection"
-	textinputservice "github.com/the-anna-project/input/service/text"
-	"github.com/the-anna-project/instrumentor/prometheus"
-	layercollection "github.com/the-anna-project/layer/collection"
-	layerservice "github.com/the-anna-project/layer/service"
-	"github.com/the-anna-project/log"
-	outputcollection "github.com/the-anna-project/output/collection"
-	textoutputservice "github.com/the-anna-project/output/service/text"
-	peerservice "github.com/the-anna-project/peer/service"
-	"github.com/the-anna-project/permutation/service"
-	positionservice "github.com/the-anna-project/position/service"
-	"github.com/the-anna-project/random"
-	endpointcollection "github.com/the-anna-project/server/collection"
-	metricendpoint "github.com/the-anna-project/server/service/metric"
-	textendpoint "github.com/the-anna-project/server/service/text"
-	objectspec "github.com/the-anna-project/spec/object"
-	servicespec "github.com/the-anna-project/spec/service"
-	storagecollection "github.com/the-anna-project/storage/collection"
-	memorystorage "github.com/the-anna-project/storage/service/memory"
-	redisstorage "github.com/the-anna-project/storage/service/redis"
-	workerservice "github.com/the-anna-project/worker/service"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+	storagecollection "github.com/the-anna-project/annad/storage/collection"
+	memorystorage "github.com/the-anna-project/annad/storage/service/memory"
+	redisstorage "github.com/the-anna-project/annad/storage/service/redis"
+	workerservice "github.com/the-anna-project/annad/worker/service"
 )
 
-func (c *Command) newServiceCollection() servicespec.ServiceCollection {
+// NewServiceCollection creates the service collection of the neural network as
+// configured by the flags of the boot command. All services are wired with each
+// other, but not booted. See BootServiceCollection.
+func (c *Command) NewServiceCollection() servicespec.ServiceCollection {
 	// Set.
 	collection := servicecollection.New()
 
 	collection.SetActivatorService(c.newActivatorService())
+	collection.SetBehaviourService(c.newBehaviourService())
+	collection.SetBusService(c.newBusService())
 	collection.SetConnectionService(c.newConnectionService())
 	collection.SetEndpointCollection(c.newEndpointCollection())
 	collection.SetFeatureService(c.newFeatureService())
@@ -64,7 +74,16 @@ func (c *Command) newServiceCollection() servicespec.ServiceCollection {
 	collection.SetTrackerService(c.newTrackerService())
 	collection.SetWorkerService(c.newWorkerService())
 
+	// The ID service is wrapped first, because it is given the random service
+	// not being recorded. See journal.Recorder.IDService.
+	if c.recorder != nil {
+		collection.SetIDService(c.recorder.IDService(collection.ID(), collection.Random()))
+		collection.SetRandomService(c.recorder.RandomService(collection.Random()))
+	}
+
 	collection.Activator().SetServiceCollection(collection)
+	collection.Behaviour().SetServiceCollection(collection)
+	collection.Bus().SetServiceCollection(collection)
 	collection.Connection().SetServiceCollection(collection)
 	collection.Endpoint().Metric().SetServiceCollection(collection)
 	collection.Endpoint().Text().SetServiceCollection(collection)
@@ -76,12 +95,13 @@ func (c *Command) newServiceCollection() servicespec.ServiceCollection {
 	collection.Instrumentor().SetServiceCollection(collection)
 	collection.Layer().Behaviour().SetServiceCollection(collection)
 	collection.Layer().Information().SetServiceCollection(collection)
+	collection.Layer().Position().SetServiceCollection(collection)
 	collection.Log().SetServiceCollection(collection)
 	collection.Network().SetServiceCollection(collection)
 	collection.Output().Text().SetServiceCollection(collection)
 	collection.Peer().SetServiceCollection(collection)
 	collection.Permutation().SetServiceCollection(collection)
-	collection.Position().SetServiceCollection(collection)
+	collection.Position().SetServiceCollection(c.newStreamCollection(collection, "position"))
 	collection.Random().SetServiceCollection(collection)
 	collection.Storage().Connection().SetServiceCollection(collection)
 	collection.Storage().Feature().SetServiceCollection(collection)
@@ -93,8 +113,71 @@ func (c *Command) newServiceCollection() servicespec.ServiceCollection {
 	return collection
 }
 
+// BootServiceCollection boots the services of the given service collection in
+// the order of their dependencies. Services like the storage have to be booted
+// before other services use them while booting themselves. E.g. the network
+// recovers scheduled network events from the storage. The endpoints are not
+// booted, so the caller decides whether requests are accepted, as soon as the
+// neural network is ready.
+func (c *Command) BootServiceCollection(collection servicespec.ServiceCollection) {
+	collection.Random().Boot()
+	collection.ID().Boot()
+	collection.Log().Boot()
+	collection.Instrumentor().Boot()
+	collection.Storage().Connection().Boot()
+	collection.Storage().Feature().Boot()
+	collection.Storage().General().Boot()
+	collection.Storage().Peer().Boot()
+	collection.FS().Boot()
+	collection.Worker().Boot()
+	collection.Input().Text().Boot()
+	collection.Output().Text().Boot()
+	collection.Layer().Behaviour().Boot()
+	collection.Layer().Information().Boot()
+	collection.Layer().Position().Boot()
+	collection.Connection().Boot()
+	collection.Peer().Boot()
+	collection.Permutation().Boot()
+	collection.Position().Boot()
+	collection.Behaviour().Boot()
+	collection.Bus().Boot()
+	collection.Feature().Boot()
+	collection.Forwarder().Boot()
+	collection.Tracker().Boot()
+	collection.Activator().Boot()
+	collection.Network().Boot()
+}
+
 func (c *Command) newActivatorService() servicespec.ActivatorService {
-	return activator.New()
+	config := activator.DefaultConfig()
+	config.QueueDeadLetter = c.configCollection.Activator().Queue().DeadLetter()
+	config.QueueSweepInterval = c.configCollection.Activator().Queue().SweepInterval()
+	config.QueueTTL = c.configCollection.Activator().Queue().TTL()
+	config.Strategies = strings.Split(c.configCollection.Activator().Lookup().Strategies(), ",")
+
+	activatorService, err := activator.New(config)
+	if err != nil {
+		panic(err)
+	}
+
+	return activatorService
+}
+
+func (c *Command) newBehaviourService() servicespec.BehaviourService {
+	return behaviour.New()
+}
+
+func (c *Command) newBusService() servicespec.BusService {
+	config := bus.DefaultConfig()
+	config.BufferSize = c.configCollection.Bus().Buffer()
+	config.Tracing = c.configCollection.Bus().Tracing()
+
+	busService, err := bus.New(config)
+	if err != nil {
+		panic(err)
+	}
+
+	return busService
 }
 
 func (c *Command) newConnectionService() servicespec.ConnectionService {
@@ -144,6 +227,18 @@ func (c *Command) newFSService() servicespec.FSService {
 }
 
 func (c *Command) newIDService() servicespec.IDService {
+	if c.configCollection.Deterministic().Seed() != 0 {
+		config := hashid.DefaultConfig()
+		config.Seed = c.configCollection.Deterministic().Seed()
+
+		idService, err := hashid.New(config)
+		if err != nil {
+			panic(err)
+		}
+
+		return idService
+	}
+
 	return id.New()
 }
 
@@ -212,7 +307,60 @@ func (c *Command) newLogService() servicespec.LogService {
 }
 
 func (c *Command) newNetworkService() servicespec.NetworkService {
-	return network.New()
+	priorityCLGs, err := parseCLGPriorities(c.configCollection.Network().Priority().CLG())
+	if err != nil {
+		panic(err)
+	}
+
+	config := network.DefaultConfig()
+	config.BudgetDeadline = c.configCollection.Network().Budget().Deadline()
+	config.BudgetDepth = c.configCollection.Network().Budget().Depth()
+	config.BudgetEvents = c.configCollection.Network().Budget().Events()
+	config.CandidatesLimit = c.configCollection.Network().Candidates().Limit()
+	config.CandidatesWindow = c.configCollection.Network().Candidates().Window()
+	config.CLGCacheSize = c.configCollection.Network().CLG().CacheSize()
+	config.CLGQuarantineDuration = c.configCollection.Network().CLG().QuarantineDuration()
+	config.CLGQuarantineThreshold = c.configCollection.Network().CLG().QuarantineThreshold()
+	config.CLGTimeout = c.configCollection.Network().CLG().Timeout()
+	config.Frozen = c.configCollection.Network().Mode().Frozen()
+	config.PriorityAging = c.configCollection.Network().Priority().Aging()
+	config.PriorityCLGs = priorityCLGs
+	config.PriorityDepth = c.configCollection.Network().Priority().Depth()
+	config.PriorityInteractive = c.configCollection.Network().Priority().Interactive()
+	config.PriorityTraining = c.configCollection.Network().Priority().Training()
+	config.RetryBackoff = c.configCollection.Network().Retry().Backoff()
+	config.RetryBackoffMax = c.configCollection.Network().Retry().BackoffMax()
+	config.RetryLimit = c.configCollection.Network().Retry().Limit()
+	config.WorkersAutoscale = c.configCollection.Network().Workers().Autoscale()
+	config.WorkersAutoscaleInterval = c.configCollection.Network().Workers().AutoscaleInterval()
+	config.WorkersAutoscaleLatency = c.configCollection.Network().Workers().AutoscaleLatency()
+	config.WorkersAutoscaleMax = c.configCollection.Network().Workers().AutoscaleMax()
+	config.WorkersAutoscaleMin = c.configCollection.Network().Workers().AutoscaleMin()
+	config.WorkersEvent = c.configCollection.Network().Workers().Event()
+	config.WorkersInput = c.configCollection.Network().Workers().Input()
+
+	// The recorder records the text input the network receives and the text
+	// output it sends.
+	if c.recorder != nil {
+		config.Journal = c.recorder
+	}
+
+	// Random numbers and IDs are only reproducible as long as they are drawn in
+	// the same order. Thus the deterministic mode and the journal recording
+	// handle one input and one network event at a time, no matter how many
+	// workers are configured. Replaying a journal does the same.
+	if c.configCollection.Deterministic().Seed() != 0 || c.recorder != nil {
+		config.WorkersAutoscale = false
+		config.WorkersEvent = 1
+		config.WorkersInput = 1
+	}
+
+	networkService, err := network.New(config)
+	if err != nil {
+		panic(err)
+	}
+
+	return networkService
 }
 
 func (c *Command) newOutputCollection() servicespec.OutputCollection {
@@ -247,6 +395,18 @@ func (c *Command) newPositionService() servicespec.PositionService {
 }
 
 func (c *Command) newRandomService() servicespec.RandomService {
+	if c.configCollection.Deterministic().Seed() != 0 {
+		config := seededrandom.DefaultConfig()
+		config.Seed = c.configCollection.Deterministic().Seed()
+
+		randomService, err := seededrandom.New(config)
+		if err != nil {
+			panic(err)
+		}
+
+		return randomService
+	}
+
 	randomService := random.New()
 
 	randomService.SetBackoffFactory(c.newBackoffFactory())
@@ -326,8 +486,47 @@ func (c *Command) newStorageCollection() servicespec.StorageCollection {
 	return newCollection
 }
 
+// newStreamCollection returns the service collection used by subsystems drawing
+// random numbers outside of the network workers. E.g. the position service
+// draws the positions of the peers the tracker creates while it handles
+// network events published by the bus. In deterministic mode these subsystems
+// draw from their own stream of random numbers, which is named by the given
+// stream. That way they do not change the random numbers the network workers
+// draw, no matter when the bus delivers network events. Otherwise the given
+// service collection is returned.
+func (c *Command) newStreamCollection(collection servicespec.ServiceCollection, stream string) servicespec.ServiceCollection {
+	if c.configCollection.Deterministic().Seed() == 0 {
+		return collection
+	}
+
+	config := seededrandom.DefaultConfig()
+	config.Seed = c.configCollection.Deterministic().Seed()
+	config.Stream = stream
+
+	randomService, err := seededrandom.New(config)
+	if err != nil {
+		panic(err)
+	}
+	randomService.SetServiceCollection(collection)
+
+	newCollection := &streamCollection{
+		ServiceCollection: collection,
+		randomService:     randomService,
+	}
+
+	return newCollection
+}
+
 func (c *Command) newTrackerService() servicespec.TrackerService {
-	return tracker.New()
+	config := tracker.DefaultConfig()
+	config.PatternLength = c.configCollection.Tracker().Pattern().Length()
+
+	trackerService, err := tracker.New(config)
+	if err != nil {
+		panic(err)
+	}
+
+	return trackerService
 }
 
 func (c *Command) newWorkerService() servicespec.WorkerService {
@@ -4,6 +4,9 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/the-anna-project/annad/command/boot"
+	"github.com/the-anna-project/annad/command/eval"
+	"github.com/the-anna-project/annad/command/replay"
+	"github.com/the-anna-project/annad/command/train"
 	"github.com/the-anna-project/annad/command/version"
 )
 
@@ -12,6 +15,9 @@ func New() *Command {
 	command := &Command{}
 
 	command.SetBootCommand(boot.New())
+	command.SetEvalCommand(eval.New())
+	command.SetReplayCommand(replay.New())
+	command.SetTrainCommand(train.New())
 	command.SetVersionCommand(version.New())
 
 	return command
@@ -22,6 +28,9 @@ type Command struct {
 	// Dependencies.
 
 	bootCommand    *boot.Command
+	evalCommand    *eval.Command
+	replayCommand  *replay.Command
+	trainCommand   *train.Command
 	versionCommand *version.Command
 }
 
@@ -40,6 +49,9 @@ func (c *Command) New() *cobra.Command {
 	}
 
 	newCommand.AddCommand(c.bootCommand.New())
+	newCommand.AddCommand(c.evalCommand.New())
+	newCommand.AddCommand(c.replayCommand.New())
+	newCommand.AddCommand(c.trainCommand.New())
 	newCommand.AddCommand(c.versionCommand.New())
 
 	return newCommand
@@ -50,16 +62,46 @@ func (c *Command) BootCommand() *boot.Command {
 	return c.bootCommand
 }
 
+// EvalCommand returns the eval subcommand of the annad command.
+func (c *Command) EvalCommand() *eval.Command {
+	return c.evalCommand
+}
+
+// ReplayCommand returns the replay subcommand of the annad command.
+func (c *Command) ReplayCommand() *replay.Command {
+	return c.replayCommand
+}
+
 // SetBootCommand sets the boot subcommand for the annad command.
 func (c *Command) SetBootCommand(command *boot.Command) {
 	c.bootCommand = command
 }
 
+// SetEvalCommand sets the eval subcommand for the annad command.
+func (c *Command) SetEvalCommand(command *eval.Command) {
+	c.evalCommand = command
+}
+
+// SetReplayCommand sets the replay subcommand for the annad command.
+func (c *Command) SetReplayCommand(command *replay.Command) {
+	c.replayCommand = command
+}
+
+// SetTrainCommand sets the train subcommand for the annad command.
+func (c *Command) SetTrainCommand(command *train.Command) {
+	c.trainCommand = command
+}
+
 // SetVersionCommand sets the version subcommand for the annad command.
 func (c *Command) SetVersionCommand(command *version.Command) {
 	c.versionCommand = command
 }
 
+// TrainCommand returns the train subcommand of the annad command.
+func (c *Command) TrainCommand() *train.Command {
+	return c.trainCommand
+}
+
 // VersionCommand returns the version subcommand of the annad command.
 func (c *Command) VersionCommand() *version.Command {
 	return c.versionCommand
@@ -0,0 +1,172 @@
+package eval
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/spf13/cobra"
+
+	"github.com/the-anna-project/annad/command/boot"
+	"github.com/the-anna-project/annad/service/dataset"
+)
+
+// New creates a new eval command.
+func New() *Command {
+	command := &Command{}
+
+	command.SetBootCommand(boot.New())
+
+	return command
+}
+
+// Command represents the eval command.
+type Command struct {
+	// Dependencies.
+
+	// bootCommand creates the neural network being evaluated within the current
+	// process. Its flags are part of the eval command.
+	bootCommand *boot.Command
+
+	// Settings.
+
+	budget      time.Duration
+	concurrency int
+	format      string
+	output      string
+	remote      bool
+}
+
+// Execute represents the cobra run method.
+func (c *Command) Execute(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.HelpFunc()(cmd, nil)
+		os.Exit(1)
+	}
+	if c.output != OutputJSON && c.output != OutputText {
+		panic(maskAnyf(invalidOutputError, "'%s'", c.output))
+	}
+
+	// Merge the parsed flags with their counterparts of the config file and the
+	// process environment, the same way the boot command does.
+	err := c.bootCommand.ConfigCollection().Merge(cmd.Flags())
+	if err != nil {
+		panic(err)
+	}
+
+	examples, err := c.readDataset(args[0])
+	if err != nil {
+		panic(err)
+	}
+
+	results, err := c.Eval(examples)
+	if err != nil {
+		panic(err)
+	}
+
+	err = writeReport(os.Stdout, dataset.Evaluate(results), c.output)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// New creates a new cobra command for the eval command.
+func (c *Command) New() *cobra.Command {
+	newCmd := &cobra.Command{
+		Use:   "eval <dataset>",
+		Short: "Evaluate the neural network using a dataset.",
+		Long:  "Evaluate the neural network using a dataset of input and expected output pairs, which should be held out of training. The dataset formats are the same as the ones of the train command. Other than training, evaluating does not let the neural network learn. By default the neural network is booted within the current process in frozen mode, configured by the same flags as the boot command, so the storage flags should point to the storage of a trained neural network. Using the eval.remote flag a running daemon is evaluated instead, using its text and metric endpoints. It only does not learn in case it was booted using the network.mode.frozen flag.",
+		Run:   c.Execute,
+	}
+
+	newCmd.PersistentFlags().AddFlagSet(c.bootCommand.New().PersistentFlags())
+
+	newCmd.PersistentFlags().DurationVar(&c.budget, "eval.budget", 30*time.Second, "duration the neural network is given to meet the expectation of an example not providing its own budget")
+	newCmd.PersistentFlags().IntVar(&c.concurrency, "eval.concurrency", 1, "maximum number of examples being evaluated at the same time")
+	newCmd.PersistentFlags().StringVar(&c.format, "eval.format", "", "format of the dataset (e.g. csv, jsonl), empty detects the format using the file extension")
+	newCmd.PersistentFlags().StringVar(&c.output, "eval.output", OutputText, "format of the report (e.g. json, text)")
+	newCmd.PersistentFlags().BoolVar(&c.remote, "eval.remote", false, "whether to evaluate a running daemon reachable using the endpoint flags instead of booting the neural network within the current process")
+
+	return newCmd
+}
+
+// SetBootCommand sets the boot command for the eval command to create the
+// neural network being evaluated within the current process.
+func (c *Command) SetBootCommand(command *boot.Command) {
+	c.bootCommand = command
+}
+
+// Eval runs the given examples once against the neural network and returns
+// the results. The neural network booted within the current process is always
+// frozen.
+func (c *Command) Eval(examples []dataset.Example) ([]dataset.Result, error) {
+	configCollection := c.bootCommand.ConfigCollection()
+
+	var newNetwork dataset.Network
+	if c.remote {
+		newRemoteConfig := dataset.DefaultRemoteConfig()
+		newRemoteConfig.MetricAddress = configCollection.Endpoint().Metric().Address()
+		newRemoteConfig.TextAddress = configCollection.Endpoint().Text().Address()
+		var err error
+		newNetwork, err = dataset.NewRemoteNetwork(newRemoteConfig)
+		if err != nil {
+			return nil, maskAny(err)
+		}
+	} else {
+		frozen := true
+		configCollection.Network().Mode().SetFrozen(&frozen)
+
+		// The endpoints are not booted. The examples are fed into the neural
+		// network directly.
+		collection := c.bootCommand.NewServiceCollection()
+		collection.Log().SetRootLogger(kitlog.NewNopLogger())
+		c.bootCommand.BootServiceCollection(collection)
+		defer collection.Shutdown()
+
+		var err error
+		newNetwork, err = dataset.NewLocalNetwork(collection)
+		if err != nil {
+			return nil, maskAny(err)
+		}
+	}
+	defer newNetwork.Close()
+
+	newRunnerConfig := dataset.DefaultRunnerConfig()
+	newRunnerConfig.Budget = c.budget
+	newRunnerConfig.Concurrency = c.concurrency
+	newRunnerConfig.Network = newNetwork
+	newRunnerConfig.SessionID = fmt.Sprintf("eval-%d", time.Now().UnixNano())
+	newRunner, err := dataset.NewRunner(newRunnerConfig)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	results, err := newRunner.Run(examples)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	return results, nil
+}
+
+// readDataset reads the examples of the dataset stored in the given file.
+func (c *Command) readDataset(file string) ([]dataset.Example, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+	defer f.Close()
+
+	format := c.format
+	if format == "" {
+		format = dataset.Format(file)
+	}
+
+	examples, err := dataset.Read(f, format)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	return examples, nil
+}
@@ -0,0 +1,53 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/the-anna-project/annad/service/dataset"
+)
+
+const (
+	// OutputJSON is the output format writing the evaluation as JSON object.
+	OutputJSON = "json"
+	// OutputText is the output format writing the evaluation human readable.
+	OutputText = "text"
+)
+
+// writeReport writes the given evaluation to the given writer, using the given
+// output format.
+func writeReport(w io.Writer, evaluation dataset.Evaluation, output string) error {
+	switch output {
+	case OutputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		err := encoder.Encode(evaluation)
+		if err != nil {
+			return maskAny(err)
+		}
+	case OutputText:
+		writeText(w, evaluation)
+	default:
+		return maskAnyf(invalidOutputError, "'%s'", output)
+	}
+
+	return nil
+}
+
+// writeText writes the exact match rate, the latency percentiles, the
+// involvement of each CLG and the confused outputs of the given evaluation.
+func writeText(w io.Writer, evaluation dataset.Evaluation) {
+	fmt.Fprintf(w, "Exact matches: %.2f%% (%d/%d)\n", 100*evaluation.ExactMatchRate, evaluation.ExactMatches, evaluation.Examples)
+	fmt.Fprintf(w, "Latency: p50 %s, p90 %s, p99 %s, max %s\n", evaluation.Latency.P50, evaluation.Latency.P90, evaluation.Latency.P99, evaluation.Latency.Max)
+
+	fmt.Fprintf(w, "CLG involvement:\n")
+	for _, c := range evaluation.CLGs {
+		fmt.Fprintf(w, "  %s: %d examples, %d calculations\n", c.Name, c.Examples, c.Calculations)
+	}
+
+	fmt.Fprintf(w, "Confusions:\n")
+	for _, c := range evaluation.Confusions {
+		fmt.Fprintf(w, "  %q -> %q: %d\n", c.Expectation, c.Output, c.Count)
+	}
+}
@@ -0,0 +1,115 @@
+package replay
+
+import (
+	"fmt"
+	"os"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/spf13/cobra"
+
+	"github.com/the-anna-project/annad/command/boot"
+	"github.com/the-anna-project/annad/service/journal"
+)
+
+// New creates a new replay command.
+func New() *Command {
+	return &Command{}
+}
+
+// Command represents the replay command.
+type Command struct{}
+
+// Execute represents the cobra run method.
+func (c *Command) Execute(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.HelpFunc()(cmd, nil)
+		os.Exit(1)
+	}
+
+	entries, err := c.Replay(args[0])
+	if journal.IsDivergence(err) {
+		fmt.Printf("Replay diverged: %s\n", err)
+		os.Exit(1)
+	} else if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("Replay matched the journal: %s\n", journal.Summary(entries))
+}
+
+// New creates a new cobra command for the replay command.
+func (c *Command) New() *cobra.Command {
+	newCmd := &cobra.Command{
+		Use:   "replay <journal>",
+		Short: "Replay a journal recorded by the anna daemon.",
+		Long:  "Replay a journal recorded by the anna daemon using the journal.record flag of the boot command. The neural network is rebuilt using the recorded flags and uses the journal as the source of random numbers and IDs. The recorded input is fed into the neural network and the produced output is checked against the recorded output. The first divergence is reported.",
+		Run:   c.Execute,
+	}
+
+	return newCmd
+}
+
+// Replay replays the journal read from the given file. The entries of the
+// journal are returned. In case the replay diverged, the returned error is the
+// divergence. See journal.IsDivergence.
+func (c *Command) Replay(file string) ([]journal.Entry, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+	defer f.Close()
+
+	entries, err := journal.Read(f)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	// The service collection is created by a boot command configured using the
+	// recorded flags. Flags not being recorded keep their defaults, regardless
+	// of any config file or process environment.
+	bootCommand := boot.New()
+	bootCmd := bootCommand.New()
+	for name, value := range journal.Flags(entries) {
+		err := bootCmd.PersistentFlags().Set(name, value)
+		if err != nil {
+			return nil, maskAny(err)
+		}
+	}
+
+	// The journal was recorded handling one input and one network event at a
+	// time. Otherwise the recorded random numbers and IDs would be served in
+	// another order than they were drawn.
+	for name, value := range map[string]string{"network.workers.autoscale": "false", "network.workers.event": "1", "network.workers.input": "1"} {
+		err := bootCmd.PersistentFlags().Set(name, value)
+		if err != nil {
+			return nil, maskAny(err)
+		}
+	}
+	collection := bootCommand.NewServiceCollection()
+	collection.Log().SetRootLogger(kitlog.NewNopLogger())
+
+	newReplayerConfig := journal.DefaultReplayerConfig()
+	newReplayerConfig.Entries = entries
+	newReplayerConfig.IDService = collection.ID()
+	newReplayerConfig.RandomService = collection.Random()
+	newReplayer, err := journal.NewReplayer(newReplayerConfig)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+	collection.SetIDService(newReplayer.IDService())
+	collection.SetRandomService(newReplayer.RandomService())
+	collection.ID().SetServiceCollection(collection)
+	collection.Random().SetServiceCollection(collection)
+
+	// The endpoints are not booted. The replayer feeds the recorded input into
+	// the neural network itself.
+	bootCommand.BootServiceCollection(collection)
+	defer collection.Shutdown()
+
+	err = newReplayer.Replay(collection)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	return entries, nil
+}
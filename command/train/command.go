@@ -0,0 +1,173 @@
+package train
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+	"github.com/spf13/cobra"
+
+	"github.com/the-anna-project/annad/command/boot"
+	"github.com/the-anna-project/annad/service/dataset"
+)
+
+// New creates a new train command.
+func New() *Command {
+	command := &Command{}
+
+	command.SetBootCommand(boot.New())
+
+	return command
+}
+
+// Command represents the train command.
+type Command struct {
+	// Dependencies.
+
+	// bootCommand creates the neural network being trained within the current
+	// process. Its flags are part of the train command.
+	bootCommand *boot.Command
+
+	// Settings.
+
+	budget      time.Duration
+	concurrency int
+	epochs      int
+	format      string
+	remote      bool
+	shuffle     bool
+}
+
+// Execute represents the cobra run method.
+func (c *Command) Execute(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.HelpFunc()(cmd, nil)
+		os.Exit(1)
+	}
+
+	// Merge the parsed flags with their counterparts of the config file and the
+	// process environment, the same way the boot command does.
+	err := c.bootCommand.ConfigCollection().Merge(cmd.Flags())
+	if err != nil {
+		panic(err)
+	}
+
+	examples, err := c.readDataset(args[0])
+	if err != nil {
+		panic(err)
+	}
+
+	results, err := c.Train(examples)
+	if err != nil {
+		panic(err)
+	}
+
+	writeReport(os.Stdout, examples, results)
+}
+
+// New creates a new cobra command for the train command.
+func (c *Command) New() *cobra.Command {
+	newCmd := &cobra.Command{
+		Use:   "train <dataset>",
+		Short: "Train the neural network using a dataset.",
+		Long:  "Train the neural network using a dataset of input and expected output pairs. Datasets are given as JSON Lines, one object having the fields input, expectation and optionally backoff, budget, echo, matcher, outputs, retries and threshold per line, or as CSV having the columns input, expectation and optionally budget. By default the neural network is booted within the current process, configured by the same flags as the boot command. Using the train.remote flag a running daemon is trained instead, using its text and metric endpoints.",
+		Run:   c.Execute,
+	}
+
+	newCmd.PersistentFlags().AddFlagSet(c.bootCommand.New().PersistentFlags())
+
+	newCmd.PersistentFlags().DurationVar(&c.budget, "train.budget", 30*time.Second, "duration the neural network is given to meet the expectation of an example not providing its own budget")
+	newCmd.PersistentFlags().IntVar(&c.concurrency, "train.concurrency", 1, "maximum number of examples being trained at the same time")
+	newCmd.PersistentFlags().IntVar(&c.epochs, "train.epochs", 1, "number of times all examples of the dataset are trained")
+	newCmd.PersistentFlags().StringVar(&c.format, "train.format", "", "format of the dataset (e.g. csv, jsonl), empty detects the format using the file extension")
+	newCmd.PersistentFlags().BoolVar(&c.remote, "train.remote", false, "whether to train a running daemon reachable using the endpoint flags instead of booting the neural network within the current process")
+	newCmd.PersistentFlags().BoolVar(&c.shuffle, "train.shuffle", false, "whether to train the examples in a different random order each epoch")
+
+	return newCmd
+}
+
+// SetBootCommand sets the boot command for the train command to create the
+// neural network being trained within the current process.
+func (c *Command) SetBootCommand(command *boot.Command) {
+	c.bootCommand = command
+}
+
+// Train runs the given examples against the neural network for the configured
+// number of epochs and returns the results.
+func (c *Command) Train(examples []dataset.Example) ([]dataset.Result, error) {
+	configCollection := c.bootCommand.ConfigCollection()
+
+	var newNetwork dataset.Network
+	if c.remote {
+		newRemoteConfig := dataset.DefaultRemoteConfig()
+		newRemoteConfig.MetricAddress = configCollection.Endpoint().Metric().Address()
+		newRemoteConfig.TextAddress = configCollection.Endpoint().Text().Address()
+		var err error
+		newNetwork, err = dataset.NewRemoteNetwork(newRemoteConfig)
+		if err != nil {
+			return nil, maskAny(err)
+		}
+	} else {
+		// The endpoints are not booted. The examples are fed into the neural
+		// network directly.
+		collection := c.bootCommand.NewServiceCollection()
+		collection.Log().SetRootLogger(kitlog.NewNopLogger())
+		c.bootCommand.BootServiceCollection(collection)
+		defer collection.Shutdown()
+
+		var err error
+		newNetwork, err = dataset.NewLocalNetwork(collection)
+		if err != nil {
+			return nil, maskAny(err)
+		}
+	}
+	defer newNetwork.Close()
+
+	// The order of shuffled examples is reproducible in deterministic mode.
+	seed := configCollection.Deterministic().Seed()
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	newRunnerConfig := dataset.DefaultRunnerConfig()
+	newRunnerConfig.Budget = c.budget
+	newRunnerConfig.Concurrency = c.concurrency
+	newRunnerConfig.Epochs = c.epochs
+	newRunnerConfig.Network = newNetwork
+	newRunnerConfig.Seed = seed
+	newRunnerConfig.SessionID = fmt.Sprintf("train-%d", time.Now().UnixNano())
+	newRunnerConfig.Shuffle = c.shuffle
+	newRunner, err := dataset.NewRunner(newRunnerConfig)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	results, err := newRunner.Run(examples)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	return results, nil
+}
+
+// readDataset reads the examples of the dataset stored in the given file.
+func (c *Command) readDataset(file string) ([]dataset.Example, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+	defer f.Close()
+
+	format := c.format
+	if format == "" {
+		format = dataset.Format(file)
+	}
+
+	examples, err := dataset.Read(f, format)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	return examples, nil
+}
@@ -0,0 +1,63 @@
+package train
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/the-anna-project/annad/service/dataset"
+)
+
+// writeReport writes the report of the given training results to the given
+// writer. The report shows the accuracy per epoch and overall, the average
+// number of attempts until the expectation of an example was met and the CLG
+// trees which solved each example.
+func writeReport(w io.Writer, examples []dataset.Example, results []dataset.Result) {
+	var epochs []int
+	solvedByEpoch := map[int]int{}
+	totalByEpoch := map[int]int{}
+	solvers := make([][]string, len(examples))
+	var attempts, solved int
+
+	for _, r := range results {
+		if totalByEpoch[r.Epoch] == 0 {
+			epochs = append(epochs, r.Epoch)
+		}
+		totalByEpoch[r.Epoch]++
+
+		if !r.Solved {
+			continue
+		}
+		solvedByEpoch[r.Epoch]++
+		solvers[r.Index] = append(solvers[r.Index], r.CLGTreeID)
+		attempts += r.Attempts
+		solved++
+	}
+
+	for _, e := range epochs {
+		fmt.Fprintf(w, "Epoch %d: %d/%d examples solved\n", e, solvedByEpoch[e], totalByEpoch[e])
+	}
+	fmt.Fprintf(w, "Accuracy: %.2f%% (%d/%d)\n", percent(solved, len(results)), solved, len(results))
+	fmt.Fprintf(w, "Average attempts until the expectation was met: %.2f\n", average(attempts, solved))
+
+	fmt.Fprintf(w, "Solving CLG trees:\n")
+	for i, e := range examples {
+		trees := "none"
+		if len(solvers[i]) != 0 {
+			trees = strings.Join(solvers[i], ", ")
+		}
+		fmt.Fprintf(w, "  %d %q -> %q: %s\n", i+1, e.Input, e.Expectation, trees)
+	}
+}
+
+func average(sum, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+
+	return float64(sum) / float64(count)
+}
+
+func percent(part, total int) float64 {
+	return 100 * average(part, total)
+}
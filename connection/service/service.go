@@ -7,7 +7,7 @@ import (
 	"strconv"
 	"time"
 
-	servicespec "github.com/the-anna-project/spec/service"
+	servicespec "github.com/the-anna-project/annad/spec/service"
 )
 
 // Config represents the configuration used to create a new connection service.
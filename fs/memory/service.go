@@ -6,7 +6,7 @@ import (
 	"os"
 	"sync"
 
-	servicespec "github.com/the-anna-project/spec/service"
+	servicespec "github.com/the-anna-project/annad/spec/service"
 )
 
 // New creates a new memory file system service.
@@ -6,7 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 
-	servicespec "github.com/the-anna-project/spec/service"
+	servicespec "github.com/the-anna-project/annad/spec/service"
 )
 
 // New creates a new OS file system service.
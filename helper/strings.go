@@ -0,0 +1,13 @@
+// Package helper provides small functions shared by multiple packages.
+package helper
+
+// ContainsString checks whether the given list contains the given item.
+func ContainsString(list []string, item string) bool {
+	for _, s := range list {
+		if s == item {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,41 @@
+package helper
+
+import (
+	"testing"
+)
+
+func Test_Helper_ContainsString(t *testing.T) {
+	testCases := []struct {
+		List     []string
+		Item     string
+		Expected bool
+	}{
+		{
+			List:     nil,
+			Item:     "a",
+			Expected: false,
+		},
+		{
+			List:     []string{"a", "b"},
+			Item:     "c",
+			Expected: false,
+		},
+		{
+			List:     []string{"a", "b"},
+			Item:     "b",
+			Expected: true,
+		},
+		{
+			List:     []string{"a", "b"},
+			Item:     "",
+			Expected: false,
+		},
+	}
+
+	for i, testCase := range testCases {
+		output := ContainsString(testCase.List, testCase.Item)
+		if output != testCase.Expected {
+			t.Fatal("case", i+1, "expected", testCase.Expected, "got", output)
+		}
+	}
+}
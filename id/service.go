@@ -3,7 +3,7 @@
 package id
 
 import (
-	servicespec "github.com/the-anna-project/spec/service"
+	servicespec "github.com/the-anna-project/annad/spec/service"
 )
 
 const (
@@ -6,8 +6,8 @@ import (
 	"sync"
 	"testing"
 
-	"github.com/the-anna-project/random"
-	"github.com/the-anna-project/collection"
+	"github.com/the-anna-project/annad/collection/collection"
+	"github.com/the-anna-project/annad/random"
 )
 
 func Test_IDService_WithType_Error(t *testing.T) {
@@ -45,13 +45,15 @@ func Test_IDService_New(t *testing.T) {
 			defer wg.Done()
 			newObjectID, err := idService.New()
 			if err != nil {
-				t.Fatal("expected", nil, "got", err)
+				t.Error("expected", nil, "got", err)
+				return
 			}
 
 			mutex.Lock()
 			defer mutex.Unlock()
 			if _, ok := alreadySeen[newObjectID]; ok {
-				t.Fatal("idService.New returned the same ID twice")
+				t.Error("idService.New returned the same ID twice")
+				return
 			}
 			alreadySeen[newObjectID] = struct{}{}
 		}()
@@ -78,13 +80,15 @@ func Test_IDService_WithType(t *testing.T) {
 			defer wg.Done()
 			newObjectID, err := idService.WithType(Hex128)
 			if err != nil {
-				t.Fatal("expected", nil, "got", err)
+				t.Error("expected", nil, "got", err)
+				return
 			}
 
 			mutex.Lock()
 			defer mutex.Unlock()
 			if _, ok := alreadySeen[newObjectID]; ok {
-				t.Fatal("idService.New returned the same ID twice")
+				t.Error("idService.New returned the same ID twice")
+				return
 			}
 			alreadySeen[newObjectID] = struct{}{}
 		}()
@@ -1,7 +1,7 @@
 package collection
 
 import (
-	servicespec "github.com/the-anna-project/spec/service"
+	servicespec "github.com/the-anna-project/annad/spec/service"
 )
 
 // New creates a new input collection.
@@ -0,0 +1,99 @@
+// Package expectation implements spec.Expectation. An expectation describes
+// the output a client expects the neural network to calculate for the input
+// it provided.
+package expectation
+
+import (
+	"encoding/json"
+
+	objectspec "github.com/the-anna-project/annad/spec/object"
+)
+
+// New creates a new expectation object expecting the given output.
+func New(output string) objectspec.Expectation {
+	return &object{
+		output: output,
+	}
+}
+
+type object struct {
+	// Settings.
+
+	// matcher represents the name of the matcher checking calculated output
+	// against the expectation.
+	matcher string
+	// output represents the output the neural network is expected to calculate.
+	output string
+	// outputs represents alternative outputs being expected as well.
+	outputs []string
+	// threshold represents the threshold of the matcher.
+	threshold float64
+}
+
+// objectJSON represents the JSON encoding of an expectation object.
+type objectJSON struct {
+	Matcher   string   `json:"matcher,omitempty"`
+	Output    string   `json:"output"`
+	Outputs   []string `json:"outputs,omitempty"`
+	Threshold float64  `json:"threshold,omitempty"`
+}
+
+func (o *object) GetMatcher() string {
+	return o.matcher
+}
+
+func (o *object) GetOutput() string {
+	return o.output
+}
+
+func (o *object) GetOutputs() []string {
+	return o.outputs
+}
+
+func (o *object) GetThreshold() float64 {
+	return o.threshold
+}
+
+// MarshalJSON encodes the expectation object, so it can be stored and
+// decoded later on using UnmarshalJSON.
+func (o *object) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(objectJSON{
+		Matcher:   o.matcher,
+		Output:    o.output,
+		Outputs:   o.outputs,
+		Threshold: o.threshold,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func (o *object) SetMatcher(matcher string) {
+	o.matcher = matcher
+}
+
+func (o *object) SetOutputs(outputs []string) {
+	o.outputs = outputs
+}
+
+func (o *object) SetThreshold(threshold float64) {
+	o.threshold = threshold
+}
+
+// UnmarshalJSON decodes the given JSON encoded expectation object.
+func (o *object) UnmarshalJSON(b []byte) error {
+	var aux objectJSON
+	err := json.Unmarshal(b, &aux)
+	if err != nil {
+		return err
+	}
+
+	o.matcher = aux.Matcher
+	o.output = aux.Output
+	o.outputs = aux.Outputs
+	o.threshold = aux.Threshold
+
+	return nil
+}
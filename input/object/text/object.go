@@ -1,7 +1,9 @@
 package text
 
 import (
-	objectspec "github.com/the-anna-project/spec/object"
+	"time"
+
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
 // New creates a new text input object.
@@ -12,6 +14,14 @@ func New() objectspec.TextInput {
 type object struct {
 	// Settings.
 
+	// backoff represents the initial delay between retries of the neural
+	// network trying to meet the given expectation. 0 means the network's
+	// default is used.
+	backoff time.Duration
+	// deadline represents the duration the neural network is allowed to
+	// calculate an answer for the given input. 0 means the network's default is
+	// used.
+	deadline time.Duration
 	// echo being set to true causes the provided input simply to be echoed back.
 	// The provided input goes through the whole stack and is streamed back and
 	// forth, but bypasses neural network. This is useful to test the
@@ -26,11 +36,23 @@ type object struct {
 	// be a none empty input given when requesting calculations from the neural
 	// network.
 	input string
+	// retries represents the maximum number of retries of the neural network
+	// trying to meet the given expectation. 0 means the network's default is
+	// used.
+	retries int
 	// sessionID represents the session the current text request is associated
 	// with. This is provided to differentiate streams between different users.
 	sessionID string
 }
 
+func (o *object) Backoff() time.Duration {
+	return o.backoff
+}
+
+func (o *object) Deadline() time.Duration {
+	return o.deadline
+}
+
 func (o *object) Echo() bool {
 	return o.echo
 }
@@ -43,10 +65,22 @@ func (o *object) Input() string {
 	return o.input
 }
 
+func (o *object) Retries() int {
+	return o.retries
+}
+
 func (o *object) SessionID() string {
 	return o.sessionID
 }
 
+func (o *object) SetBackoff(backoff time.Duration) {
+	o.backoff = backoff
+}
+
+func (o *object) SetDeadline(deadline time.Duration) {
+	o.deadline = deadline
+}
+
 func (o *object) SetEcho(echo bool) {
 	o.echo = echo
 }
@@ -59,6 +93,10 @@ func (o *object) SetInput(input string) {
 	o.input = input
 }
 
+func (o *object) SetRetries(retries int) {
+	o.retries = retries
+}
+
 func (o *object) SetSessionID(sessionID string) {
 	o.sessionID = sessionID
 }
@@ -1,7 +1,7 @@
 package memory
 
 import (
-	objectspec "github.com/the-anna-project/spec/object"
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
 // HistogramConfig represents the configuration used to create a new memory
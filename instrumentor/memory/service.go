@@ -7,8 +7,8 @@ package memory
 import (
 	"net/http"
 
-	objectspec "github.com/the-anna-project/spec/object"
-	servicespec "github.com/the-anna-project/spec/service"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
 )
 
 // New creates a new memory instrumentation service.
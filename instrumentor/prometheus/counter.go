@@ -3,7 +3,7 @@ package prometheus
 import (
 	prometheusclient "github.com/prometheus/client_golang/prometheus"
 
-	objectspec "github.com/the-anna-project/spec/object"
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
 // CounterConfig represents the configuration used to create a new prometheus
@@ -10,8 +10,8 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 
-	objectspec "github.com/the-anna-project/spec/object"
-	servicespec "github.com/the-anna-project/spec/service"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
 )
 
 // New creates a new pronetheus instrumentor service.
@@ -108,7 +108,11 @@ func (s *service) GetCounter(key string) (objectspec.InstrumentorCounter, error)
 	}
 
 	err = prometheus.Register(newCounter.(*counter).ClientCounter)
-	if err != nil {
+	if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+		// Another instrumentor within the same process registered the same
+		// metric already. We switch over to using the existing one.
+		newCounter.(*counter).ClientCounter = are.ExistingCollector.(prometheus.Counter)
+	} else if err != nil {
 		return nil, maskAny(err)
 	}
 	s.counters[key] = newCounter
@@ -133,7 +137,11 @@ func (s *service) GetGauge(key string) (objectspec.InstrumentorGauge, error) {
 	}
 
 	err = prometheus.Register(newGauge.(*gauge).ClientGauge)
-	if err != nil {
+	if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+		// Another instrumentor within the same process registered the same
+		// metric already. We switch over to using the existing one.
+		newGauge.(*gauge).ClientGauge = are.ExistingCollector.(prometheus.Gauge)
+	} else if err != nil {
 		return nil, maskAny(err)
 	}
 	s.gauges[key] = newGauge
@@ -158,7 +166,11 @@ func (s *service) GetHistogram(key string) (objectspec.InstrumentorHistogram, er
 	}
 
 	err = prometheus.Register(newHistogram.(*histogram).ClientHistogram)
-	if err != nil {
+	if are, ok := err.(prometheus.AlreadyRegisteredError); ok {
+		// Another instrumentor within the same process registered the same
+		// metric already. We switch over to using the existing one.
+		newHistogram.(*histogram).ClientHistogram = are.ExistingCollector.(prometheus.Histogram)
+	} else if err != nil {
 		return nil, maskAny(err)
 	}
 	s.histograms[key] = newHistogram
@@ -2,7 +2,7 @@
 // layers.
 package collection
 
-import servicespec "github.com/the-anna-project/spec/service"
+import servicespec "github.com/the-anna-project/annad/spec/service"
 
 // New creates a new layer collection.
 func New() servicespec.LayerCollection {
@@ -6,8 +6,8 @@ import (
 	"fmt"
 	"strings"
 
-	peerservice "github.com/the-anna-project/peer/service"
-	servicespec "github.com/the-anna-project/spec/service"
+	peerservice "github.com/the-anna-project/annad/peer/service"
+	servicespec "github.com/the-anna-project/annad/spec/service"
 )
 
 const (
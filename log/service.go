@@ -2,7 +2,7 @@
 // output to gather runtime information.
 package log
 
-import servicespec "github.com/the-anna-project/spec/service"
+import servicespec "github.com/the-anna-project/annad/spec/service"
 
 // New creates a new log service.
 func New() servicespec.LogService {
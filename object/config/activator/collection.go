@@ -0,0 +1,40 @@
+package activator
+
+import (
+	"github.com/the-anna-project/annad/object/config/activator/lookup"
+	"github.com/the-anna-project/annad/object/config/activator/queue"
+)
+
+// NewCollection creates a new activator object. It provides configuration for
+// the activator.
+func NewCollection() *Collection {
+	return &Collection{}
+}
+
+// Collection represents the activator collection.
+type Collection struct {
+	// Settings.
+
+	lookup *lookup.Object
+	queue  *queue.Object
+}
+
+// Lookup returns the lookup config of the activator collection.
+func (c *Collection) Lookup() *lookup.Object {
+	return c.lookup
+}
+
+// Queue returns the activation queue config of the activator collection.
+func (c *Collection) Queue() *queue.Object {
+	return c.queue
+}
+
+// SetLookup sets the lookup config for the activator collection.
+func (c *Collection) SetLookup(lookup *lookup.Object) {
+	c.lookup = lookup
+}
+
+// SetQueue sets the activation queue config for the activator collection.
+func (c *Collection) SetQueue(queue *queue.Object) {
+	c.queue = queue
+}
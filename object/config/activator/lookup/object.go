@@ -0,0 +1,27 @@
+package lookup
+
+// New creates a new lookup object. It provides configuration for the lookup
+// strategies of the activator.
+func New() *Object {
+	return &Object{}
+}
+
+// Object represents the lookup config object.
+type Object struct {
+	// Settings.
+
+	// strategies is a comma separated list of names of lookup strategies. The
+	// activator executes the strategies in this order until one of them finds a
+	// network payload.
+	strategies *string
+}
+
+// SetStrategies sets the strategies for the lookup config.
+func (o *Object) SetStrategies(strategies *string) {
+	o.strategies = strategies
+}
+
+// Strategies returns the strategies of the lookup config.
+func (o *Object) Strategies() string {
+	return *o.strategies
+}
@@ -0,0 +1,55 @@
+package queue
+
+import (
+	"time"
+)
+
+// New creates a new queue object. It provides configuration for the
+// activation queues of the activator.
+func New() *Object {
+	return &Object{}
+}
+
+// Object represents the activation queue config object.
+type Object struct {
+	// Settings.
+
+	// deadLetter defines whether expired network payloads are moved to the dead
+	// letter queue instead of being dropped.
+	deadLetter *bool
+	// sweepInterval is the interval in which the activation queues are swept.
+	sweepInterval *time.Duration
+	// ttl is the duration a network payload is allowed to wait within an
+	// activation queue.
+	ttl *time.Duration
+}
+
+// DeadLetter returns the dead letter flag of the queue config.
+func (o *Object) DeadLetter() bool {
+	return *o.deadLetter
+}
+
+// SetDeadLetter sets the dead letter flag for the queue config.
+func (o *Object) SetDeadLetter(deadLetter *bool) {
+	o.deadLetter = deadLetter
+}
+
+// SetSweepInterval sets the sweep interval for the queue config.
+func (o *Object) SetSweepInterval(sweepInterval *time.Duration) {
+	o.sweepInterval = sweepInterval
+}
+
+// SetTTL sets the TTL for the queue config.
+func (o *Object) SetTTL(ttl *time.Duration) {
+	o.ttl = ttl
+}
+
+// SweepInterval returns the sweep interval of the queue config.
+func (o *Object) SweepInterval() time.Duration {
+	return *o.sweepInterval
+}
+
+// TTL returns the TTL of the queue config.
+func (o *Object) TTL() time.Duration {
+	return *o.ttl
+}
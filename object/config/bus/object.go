@@ -0,0 +1,39 @@
+package bus
+
+// New creates a new bus object. It provides configuration for the internal
+// publish/subscribe bus carrying network events.
+func New() *Object {
+	return &Object{}
+}
+
+// Object represents the bus config object.
+type Object struct {
+	// Settings.
+
+	// buffer is the number of network events buffered for each subscriber of
+	// the bus.
+	buffer *int
+	// tracing defines whether each network event published on the bus is
+	// logged.
+	tracing *bool
+}
+
+// Buffer returns the buffer of the bus config.
+func (o *Object) Buffer() int {
+	return *o.buffer
+}
+
+// SetBuffer sets the buffer for the bus config.
+func (o *Object) SetBuffer(buffer *int) {
+	o.buffer = buffer
+}
+
+// SetTracing sets the tracing for the bus config.
+func (o *Object) SetTracing(tracing *bool) {
+	o.tracing = tracing
+}
+
+// Tracing returns the tracing of the bus config.
+func (o *Object) Tracing() bool {
+	return *o.tracing
+}
@@ -6,10 +6,24 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
+	"github.com/the-anna-project/annad/object/config/activator"
+	"github.com/the-anna-project/annad/object/config/activator/lookup"
+	"github.com/the-anna-project/annad/object/config/activator/queue"
+	"github.com/the-anna-project/annad/object/config/bus"
 	"github.com/the-anna-project/annad/object/config/config"
+	"github.com/the-anna-project/annad/object/config/deterministic"
 	"github.com/the-anna-project/annad/object/config/endpoint"
 	"github.com/the-anna-project/annad/object/config/endpoint/metric"
 	"github.com/the-anna-project/annad/object/config/endpoint/text"
+	"github.com/the-anna-project/annad/object/config/journal"
+	"github.com/the-anna-project/annad/object/config/network"
+	"github.com/the-anna-project/annad/object/config/network/budget"
+	"github.com/the-anna-project/annad/object/config/network/candidates"
+	"github.com/the-anna-project/annad/object/config/network/clg"
+	"github.com/the-anna-project/annad/object/config/network/mode"
+	"github.com/the-anna-project/annad/object/config/network/priority"
+	"github.com/the-anna-project/annad/object/config/network/retry"
+	"github.com/the-anna-project/annad/object/config/network/workers"
 	"github.com/the-anna-project/annad/object/config/space"
 	spaceconnection "github.com/the-anna-project/annad/object/config/space/connection"
 	"github.com/the-anna-project/annad/object/config/space/dimension"
@@ -18,6 +32,9 @@ import (
 	storageconnection "github.com/the-anna-project/annad/object/config/storage/connection"
 	"github.com/the-anna-project/annad/object/config/storage/feature"
 	"github.com/the-anna-project/annad/object/config/storage/general"
+	storagepeer "github.com/the-anna-project/annad/object/config/storage/peer"
+	"github.com/the-anna-project/annad/object/config/tracker"
+	"github.com/the-anna-project/annad/object/config/tracker/pattern"
 )
 
 // NewCollection creates a new config collection. It provides configuration for
@@ -25,18 +42,35 @@ import (
 func NewCollection() *Collection {
 	collection := &Collection{}
 
+	collection.SetActivatorCollection(activator.NewCollection())
+	collection.SetBus(bus.New())
 	collection.SetConfig(config.New())
+	collection.SetDeterministic(deterministic.New())
 	collection.SetEndpointCollection(endpoint.NewCollection())
+	collection.SetJournal(journal.New())
+	collection.SetNetworkCollection(network.NewCollection())
 	collection.SetSpaceCollection(space.NewCollection())
 	collection.SetStorageCollection(storage.NewCollection())
+	collection.SetTrackerCollection(tracker.NewCollection())
+	collection.Activator().SetLookup(lookup.New())
+	collection.Activator().SetQueue(queue.New())
 	collection.Endpoint().SetMetric(metric.New())
 	collection.Endpoint().SetText(text.New())
+	collection.Network().SetBudget(budget.New())
+	collection.Network().SetCandidates(candidates.New())
+	collection.Network().SetCLG(clg.New())
+	collection.Network().SetMode(mode.New())
+	collection.Network().SetPriority(priority.New())
+	collection.Network().SetRetry(retry.New())
+	collection.Network().SetWorkers(workers.New())
 	collection.Space().SetConnection(spaceconnection.New())
 	collection.Space().SetDimension(dimension.New())
 	collection.Space().SetPeer(peer.New())
 	collection.Storage().SetConnection(storageconnection.New())
 	collection.Storage().SetFeature(feature.New())
 	collection.Storage().SetGeneral(general.New())
+	collection.Storage().SetPeer(storagepeer.New())
+	collection.Tracker().SetPattern(pattern.New())
 
 	return collection
 }
@@ -45,10 +79,26 @@ func NewCollection() *Collection {
 type Collection struct {
 	// Settings.
 
-	endpointCollection *endpoint.Collection
-	config             *config.Object
-	spaceCollection    *space.Collection
-	storageCollection  *storage.Collection
+	activatorCollection *activator.Collection
+	bus                 *bus.Object
+	endpointCollection  *endpoint.Collection
+	config              *config.Object
+	deterministic       *deterministic.Object
+	journal             *journal.Object
+	networkCollection   *network.Collection
+	spaceCollection     *space.Collection
+	storageCollection   *storage.Collection
+	trackerCollection   *tracker.Collection
+}
+
+// Activator returns the activator collection of the config collection.
+func (c *Collection) Activator() *activator.Collection {
+	return c.activatorCollection
+}
+
+// Bus returns the bus config of the config collection.
+func (c *Collection) Bus() *bus.Object {
+	return c.bus
 }
 
 // Config returns the config file config of the config collection.
@@ -56,11 +106,21 @@ func (c *Collection) Config() *config.Object {
 	return c.config
 }
 
+// Deterministic returns the deterministic config of the config collection.
+func (c *Collection) Deterministic() *deterministic.Object {
+	return c.deterministic
+}
+
 // Endpoint returns the endpoint collection of the config collection.
 func (c *Collection) Endpoint() *endpoint.Collection {
 	return c.endpointCollection
 }
 
+// Journal returns the journal config of the config collection.
+func (c *Collection) Journal() *journal.Object {
+	return c.journal
+}
+
 // Merge combines values of a flag-set with these of their corresponding
 // environment and config file variables, in this order.
 func (c *Collection) Merge(flagSet *pflag.FlagSet) error {
@@ -104,16 +164,47 @@ func (c *Collection) Merge(flagSet *pflag.FlagSet) error {
 	return nil
 }
 
+// Network returns the network collection of the config collection.
+func (c *Collection) Network() *network.Collection {
+	return c.networkCollection
+}
+
+// SetActivatorCollection sets the activator collection for the config
+// collection.
+func (c *Collection) SetActivatorCollection(activatorCollection *activator.Collection) {
+	c.activatorCollection = activatorCollection
+}
+
+// SetBus sets the bus config for the config collection.
+func (c *Collection) SetBus(bus *bus.Object) {
+	c.bus = bus
+}
+
 // SetConfig sets the config file config for the config collection.
 func (c *Collection) SetConfig(config *config.Object) {
 	c.config = config
 }
 
+// SetDeterministic sets the deterministic config for the config collection.
+func (c *Collection) SetDeterministic(deterministic *deterministic.Object) {
+	c.deterministic = deterministic
+}
+
 // SetEndpointCollection sets the endpoint collection for the config collection.
 func (c *Collection) SetEndpointCollection(endpointCollection *endpoint.Collection) {
 	c.endpointCollection = endpointCollection
 }
 
+// SetJournal sets the journal config for the config collection.
+func (c *Collection) SetJournal(journal *journal.Object) {
+	c.journal = journal
+}
+
+// SetNetworkCollection sets the network collection for the config collection.
+func (c *Collection) SetNetworkCollection(networkCollection *network.Collection) {
+	c.networkCollection = networkCollection
+}
+
 // SetSpaceCollection sets the space collection for the config collection.
 func (c *Collection) SetSpaceCollection(spaceCollection *space.Collection) {
 	c.spaceCollection = spaceCollection
@@ -124,6 +215,11 @@ func (c *Collection) SetStorageCollection(storageCollection *storage.Collection)
 	c.storageCollection = storageCollection
 }
 
+// SetTrackerCollection sets the tracker collection for the config collection.
+func (c *Collection) SetTrackerCollection(trackerCollection *tracker.Collection) {
+	c.trackerCollection = trackerCollection
+}
+
 // Space returns the space collection of the config collection.
 func (c *Collection) Space() *space.Collection {
 	return c.spaceCollection
@@ -133,3 +229,8 @@ func (c *Collection) Space() *space.Collection {
 func (c *Collection) Storage() *storage.Collection {
 	return c.storageCollection
 }
+
+// Tracker returns the tracker collection of the config collection.
+func (c *Collection) Tracker() *tracker.Collection {
+	return c.trackerCollection
+}
@@ -11,28 +11,28 @@ type Object struct {
 	// Settings.
 
 	// dir represents the directory in which the config file can be found.
-	dir string
+	dir *string
 	// name represents the file name of the config file without extension. The
 	// actual config file can have either json or yaml extension and format.
-	name string
+	name *string
 }
 
 // Dir returns the dir of the file config.
 func (o *Object) Dir() string {
-	return o.dir
+	return *o.dir
 }
 
 // Name returns the name of the file config.
 func (o *Object) Name() string {
-	return o.name
+	return *o.name
 }
 
 // SetDir sets the dir for the file config.
 func (o *Object) SetDir(dir *string) {
-	o.dir = *dir
+	o.dir = dir
 }
 
 // SetName sets the name for the file config.
 func (o *Object) SetName(name *string) {
-	o.name = *name
+	o.name = name
 }
@@ -0,0 +1,26 @@
+package deterministic
+
+// New creates a new deterministic object. It provides configuration for
+// reproducible runs of the neural network.
+func New() *Object {
+	return &Object{}
+}
+
+// Object represents the deterministic config object.
+type Object struct {
+	// Settings.
+
+	// seed is used to create pseudo random numbers and IDs. A seed of 0 disables
+	// the deterministic mode.
+	seed *int64
+}
+
+// Seed returns the seed of the deterministic config.
+func (o *Object) Seed() int64 {
+	return *o.seed
+}
+
+// SetSeed sets the seed for the deterministic config.
+func (o *Object) SetSeed(seed *int64) {
+	o.seed = seed
+}
@@ -10,15 +10,15 @@ func New() *Object {
 type Object struct {
 	// Settings.
 
-	address string
+	address *string
 }
 
 // Address returns the address of the endpoint config.
 func (o *Object) Address() string {
-	return o.address
+	return *o.address
 }
 
 // SetAddress sets the address for the endpoint config.
 func (o *Object) SetAddress(address *string) {
-	o.address = *address
+	o.address = address
 }
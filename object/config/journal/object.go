@@ -0,0 +1,26 @@
+package journal
+
+// New creates a new journal object. It provides configuration for recording
+// journals of network sessions.
+func New() *Object {
+	return &Object{}
+}
+
+// Object represents the journal config object.
+type Object struct {
+	// Settings.
+
+	// record is the file a journal of the network sessions is recorded to. An
+	// empty file disables recording.
+	record *string
+}
+
+// Record returns the record file of the journal config.
+func (o *Object) Record() string {
+	return *o.record
+}
+
+// SetRecord sets the record file for the journal config.
+func (o *Object) SetRecord(record *string) {
+	o.record = record
+}
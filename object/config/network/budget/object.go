@@ -0,0 +1,56 @@
+package budget
+
+import (
+	"time"
+)
+
+// New creates a new budget object. It provides configuration for the budget
+// of CLG trees.
+func New() *Object {
+	return &Object{}
+}
+
+// Object represents the network budget config object.
+type Object struct {
+	// Settings.
+
+	// deadline is the duration a CLG tree is allowed to calculate an answer for
+	// the input it was created for.
+	deadline *time.Duration
+	// depth is the maximum number of hops a network payload is allowed to
+	// travel within its CLG tree.
+	depth *int
+	// events is the maximum number of network events a CLG tree is allowed to
+	// cause.
+	events *int
+}
+
+// Deadline returns the deadline of the budget config.
+func (o *Object) Deadline() time.Duration {
+	return *o.deadline
+}
+
+// Depth returns the depth of the budget config.
+func (o *Object) Depth() int {
+	return *o.depth
+}
+
+// Events returns the events of the budget config.
+func (o *Object) Events() int {
+	return *o.events
+}
+
+// SetDeadline sets the deadline for the budget config.
+func (o *Object) SetDeadline(deadline *time.Duration) {
+	o.deadline = deadline
+}
+
+// SetDepth sets the depth for the budget config.
+func (o *Object) SetDepth(depth *int) {
+	o.depth = depth
+}
+
+// SetEvents sets the events for the budget config.
+func (o *Object) SetEvents(events *int) {
+	o.events = events
+}
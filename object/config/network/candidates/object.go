@@ -0,0 +1,42 @@
+package candidates
+
+import (
+	"time"
+)
+
+// New creates a new candidates object. It provides configuration for
+// gathering and ranking the outputs of CLG trees.
+func New() *Object {
+	return &Object{}
+}
+
+// Object represents the network candidates config object.
+type Object struct {
+	// Settings.
+
+	// limit is the maximum number of candidates sent to the client per CLG tree.
+	limit *int
+	// window is the duration the outputs of a CLG tree are gathered, starting
+	// with its first output.
+	window *time.Duration
+}
+
+// Limit returns the limit of the candidates config.
+func (o *Object) Limit() int {
+	return *o.limit
+}
+
+// SetLimit sets the limit for the candidates config.
+func (o *Object) SetLimit(limit *int) {
+	o.limit = limit
+}
+
+// SetWindow sets the window for the candidates config.
+func (o *Object) SetWindow(window *time.Duration) {
+	o.window = window
+}
+
+// Window returns the window of the candidates config.
+func (o *Object) Window() time.Duration {
+	return *o.window
+}
@@ -0,0 +1,66 @@
+package clg
+
+import (
+	"time"
+)
+
+// New creates a new CLG object. It provides configuration for the execution of
+// CLGs within the network.
+func New() *Object {
+	return &Object{}
+}
+
+// Object represents the network CLG config object.
+type Object struct {
+	// Settings.
+
+	// cacheSize is the maximum number of pure CLG results being memoized.
+	cacheSize *int
+	// quarantineDuration is the duration a misbehaving CLG is quarantined.
+	quarantineDuration *time.Duration
+	// quarantineThreshold is the number of consecutive failures after which a
+	// CLG is quarantined.
+	quarantineThreshold *int
+	// timeout is the duration a CLG execution is allowed to take.
+	timeout *time.Duration
+}
+
+// CacheSize returns the cache size of the CLG config.
+func (o *Object) CacheSize() int {
+	return *o.cacheSize
+}
+
+// QuarantineDuration returns the quarantine duration of the CLG config.
+func (o *Object) QuarantineDuration() time.Duration {
+	return *o.quarantineDuration
+}
+
+// QuarantineThreshold returns the quarantine threshold of the CLG config.
+func (o *Object) QuarantineThreshold() int {
+	return *o.quarantineThreshold
+}
+
+// SetCacheSize sets the cache size for the CLG config.
+func (o *Object) SetCacheSize(cacheSize *int) {
+	o.cacheSize = cacheSize
+}
+
+// SetQuarantineDuration sets the quarantine duration for the CLG config.
+func (o *Object) SetQuarantineDuration(quarantineDuration *time.Duration) {
+	o.quarantineDuration = quarantineDuration
+}
+
+// SetQuarantineThreshold sets the quarantine threshold for the CLG config.
+func (o *Object) SetQuarantineThreshold(quarantineThreshold *int) {
+	o.quarantineThreshold = quarantineThreshold
+}
+
+// SetTimeout sets the timeout for the CLG config.
+func (o *Object) SetTimeout(timeout *time.Duration) {
+	o.timeout = timeout
+}
+
+// Timeout returns the timeout of the CLG config.
+func (o *Object) Timeout() time.Duration {
+	return *o.timeout
+}
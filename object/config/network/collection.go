@@ -0,0 +1,102 @@
+package network
+
+import (
+	"github.com/the-anna-project/annad/object/config/network/budget"
+	"github.com/the-anna-project/annad/object/config/network/candidates"
+	"github.com/the-anna-project/annad/object/config/network/clg"
+	"github.com/the-anna-project/annad/object/config/network/mode"
+	"github.com/the-anna-project/annad/object/config/network/priority"
+	"github.com/the-anna-project/annad/object/config/network/retry"
+	"github.com/the-anna-project/annad/object/config/network/workers"
+)
+
+// NewCollection creates a new network object. It provides configuration for
+// the neural network.
+func NewCollection() *Collection {
+	return &Collection{}
+}
+
+// Collection represents the network collection.
+type Collection struct {
+	// Settings.
+
+	budget     *budget.Object
+	candidates *candidates.Object
+	clg        *clg.Object
+	mode       *mode.Object
+	priority   *priority.Object
+	retry      *retry.Object
+	workers    *workers.Object
+}
+
+// Budget returns the CLG tree budget config of the network collection.
+func (c *Collection) Budget() *budget.Object {
+	return c.budget
+}
+
+// Candidates returns the CLG tree output candidates config of the network
+// collection.
+func (c *Collection) Candidates() *candidates.Object {
+	return c.candidates
+}
+
+// CLG returns the CLG execution config of the network collection.
+func (c *Collection) CLG() *clg.Object {
+	return c.clg
+}
+
+// Mode returns the operation mode config of the network collection.
+func (c *Collection) Mode() *mode.Object {
+	return c.mode
+}
+
+// Priority returns the event priority config of the network collection.
+func (c *Collection) Priority() *priority.Object {
+	return c.priority
+}
+
+// Retry returns the CLG tree retry config of the network collection.
+func (c *Collection) Retry() *retry.Object {
+	return c.retry
+}
+
+// SetBudget sets the CLG tree budget config for the network collection.
+func (c *Collection) SetBudget(budget *budget.Object) {
+	c.budget = budget
+}
+
+// SetCandidates sets the CLG tree output candidates config for the network
+// collection.
+func (c *Collection) SetCandidates(candidates *candidates.Object) {
+	c.candidates = candidates
+}
+
+// SetCLG sets the CLG execution config for the network collection.
+func (c *Collection) SetCLG(clg *clg.Object) {
+	c.clg = clg
+}
+
+// SetMode sets the operation mode config for the network collection.
+func (c *Collection) SetMode(mode *mode.Object) {
+	c.mode = mode
+}
+
+// SetPriority sets the event priority config for the network collection.
+func (c *Collection) SetPriority(priority *priority.Object) {
+	c.priority = priority
+}
+
+// SetRetry sets the CLG tree retry config for the network collection.
+func (c *Collection) SetRetry(retry *retry.Object) {
+	c.retry = retry
+}
+
+// SetWorkers sets the worker pool config for the network collection.
+func (c *Collection) SetWorkers(workers *workers.Object) {
+	c.workers = workers
+}
+
+// Workers returns the worker pool config of the network collection.
+func (c *Collection) Workers() *workers.Object {
+	return c.workers
+}
@@ -0,0 +1,26 @@
+package mode
+
+// New creates a new mode object. It provides configuration for the mode the
+// neural network operates in.
+func New() *Object {
+	return &Object{}
+}
+
+// Object represents the network mode config object.
+type Object struct {
+	// Settings.
+
+	// frozen defines whether the neural network only uses what it already
+	// learned, without learning anything new.
+	frozen *bool
+}
+
+// Frozen returns the frozen setting of the mode config.
+func (o *Object) Frozen() bool {
+	return *o.frozen
+}
+
+// SetFrozen sets the frozen setting for the mode config.
+func (o *Object) SetFrozen(frozen *bool) {
+	o.frozen = frozen
+}
@@ -0,0 +1,82 @@
+package priority
+
+// New creates a new priority object. It provides configuration for the
+// scheduling of network events.
+func New() *Object {
+	return &Object{}
+}
+
+// Object represents the network priority config object.
+type Object struct {
+	// Settings.
+
+	// aging is the number of network events being scheduled after which a
+	// waiting network event gained one priority point compared to newly
+	// scheduled network events. This prevents network events of low priority
+	// from starving.
+	aging *int
+	// clg is a comma separated list of CLG names and priorities, where each pair
+	// is separated by a colon. E.g. output:10,input:5 adds 10 to the priority of
+	// network events being dispatched to output CLGs.
+	clg *string
+	// depth is the priority added to a network event for each hop its network
+	// payload travelled within its CLG tree.
+	depth *int
+	// interactive is the priority of network events belonging to interactive
+	// sessions, that is, requests not providing any expectation.
+	interactive *int
+	// training is the priority of network events belonging to training
+	// sessions, that is, requests providing an expectation.
+	training *int
+}
+
+// Aging returns the aging of the priority config.
+func (o *Object) Aging() int {
+	return *o.aging
+}
+
+// CLG returns the CLG priorities of the priority config.
+func (o *Object) CLG() string {
+	return *o.clg
+}
+
+// Depth returns the depth priority of the priority config.
+func (o *Object) Depth() int {
+	return *o.depth
+}
+
+// Interactive returns the interactive session priority of the priority config.
+func (o *Object) Interactive() int {
+	return *o.interactive
+}
+
+// SetAging sets the aging for the priority config.
+func (o *Object) SetAging(aging *int) {
+	o.aging = aging
+}
+
+// SetCLG sets the CLG priorities for the priority config.
+func (o *Object) SetCLG(clg *string) {
+	o.clg = clg
+}
+
+// SetDepth sets the depth priority for the priority config.
+func (o *Object) SetDepth(depth *int) {
+	o.depth = depth
+}
+
+// SetInteractive sets the interactive session priority for the priority
+// config.
+func (o *Object) SetInteractive(interactive *int) {
+	o.interactive = interactive
+}
+
+// SetTraining sets the training session priority for the priority config.
+func (o *Object) SetTraining(training *int) {
+	o.training = training
+}
+
+// Training returns the training session priority of the priority config.
+func (o *Object) Training() int {
+	return *o.training
+}
@@ -0,0 +1,54 @@
+package retry
+
+import (
+	"time"
+)
+
+// New creates a new retry object. It provides configuration for retrying CLG
+// trees whose output did not meet the expectation of their request.
+func New() *Object {
+	return &Object{}
+}
+
+// Object represents the network retry config object.
+type Object struct {
+	// Settings.
+
+	// backoff is the delay before the first retry of a CLG tree. The delay
+	// doubles with each further retry.
+	backoff *time.Duration
+	// backoffMax is the maximum delay between two retries of a CLG tree.
+	backoffMax *time.Duration
+	// limit is the maximum number of retries of a CLG tree.
+	limit *int
+}
+
+// Backoff returns the backoff of the retry config.
+func (o *Object) Backoff() time.Duration {
+	return *o.backoff
+}
+
+// BackoffMax returns the maximum backoff of the retry config.
+func (o *Object) BackoffMax() time.Duration {
+	return *o.backoffMax
+}
+
+// Limit returns the limit of the retry config.
+func (o *Object) Limit() int {
+	return *o.limit
+}
+
+// SetBackoff sets the backoff for the retry config.
+func (o *Object) SetBackoff(backoff *time.Duration) {
+	o.backoff = backoff
+}
+
+// SetBackoffMax sets the maximum backoff for the retry config.
+func (o *Object) SetBackoffMax(backoffMax *time.Duration) {
+	o.backoffMax = backoffMax
+}
+
+// SetLimit sets the limit for the retry config.
+func (o *Object) SetLimit(limit *int) {
+	o.limit = limit
+}
@@ -0,0 +1,106 @@
+package workers
+
+import (
+	"time"
+)
+
+// New creates a new workers object. It provides configuration for the worker
+// pools of the network.
+func New() *Object {
+	return &Object{}
+}
+
+// Object represents the network workers config object.
+type Object struct {
+	// Settings.
+
+	// autoscale defines whether the number of event workers is scaled
+	// automatically.
+	autoscale *bool
+	// autoscaleInterval is the interval in which the autoscaler decides about
+	// the number of event workers.
+	autoscaleInterval *time.Duration
+	// autoscaleLatency is the average event handler latency above which the
+	// autoscaler grows the event workers cautiously.
+	autoscaleLatency *time.Duration
+	// autoscaleMax is the maximum number of event workers the autoscaler scales
+	// up to.
+	autoscaleMax *int
+	// autoscaleMin is the minimum number of event workers the autoscaler scales
+	// down to.
+	autoscaleMin *int
+	// event is the number of event workers.
+	event *int
+	// input is the number of input workers.
+	input *int
+}
+
+// Autoscale returns the autoscale flag of the workers config.
+func (o *Object) Autoscale() bool {
+	return *o.autoscale
+}
+
+// AutoscaleInterval returns the autoscale interval of the workers config.
+func (o *Object) AutoscaleInterval() time.Duration {
+	return *o.autoscaleInterval
+}
+
+// AutoscaleLatency returns the autoscale latency of the workers config.
+func (o *Object) AutoscaleLatency() time.Duration {
+	return *o.autoscaleLatency
+}
+
+// AutoscaleMax returns the autoscale maximum of the workers config.
+func (o *Object) AutoscaleMax() int {
+	return *o.autoscaleMax
+}
+
+// AutoscaleMin returns the autoscale minimum of the workers config.
+func (o *Object) AutoscaleMin() int {
+	return *o.autoscaleMin
+}
+
+// Event returns the number of event workers of the workers config.
+func (o *Object) Event() int {
+	return *o.event
+}
+
+// Input returns the number of input workers of the workers config.
+func (o *Object) Input() int {
+	return *o.input
+}
+
+// SetAutoscale sets the autoscale flag for the workers config.
+func (o *Object) SetAutoscale(autoscale *bool) {
+	o.autoscale = autoscale
+}
+
+// SetAutoscaleInterval sets the autoscale interval for the workers config.
+func (o *Object) SetAutoscaleInterval(autoscaleInterval *time.Duration) {
+	o.autoscaleInterval = autoscaleInterval
+}
+
+// SetAutoscaleLatency sets the autoscale latency for the workers config.
+func (o *Object) SetAutoscaleLatency(autoscaleLatency *time.Duration) {
+	o.autoscaleLatency = autoscaleLatency
+}
+
+// SetAutoscaleMax sets the autoscale maximum for the workers config.
+func (o *Object) SetAutoscaleMax(autoscaleMax *int) {
+	o.autoscaleMax = autoscaleMax
+}
+
+// SetAutoscaleMin sets the autoscale minimum for the workers config.
+func (o *Object) SetAutoscaleMin(autoscaleMin *int) {
+	o.autoscaleMin = autoscaleMin
+}
+
+// SetEvent sets the number of event workers for the workers config.
+func (o *Object) SetEvent(event *int) {
+	o.event = event
+}
+
+// SetInput sets the number of input workers for the workers config.
+func (o *Object) SetInput(input *int) {
+	o.input = input
+}
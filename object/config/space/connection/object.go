@@ -12,15 +12,15 @@ type Object struct {
 
 	// weight is the default score applied to a connection expressing its
 	// importance.
-	weight int
+	weight *int
 }
 
 // Weight returns the weight of the connection config.
 func (o *Object) Weight() int {
-	return o.weight
+	return *o.weight
 }
 
 // SetWeight sets the weight for the connection config.
 func (o *Object) SetWeight(weight *int) {
-	o.weight = *weight
+	o.weight = weight
 }
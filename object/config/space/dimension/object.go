@@ -12,29 +12,29 @@ type Object struct {
 
 	// count is the default number of directional coordinates within the
 	// connection space. E.g. a dice has 3 dimensions.
-	count int
+	count *int
 	// depth is the default size of each directional coordinate within the
 	// connection space. E.g. using a depth of 3, the resulting volume being taken
 	// by a 3 dimensional space would be 9.
-	depth int
+	depth *int
 }
 
 // Count returns the count of the dimension config.
 func (o *Object) Count() int {
-	return o.count
+	return *o.count
 }
 
 // Depth returns the depth of the dimension config.
 func (o *Object) Depth() int {
-	return o.depth
+	return *o.depth
 }
 
 // SetCount sets the count for the dimension config.
 func (o *Object) SetCount(count *int) {
-	o.count = *count
+	o.count = count
 }
 
 // SetDepth sets the depth for the dimension config.
 func (o *Object) SetDepth(depth *int) {
-	o.depth = *depth
+	o.depth = depth
 }
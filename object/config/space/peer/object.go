@@ -12,15 +12,15 @@ type Object struct {
 
 	// position describes the default position of new peers within the connection
 	// space.
-	position string
+	position *string
 }
 
 // Position returns the position of the peer config.
 func (o *Object) Position() string {
-	return o.position
+	return *o.position
 }
 
 // SetPosition sets the position for the peer config.
 func (o *Object) SetPosition(position *string) {
-	o.position = *position
+	o.position = position
 }
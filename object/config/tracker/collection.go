@@ -0,0 +1,28 @@
+package tracker
+
+import (
+	"github.com/the-anna-project/annad/object/config/tracker/pattern"
+)
+
+// NewCollection creates a new tracker object. It provides configuration for
+// the tracker.
+func NewCollection() *Collection {
+	return &Collection{}
+}
+
+// Collection represents the tracker collection.
+type Collection struct {
+	// Settings.
+
+	pattern *pattern.Object
+}
+
+// Pattern returns the pattern config of the tracker collection.
+func (c *Collection) Pattern() *pattern.Object {
+	return c.pattern
+}
+
+// SetPattern sets the pattern config for the tracker collection.
+func (c *Collection) SetPattern(pattern *pattern.Object) {
+	c.pattern = pattern
+}
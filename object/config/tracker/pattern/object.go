@@ -0,0 +1,25 @@
+package pattern
+
+// New creates a new pattern object. It provides configuration for the path
+// patterns mined by the tracker.
+func New() *Object {
+	return &Object{}
+}
+
+// Object represents the pattern config object.
+type Object struct {
+	// Settings.
+
+	// length is the maximum number of CLG kinds a path pattern is made of.
+	length *int
+}
+
+// Length returns the length of the pattern config.
+func (o *Object) Length() int {
+	return *o.length
+}
+
+// SetLength sets the length for the pattern config.
+func (o *Object) SetLength(length *int) {
+	o.length = length
+}
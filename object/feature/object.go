@@ -3,7 +3,7 @@ package feature
 import (
 	"sync"
 
-	objectspec "github.com/the-anna-project/spec/object"
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
 // New creates a new feature object. A feature represents a differentiable part
@@ -0,0 +1,53 @@
+package networkevent
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+// MarshalJSON encodes the network event without its network payload. Only the
+// ID of the network payload is encoded, if any. That way network events stay
+// small when being streamed. Network events of kind output-emitted encode the
+// output carried by their network payload as well, so clients of the stream
+// learn what was sent to which session.
+func (ne *networkEvent) MarshalJSON() ([]byte, error) {
+	var networkPayloadID, output string
+	if ne.NetworkPayload != nil {
+		networkPayloadID = ne.NetworkPayload.GetID()
+
+		args := ne.NetworkPayload.GetArgs()
+		if ne.Kind == KindOutputEmitted && len(args) == 1 && args[0].Kind() == reflect.String {
+			output = args[0].String()
+		}
+	}
+
+	b, err := json.Marshal(&struct {
+		BehaviourID      string        `json:"behaviour_id,omitempty"`
+		CLGName          string        `json:"clg_name,omitempty"`
+		CLGTreeID        string        `json:"clg_tree_id"`
+		Duration         time.Duration `json:"duration,omitempty"`
+		Error            string        `json:"error,omitempty"`
+		Kind             string        `json:"kind"`
+		NetworkPayloadID string        `json:"network_payload_id,omitempty"`
+		Output           string        `json:"output,omitempty"`
+		SessionID        string        `json:"session_id,omitempty"`
+		Time             time.Time     `json:"time"`
+	}{
+		BehaviourID:      ne.BehaviourID,
+		CLGName:          ne.CLGName,
+		CLGTreeID:        ne.CLGTreeID,
+		Duration:         ne.Duration,
+		Error:            ne.Error,
+		Kind:             ne.Kind,
+		NetworkPayloadID: networkPayloadID,
+		Output:           output,
+		SessionID:        ne.SessionID,
+		Time:             ne.Time,
+	})
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	return b, nil
+}
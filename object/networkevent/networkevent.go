@@ -0,0 +1,168 @@
+// Package networkevent implements spec.NetworkEvent to describe what happens
+// within the neural network.
+package networkevent
+
+import (
+	"time"
+
+	objectspec "github.com/the-anna-project/annad/spec/object"
+)
+
+const (
+	// KindActivated is the kind of network events published as soon as the
+	// interface of a requested CLG is satisfied.
+	KindActivated = "activated"
+	// KindCalculated is the kind of network events published as soon as a CLG
+	// executed its business logic.
+	KindCalculated = "calculated"
+	// KindExpired is the kind of network events published as soon as a network
+	// payload waited too long within an activation queue. The branch of its CLG
+	// tree leading to the requested CLG ended there.
+	KindExpired = "expired"
+	// KindForwarded is the kind of network events published as soon as the
+	// network payload calculated by a CLG is forwarded to other CLGs.
+	KindForwarded = "forwarded"
+	// KindInputReceived is the kind of network events published as soon as the
+	// network received input.
+	KindInputReceived = "input-received"
+	// KindOutputEmitted is the kind of network events published as soon as
+	// output was sent to the client. Its network payload carries the output as
+	// its only argument.
+	KindOutputEmitted = "output-emitted"
+	// KindTreeFinished is the kind of network events published as soon as a CLG
+	// tree ended.
+	KindTreeFinished = "tree-finished"
+)
+
+// Config represents the configuration used to create a new network event
+// object.
+type Config struct {
+	// Settings.
+
+	// BehaviourID is the behaviour ID of the CLG the network event is associated
+	// with, if any.
+	BehaviourID string
+
+	// CLGName is the name of the CLG the network event is associated with, if
+	// any.
+	CLGName string
+
+	// CLGTreeID is the ID of the CLG tree the network event belongs to.
+	CLGTreeID string
+
+	// Duration is the duration the stage of the network event took, if
+	// measured.
+	Duration time.Duration
+
+	// Error is the error message of the stage of the network event, if any.
+	Error string
+
+	// Kind is the kind of the network event. See the Kind constants.
+	Kind string
+
+	// NetworkPayload is the network payload the network event is associated
+	// with, if any.
+	NetworkPayload objectspec.NetworkPayload
+
+	// SessionID is the ID of the session the network event belongs to, if any.
+	SessionID string
+
+	// Time is the point in time the network event happened. In case it is not
+	// set, the point in time the network event is created is used.
+	Time time.Time
+}
+
+// DefaultConfig provides a default configuration to create a new network
+// event object by best effort.
+func DefaultConfig() Config {
+	newConfig := Config{
+		BehaviourID:    "",
+		CLGName:        "",
+		CLGTreeID:      "",
+		Duration:       0,
+		Error:          "",
+		Kind:           "",
+		NetworkPayload: nil,
+		SessionID:      "",
+		Time:           time.Time{},
+	}
+
+	return newConfig
+}
+
+// NetworkPayloadConfig provides a configuration to create a new network event
+// of the given kind, being associated with the given network payload. The
+// behaviour ID, CLG name, CLG tree ID and session ID are looked up using the
+// context of the given network payload.
+func NetworkPayloadConfig(kind string, networkPayload objectspec.NetworkPayload) Config {
+	newConfig := DefaultConfig()
+	newConfig.Kind = kind
+	newConfig.NetworkPayload = networkPayload
+
+	ctx := networkPayload.GetContext()
+	newConfig.BehaviourID, _ = ctx.GetBehaviourID()
+	newConfig.CLGName, _ = ctx.GetCLGName()
+	newConfig.CLGTreeID, _ = ctx.GetCLGTreeID()
+	newConfig.SessionID, _ = ctx.GetSessionID()
+
+	return newConfig
+}
+
+// New creates a new configured network event object.
+func New(config Config) (objectspec.NetworkEvent, error) {
+	if config.Kind == "" {
+		return nil, maskAnyf(invalidConfigError, "kind must not be empty")
+	}
+	if config.CLGTreeID == "" {
+		return nil, maskAnyf(invalidConfigError, "CLG tree ID must not be empty")
+	}
+	if config.Time.IsZero() {
+		config.Time = time.Now()
+	}
+
+	newObject := &networkEvent{
+		Config: config,
+	}
+
+	return newObject, nil
+}
+
+type networkEvent struct {
+	Config
+}
+
+func (ne *networkEvent) GetBehaviourID() string {
+	return ne.BehaviourID
+}
+
+func (ne *networkEvent) GetCLGName() string {
+	return ne.CLGName
+}
+
+func (ne *networkEvent) GetCLGTreeID() string {
+	return ne.CLGTreeID
+}
+
+func (ne *networkEvent) GetDuration() time.Duration {
+	return ne.Duration
+}
+
+func (ne *networkEvent) GetError() string {
+	return ne.Error
+}
+
+func (ne *networkEvent) GetKind() string {
+	return ne.Kind
+}
+
+func (ne *networkEvent) GetNetworkPayload() objectspec.NetworkPayload {
+	return ne.NetworkPayload
+}
+
+func (ne *networkEvent) GetSessionID() string {
+	return ne.SessionID
+}
+
+func (ne *networkEvent) GetTime() time.Time {
+	return ne.Time
+}
@@ -0,0 +1,90 @@
+package networkevent
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/the-anna-project/annad/object/context"
+	"github.com/the-anna-project/annad/object/networkpayload"
+)
+
+func Test_NetworkEvent_New_Error(t *testing.T) {
+	newConfig := DefaultConfig()
+	newConfig.CLGTreeID = "tree-1"
+	_, err := New(newConfig)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	newConfig = DefaultConfig()
+	newConfig.Kind = KindActivated
+	_, err = New(newConfig)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+func Test_NetworkEvent_NetworkPayloadConfig(t *testing.T) {
+	ctx := context.MustNew()
+	ctx.SetBehaviourID("behaviour-1")
+	ctx.SetCLGName("sum")
+	ctx.SetCLGTreeID("tree-1")
+	ctx.SetSessionID("session-1")
+
+	newNetworkPayloadConfig := networkpayload.DefaultConfig()
+	newNetworkPayloadConfig.Context = ctx
+	newNetworkPayloadConfig.ID = "network-payload-1"
+	newNetworkPayload, err := networkpayload.New(newNetworkPayloadConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	newConfig := NetworkPayloadConfig(KindCalculated, newNetworkPayload)
+	newConfig.Duration = time.Millisecond
+	newConfig.Time = time.Unix(0, 0).UTC()
+	newNetworkEvent, err := New(newConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	b, err := json.Marshal(newNetworkEvent)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	expected := `{"behaviour_id":"behaviour-1","clg_name":"sum","clg_tree_id":"tree-1","duration":1000000,"kind":"calculated","network_payload_id":"network-payload-1","session_id":"session-1","time":"1970-01-01T00:00:00Z"}`
+	if string(b) != expected {
+		t.Fatal("expected", expected, "got", string(b))
+	}
+}
+
+func Test_NetworkEvent_MarshalJSON_Output(t *testing.T) {
+	ctx := context.MustNew()
+	ctx.SetCLGTreeID("tree-1")
+
+	newNetworkPayloadConfig := networkpayload.DefaultConfig()
+	newNetworkPayloadConfig.Args = []reflect.Value{reflect.ValueOf("hello")}
+	newNetworkPayloadConfig.Context = ctx
+	newNetworkPayloadConfig.ID = "network-payload-1"
+	newNetworkPayload, err := networkpayload.New(newNetworkPayloadConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	newConfig := NetworkPayloadConfig(KindOutputEmitted, newNetworkPayload)
+	newConfig.Time = time.Unix(0, 0).UTC()
+	newNetworkEvent, err := New(newConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	b, err := json.Marshal(newNetworkEvent)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	expected := `{"clg_tree_id":"tree-1","kind":"output-emitted","network_payload_id":"network-payload-1","output":"hello","time":"1970-01-01T00:00:00Z"}`
+	if string(b) != expected {
+		t.Fatal("expected", expected, "got", string(b))
+	}
+}
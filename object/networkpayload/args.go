@@ -0,0 +1,69 @@
+package networkpayload
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// jsonArg represents a single argument of a network payload being encoded as
+// JSON. JSON does not preserve Go types, so the type name is stored alongside
+// the value to be able to restore the argument.
+type jsonArg struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// argTypes provides a mapping of type names pointing to the types of the
+// arguments network payloads are able to carry through JSON.
+var argTypes = map[string]reflect.Type{}
+
+func init() {
+	for _, v := range []interface{}{
+		false, float64(0), int(0), "",
+		[]bool{}, []float64{}, []int{}, []string{},
+	} {
+		t := reflect.TypeOf(v)
+		argTypes[t.String()] = t
+	}
+}
+
+// marshalArgs encodes the given arguments including their type names.
+func marshalArgs(args []reflect.Value) ([]jsonArg, error) {
+	var jsonArgs []jsonArg
+
+	for _, a := range args {
+		if !a.IsValid() {
+			return nil, maskAnyf(invalidArgError, "must not be empty")
+		}
+		if _, ok := argTypes[a.Type().String()]; !ok {
+			return nil, maskAnyf(invalidArgError, "type %s not supported", a.Type())
+		}
+		b, err := json.Marshal(a.Interface())
+		if err != nil {
+			return nil, maskAny(err)
+		}
+		jsonArgs = append(jsonArgs, jsonArg{Type: a.Type().String(), Value: b})
+	}
+
+	return jsonArgs, nil
+}
+
+// unmarshalArgs restores the arguments encoded by marshalArgs.
+func unmarshalArgs(jsonArgs []jsonArg) ([]reflect.Value, error) {
+	var args []reflect.Value
+
+	for _, a := range jsonArgs {
+		t, ok := argTypes[a.Type]
+		if !ok {
+			return nil, maskAnyf(invalidArgError, "type %s not supported", a.Type)
+		}
+		v := reflect.New(t)
+		err := json.Unmarshal(a.Value, v.Interface())
+		if err != nil {
+			return nil, maskAny(err)
+		}
+		args = append(args, v.Elem())
+	}
+
+	return args, nil
+}
@@ -28,3 +28,10 @@ var invalidConfigError = errgo.New("invalid config")
 func IsInvalidConfig(err error) bool {
 	return errgo.Cause(err) == invalidConfigError
 }
+
+var invalidArgError = errgo.New("invalid arg")
+
+// IsInvalidArg asserts invalidArgError.
+func IsInvalidArg(err error) bool {
+	return errgo.Cause(err) == invalidArgError
+}
@@ -7,10 +7,17 @@ import (
 func (np *networkPayload) MarshalJSON() ([]byte, error) {
 	type NetworkPayloadClone networkPayload
 
+	args, err := marshalArgs(np.Args)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
 	b, err := json.Marshal(&struct {
 		*NetworkPayloadClone
+		Args []jsonArg
 	}{
 		NetworkPayloadClone: (*NetworkPayloadClone)(np),
+		Args:                args,
 	})
 	if err != nil {
 		return nil, maskAny(err)
@@ -9,6 +9,7 @@ func (np *networkPayload) UnmarshalJSON(b []byte) error {
 
 	aux := &struct {
 		*NetworkPayloadClone
+		Args []jsonArg
 	}{
 		NetworkPayloadClone: (*NetworkPayloadClone)(np),
 	}
@@ -17,5 +18,10 @@ func (np *networkPayload) UnmarshalJSON(b []byte) error {
 		return maskAny(err)
 	}
 
+	np.Args, err = unmarshalArgs(aux.Args)
+	if err != nil {
+		return maskAny(err)
+	}
+
 	return nil
 }
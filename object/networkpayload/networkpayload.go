@@ -4,7 +4,7 @@ import (
 	"reflect"
 
 	"github.com/the-anna-project/annad/object/context"
-	objectspec "github.com/the-anna-project/spec/object"
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
 // Config represents the configuration used to create a new
@@ -19,10 +19,26 @@ type Config struct {
 
 	Context objectspec.Context
 
+	// Depth represents the number of hops the current network payload travelled
+	// within its CLG tree. Each forwarding step increments the depth of the
+	// forwarded network payload by one.
+	Depth int
+
 	// Destination represents the object ID of the CLG receiving the current
 	// network payload.
 	Destination string
 
+	// ID represents the unique ID of the current network payload. It is
+	// supposed to be created using the ID service, which makes it reproducible
+	// the same way all other IDs of the neural network are.
+	ID string
+
+	// Path represents the behaviour IDs of the CLGs the current network payload
+	// passed within its CLG tree, ordered from the first to the most recent
+	// one. Each forwarding step appends the behaviour ID of the forwarding CLG.
+	// When network payloads are merged, their paths are merged as well.
+	Path []string
+
 	// Sources represents the object IDs of the CLGs being involved providing the
 	// current network payload. In fact, a network payload can only be sent by
 	// one CLG. Reason for this being a list is the merge of network payloads
@@ -39,19 +55,21 @@ func DefaultConfig() Config {
 	newConfig := Config{
 		Args:        nil,
 		Context:     context.MustNew(),
+		Depth:       0,
 		Destination: "",
+		ID:          "",
+		Path:        nil,
 		Sources:     nil,
 	}
 
 	return newConfig
 }
 
-// New creates a new configured network payload object.
+// New creates a new configured network payload object. Each network payload
+// is identified by the ID of its configuration.
 func New(config Config) (objectspec.NetworkPayload, error) {
 	newObject := &networkPayload{
 		Config: config,
-
-		ID: "",
 	}
 
 	return newObject, nil
@@ -70,8 +88,6 @@ func MustNew() objectspec.NetworkPayload {
 
 type networkPayload struct {
 	Config
-
-	ID string
 }
 
 func (np *networkPayload) GetArgs() []reflect.Value {
@@ -86,6 +102,10 @@ func (np *networkPayload) GetCLGInput() []reflect.Value {
 	return append([]reflect.Value{reflect.ValueOf(np.GetContext())}, np.GetArgs()...)
 }
 
+func (np *networkPayload) GetDepth() int {
+	return np.Depth
+}
+
 func (np *networkPayload) GetDestination() string {
 	return np.Destination
 }
@@ -94,6 +114,10 @@ func (np *networkPayload) GetID() string {
 	return np.ID
 }
 
+func (np *networkPayload) GetPath() []string {
+	return np.Path
+}
+
 func (np *networkPayload) GetSources() []string {
 	return np.Sources
 }
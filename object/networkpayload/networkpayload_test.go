@@ -0,0 +1,90 @@
+package networkpayload
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func Test_NetworkPayload_New_ID(t *testing.T) {
+	newConfig := DefaultConfig()
+	newConfig.ID = "network-payload-id"
+	newNetworkPayload, err := New(newConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	if newNetworkPayload.GetID() != "network-payload-id" {
+		t.Fatal("expected", "network-payload-id", "got", newNetworkPayload.GetID())
+	}
+}
+
+func Test_NetworkPayload_JSON_ID(t *testing.T) {
+	newConfig := DefaultConfig()
+	newConfig.ID = "network-payload-id"
+	newNetworkPayload, err := New(newConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	b, err := json.Marshal(newNetworkPayload)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	decoded := MustNew()
+	err = json.Unmarshal(b, &decoded)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	if decoded.GetID() != newNetworkPayload.GetID() {
+		t.Fatal("expected", newNetworkPayload.GetID(), "got", decoded.GetID())
+	}
+}
+
+func Test_NetworkPayload_JSON_Args(t *testing.T) {
+	args := []interface{}{3.5, 4, "foo", true, []string{"a", "b"}}
+
+	newConfig := DefaultConfig()
+	for _, a := range args {
+		newConfig.Args = append(newConfig.Args, reflect.ValueOf(a))
+	}
+	newNetworkPayload, err := New(newConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	b, err := json.Marshal(newNetworkPayload)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	decoded := MustNew()
+	err = json.Unmarshal(b, &decoded)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	// The types of the arguments are preserved, so 4 is still an int and not
+	// a float64 like JSON numbers usually are.
+	var decodedArgs []interface{}
+	for _, a := range decoded.GetArgs() {
+		decodedArgs = append(decodedArgs, a.Interface())
+	}
+	if !reflect.DeepEqual(decodedArgs, args) {
+		t.Fatal("expected", args, "got", decodedArgs)
+	}
+}
+
+func Test_NetworkPayload_JSON_Args_Error(t *testing.T) {
+	newConfig := DefaultConfig()
+	newConfig.Args = []reflect.Value{reflect.ValueOf(map[string]string{})}
+	newNetworkPayload, err := New(newConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	_, err = newNetworkPayload.MarshalJSON()
+	if !IsInvalidArg(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
@@ -0,0 +1,78 @@
+// Package text provides a simple object for receiving text output.
+package text
+
+import (
+	objectspec "github.com/the-anna-project/annad/spec/object"
+)
+
+// New creates a new text output object.
+func New() objectspec.TextOutput {
+	return &object{}
+}
+
+type object struct {
+	// Settings.
+
+	// attempts represents the number of outputs not meeting the expectation of
+	// the request, if the neural network gave up on meeting it.
+	attempts int
+	// candidates represents the ranked outputs of the CLG tree, if any.
+	candidates []objectspec.Candidate
+	// clgTreeID represents the ID of the CLG tree which calculated the output.
+	clgTreeID string
+	// code represents the API response code of the output, if any.
+	code string
+	// output represents the output being calculated by the neural network.
+	output string
+	// score represents how close the output comes to the expectation of the
+	// request, if the neural network gave up on meeting it.
+	score float64
+}
+
+func (ti *object) Attempts() int {
+	return ti.attempts
+}
+
+func (ti *object) Candidates() []objectspec.Candidate {
+	return ti.candidates
+}
+
+func (ti *object) CLGTreeID() string {
+	return ti.clgTreeID
+}
+
+func (ti *object) Code() string {
+	return ti.code
+}
+
+func (ti *object) Output() string {
+	return ti.output
+}
+
+func (ti *object) Score() float64 {
+	return ti.score
+}
+
+func (ti *object) SetAttempts(attempts int) {
+	ti.attempts = attempts
+}
+
+func (ti *object) SetCandidates(candidates []objectspec.Candidate) {
+	ti.candidates = candidates
+}
+
+func (ti *object) SetCLGTreeID(clgTreeID string) {
+	ti.clgTreeID = clgTreeID
+}
+
+func (ti *object) SetCode(code string) {
+	ti.code = code
+}
+
+func (ti *object) SetOutput(output string) {
+	ti.output = output
+}
+
+func (ti *object) SetScore(score float64) {
+	ti.score = score
+}
@@ -2,8 +2,8 @@
 package text
 
 import (
-	objectspec "github.com/the-anna-project/spec/object"
-	servicespec "github.com/the-anna-project/spec/service"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
 )
 
 // New creates a new text output service.
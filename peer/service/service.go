@@ -7,7 +7,7 @@ import (
 	"sync"
 	"time"
 
-	servicespec "github.com/the-anna-project/spec/service"
+	servicespec "github.com/the-anna-project/annad/spec/service"
 )
 
 // New creates a new peer service.
@@ -1,7 +1,7 @@
 package list
 
 import (
-	objectspec "github.com/the-anna-project/spec/object"
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
 // New creates a new permutation list object.
@@ -1,7 +1,7 @@
 package permutation
 
 import (
-	objectspec "github.com/the-anna-project/spec/object"
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
 func createIndizesWithDelta(list objectspec.PermutationList, delta int) ([]int, error) {
@@ -34,8 +34,8 @@
 package permutation
 
 import (
-	objectspec "github.com/the-anna-project/spec/object"
-	servicespec "github.com/the-anna-project/spec/service"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
 )
 
 // New creates a new permutation service.
@@ -4,7 +4,7 @@ import (
 	"reflect"
 	"testing"
 
-	permutationlist "github.com/the-anna-project/permutation/object/list"
+	permutationlist "github.com/the-anna-project/annad/permutation/object/list"
 )
 
 // Test_Permutation_Service_PermuteBy_AbsoluteDelta tests permutations by
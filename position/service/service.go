@@ -6,7 +6,7 @@ import (
 	"strconv"
 	"strings"
 
-	servicespec "github.com/the-anna-project/spec/service"
+	servicespec "github.com/the-anna-project/annad/spec/service"
 )
 
 // Config represents the configuration used to create a new position service.
@@ -0,0 +1,46 @@
+package metric
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// modeEndpoint is the HTTP endpoint reporting and changing the mode of the
+	// neural network.
+	modeEndpoint = "/mode"
+)
+
+// mode represents the mode of the neural network as reported by modeHandler.
+type mode struct {
+	Frozen bool `json:"frozen"`
+}
+
+// modeHandler responds with the current mode of the neural network. Using PUT
+// or POST the neural network is frozen or unfrozen at runtime according to the
+// query parameter frozen, e.g. /mode?frozen=true. Then the response describes
+// the changed mode.
+func (s *service) modeHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+	case "POST", "PUT":
+		frozen, err := strconv.ParseBool(r.URL.Query().Get("frozen"))
+		if err != nil {
+			http.Error(w, "query parameter frozen must be a boolean", http.StatusBadRequest)
+			return
+		}
+		s.Service().Network().SetFrozen(frozen)
+		s.Service().Log().Line("msg", "network frozen: %t", frozen)
+	default:
+		w.Header().Set("Allow", "GET, POST, PUT")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(mode{Frozen: s.Service().Network().Frozen()})
+	if err != nil {
+		s.Service().Log().Line("msg", "%#v", maskAny(err))
+	}
+}
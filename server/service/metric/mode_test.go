@@ -0,0 +1,102 @@
+package metric
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	kitlog "github.com/go-kit/kit/log"
+
+	servicecollection "github.com/the-anna-project/annad/collection/collection"
+	"github.com/the-anna-project/annad/log"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+)
+
+// testNetwork is a network only providing its mode. Calling any other method
+// panics.
+type testNetwork struct {
+	servicespec.NetworkService
+	frozen bool
+}
+
+func (n *testNetwork) Frozen() bool          { return n.frozen }
+func (n *testNetwork) SetFrozen(frozen bool) { n.frozen = frozen }
+
+func Test_Metric_modeHandler(t *testing.T) {
+	newLogService := log.New()
+	newLogService.SetRootLogger(kitlog.NewNopLogger())
+
+	newNetwork := &testNetwork{}
+
+	collection := servicecollection.New()
+	collection.SetLogService(newLogService)
+	collection.SetNetworkService(newNetwork)
+
+	collection.Log().SetServiceCollection(collection)
+
+	newService := &service{
+		serviceCollection: collection,
+	}
+
+	testCases := []struct {
+		Method         string
+		Query          string
+		ExpectedCode   int
+		ExpectedBody   string
+		ExpectedFrozen bool
+	}{
+		{
+			Method:         "GET",
+			Query:          "",
+			ExpectedCode:   http.StatusOK,
+			ExpectedBody:   `{"frozen":false}`,
+			ExpectedFrozen: false,
+		},
+		{
+			Method:         "PUT",
+			Query:          "?frozen=true",
+			ExpectedCode:   http.StatusOK,
+			ExpectedBody:   `{"frozen":true}`,
+			ExpectedFrozen: true,
+		},
+		{
+			Method:         "POST",
+			Query:          "?frozen=foo",
+			ExpectedCode:   http.StatusBadRequest,
+			ExpectedBody:   "query parameter frozen must be a boolean",
+			ExpectedFrozen: true,
+		},
+		{
+			Method:         "DELETE",
+			Query:          "",
+			ExpectedCode:   http.StatusMethodNotAllowed,
+			ExpectedBody:   "method not allowed",
+			ExpectedFrozen: true,
+		},
+		{
+			Method:         "POST",
+			Query:          "?frozen=false",
+			ExpectedCode:   http.StatusOK,
+			ExpectedBody:   `{"frozen":false}`,
+			ExpectedFrozen: false,
+		},
+	}
+
+	for i, testCase := range testCases {
+		request := httptest.NewRequest(testCase.Method, modeEndpoint+testCase.Query, nil)
+		recorder := httptest.NewRecorder()
+		newService.modeHandler(recorder, request)
+
+		if recorder.Code != testCase.ExpectedCode {
+			t.Fatal("case", i+1, "expected", testCase.ExpectedCode, "got", recorder.Code)
+		}
+		body := strings.TrimSpace(recorder.Body.String())
+		if body != testCase.ExpectedBody {
+			t.Fatal("case", i+1, "expected", testCase.ExpectedBody, "got", body)
+		}
+		if newNetwork.Frozen() != testCase.ExpectedFrozen {
+			t.Fatal("case", i+1, "expected", testCase.ExpectedFrozen, "got", newNetwork.Frozen())
+		}
+	}
+}
@@ -1,5 +1,7 @@
 // Package metric implements a HTTP server to provide Anna's metrics
-// over network.
+// over network. Besides the metrics, the network events published on the bus
+// are streamed live using the /events endpoint. The mode of the neural network
+// is reported and changed using the /mode endpoint.
 package metric
 
 import (
@@ -9,7 +11,7 @@ import (
 
 	"github.com/tylerb/graceful"
 
-	servicespec "github.com/the-anna-project/spec/service"
+	servicespec "github.com/the-anna-project/annad/spec/service"
 )
 
 // New creates a new metric endpoint service.
@@ -47,19 +49,25 @@ func (s *service) Boot() {
 			"type": "service",
 		}
 
-		s.bootOnce = sync.Once{}
+		// The handlers are registered using a dedicated multiplexer instead of
+		// http.DefaultServeMux. That way multiple metric endpoints can be booted
+		// within the same process, e.g. by integration tests.
+		mux := http.NewServeMux()
+		mux.Handle(s.Service().Instrumentor().GetHTTPEndpoint(), s.Service().Instrumentor().GetHTTPHandler())
+		mux.HandleFunc(modeEndpoint, s.modeHandler)
+		mux.HandleFunc(streamEndpoint, s.streamHandler)
+
 		s.closer = make(chan struct{}, 1)
 		s.httpServer = &graceful.Server{
 			NoSignalHandling: true,
 			Server: &http.Server{
-				Addr: s.address,
+				Addr:    s.address,
+				Handler: mux,
 			},
 			Timeout: 3 * time.Second,
 		}
 		s.shutdownOnce = sync.Once{}
 
-		http.Handle(s.Service().Instrumentor().GetHTTPEndpoint(), s.Service().Instrumentor().GetHTTPHandler())
-
 		go func() {
 			s.Service().Log().Line("msg", "HTTP server starts to listen on '%s'", s.address)
 			err := s.httpServer.ListenAndServe()
@@ -90,6 +98,10 @@ func (s *service) Shutdown() {
 	s.Service().Log().Line("func", "Shutdown")
 
 	s.shutdownOnce.Do(func() {
+		if s.closer == nil {
+			// The endpoint was never booted, so there is no HTTP server to stop.
+			return
+		}
 		close(s.closer)
 
 		var wg sync.WaitGroup
@@ -0,0 +1,137 @@
+package metric
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	objectspec "github.com/the-anna-project/annad/spec/object"
+)
+
+const (
+	// streamEndpoint is the HTTP endpoint streaming network events.
+	streamEndpoint = "/events"
+)
+
+// streamFilter decides which network events are streamed to a client. Empty
+// lists match any network event.
+type streamFilter struct {
+	clgTreeIDs []string
+	kinds      []string
+	sessionIDs []string
+}
+
+// newStreamFilter creates a new stream filter using the given query. The
+// query parameters clg_tree_id, kind and session_id may be given multiple
+// times, or as comma separated lists.
+func newStreamFilter(query url.Values) streamFilter {
+	values := func(key string) []string {
+		var result []string
+		for _, v := range query[key] {
+			for _, s := range strings.Split(v, ",") {
+				if s != "" {
+					result = append(result, s)
+				}
+			}
+		}
+		return result
+	}
+
+	newFilter := streamFilter{
+		clgTreeIDs: values("clg_tree_id"),
+		kinds:      values("kind"),
+		sessionIDs: values("session_id"),
+	}
+
+	return newFilter
+}
+
+// match checks whether the given network event passes the stream filter.
+func (f streamFilter) match(networkEvent objectspec.NetworkEvent) bool {
+	return matchAny(f.clgTreeIDs, networkEvent.GetCLGTreeID()) && matchAny(f.kinds, networkEvent.GetKind()) && matchAny(f.sessionIDs, networkEvent.GetSessionID())
+}
+
+func matchAny(list []string, value string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, l := range list {
+		if l == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// streamHandler streams the network events published on the bus to the
+// client as they happen. Clients accepting text/event-stream receive
+// Server-Sent Events. All other clients receive one JSON object per line. See
+// newStreamFilter for the supported query parameters.
+func (s *service) streamHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	filter := newStreamFilter(r.URL.Query())
+
+	// Each client gets its own subscription, so slow clients only drop network
+	// events for themselves.
+	id, err := s.Service().ID().New()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	name := fmt.Sprintf("stream-%s", id)
+	networkEvents, err := s.Service().Bus().Subscribe(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer s.Service().Bus().Unsubscribe(name)
+
+	sse := strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+	if sse {
+		w.Header().Set("Content-Type", "text/event-stream")
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-s.closer:
+			return
+		case <-r.Context().Done():
+			return
+		case networkEvent, ok := <-networkEvents:
+			if !ok {
+				return
+			}
+			if !filter.match(networkEvent) {
+				continue
+			}
+
+			b, err := json.Marshal(networkEvent)
+			if err != nil {
+				s.Service().Log().Line("msg", "%#v", maskAny(err))
+				continue
+			}
+			if sse {
+				_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", networkEvent.GetKind(), b)
+			} else {
+				_, err = fmt.Fprintf(w, "%s\n", b)
+			}
+			if err != nil {
+				// The client went away.
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
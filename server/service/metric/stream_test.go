@@ -0,0 +1,179 @@
+package metric
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+
+	servicecollection "github.com/the-anna-project/annad/collection/collection"
+	"github.com/the-anna-project/annad/id"
+	"github.com/the-anna-project/annad/log"
+	"github.com/the-anna-project/annad/random"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+)
+
+type testNetworkEvent struct {
+	clgTreeID string
+	kind      string
+	sessionID string
+}
+
+func (e *testNetworkEvent) GetBehaviourID() string                       { return "" }
+func (e *testNetworkEvent) GetCLGName() string                           { return "" }
+func (e *testNetworkEvent) GetCLGTreeID() string                         { return e.clgTreeID }
+func (e *testNetworkEvent) GetDuration() time.Duration                   { return 0 }
+func (e *testNetworkEvent) GetError() string                             { return "" }
+func (e *testNetworkEvent) GetKind() string                              { return e.kind }
+func (e *testNetworkEvent) GetNetworkPayload() objectspec.NetworkPayload { return nil }
+func (e *testNetworkEvent) GetSessionID() string                         { return e.sessionID }
+func (e *testNetworkEvent) GetTime() time.Time                           { return time.Time{} }
+
+func (e *testNetworkEvent) MarshalJSON() ([]byte, error) {
+	return []byte(`{"clg_tree_id":"` + e.clgTreeID + `","kind":"` + e.kind + `"}`), nil
+}
+
+// testBus is a bus having a single subscription. The subscription is signalled
+// using subscribed.
+type testBus struct {
+	events     chan objectspec.NetworkEvent
+	subscribed chan struct{}
+}
+
+func (b *testBus) Boot()                                                 {}
+func (b *testBus) Dropped(name string) int64                             { return 0 }
+func (b *testBus) Metadata() map[string]string                           { return nil }
+func (b *testBus) Publish(networkEvent objectspec.NetworkEvent)          { b.events <- networkEvent }
+func (b *testBus) Service() servicespec.ServiceCollection                { return nil }
+func (b *testBus) SetServiceCollection(sc servicespec.ServiceCollection) {}
+func (b *testBus) Shutdown()                                             {}
+func (b *testBus) Unsubscribe(name string)                               {}
+func (b *testBus) Subscribe(name string) (<-chan objectspec.NetworkEvent, error) {
+	close(b.subscribed)
+	return b.events, nil
+}
+func (b *testBus) SubscribeBlocking(name string) (<-chan objectspec.NetworkEvent, error) {
+	return b.Subscribe(name)
+}
+
+func Test_Metric_streamFilter(t *testing.T) {
+	testCases := []struct {
+		Query    string
+		Event    *testNetworkEvent
+		Expected bool
+	}{
+		{
+			Query:    "",
+			Event:    &testNetworkEvent{clgTreeID: "tree-1", kind: "activated", sessionID: "session-1"},
+			Expected: true,
+		},
+		{
+			Query:    "clg_tree_id=tree-1",
+			Event:    &testNetworkEvent{clgTreeID: "tree-1", kind: "activated", sessionID: "session-1"},
+			Expected: true,
+		},
+		{
+			Query:    "clg_tree_id=tree-2",
+			Event:    &testNetworkEvent{clgTreeID: "tree-1", kind: "activated", sessionID: "session-1"},
+			Expected: false,
+		},
+		{
+			Query:    "session_id=session-2,session-1&kind=activated",
+			Event:    &testNetworkEvent{clgTreeID: "tree-1", kind: "activated", sessionID: "session-1"},
+			Expected: true,
+		},
+		{
+			Query:    "session_id=session-1&kind=calculated&kind=forwarded",
+			Event:    &testNetworkEvent{clgTreeID: "tree-1", kind: "activated", sessionID: "session-1"},
+			Expected: false,
+		},
+	}
+
+	for i, testCase := range testCases {
+		query, err := url.ParseQuery(testCase.Query)
+		if err != nil {
+			t.Fatal("case", i+1, "expected", nil, "got", err)
+		}
+		output := newStreamFilter(query).match(testCase.Event)
+		if output != testCase.Expected {
+			t.Fatal("case", i+1, "expected", testCase.Expected, "got", output)
+		}
+	}
+}
+
+func Test_Metric_streamHandler(t *testing.T) {
+	newBus := &testBus{
+		events:     make(chan objectspec.NetworkEvent, 10),
+		subscribed: make(chan struct{}),
+	}
+
+	newLogService := log.New()
+	newLogService.SetRootLogger(kitlog.NewNopLogger())
+
+	collection := servicecollection.New()
+	collection.SetBusService(newBus)
+	collection.SetIDService(id.New())
+	collection.SetLogService(newLogService)
+	collection.SetRandomService(random.New())
+
+	collection.ID().SetServiceCollection(collection)
+	collection.Log().SetServiceCollection(collection)
+	collection.Random().SetServiceCollection(collection)
+
+	newService := &service{
+		closer:            make(chan struct{}),
+		serviceCollection: collection,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(newService.streamHandler))
+	defer server.Close()
+	defer close(newService.closer)
+
+	for _, accept := range []string{"application/x-ndjson", "text/event-stream"} {
+		newBus.subscribed = make(chan struct{})
+
+		request, err := http.NewRequest("GET", server.URL+"?clg_tree_id=tree-1", nil)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		request.Header.Set("Accept", accept)
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		<-newBus.subscribed
+
+		// The network event of the other CLG tree is filtered.
+		newBus.Publish(&testNetworkEvent{clgTreeID: "tree-2", kind: "activated"})
+		newBus.Publish(&testNetworkEvent{clgTreeID: "tree-1", kind: "calculated"})
+
+		reader := bufio.NewReader(response.Body)
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		if accept == "text/event-stream" {
+			if line != "event: calculated\n" {
+				t.Fatal("expected", "event: calculated", "got", line)
+			}
+			line, err = reader.ReadString('\n')
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+		}
+		if !strings.Contains(line, `"clg_tree_id":"tree-1"`) {
+			t.Fatal("expected", "tree-1", "got", line)
+		}
+		if response.Header.Get("Content-Type") != accept {
+			t.Fatal("expected", accept, "got", response.Header.Get("Content-Type"))
+		}
+
+		response.Body.Close()
+	}
+}
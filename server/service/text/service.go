@@ -11,10 +11,11 @@ import (
 
 	"google.golang.org/grpc"
 
-	textinputobject "github.com/the-anna-project/input/object/text"
-	apispec "github.com/the-anna-project/spec/api"
-	objectspec "github.com/the-anna-project/spec/object"
-	servicespec "github.com/the-anna-project/spec/service"
+	expectationobject "github.com/the-anna-project/annad/input/object/expectation"
+	textinputobject "github.com/the-anna-project/annad/input/object/text"
+	apispec "github.com/the-anna-project/annad/spec/api"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
 )
 
 // New creates a new text endpoint service.
@@ -54,7 +55,6 @@ func (s *service) Boot() {
 			"type": "service",
 		}
 
-		s.bootOnce = sync.Once{}
 		s.closer = make(chan struct{}, 1)
 		s.gRPCServer = grpc.NewServer()
 		s.shutdownOnce = sync.Once{}
@@ -91,10 +91,39 @@ func (s *service) DecodeResponse(textOutput objectspec.TextOutput) *StreamTextRe
 	streamTextResponse := &StreamTextResponse{
 		Code: apispec.CodeData,
 		Data: &StreamTextResponseData{
-			Output: textOutput.Output(),
+			Attempts:  int64(textOutput.Attempts()),
+			CLGTreeID: textOutput.CLGTreeID(),
+			Output:    textOutput.Output(),
+			Score:     textOutput.Score(),
 		},
 		Text: apispec.TextData,
 	}
+	for _, c := range textOutput.Candidates() {
+		streamTextResponseCandidate := &StreamTextResponseCandidate{
+			BehaviourIDs: c.BehaviourIDs,
+			Output:       c.Output,
+			Score:        c.Score,
+		}
+		streamTextResponse.Data.Candidates = append(streamTextResponse.Data.Candidates, streamTextResponseCandidate)
+	}
+
+	// Output carrying a code is not data calculated by the neural network, but
+	// tells the client why there is no such data.
+	switch textOutput.Code() {
+	case "":
+	case apispec.CodeBudgetExhausted:
+		streamTextResponse.Code = apispec.CodeBudgetExhausted
+		streamTextResponse.Text = apispec.TextBudgetExhausted
+	case apispec.CodeNoLearnedPath:
+		streamTextResponse.Code = apispec.CodeNoLearnedPath
+		streamTextResponse.Text = apispec.TextNoLearnedPath
+	case apispec.CodeRetriesExhausted:
+		streamTextResponse.Code = apispec.CodeRetriesExhausted
+		streamTextResponse.Text = apispec.TextRetriesExhausted
+	default:
+		streamTextResponse.Code = textOutput.Code()
+		streamTextResponse.Text = apispec.TextError
+	}
 
 	return streamTextResponse
 }
@@ -102,10 +131,46 @@ func (s *service) DecodeResponse(textOutput objectspec.TextOutput) *StreamTextRe
 func (s *service) EncodeRequest(streamTextRequest *StreamTextRequest) (objectspec.TextInput, error) {
 	textInputObject := textinputobject.New()
 	textInputObject.SetEcho(streamTextRequest.Echo)
-	//textInputObject.SetExpectation(streamTextRequest.Expectation)
+	// An empty expectation means there is none. Then the request is handled
+	// interactively instead of being a training request. The matcher, outputs
+	// and threshold only refine an expectation.
+	if streamTextRequest.Expectation != "" {
+		expectation := expectationobject.New(streamTextRequest.Expectation)
+		expectation.SetMatcher(streamTextRequest.ExpectationMatcher)
+		expectation.SetOutputs(streamTextRequest.ExpectationOutputs)
+		expectation.SetThreshold(streamTextRequest.ExpectationThreshold)
+		textInputObject.SetExpectation(expectation)
+	}
 	textInputObject.SetInput(streamTextRequest.Input)
 	textInputObject.SetSessionID(streamTextRequest.SessionID)
 
+	// Requests not limiting their retries use the defaults of the network.
+	if streamTextRequest.Retries < 0 {
+		return nil, maskAnyf(invalidRequestError, "retries must not be negative")
+	}
+	textInputObject.SetRetries(int(streamTextRequest.Retries))
+	if streamTextRequest.Backoff != "" {
+		backoff, err := time.ParseDuration(streamTextRequest.Backoff)
+		if err != nil {
+			return nil, maskAnyf(invalidRequestError, "%s", err)
+		}
+		if backoff < 0 {
+			return nil, maskAnyf(invalidRequestError, "backoff must not be negative")
+		}
+		textInputObject.SetBackoff(backoff)
+	}
+	// Requests not limiting their deadline use the default of the network.
+	if streamTextRequest.Deadline != "" {
+		deadline, err := time.ParseDuration(streamTextRequest.Deadline)
+		if err != nil {
+			return nil, maskAnyf(invalidRequestError, "%s", err)
+		}
+		if deadline < 0 {
+			return nil, maskAnyf(invalidRequestError, "deadline must not be negative")
+		}
+		textInputObject.SetDeadline(deadline)
+	}
+
 	return textInputObject, nil
 }
 
@@ -129,6 +194,10 @@ func (s *service) Shutdown() {
 	s.Service().Log().Line("func", "Shutdown")
 
 	s.shutdownOnce.Do(func() {
+		if s.closer == nil {
+			// The endpoint was never booted, so there is no gRPC server to stop.
+			return
+		}
 		close(s.closer)
 
 		var wg sync.WaitGroup
@@ -150,8 +219,19 @@ func (s *service) Shutdown() {
 func (s *service) StreamText(stream TextEndpoint_StreamTextServer) error {
 	s.Service().Log().Line("func", "StreamText")
 
+	// done is closed as soon as the stream ended, either by the client or by
+	// StreamText returning. Closing is guarded, because both can happen.
+	var doneOnce sync.Once
 	done := make(chan struct{}, 1)
-	fail := make(chan error, 1)
+	closeDone := func() {
+		doneOnce.Do(func() {
+			close(done)
+		})
+	}
+	defer closeDone()
+	// Both goroutines below might fail. fail is buffered for both, so none of
+	// them blocks forever in case StreamText already returned.
+	fail := make(chan error, 2)
 
 	// Listen on the server input stream and forward it to the neural network.
 	go func() {
@@ -160,7 +240,7 @@ func (s *service) StreamText(stream TextEndpoint_StreamTextServer) error {
 			if err == io.EOF {
 				// The stream ended. We broadcast to all goroutines by closing the done
 				// channel.
-				close(done)
+				closeDone()
 				return
 			} else if err != nil {
 				fail <- maskAny(err)
@@ -172,7 +252,11 @@ func (s *service) StreamText(stream TextEndpoint_StreamTextServer) error {
 				fail <- maskAny(err)
 				return
 			}
-			s.Service().Input().Text().Channel() <- textRequest
+			select {
+			case <-done:
+				return
+			case s.Service().Input().Text().Channel() <- textRequest:
+			}
 		}
 	}()
 
@@ -194,15 +278,12 @@ func (s *service) StreamText(stream TextEndpoint_StreamTextServer) error {
 		}
 	}()
 
-	for {
-		select {
-		case <-stream.Context().Done():
-			close(done)
-			return maskAny(stream.Context().Err())
-		case <-done:
-			return nil
-		case err := <-fail:
-			return maskAny(err)
-		}
+	select {
+	case <-stream.Context().Done():
+		return maskAny(stream.Context().Err())
+	case <-done:
+		return nil
+	case err := <-fail:
+		return maskAny(err)
 	}
 }
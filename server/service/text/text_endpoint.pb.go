@@ -0,0 +1,383 @@
+// Code generated by protoc-gen-go.
+// source: text_endpoint.proto
+// DO NOT EDIT!
+
+/*
+Package text is a generated protocol buffer package.
+
+It is generated from these files:
+	text_endpoint.proto
+
+It has these top-level messages:
+	StreamTextRequest
+	StreamTextResponse
+	StreamTextResponseData
+	StreamTextResponseCandidate
+*/
+package text
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type StreamTextRequest struct {
+	Echo                 bool     `protobuf:"varint,1,opt,name=Echo,json=echo" json:"Echo,omitempty"`
+	Input                string   `protobuf:"bytes,2,opt,name=Input,json=input" json:"Input,omitempty"`
+	SessionID            string   `protobuf:"bytes,3,opt,name=SessionID,json=sessionID" json:"SessionID,omitempty"`
+	Expectation          string   `protobuf:"bytes,4,opt,name=Expectation,json=expectation" json:"Expectation,omitempty"`
+	ExpectationMatcher   string   `protobuf:"bytes,5,opt,name=ExpectationMatcher,json=expectationMatcher" json:"ExpectationMatcher,omitempty"`
+	ExpectationOutputs   []string `protobuf:"bytes,6,rep,name=ExpectationOutputs,json=expectationOutputs" json:"ExpectationOutputs,omitempty"`
+	ExpectationThreshold float64  `protobuf:"fixed64,7,opt,name=ExpectationThreshold,json=expectationThreshold" json:"ExpectationThreshold,omitempty"`
+	Retries              int64    `protobuf:"varint,8,opt,name=Retries,json=retries" json:"Retries,omitempty"`
+	Backoff              string   `protobuf:"bytes,9,opt,name=Backoff,json=backoff" json:"Backoff,omitempty"`
+	Deadline             string   `protobuf:"bytes,10,opt,name=Deadline,json=deadline" json:"Deadline,omitempty"`
+}
+
+func (m *StreamTextRequest) Reset()                    { *m = StreamTextRequest{} }
+func (m *StreamTextRequest) String() string            { return proto.CompactTextString(m) }
+func (*StreamTextRequest) ProtoMessage()               {}
+func (*StreamTextRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+func (m *StreamTextRequest) GetEcho() bool {
+	if m != nil {
+		return m.Echo
+	}
+	return false
+}
+
+func (m *StreamTextRequest) GetInput() string {
+	if m != nil {
+		return m.Input
+	}
+	return ""
+}
+
+func (m *StreamTextRequest) GetSessionID() string {
+	if m != nil {
+		return m.SessionID
+	}
+	return ""
+}
+
+func (m *StreamTextRequest) GetExpectation() string {
+	if m != nil {
+		return m.Expectation
+	}
+	return ""
+}
+
+func (m *StreamTextRequest) GetExpectationMatcher() string {
+	if m != nil {
+		return m.ExpectationMatcher
+	}
+	return ""
+}
+
+func (m *StreamTextRequest) GetExpectationOutputs() []string {
+	if m != nil {
+		return m.ExpectationOutputs
+	}
+	return nil
+}
+
+func (m *StreamTextRequest) GetExpectationThreshold() float64 {
+	if m != nil {
+		return m.ExpectationThreshold
+	}
+	return 0
+}
+
+func (m *StreamTextRequest) GetRetries() int64 {
+	if m != nil {
+		return m.Retries
+	}
+	return 0
+}
+
+func (m *StreamTextRequest) GetBackoff() string {
+	if m != nil {
+		return m.Backoff
+	}
+	return ""
+}
+
+func (m *StreamTextRequest) GetDeadline() string {
+	if m != nil {
+		return m.Deadline
+	}
+	return ""
+}
+
+type StreamTextResponse struct {
+	Code string                  `protobuf:"bytes,1,opt,name=Code,json=code" json:"Code,omitempty"`
+	Data *StreamTextResponseData `protobuf:"bytes,2,opt,name=Data,json=data" json:"Data,omitempty"`
+	Text string                  `protobuf:"bytes,3,opt,name=Text,json=text" json:"Text,omitempty"`
+}
+
+func (m *StreamTextResponse) Reset()                    { *m = StreamTextResponse{} }
+func (m *StreamTextResponse) String() string            { return proto.CompactTextString(m) }
+func (*StreamTextResponse) ProtoMessage()               {}
+func (*StreamTextResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *StreamTextResponse) GetCode() string {
+	if m != nil {
+		return m.Code
+	}
+	return ""
+}
+
+func (m *StreamTextResponse) GetData() *StreamTextResponseData {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *StreamTextResponse) GetText() string {
+	if m != nil {
+		return m.Text
+	}
+	return ""
+}
+
+type StreamTextResponseData struct {
+	Output     string                         `protobuf:"bytes,1,opt,name=Output,json=output" json:"Output,omitempty"`
+	Attempts   int64                          `protobuf:"varint,2,opt,name=Attempts,json=attempts" json:"Attempts,omitempty"`
+	Score      float64                        `protobuf:"fixed64,3,opt,name=Score,json=score" json:"Score,omitempty"`
+	CLGTreeID  string                         `protobuf:"bytes,4,opt,name=CLGTreeID,json=cLGTreeID" json:"CLGTreeID,omitempty"`
+	Candidates []*StreamTextResponseCandidate `protobuf:"bytes,5,rep,name=Candidates,json=candidates" json:"Candidates,omitempty"`
+}
+
+func (m *StreamTextResponseData) Reset()                    { *m = StreamTextResponseData{} }
+func (m *StreamTextResponseData) String() string            { return proto.CompactTextString(m) }
+func (*StreamTextResponseData) ProtoMessage()               {}
+func (*StreamTextResponseData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *StreamTextResponseData) GetOutput() string {
+	if m != nil {
+		return m.Output
+	}
+	return ""
+}
+
+func (m *StreamTextResponseData) GetAttempts() int64 {
+	if m != nil {
+		return m.Attempts
+	}
+	return 0
+}
+
+func (m *StreamTextResponseData) GetScore() float64 {
+	if m != nil {
+		return m.Score
+	}
+	return 0
+}
+
+func (m *StreamTextResponseData) GetCLGTreeID() string {
+	if m != nil {
+		return m.CLGTreeID
+	}
+	return ""
+}
+
+func (m *StreamTextResponseData) GetCandidates() []*StreamTextResponseCandidate {
+	if m != nil {
+		return m.Candidates
+	}
+	return nil
+}
+
+type StreamTextResponseCandidate struct {
+	Output       string   `protobuf:"bytes,1,opt,name=Output,json=output" json:"Output,omitempty"`
+	Score        float64  `protobuf:"fixed64,2,opt,name=Score,json=score" json:"Score,omitempty"`
+	BehaviourIDs []string `protobuf:"bytes,3,rep,name=BehaviourIDs,json=behaviourIDs" json:"BehaviourIDs,omitempty"`
+}
+
+func (m *StreamTextResponseCandidate) Reset()                    { *m = StreamTextResponseCandidate{} }
+func (m *StreamTextResponseCandidate) String() string            { return proto.CompactTextString(m) }
+func (*StreamTextResponseCandidate) ProtoMessage()               {}
+func (*StreamTextResponseCandidate) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *StreamTextResponseCandidate) GetOutput() string {
+	if m != nil {
+		return m.Output
+	}
+	return ""
+}
+
+func (m *StreamTextResponseCandidate) GetScore() float64 {
+	if m != nil {
+		return m.Score
+	}
+	return 0
+}
+
+func (m *StreamTextResponseCandidate) GetBehaviourIDs() []string {
+	if m != nil {
+		return m.BehaviourIDs
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*StreamTextRequest)(nil), "StreamTextRequest")
+	proto.RegisterType((*StreamTextResponse)(nil), "StreamTextResponse")
+	proto.RegisterType((*StreamTextResponseData)(nil), "StreamTextResponseData")
+	proto.RegisterType((*StreamTextResponseCandidate)(nil), "StreamTextResponseCandidate")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for TextEndpoint service
+
+type TextEndpointClient interface {
+	StreamText(ctx context.Context, opts ...grpc.CallOption) (TextEndpoint_StreamTextClient, error)
+}
+
+type textEndpointClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewTextEndpointClient(cc *grpc.ClientConn) TextEndpointClient {
+	return &textEndpointClient{cc}
+}
+
+func (c *textEndpointClient) StreamText(ctx context.Context, opts ...grpc.CallOption) (TextEndpoint_StreamTextClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_TextEndpoint_serviceDesc.Streams[0], c.cc, "/TextEndpoint/StreamText", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &textEndpointStreamTextClient{stream}
+	return x, nil
+}
+
+type TextEndpoint_StreamTextClient interface {
+	Send(*StreamTextRequest) error
+	Recv() (*StreamTextResponse, error)
+	grpc.ClientStream
+}
+
+type textEndpointStreamTextClient struct {
+	grpc.ClientStream
+}
+
+func (x *textEndpointStreamTextClient) Send(m *StreamTextRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *textEndpointStreamTextClient) Recv() (*StreamTextResponse, error) {
+	m := new(StreamTextResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for TextEndpoint service
+
+type TextEndpointServer interface {
+	StreamText(TextEndpoint_StreamTextServer) error
+}
+
+func RegisterTextEndpointServer(s *grpc.Server, srv TextEndpointServer) {
+	s.RegisterService(&_TextEndpoint_serviceDesc, srv)
+}
+
+func _TextEndpoint_StreamText_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TextEndpointServer).StreamText(&textEndpointStreamTextServer{stream})
+}
+
+type TextEndpoint_StreamTextServer interface {
+	Send(*StreamTextResponse) error
+	Recv() (*StreamTextRequest, error)
+	grpc.ServerStream
+}
+
+type textEndpointStreamTextServer struct {
+	grpc.ServerStream
+}
+
+func (x *textEndpointStreamTextServer) Send(m *StreamTextResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *textEndpointStreamTextServer) Recv() (*StreamTextRequest, error) {
+	m := new(StreamTextRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _TextEndpoint_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "TextEndpoint",
+	HandlerType: (*TextEndpointServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamText",
+			Handler:       _TextEndpoint_StreamText_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "text_endpoint.proto",
+}
+
+func init() { proto.RegisterFile("text_endpoint.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 453 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x53, 0xc1, 0x6e, 0xd3, 0x40,
+	0x10, 0x65, 0x6b, 0x3b, 0xb1, 0x27, 0xb9, 0xb0, 0x8d, 0xca, 0xaa, 0xf4, 0x60, 0xf9, 0x64, 0x09,
+	0x29, 0x42, 0xe1, 0x08, 0x17, 0x1a, 0x47, 0x28, 0x02, 0x84, 0xb4, 0xc9, 0x1d, 0x6d, 0xec, 0xa9,
+	0x6c, 0xd1, 0x7a, 0x8d, 0x77, 0x8c, 0xf2, 0x75, 0xfc, 0x0b, 0x7f, 0x82, 0x76, 0xe3, 0xa6, 0x56,
+	0x13, 0x38, 0xbe, 0x79, 0x6f, 0x3d, 0x6f, 0xde, 0x78, 0xe0, 0x92, 0x70, 0x4f, 0xdf, 0xb1, 0x2e,
+	0x1a, 0x5d, 0xd5, 0x34, 0x6f, 0x5a, 0x4d, 0x3a, 0xf9, 0x73, 0x01, 0x2f, 0x37, 0xd4, 0xa2, 0x7a,
+	0xd8, 0xe2, 0x9e, 0x24, 0xfe, 0xec, 0xd0, 0x10, 0xe7, 0xe0, 0xaf, 0xf2, 0x52, 0x0b, 0x16, 0xb3,
+	0x34, 0x94, 0x3e, 0xe6, 0xa5, 0xe6, 0x33, 0x08, 0xd6, 0x75, 0xd3, 0x91, 0xb8, 0x88, 0x59, 0x1a,
+	0xc9, 0xa0, 0xb2, 0x80, 0xdf, 0x40, 0xb4, 0x41, 0x63, 0x2a, 0x5d, 0xaf, 0x33, 0xe1, 0x39, 0x26,
+	0x32, 0x8f, 0x05, 0x1e, 0xc3, 0x64, 0xb5, 0x6f, 0x30, 0x27, 0x45, 0x95, 0xae, 0x85, 0xef, 0xf8,
+	0x09, 0x3e, 0x95, 0xf8, 0x1c, 0xf8, 0x40, 0xf1, 0x55, 0x51, 0x5e, 0x62, 0x2b, 0x02, 0x27, 0xe4,
+	0x78, 0xc2, 0x3c, 0xd3, 0x7f, 0xeb, 0xa8, 0xe9, 0xc8, 0x88, 0x51, 0xec, 0x3d, 0xd3, 0xf7, 0x0c,
+	0x5f, 0xc0, 0x6c, 0xa0, 0xdf, 0x96, 0x2d, 0x9a, 0x52, 0xdf, 0x17, 0x62, 0x1c, 0xb3, 0x94, 0xc9,
+	0x19, 0x9e, 0xe1, 0xb8, 0x80, 0xb1, 0x44, 0x6a, 0x2b, 0x34, 0x22, 0x8c, 0x59, 0xea, 0xc9, 0x71,
+	0x7b, 0x80, 0x96, 0xb9, 0x55, 0xf9, 0x0f, 0x7d, 0x77, 0x27, 0x22, 0x67, 0x71, 0xbc, 0x3b, 0x40,
+	0x7e, 0x0d, 0x61, 0x86, 0xaa, 0xb8, 0xaf, 0x6a, 0x14, 0xe0, 0xa8, 0xb0, 0xe8, 0x71, 0x52, 0x01,
+	0x1f, 0x46, 0x6c, 0x1a, 0x5d, 0x1b, 0xb4, 0x19, 0x2f, 0x75, 0x81, 0x2e, 0xe3, 0x48, 0xfa, 0xb9,
+	0x2e, 0x90, 0xbf, 0x01, 0x3f, 0x53, 0xa4, 0x5c, 0xc4, 0x93, 0xc5, 0xab, 0xf9, 0xe9, 0x33, 0x4b,
+	0x4b, 0xbf, 0x50, 0xa4, 0xec, 0x07, 0x2c, 0xd3, 0xa7, 0xee, 0xdb, 0xed, 0x26, 0xbf, 0x19, 0x5c,
+	0x9d, 0x7f, 0xc4, 0xaf, 0x60, 0x74, 0x08, 0xa5, 0xef, 0x38, 0xd2, 0x0e, 0x59, 0xe7, 0x1f, 0x89,
+	0xf0, 0xa1, 0x21, 0xe3, 0xfa, 0x7a, 0x32, 0x54, 0x3d, 0xb6, 0x3b, 0xdf, 0xe4, 0xba, 0x45, 0xd7,
+	0x83, 0xc9, 0xc0, 0x58, 0x60, 0x77, 0xbe, 0xfc, 0xf2, 0x69, 0xdb, 0x22, 0xae, 0xb3, 0x7e, 0xa7,
+	0x51, 0xfe, 0x58, 0xe0, 0x1f, 0x00, 0x96, 0xaa, 0x2e, 0xaa, 0x42, 0x11, 0x1a, 0x11, 0xc4, 0x5e,
+	0x3a, 0x59, 0xdc, 0x9c, 0x99, 0xe4, 0x28, 0x92, 0x90, 0x1f, 0xf5, 0x89, 0x86, 0xd7, 0xff, 0x91,
+	0xfe, 0x73, 0x88, 0xa3, 0xd1, 0x8b, 0xa1, 0xd1, 0x04, 0xa6, 0xb7, 0x58, 0xaa, 0x5f, 0x95, 0xee,
+	0xda, 0x75, 0x66, 0x84, 0xe7, 0x7e, 0x93, 0xe9, 0x6e, 0x50, 0x5b, 0x7c, 0x86, 0xa9, 0x6d, 0xb5,
+	0xea, 0xcf, 0x82, 0xbf, 0x07, 0x78, 0x32, 0xc0, 0xf9, 0xfc, 0xe4, 0x38, 0xae, 0x2f, 0xcf, 0x0c,
+	0x93, 0xbc, 0x48, 0xd9, 0x5b, 0xb6, 0x1b, 0xb9, 0xa3, 0x7a, 0xf7, 0x77, 0x00, 0x36, 0xee, 0xa2,
+	0xf6, 0x6b, 0x03, 0x00, 0x00,
+}
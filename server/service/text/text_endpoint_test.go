@@ -0,0 +1,64 @@
+package text
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+// Test_TextEndpoint_Descriptor ensures the file descriptor describes each
+// message the way its Go struct is generated. Descriptor based tooling like
+// jsonpb or gRPC reflection relies on it.
+func Test_TextEndpoint_Descriptor(t *testing.T) {
+	testMessages := []interface {
+		Descriptor() ([]byte, []int)
+	}{
+		&StreamTextRequest{},
+		&StreamTextResponse{},
+		&StreamTextResponseData{},
+		&StreamTextResponseCandidate{},
+	}
+
+	for i, testMessage := range testMessages {
+		gzipped, index := testMessage.Descriptor()
+		reader, err := gzip.NewReader(bytes.NewReader(gzipped))
+		if err != nil {
+			t.Fatal("case", i+1, "expected", nil, "got", err)
+		}
+		raw, err := ioutil.ReadAll(reader)
+		if err != nil {
+			t.Fatal("case", i+1, "expected", nil, "got", err)
+		}
+		var fileDescriptor descriptor.FileDescriptorProto
+		err = proto.Unmarshal(raw, &fileDescriptor)
+		if err != nil {
+			t.Fatal("case", i+1, "expected", nil, "got", err)
+		}
+
+		if len(index) != 1 || index[0] >= len(fileDescriptor.MessageType) {
+			t.Fatal("case", i+1, "expected", "existing message index", "got", index)
+		}
+		messageDescriptor := fileDescriptor.MessageType[index[0]]
+		messageType := reflect.TypeOf(testMessage).Elem()
+		if messageDescriptor.GetName() != messageType.Name() {
+			t.Fatal("case", i+1, "expected", messageType.Name(), "got", messageDescriptor.GetName())
+		}
+		if len(messageDescriptor.Field) != messageType.NumField() {
+			t.Fatal("case", i+1, "expected", messageType.NumField(), "got", len(messageDescriptor.Field))
+		}
+		for j, fieldDescriptor := range messageDescriptor.Field {
+			tag := messageType.Field(j).Tag.Get("protobuf")
+			expected := fmt.Sprintf(",%d,", fieldDescriptor.GetNumber())
+			if !strings.Contains(tag, expected) || !strings.Contains(tag, "name="+fieldDescriptor.GetName()+",") {
+				t.Fatal("case", i+1, "expected", fieldDescriptor.GetName(), "got", tag)
+			}
+		}
+	}
+}
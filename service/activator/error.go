@@ -49,3 +49,10 @@ var networkPayloadNotFoundError = errgo.New("network payload not found")
 func IsNetworkPayloadNotFound(err error) bool {
 	return errgo.Cause(err) == networkPayloadNotFoundError
 }
+
+var invalidCLGTreeIDError = errgo.New("invalid CLG tree ID")
+
+// IsInvalidCLGTreeID asserts invalidCLGTreeIDError.
+func IsInvalidCLGTreeID(err error) bool {
+	return errgo.Cause(err) == invalidCLGTreeIDError
+}
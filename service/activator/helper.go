@@ -3,10 +3,12 @@ package activator
 import (
 	"encoding/json"
 	"reflect"
+	"sort"
 	"strings"
 
+	"github.com/the-anna-project/annad/helper"
 	"github.com/the-anna-project/annad/object/networkpayload"
-	objectspec "github.com/the-anna-project/spec/object"
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
 func equalStrings(a, b []string) bool {
@@ -23,33 +25,106 @@ func equalStrings(a, b []string) bool {
 	return true
 }
 
-// getInputTypes returns a list of reflect types that represent the output
-// arguments of a function. Therefore f must be a function. Otherwhise
-// getInputTypes panics if f's reflect.Kind is not reflect.Func.
-func getInputTypes(f interface{}) []reflect.Type {
-	t := reflect.TypeOf(f)
+// matchQueue returns all combinations of the given queued network payloads
+// whose concatenated argument types equal the given CLG types. A network
+// payload can be part of a combination multiple times. A combination consists
+// of at most len(clgTypes) network payloads. The returned combinations are
+// ordered by their length first and by the queue positions of their network
+// payloads second. This is the same set and order of combinations the
+// permutation of the queue creates, without permuting the whole queue.
+//
+// Therefore the queued network payloads are indexed by their argument type
+// signatures. For each position within the CLG types the index lists the
+// network payloads whose signature matches the CLG types starting at this
+// position. Combinations are then only built of network payloads listed at
+// the position the combination reached so far.
+func matchQueue(queue []objectspec.NetworkPayload, clgTypes []string) [][]objectspec.NetworkPayload {
+	if len(clgTypes) == 0 {
+		return nil
+	}
 
-	var inputType []reflect.Type
+	// Group the queued network payloads by their argument type signature.
+	var signatures [][]string
+	signatureIndizes := map[string][]int{}
+	for i, np := range queue {
+		signature := typesToStrings(valuesToTypes([]interface{}{np}))
+		key := strings.Join(signature, ",")
+		if _, ok := signatureIndizes[key]; !ok {
+			signatures = append(signatures, signature)
+		}
+		signatureIndizes[key] = append(signatureIndizes[key], i)
+	}
 
-	for i := 0; i < t.NumIn(); i++ {
-		inputType = append(inputType, t.In(i))
+	// Index the queue positions of the network payloads matching the CLG types
+	// at each position. Network payloads without arguments match at each
+	// position, including the very end of the CLG types.
+	index := make([][]int, len(clgTypes)+1)
+	for _, signature := range signatures {
+		key := strings.Join(signature, ",")
+		for offset := 0; offset+len(signature) <= len(clgTypes); offset++ {
+			if equalStrings(signature, clgTypes[offset:offset+len(signature)]) {
+				index[offset] = append(index[offset], signatureIndizes[key]...)
+			}
+		}
+	}
+	for offset := range index {
+		sort.Ints(index[offset])
+	}
+
+	// Build the combinations of each length using the index.
+	var matches [][]objectspec.NetworkPayload
+	var combine func(indizes []int, offset, length int)
+	combine = func(indizes []int, offset, length int) {
+		if len(indizes) == length {
+			if offset == len(clgTypes) {
+				match := make([]objectspec.NetworkPayload, 0, length)
+				for _, i := range indizes {
+					match = append(match, queue[i])
+				}
+				matches = append(matches, match)
+			}
+			return
+		}
+
+		for _, i := range index[offset] {
+			combine(append(indizes, i), offset+len(queue[i].GetArgs()), length)
+		}
+	}
+	for length := 1; length <= len(clgTypes); length++ {
+		combine(make([]int, 0, length), 0, length)
 	}
 
-	return inputType
+	return matches
 }
 
-func mergeNetworkPayloads(networkPayloads []objectspec.NetworkPayload) (objectspec.NetworkPayload, error) {
+func (s *service) mergeNetworkPayloads(networkPayloads []objectspec.NetworkPayload) (objectspec.NetworkPayload, error) {
 	if len(networkPayloads) == 0 {
 		return nil, maskAny(networkPayloadNotFoundError)
 	}
 
 	var args []reflect.Value
+	var depth int
+	var path []string
 	var sources []string
 	for _, np := range networkPayloads {
 		for _, v := range np.GetArgs() {
 			args = append(args, v)
 		}
 
+		// The merged network payload is as deep as the deepest network payload it
+		// is made of.
+		if np.GetDepth() > depth {
+			depth = np.GetDepth()
+		}
+
+		// The merged network payload passed all CLGs the network payloads it is
+		// made of passed. Each behaviour ID is only listed once.
+		for _, behaviourID := range np.GetPath() {
+			if !helper.ContainsString(path, behaviourID) {
+				path = append(path, behaviourID)
+			}
+		}
+
 		sources = append(sources, np.GetSources()...)
 	}
 
@@ -59,10 +134,17 @@ func mergeNetworkPayloads(networkPayloads []objectspec.NetworkPayload) (objectsp
 		return nil, maskAnyf(invalidBehaviourIDError, "must not be empty")
 	}
 
+	networkPayloadID, err := s.Service().ID().New()
+	if err != nil {
+		return nil, maskAny(err)
+	}
 	networkPayloadConfig := networkpayload.DefaultConfig()
 	networkPayloadConfig.Args = args
 	networkPayloadConfig.Context = ctx
+	networkPayloadConfig.Depth = depth
 	networkPayloadConfig.Destination = string(behaviourID)
+	networkPayloadConfig.ID = networkPayloadID
+	networkPayloadConfig.Path = path
 	networkPayloadConfig.Sources = sources
 	networkPayload, err := networkpayload.New(networkPayloadConfig)
 	if err != nil {
@@ -82,19 +164,17 @@ func queueToValues(queue []objectspec.NetworkPayload) []interface{} {
 	return values
 }
 
-func stringToQueue(s string) ([]objectspec.NetworkPayload, error) {
-	var queue []objectspec.NetworkPayload
+// stringToQueue decodes the given JSON array of queue entries as stored by
+// persistQueue.
+func stringToQueue(s string) ([]queueEntry, error) {
+	var entries []queueEntry
 
-	for _, s := range strings.Split(s, ",") {
-		np := networkpayload.MustNew()
-		err := json.Unmarshal([]byte(s), &np)
-		if err != nil {
-			return nil, maskAny(err)
-		}
-		queue = append(queue, np)
+	err := json.Unmarshal([]byte(s), &entries)
+	if err != nil {
+		return nil, maskAny(err)
 	}
 
-	return queue, nil
+	return entries, nil
 }
 
 func typesToStrings(types []reflect.Type) []string {
@@ -0,0 +1,164 @@
+package activator
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/the-anna-project/annad/object/networkpayload"
+	permutationlist "github.com/the-anna-project/annad/permutation/object/list"
+	"github.com/the-anna-project/annad/permutation/service"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+)
+
+// permuteQueue finds all combinations of the given queued network payloads
+// satisfying the given CLG types by permuting the whole queue. This is how the
+// activator used to find combinations before matchQueue was introduced. It is
+// kept to verify matchQueue against it and to compare both approaches.
+func permuteQueue(queue []objectspec.NetworkPayload, clgTypes []string) ([][]objectspec.NetworkPayload, error) {
+	permutationService := permutation.New()
+
+	permutationList := permutationlist.New()
+	permutationList.SetMaxGrowth(len(clgTypes))
+	permutationList.SetRawValues(queueToValues(queue))
+
+	var possibleMatches [][]objectspec.NetworkPayload
+	for {
+		permutedValues := permutationList.PermutedValues()
+		valueTypes := typesToStrings(valuesToTypes(permutedValues))
+		if equalStrings(clgTypes, valueTypes) {
+			possibleMatches = append(possibleMatches, valuesToQueue(permutedValues))
+		}
+
+		err := permutationService.PermuteBy(permutationList, 1)
+		if permutation.IsMaxGrowthReached(err) {
+			break
+		} else if err != nil {
+			return nil, maskAny(err)
+		}
+	}
+
+	return possibleMatches, nil
+}
+
+// testQueue creates a queue of the given size. The network payloads are made
+// of pseudo random arguments of the given types. Some network payloads carry
+// multiple arguments, some carry none.
+func testQueue(r *rand.Rand, size int, types []interface{}) []objectspec.NetworkPayload {
+	var queue []objectspec.NetworkPayload
+
+	for i := 0; i < size; i++ {
+		var args []reflect.Value
+		n := r.Intn(3)
+		for j := 0; j < n; j++ {
+			args = append(args, reflect.ValueOf(types[r.Intn(len(types))]))
+		}
+
+		newNetworkPayloadConfig := networkpayload.DefaultConfig()
+		newNetworkPayloadConfig.Args = args
+		newNetworkPayloadConfig.Destination = "destination"
+		newNetworkPayloadConfig.Sources = []string{fmt.Sprintf("source-%d", i)}
+		newNetworkPayload, err := networkpayload.New(newNetworkPayloadConfig)
+		if err != nil {
+			panic(err)
+		}
+
+		queue = append(queue, newNetworkPayload)
+	}
+
+	return queue
+}
+
+// testCLGTypes creates CLG types of the given arity using the given types.
+func testCLGTypes(r *rand.Rand, arity int, types []interface{}) []string {
+	var clgTypes []string
+
+	for i := 0; i < arity; i++ {
+		clgTypes = append(clgTypes, reflect.TypeOf(types[r.Intn(len(types))]).String())
+	}
+
+	return clgTypes
+}
+
+var testTypes = []interface{}{
+	3,
+	"foo",
+	4.8,
+}
+
+func Test_Activator_matchQueue(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for arity := 1; arity <= 5; arity++ {
+		for size := 1; size <= 8; size++ {
+			queue := testQueue(r, size, testTypes)
+			clgTypes := testCLGTypes(r, arity, testTypes)
+
+			expected, err := permuteQueue(queue, clgTypes)
+			if err != nil {
+				t.Fatal("arity", arity, "size", size, "expected", nil, "got", err)
+			}
+			output := matchQueue(queue, clgTypes)
+
+			if len(output) != len(expected) {
+				t.Fatal("arity", arity, "size", size, "expected", len(expected), "got", len(output))
+			}
+			for i := range expected {
+				if len(output[i]) != len(expected[i]) {
+					t.Fatal("arity", arity, "size", size, "expected", len(expected[i]), "got", len(output[i]))
+				}
+				for j := range expected[i] {
+					if output[i][j] != expected[i][j] {
+						t.Fatal("arity", arity, "size", size, "expected", expected[i][j], "got", output[i][j])
+					}
+				}
+			}
+		}
+	}
+}
+
+func Test_Activator_matchQueue_NoMatch(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	queue := testQueue(r, 10, testTypes)
+
+	output := matchQueue(queue, []string{"bool"})
+	if len(output) != 0 {
+		t.Fatal("expected", 0, "got", len(output))
+	}
+}
+
+func benchmarkQueues(b *testing.B, f func(b *testing.B, queue []objectspec.NetworkPayload, clgTypes []string)) {
+	for arity := 1; arity <= 5; arity++ {
+		for _, size := range []int{5, 10, 25, 50} {
+			r := rand.New(rand.NewSource(1))
+			queue := testQueue(r, size, testTypes)
+			clgTypes := testCLGTypes(r, arity, testTypes)
+
+			b.Run(fmt.Sprintf("arity-%d/size-%d", arity, size), func(b *testing.B) {
+				f(b, queue, clgTypes)
+			})
+		}
+	}
+}
+
+func Benchmark_Activator_matchQueue(b *testing.B) {
+	benchmarkQueues(b, func(b *testing.B, queue []objectspec.NetworkPayload, clgTypes []string) {
+		for i := 0; i < b.N; i++ {
+			matchQueue(queue, clgTypes)
+		}
+	})
+}
+
+// Benchmark_Activator_permuteQueue takes several minutes for the largest queues
+// of the highest arity, because the whole queue is permuted.
+func Benchmark_Activator_permuteQueue(b *testing.B) {
+	benchmarkQueues(b, func(b *testing.B, queue []objectspec.NetworkPayload, clgTypes []string) {
+		for i := 0; i < b.N; i++ {
+			_, err := permuteQueue(queue, clgTypes)
+			if err != nil {
+				b.Fatal("expected", nil, "got", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,282 @@
+package activator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/the-anna-project/annad/object/networkevent"
+	"github.com/the-anna-project/annad/object/networkpayload"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	storagecollection "github.com/the-anna-project/annad/storage/collection"
+)
+
+const (
+	// deadLetterKey is the key of the list expired network payloads are moved to
+	// in case dead lettering is enabled.
+	deadLetterKey = "activate:dead-letter:network-payload"
+
+	// queueGlob matches the keys of all activation queues.
+	queueGlob = "activate:queue:behaviour-id:*:network-payload"
+)
+
+// queueEntry is a network payload waiting within an activation queue for
+// other network payloads to complete the interface of the requested CLG.
+type queueEntry struct {
+	// Arrival is the time the network payload arrived at the activation queue.
+	Arrival time.Time `json:"arrival"`
+
+	// NetworkPayload is the queued network payload.
+	NetworkPayload objectspec.NetworkPayload `json:"network_payload"`
+}
+
+func (e *queueEntry) UnmarshalJSON(b []byte) error {
+	aux := struct {
+		Arrival        time.Time       `json:"arrival"`
+		NetworkPayload json.RawMessage `json:"network_payload"`
+	}{}
+	err := json.Unmarshal(b, &aux)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	np := networkpayload.MustNew()
+	err = json.Unmarshal(aux.NetworkPayload, &np)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	e.Arrival = aux.Arrival
+	e.NetworkPayload = np
+
+	return nil
+}
+
+// entriesToQueue returns the network payloads of the given queue entries.
+func entriesToQueue(entries []queueEntry) []objectspec.NetworkPayload {
+	var queue []objectspec.NetworkPayload
+
+	for _, e := range entries {
+		queue = append(queue, e.NetworkPayload)
+	}
+
+	return queue
+}
+
+// expireQueue splits the given queue entries into the ones still being alive
+// at the given point in time and the ones having waited longer than the given
+// TTL. The order of the queue entries is preserved.
+func expireQueue(entries []queueEntry, now time.Time, ttl time.Duration) ([]queueEntry, []queueEntry) {
+	var alive []queueEntry
+	var expired []queueEntry
+
+	for _, e := range entries {
+		if now.Sub(e.Arrival) > ttl {
+			expired = append(expired, e)
+		} else {
+			alive = append(alive, e)
+		}
+	}
+
+	return alive, expired
+}
+
+// queueLock is the lock of a single activation queue. See lockQueue.
+type queueLock struct {
+	mutex sync.Mutex
+	// users is the number of goroutines holding or waiting for the lock.
+	users int
+}
+
+// expireEntries handles the given queue entries which expired within the
+// activation queue of the requested CLG identified by the given behaviour ID.
+// The network payloads are moved to the dead letter queue, if configured.
+// The returned network events of kind KindExpired tell subscribers of the bus
+// that the branch of the CLG tree leading to the requested CLG ended. They have
+// to be published using publishExpired after unlocking the activation queue.
+func (s *service) expireEntries(behaviourID string, expired []queueEntry) ([]objectspec.NetworkEvent, error) {
+	var networkEvents []objectspec.NetworkEvent
+	for _, e := range expired {
+		if s.queueDeadLetter {
+			b, err := json.Marshal(e)
+			if err != nil {
+				return nil, maskAny(err)
+			}
+			err = s.Service().Storage().General().PushToList(deadLetterKey, string(b))
+			if err != nil {
+				return nil, maskAny(err)
+			}
+		}
+
+		clgTreeID, ok := e.NetworkPayload.GetContext().GetCLGTreeID()
+		if !ok {
+			return nil, maskAnyf(invalidCLGTreeIDError, "must not be empty")
+		}
+		newNetworkEventConfig := networkevent.NetworkPayloadConfig(networkevent.KindExpired, e.NetworkPayload)
+		newNetworkEventConfig.Duration = time.Since(e.Arrival)
+		newNetworkEvent, err := networkevent.New(newNetworkEventConfig)
+		if err != nil {
+			return nil, maskAny(err)
+		}
+		networkEvents = append(networkEvents, newNetworkEvent)
+
+		s.Service().Log().Line("msg", "branch of CLG tree '%s' ended at behaviour ID '%s' because activation timed out", clgTreeID, behaviourID)
+	}
+
+	if len(expired) > 0 {
+		c, err := s.Service().Instrumentor().GetCounter(s.Service().Instrumentor().NewKey("activator", "queue", "expired", "counter", "total"))
+		if err != nil {
+			return nil, maskAny(err)
+		}
+		c.IncrBy(float64(len(expired)))
+	}
+
+	return networkEvents, nil
+}
+
+// loadQueue fetches the activation queue identified by the given key from the
+// underlying storage. A queue not being stored yet is empty.
+func (s *service) loadQueue(key string) ([]queueEntry, error) {
+	str, err := s.Service().Storage().General().Get(key)
+	if storagecollection.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, maskAny(err)
+	}
+	entries, err := stringToQueue(str)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	return entries, nil
+}
+
+// lockQueue locks the activation queue identified by the given key against
+// concurrent modifications of Activate and the sweeper. Other activation queues
+// are not affected. The returned function unlocks the activation queue again.
+// The lock is dropped as soon as no goroutine uses it anymore.
+func (s *service) lockQueue(key string) func() {
+	s.queueLocksMutex.Lock()
+	l, ok := s.queueLocks[key]
+	if !ok {
+		l = &queueLock{}
+		s.queueLocks[key] = l
+	}
+	l.users++
+	s.queueLocksMutex.Unlock()
+
+	l.mutex.Lock()
+
+	return func() {
+		l.mutex.Unlock()
+
+		s.queueLocksMutex.Lock()
+		l.users--
+		if l.users == 0 {
+			delete(s.queueLocks, key)
+		}
+		s.queueLocksMutex.Unlock()
+	}
+}
+
+// publishExpired publishes the network events returned by expireEntries. It
+// must not be called while holding the lock of an activation queue, because
+// publishing waits for blocking subscribers like the tracker, which must not
+// stall other activations of the queue.
+func (s *service) publishExpired(networkEvents []objectspec.NetworkEvent) {
+	for _, e := range networkEvents {
+		s.Service().Bus().Publish(e)
+	}
+}
+
+// subtractQueue returns the given queue entries without the ones holding any
+// of the given network payloads. Network payloads are identified by their IDs.
+// The order of the remaining queue entries is preserved.
+func subtractQueue(entries []queueEntry, networkPayloads []objectspec.NetworkPayload) []queueEntry {
+	ids := map[string]struct{}{}
+	for _, np := range networkPayloads {
+		ids[np.GetID()] = struct{}{}
+	}
+
+	var newEntries []queueEntry
+	for _, e := range entries {
+		if _, ok := ids[e.NetworkPayload.GetID()]; ok {
+			continue
+		}
+		newEntries = append(newEntries, e)
+	}
+
+	return newEntries
+}
+
+// sweeper is a worker pool function which periodically cleans up all
+// activation queues using sweepQueue.
+func (s *service) sweeper(canceler <-chan struct{}) error {
+	ticker := time.NewTicker(s.queueSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-canceler:
+			return nil
+		case <-ticker.C:
+			err := s.Service().Storage().General().WalkKeys(queueGlob, canceler, s.sweepQueue)
+			if err != nil {
+				s.Service().Log().Line("msg", maskAny(err))
+			}
+		}
+	}
+}
+
+// sweepQueue cleans up the activation queue identified by the given key.
+// Expired queue entries are handled the same way Activate handles them.
+// Queue entries of CLG trees which already ended are abandoned and dropped.
+// Activation queues left empty are removed.
+func (s *service) sweepQueue(key string) error {
+	behaviourID := strings.TrimSuffix(strings.TrimPrefix(key, "activate:queue:behaviour-id:"), ":network-payload")
+
+	var networkEvents []objectspec.NetworkEvent
+	unlock := s.lockQueue(key)
+	defer func() {
+		unlock()
+		s.publishExpired(networkEvents)
+	}()
+
+	entries, err := s.loadQueue(key)
+	if err != nil {
+		return maskAny(err)
+	}
+	alive, expired := expireQueue(entries, time.Now(), s.queueTTL)
+	networkEvents, err = s.expireEntries(behaviourID, expired)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	var newEntries []queueEntry
+	for _, e := range alive {
+		clgTreeID, ok := e.NetworkPayload.GetContext().GetCLGTreeID()
+		if !ok {
+			return maskAnyf(invalidCLGTreeIDError, "must not be empty")
+		}
+		budgetKey := fmt.Sprintf("clg-tree-id:%s:budget", clgTreeID)
+		budget, err := s.Service().Storage().General().GetStringMap(budgetKey)
+		if err != nil {
+			return maskAny(err)
+		}
+		if budget["ended"] == "true" {
+			// The CLG tree the network payload belongs to already ended. The network
+			// payload is abandoned.
+			continue
+		}
+		newEntries = append(newEntries, e)
+	}
+
+	err = s.persistQueue(key, newEntries)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,255 @@
+package activator
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/the-anna-project/annad/object/networkpayload"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+)
+
+func Test_Activator_expireQueue(t *testing.T) {
+	now := time.Now()
+	ttl := time.Minute
+
+	testCases := []struct {
+		Arrivals        []time.Time
+		ExpectedAlive   []time.Time
+		ExpectedExpired int
+	}{
+		{
+			Arrivals:        nil,
+			ExpectedAlive:   nil,
+			ExpectedExpired: 0,
+		},
+		{
+			Arrivals:        []time.Time{now},
+			ExpectedAlive:   []time.Time{now},
+			ExpectedExpired: 0,
+		},
+		{
+			Arrivals:        []time.Time{now.Add(-ttl)},
+			ExpectedAlive:   []time.Time{now.Add(-ttl)},
+			ExpectedExpired: 0,
+		},
+		{
+			Arrivals:        []time.Time{now.Add(-ttl - time.Second)},
+			ExpectedAlive:   nil,
+			ExpectedExpired: 1,
+		},
+		{
+			Arrivals:        []time.Time{now.Add(-2 * ttl), now.Add(-time.Second), now.Add(-ttl - time.Second), now},
+			ExpectedAlive:   []time.Time{now.Add(-time.Second), now},
+			ExpectedExpired: 2,
+		},
+	}
+
+	for i, testCase := range testCases {
+		var entries []queueEntry
+		for _, a := range testCase.Arrivals {
+			entries = append(entries, queueEntry{Arrival: a, NetworkPayload: networkpayload.MustNew()})
+		}
+
+		alive, expired := expireQueue(entries, now, ttl)
+		if len(alive) != len(testCase.ExpectedAlive) {
+			t.Fatal("case", i+1, "expected", len(testCase.ExpectedAlive), "got", len(alive))
+		}
+		for j, e := range alive {
+			if !e.Arrival.Equal(testCase.ExpectedAlive[j]) {
+				t.Fatal("case", i+1, "expected", testCase.ExpectedAlive[j], "got", e.Arrival)
+			}
+		}
+		if len(expired) != testCase.ExpectedExpired {
+			t.Fatal("case", i+1, "expected", testCase.ExpectedExpired, "got", len(expired))
+		}
+	}
+}
+
+func Test_Activator_stringToQueue(t *testing.T) {
+	arrival := time.Now().UTC()
+
+	newNetworkPayloadConfig := networkpayload.DefaultConfig()
+	newNetworkPayloadConfig.Destination = "destination"
+	newNetworkPayloadConfig.Sources = []string{"source-1"}
+	newNetworkPayload, err := networkpayload.New(newNetworkPayloadConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	b, err := json.Marshal([]queueEntry{{Arrival: arrival, NetworkPayload: newNetworkPayload}, {Arrival: arrival, NetworkPayload: newNetworkPayload}})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	entries, err := stringToQueue(string(b))
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(entries) != 2 {
+		t.Fatal("expected", 2, "got", len(entries))
+	}
+	if !entries[1].Arrival.Equal(arrival) {
+		t.Fatal("expected", arrival, "got", entries[1].Arrival)
+	}
+	if entries[1].NetworkPayload.GetDestination() != "destination" {
+		t.Fatal("expected", "destination", "got", entries[1].NetworkPayload.GetDestination())
+	}
+}
+
+func Test_Activator_lockQueue(t *testing.T) {
+	newService, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	s := newService.(*service)
+
+	unlockA := s.lockQueue("a")
+
+	// Other activation queues are not blocked by the lock of queue a.
+	unlockB := s.lockQueue("b")
+	unlockB()
+
+	// Queue a can only be locked again as soon as it was unlocked.
+	locked := make(chan struct{})
+	go func() {
+		unlock := s.lockQueue("a")
+		unlock()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+		t.Fatal("expected", "queue a to be locked", "got", "queue a locked twice")
+	case <-time.After(10 * time.Millisecond):
+	}
+	unlockA()
+	select {
+	case <-locked:
+	case <-time.After(time.Second):
+		t.Fatal("expected", "queue a to be unlocked", "got", "timeout")
+	}
+
+	// Locks are dropped as soon as nobody uses them anymore.
+	s.queueLocksMutex.Lock()
+	defer s.queueLocksMutex.Unlock()
+	if len(s.queueLocks) != 0 {
+		t.Fatal("expected", 0, "got", len(s.queueLocks))
+	}
+}
+
+func Test_Activator_New_Error_Queue(t *testing.T) {
+	newConfig := DefaultConfig()
+	newConfig.QueueSweepInterval = 0
+	_, err := New(newConfig)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	newConfig = DefaultConfig()
+	newConfig.QueueTTL = 0
+	_, err = New(newConfig)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+func Test_Activator_subtractQueue(t *testing.T) {
+	serviceCollection, shutdown := testServiceCollection(t)
+	defer shutdown()
+
+	newService, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	newService.SetServiceCollection(serviceCollection)
+
+	testCases := []struct {
+		CLGTypes          []string
+		Queue             []objectspec.NetworkPayload
+		ExpectedMerged    string
+		ExpectedRemaining string
+	}{
+		// One source.
+		{
+			CLGTypes: []string{"int"},
+			Queue: []objectspec.NetworkPayload{
+				testNetworkPayload(t, "tree-1", "a", 3),
+				testNetworkPayload(t, "tree-1", "b", "foo"),
+			},
+			ExpectedMerged:    "[a]",
+			ExpectedRemaining: "[b]",
+		},
+		// Two sources.
+		{
+			CLGTypes: []string{"int", "string"},
+			Queue: []objectspec.NetworkPayload{
+				testNetworkPayload(t, "tree-1", "a", 3),
+				testNetworkPayload(t, "tree-1", "x", 3.8),
+				testNetworkPayload(t, "tree-1", "b", "foo"),
+				testNetworkPayload(t, "tree-1", "y", 4.8),
+			},
+			ExpectedMerged:    "[a b]",
+			ExpectedRemaining: "[x y]",
+		},
+		// Two network payloads sent by the same source.
+		{
+			CLGTypes: []string{"int", "string"},
+			Queue: []objectspec.NetworkPayload{
+				testNetworkPayload(t, "tree-1", "a", 3),
+				testNetworkPayload(t, "tree-1", "a", "foo"),
+				testNetworkPayload(t, "tree-1", "a", 3.8),
+			},
+			ExpectedMerged:    "[a a]",
+			ExpectedRemaining: "[a]",
+		},
+		// Three sources.
+		{
+			CLGTypes: []string{"int", "string", "float64"},
+			Queue: []objectspec.NetworkPayload{
+				testNetworkPayload(t, "tree-1", "a", 3),
+				testNetworkPayload(t, "tree-1", "b", "foo"),
+				testNetworkPayload(t, "tree-1", "c", 3.8),
+				testNetworkPayload(t, "tree-1", "d", 4),
+			},
+			ExpectedMerged:    "[a b c]",
+			ExpectedRemaining: "[d]",
+		},
+		// Three sources, nothing remaining.
+		{
+			CLGTypes: []string{"int", "string", "float64"},
+			Queue: []objectspec.NetworkPayload{
+				testNetworkPayload(t, "tree-1", "c", 3.8),
+				testNetworkPayload(t, "tree-1", "b", "foo"),
+				testNetworkPayload(t, "tree-1", "a", 3),
+			},
+			ExpectedMerged:    "[a b c]",
+			ExpectedRemaining: "[]",
+		},
+	}
+
+	for i, testCase := range testCases {
+		var entries []queueEntry
+		for _, np := range testCase.Queue {
+			entries = append(entries, queueEntry{Arrival: time.Now(), NetworkPayload: np})
+		}
+
+		possibleMatches := matchQueue(testCase.Queue, testCase.CLGTypes)
+		if len(possibleMatches) == 0 {
+			t.Fatal("case", i+1, "expected", "matches", "got", nil)
+		}
+		matches := possibleMatches[0]
+
+		newNetworkPayload, err := newService.(*service).mergeNetworkPayloads(matches)
+		if err != nil {
+			t.Fatal("case", i+1, "expected", nil, "got", err)
+		}
+		if testSources([]objectspec.NetworkPayload{newNetworkPayload}) != testCase.ExpectedMerged {
+			t.Fatal("case", i+1, "expected", testCase.ExpectedMerged, "got", testSources([]objectspec.NetworkPayload{newNetworkPayload}))
+		}
+
+		remaining := entriesToQueue(subtractQueue(entries, matches))
+		if testSources(remaining) != testCase.ExpectedRemaining {
+			t.Fatal("case", i+1, "expected", testCase.ExpectedRemaining, "got", testSources(remaining))
+		}
+	}
+}
@@ -2,12 +2,22 @@ package activator
 
 import (
 	"encoding/json"
-
-	objectspec "github.com/the-anna-project/spec/object"
 )
 
-func (s *service) persistQueue(key string, queue []objectspec.NetworkPayload) error {
-	b, err := json.Marshal(queue)
+// persistQueue stores the given queue entries as the activation queue
+// identified by the given key. An empty activation queue is removed from the
+// underlying storage.
+func (s *service) persistQueue(key string, entries []queueEntry) error {
+	if len(entries) == 0 {
+		err := s.Service().Storage().General().Remove(key)
+		if err != nil {
+			return maskAny(err)
+		}
+
+		return nil
+	}
+
+	b, err := json.Marshal(entries)
 	if err != nil {
 		return maskAny(err)
 	}
@@ -0,0 +1,403 @@
+package activator
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	connectionservice "github.com/the-anna-project/annad/connection/service"
+	"github.com/the-anna-project/annad/service/behaviour"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+)
+
+const (
+	// StrategyConfiguration is the name of the lookup strategy reusing the
+	// stored activation configuration of the requested CLG. See
+	// ConfigurationStrategy.
+	StrategyConfiguration = "configuration"
+	// StrategyConnectionWeight is the name of the lookup strategy preferring
+	// sources having the highest connection weight. See
+	// ConnectionWeightStrategy.
+	StrategyConnectionWeight = "connection-weight"
+	// StrategyFirstCome is the name of the lookup strategy preferring network
+	// payloads having been queued first. See FirstComeStrategy.
+	StrategyFirstCome = "first-come"
+	// StrategyRandom is the name of the lookup strategy choosing a random
+	// combination of network payloads. See RandomStrategy.
+	StrategyRandom = "random"
+	// StrategyRoot is the name of the lookup strategy activating the CLG a CLG
+	// tree starts with. See RootStrategy.
+	StrategyRoot = "root"
+	// StrategySameCLGTree is the name of the lookup strategy preferring network
+	// payloads of the same CLG tree. See SameCLGTreeStrategy.
+	StrategySameCLGTree = "same-clg-tree"
+)
+
+// Strategy represents a lookup strategy of the activator. A strategy tries to
+// find a combination of the given queued network payloads satisfying the
+// interface of the given requested CLG. The found combination is returned in
+// the order it satisfies the interface. The activator merges it into a new
+// network payload. In case no combination can be found, an error asserted by
+// IsNetworkPayloadNotFound is returned. Other services are only accessed using
+// the given service collection. That way each strategy can be used and tested
+// on its own.
+type Strategy func(serviceCollection servicespec.ServiceCollection, CLG servicespec.CLGService, queue []objectspec.NetworkPayload) ([]objectspec.NetworkPayload, error)
+
+var (
+	strategyMutex sync.RWMutex
+	strategies    = map[string]Strategy{
+		StrategyConfiguration:    ConfigurationStrategy,
+		StrategyConnectionWeight: ConnectionWeightStrategy,
+		StrategyFirstCome:        FirstComeStrategy,
+		StrategyRandom:           RandomStrategy,
+		StrategyRoot:             RootStrategy,
+		StrategySameCLGTree:      SameCLGTreeStrategy,
+	}
+)
+
+// RegisterStrategy makes the given strategy available to the activator using
+// the given name. Strategies have to be registered before the activator is
+// created. RegisterStrategy panics in case the given strategy is nil or a
+// strategy with the given name is already registered.
+func RegisterStrategy(name string, strategy Strategy) {
+	strategyMutex.Lock()
+	defer strategyMutex.Unlock()
+
+	if strategy == nil {
+		panic(fmt.Sprintf("strategy '%s' must not be nil", name))
+	}
+	if _, ok := strategies[name]; ok {
+		panic(fmt.Sprintf("strategy '%s' already registered", name))
+	}
+
+	strategies[name] = strategy
+}
+
+// lookupStrategies returns the registered strategies identified by the given
+// names, in the given order.
+func lookupStrategies(names []string) ([]Strategy, error) {
+	strategyMutex.RLock()
+	defer strategyMutex.RUnlock()
+
+	var list []Strategy
+	for _, name := range names {
+		strategy, ok := strategies[name]
+		if !ok {
+			return nil, maskAnyf(invalidConfigError, "strategy '%s' is not registered", name)
+		}
+		list = append(list, strategy)
+	}
+
+	return list, nil
+}
+
+// ConfigurationStrategy compares the given queue against the stored activation
+// configuration of the requested CLG. This configuration is a combination of
+// behaviour IDs that are known to be successful, because it was stored by
+// another strategy in some CLG tree executed before the current one. In case
+// the queue contains network payloads sent by all the CLGs listed in the
+// stored configuration, these network payloads are returned.
+func ConfigurationStrategy(serviceCollection servicespec.ServiceCollection, CLG servicespec.CLGService, queue []objectspec.NetworkPayload) ([]objectspec.NetworkPayload, error) {
+	if len(queue) == 0 {
+		return nil, maskAny(networkPayloadNotFoundError)
+	}
+
+	// Fetch the combination of successful behaviour IDs which are known to be
+	// useful for the activation of the requested CLG. The network payloads sent
+	// by the CLGs being fetched here are known to be useful because they have
+	// already been helpful for the execution of the current CLG tree.
+	behaviourID, ok := queue[0].GetContext().GetBehaviourID()
+	if !ok {
+		return nil, maskAnyf(invalidBehaviourIDError, "must not be empty")
+	}
+	behaviourIDs, err := serviceCollection.Behaviour().ActivateConfiguration(behaviourID)
+	if behaviour.IsNotFound(err) {
+		// No successful combination of behaviour IDs is stored. Thus we return an
+		// error. Eventually some other lookup is able to find a sufficient network
+		// payload.
+		return nil, maskAny(networkPayloadNotFoundError)
+	} else if err != nil {
+		return nil, maskAny(err)
+	}
+
+	// Check if there is a queued network payload for each behaviour ID we found in the
+	// storage. Here it is important to obtain the order of the behaviour IDs
+	// stored as connections. They represent the input interface of the requested
+	// CLG. Thus there must not be any variation applied to the lookup here,
+	// because we need the lookup to be reproducible.
+	var matches []objectspec.NetworkPayload
+	for _, behaviourID := range behaviourIDs {
+		for _, np := range queue {
+			// At this point there is only one source given. That is the CLG that
+			// forwarded the current network payload to here. If this is not the case,
+			// we return an error.
+			sources := np.GetSources()
+			if len(sources) != 1 {
+				return nil, maskAnyf(invalidSourcesError, "there must be one source")
+			}
+			if behaviourID == string(sources[0]) {
+				// The current behaviour ID belongs to the current network payload. We
+				// add the matching network payload to our list and go on to find the
+				// network payload belonging to the next behabiour ID.
+				matches = append(matches, np)
+				break
+			}
+		}
+	}
+	if len(behaviourIDs) != len(matches) {
+		// No match using the stored configuration associated with the requested CLG
+		// can be found. Thus we return an error. Eventually some other lookup is
+		// able to find a sufficient network payload.
+		return nil, maskAny(networkPayloadNotFoundError)
+	}
+
+	// The received network payloads are able to satisfy the interface of the
+	// requested CLG.
+	return matches, nil
+}
+
+// ConnectionWeightStrategy chooses the combination of queued network payloads
+// whose sources have the highest connection weight to the requested CLG. The
+// weights of all sources of a combination are summed up. Behaviours are
+// connected through their peers within the behaviour layer, the way the
+// tracker creates the connections. Sources not being connected to the
+// requested CLG weigh nothing. In case multiple combinations weigh the same,
+// the first one is chosen. The chosen combination is stored as activation
+// configuration of the requested CLG.
+func ConnectionWeightStrategy(serviceCollection servicespec.ServiceCollection, CLG servicespec.CLGService, queue []objectspec.NetworkPayload) ([]objectspec.NetworkPayload, error) {
+	possibleMatches := possibleMatches(CLG, queue)
+	if len(possibleMatches) == 0 {
+		return nil, maskAny(networkPayloadNotFoundError)
+	}
+
+	behaviourID, ok := queue[0].GetContext().GetBehaviourID()
+	if !ok {
+		return nil, maskAnyf(invalidBehaviourIDError, "must not be empty")
+	}
+	behaviourPeer := serviceCollection.Layer().Behaviour().PeerKey(behaviourID)
+
+	// Weights are cached, because the same sources are usually part of multiple
+	// combinations.
+	weights := map[string]float64{}
+	weight := func(source string) (float64, error) {
+		if w, ok := weights[source]; ok {
+			return w, nil
+		}
+
+		var w float64
+		sourcePeer := serviceCollection.Layer().Behaviour().PeerKey(source)
+		metadata, err := serviceCollection.Connection().Search(sourcePeer, behaviourPeer)
+		if connectionservice.IsNotFound(err) {
+			// The source is not connected to the requested CLG.
+		} else if err != nil {
+			return 0, maskAny(err)
+		} else {
+			w, err = strconv.ParseFloat(metadata["weight"], 64)
+			if err != nil {
+				return 0, maskAny(err)
+			}
+		}
+		weights[source] = w
+
+		return w, nil
+	}
+
+	var matches []objectspec.NetworkPayload
+	var highest float64
+	for _, m := range possibleMatches {
+		var sum float64
+		for _, np := range m {
+			for _, source := range np.GetSources() {
+				w, err := weight(source)
+				if err != nil {
+					return nil, maskAny(err)
+				}
+				sum += w
+			}
+		}
+
+		if matches == nil || sum > highest {
+			matches = m
+			highest = sum
+		}
+	}
+
+	err := configure(serviceCollection, matches)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	return matches, nil
+}
+
+// FirstComeStrategy chooses the combination of queued network payloads which
+// could have been activated first. That is the combination whose most
+// recently queued network payload was queued before the most recently queued
+// network payloads of all other combinations. In case multiple combinations
+// qualify, the one made of the least network payloads is chosen. The chosen
+// combination is stored as activation configuration of the requested CLG.
+func FirstComeStrategy(serviceCollection servicespec.ServiceCollection, CLG servicespec.CLGService, queue []objectspec.NetworkPayload) ([]objectspec.NetworkPayload, error) {
+	possibleMatches := possibleMatches(CLG, queue)
+	if len(possibleMatches) == 0 {
+		return nil, maskAny(networkPayloadNotFoundError)
+	}
+
+	positions := map[objectspec.NetworkPayload]int{}
+	for i, np := range queue {
+		positions[np] = i
+	}
+
+	var matches []objectspec.NetworkPayload
+	earliest := len(queue)
+	for _, m := range possibleMatches {
+		var latest int
+		for _, np := range m {
+			if positions[np] > latest {
+				latest = positions[np]
+			}
+		}
+
+		// possibleMatches are ordered by their length. Thus the first combination
+		// found is the shortest.
+		if latest < earliest {
+			matches = m
+			earliest = latest
+		}
+	}
+
+	err := configure(serviceCollection, matches)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	return matches, nil
+}
+
+// RandomStrategy chooses a random combination of queued network payloads.
+// Choosing randomly covers all possible combinations across all CLG trees
+// being created over time. This prevents us from choosing always only the
+// first matching combination, which would lack discoveries of all potential
+// combinations being created. The chosen combination is stored as activation
+// configuration of the requested CLG.
+func RandomStrategy(serviceCollection servicespec.ServiceCollection, CLG servicespec.CLGService, queue []objectspec.NetworkPayload) ([]objectspec.NetworkPayload, error) {
+	possibleMatches := possibleMatches(CLG, queue)
+	if len(possibleMatches) == 0 {
+		// No combination of the queued network payloads is able to satisfy the
+		// interface of the requested CLG.
+		return nil, maskAny(networkPayloadNotFoundError)
+	}
+
+	matchIndex, err := serviceCollection.Random().CreateMax(len(possibleMatches))
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	matches := possibleMatches[matchIndex]
+
+	err = configure(serviceCollection, matches)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	return matches, nil
+}
+
+// RootStrategy chooses a combination of queued network payloads the network
+// created from input. Such network payloads have a depth of 0 and start a CLG
+// tree, or re-enter it. Their behaviour IDs are new, so no activation
+// configuration can be known for them. Because of that, and other than all
+// other strategies, the chosen combination is not stored as activation
+// configuration of the requested CLG. A frozen network uses RootStrategy next
+// to ConfigurationStrategy.
+func RootStrategy(serviceCollection servicespec.ServiceCollection, CLG servicespec.CLGService, queue []objectspec.NetworkPayload) ([]objectspec.NetworkPayload, error) {
+	var roots []objectspec.NetworkPayload
+	for _, np := range queue {
+		if np.GetDepth() == 0 {
+			roots = append(roots, np)
+		}
+	}
+
+	possibleMatches := possibleMatches(CLG, roots)
+	if len(possibleMatches) == 0 {
+		return nil, maskAny(networkPayloadNotFoundError)
+	}
+
+	return possibleMatches[0], nil
+}
+
+// SameCLGTreeStrategy chooses a combination of queued network payloads all
+// belonging to the CLG tree of the most recently queued network payload. That
+// is the network payload causing the current activation. Network payloads of
+// other CLG trees are not merged into it. In case multiple combinations
+// qualify, the first one is chosen. The chosen combination is stored as
+// activation configuration of the requested CLG.
+func SameCLGTreeStrategy(serviceCollection servicespec.ServiceCollection, CLG servicespec.CLGService, queue []objectspec.NetworkPayload) ([]objectspec.NetworkPayload, error) {
+	if len(queue) == 0 {
+		return nil, maskAny(networkPayloadNotFoundError)
+	}
+
+	clgTreeID, ok := queue[len(queue)-1].GetContext().GetCLGTreeID()
+	if !ok {
+		return nil, maskAnyf(invalidCLGTreeIDError, "must not be empty")
+	}
+
+	var sameCLGTree []objectspec.NetworkPayload
+	for _, np := range queue {
+		id, ok := np.GetContext().GetCLGTreeID()
+		if ok && id == clgTreeID {
+			sameCLGTree = append(sameCLGTree, np)
+		}
+	}
+
+	possibleMatches := possibleMatches(CLG, sameCLGTree)
+	if len(possibleMatches) == 0 {
+		return nil, maskAny(networkPayloadNotFoundError)
+	}
+
+	matches := possibleMatches[0]
+
+	err := configure(serviceCollection, matches)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	return matches, nil
+}
+
+// configure persists the given combination of matching network payloads as
+// activation configuration for the requested CLG. This configuration is stored
+// using references of the behaviour IDs associated with CLGs that forwarded
+// signals to the requested CLG. Note that the order of behaviour IDs must be
+// preserved, because it represents the input interface of the requested CLG.
+// See ConfigurationStrategy.
+func configure(serviceCollection servicespec.ServiceCollection, matches []objectspec.NetworkPayload) error {
+	behaviourID, ok := matches[0].GetContext().GetBehaviourID()
+	if !ok {
+		return maskAnyf(invalidBehaviourIDError, "must not be empty")
+	}
+	var behaviourIDs []string
+	for _, np := range matches {
+		behaviourIDs = append(behaviourIDs, np.GetSources()...)
+	}
+	err := serviceCollection.Behaviour().SetActivateConfiguration(behaviourID, behaviourIDs)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
+
+// possibleMatches returns all combinations of the given queued network
+// payloads satisfying the interface of the given requested CLG. See
+// matchQueue.
+func possibleMatches(CLG servicespec.CLGService, queue []objectspec.NetworkPayload) [][]objectspec.NetworkPayload {
+	// The input types of the requested CLG are provided as string slice, which
+	// is easily comparable and efficient. They do not contain the context being
+	// the first input argument of each CLG by convention, because output
+	// interfaces of CLGs never have a context as first return value. That way
+	// input and output interfaces are aligned to make them comparable.
+	clgTypes := CLG.InputTypes()
+
+	return matchQueue(queue, clgTypes)
+}
@@ -0,0 +1,560 @@
+package activator
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	kitlog "github.com/go-kit/kit/log"
+
+	servicecollection "github.com/the-anna-project/annad/collection/collection"
+	connectionservice "github.com/the-anna-project/annad/connection/service"
+	"github.com/the-anna-project/annad/id"
+	memoryinstrumentor "github.com/the-anna-project/annad/instrumentor/memory"
+	layercollection "github.com/the-anna-project/annad/layer/collection"
+	layerservice "github.com/the-anna-project/annad/layer/service"
+	"github.com/the-anna-project/annad/log"
+	"github.com/the-anna-project/annad/object/context"
+	"github.com/the-anna-project/annad/object/networkpayload"
+	peerservice "github.com/the-anna-project/annad/peer/service"
+	positionservice "github.com/the-anna-project/annad/position/service"
+	"github.com/the-anna-project/annad/random"
+	"github.com/the-anna-project/annad/service/behaviour"
+	"github.com/the-anna-project/annad/service/tracker"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+	storagecollection "github.com/the-anna-project/annad/storage/collection"
+	memorystorage "github.com/the-anna-project/annad/storage/service/memory"
+	workerservice "github.com/the-anna-project/annad/worker/service"
+)
+
+const (
+	// testBehaviourID is the behaviour ID of the requested CLG used in tests.
+	testBehaviourID = "behaviour-id"
+)
+
+// testNetworkPayloads counts the network payloads created by
+// testNetworkPayload to identify each of them uniquely.
+var testNetworkPayloads int
+
+type testCLG struct {
+	calculate interface{}
+}
+
+func (c *testCLG) Boot() {}
+
+func (c *testCLG) Call(ctx objectspec.Context, args []interface{}) ([]interface{}, error) {
+	return nil, nil
+}
+
+func (c *testCLG) GetCalculate() interface{} {
+	return c.calculate
+}
+
+func (c *testCLG) InputTypes() []string {
+	t := reflect.TypeOf(c.calculate)
+
+	var types []string
+	for i := 1; i < t.NumIn(); i++ {
+		types = append(types, t.In(i).String())
+	}
+
+	return types
+}
+
+func (c *testCLG) Metadata() map[string]string {
+	return map[string]string{"kind": "test"}
+}
+
+func (c *testCLG) OutputTypes() []string {
+	return nil
+}
+
+func (c *testCLG) Service() servicespec.ServiceCollection {
+	return nil
+}
+
+func (c *testCLG) SetServiceCollection(serviceCollection servicespec.ServiceCollection) {}
+
+// testNetworkPayload creates a network payload sent by the given source to the
+// requested CLG identified by testBehaviourID, belonging to the CLG tree
+// identified by the given CLG tree ID.
+func testNetworkPayload(t *testing.T, clgTreeID, source string, args ...interface{}) objectspec.NetworkPayload {
+	var values []reflect.Value
+	for _, a := range args {
+		values = append(values, reflect.ValueOf(a))
+	}
+
+	ctx := context.MustNew()
+	ctx.SetBehaviourID(testBehaviourID)
+	ctx.SetCLGTreeID(clgTreeID)
+
+	testNetworkPayloads++
+
+	newNetworkPayloadConfig := networkpayload.DefaultConfig()
+	newNetworkPayloadConfig.Args = values
+	newNetworkPayloadConfig.Context = ctx
+	newNetworkPayloadConfig.Destination = testBehaviourID
+	newNetworkPayloadConfig.ID = fmt.Sprintf("network-payload-%d", testNetworkPayloads)
+	newNetworkPayloadConfig.Sources = []string{source}
+	newNetworkPayload, err := networkpayload.New(newNetworkPayloadConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	return newNetworkPayload
+}
+
+// testServiceCollection creates a service collection providing everything the
+// activator and its strategies need, backed by in-memory storage. The storage
+// is shut down using the returned function.
+func testServiceCollection(t *testing.T) (servicespec.ServiceCollection, func()) {
+	newConnectionConfig := connectionservice.DefaultConfig()
+	newConnectionConfig.Weight = 1
+	newConnectionService, err := connectionservice.New(newConnectionConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	newLayerCollection := layercollection.New()
+	for _, kind := range []string{layerservice.KindBehaviour, layerservice.KindPosition} {
+		newLayerConfig := layerservice.DefaultConfig()
+		newLayerConfig.Kind = kind
+		newLayerService, err := layerservice.New(newLayerConfig)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		switch kind {
+		case layerservice.KindBehaviour:
+			newLayerCollection.SetBehaviourService(newLayerService)
+		case layerservice.KindPosition:
+			newLayerCollection.SetPositionService(newLayerService)
+		}
+	}
+
+	newLogService := log.New()
+	newLogService.SetRootLogger(kitlog.NewNopLogger())
+
+	newPositionConfig := positionservice.DefaultConfig()
+	newPositionConfig.DimensionCount = 3
+	newPositionConfig.DimensionDepth = 1000000
+	newPositionService, err := positionservice.New(newPositionConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	newStorageCollection := storagecollection.New()
+	newStorageCollection.SetConnectionService(memorystorage.New())
+	newStorageCollection.SetFeatureService(memorystorage.New())
+	newStorageCollection.SetGeneralService(memorystorage.New())
+	newStorageCollection.SetPeerService(memorystorage.New())
+
+	newTrackerService, err := tracker.New(tracker.DefaultConfig())
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	collection := servicecollection.New()
+	collection.SetBehaviourService(behaviour.New())
+	collection.SetConnectionService(newConnectionService)
+	collection.SetIDService(id.New())
+	collection.SetInstrumentorService(memoryinstrumentor.New())
+	collection.SetLayerCollection(newLayerCollection)
+	collection.SetLogService(newLogService)
+	collection.SetPeerService(peerservice.New())
+	collection.SetPositionService(newPositionService)
+	collection.SetRandomService(random.New())
+	collection.SetStorageCollection(newStorageCollection)
+	collection.SetTrackerService(newTrackerService)
+	collection.SetWorkerService(workerservice.New())
+
+	collection.Behaviour().SetServiceCollection(collection)
+	collection.Connection().SetServiceCollection(collection)
+	collection.ID().SetServiceCollection(collection)
+	collection.Layer().Behaviour().SetServiceCollection(collection)
+	collection.Layer().Position().SetServiceCollection(collection)
+	collection.Log().SetServiceCollection(collection)
+	collection.Peer().SetServiceCollection(collection)
+	collection.Position().SetServiceCollection(collection)
+	collection.Random().SetServiceCollection(collection)
+	collection.Storage().Connection().SetServiceCollection(collection)
+	collection.Storage().Feature().SetServiceCollection(collection)
+	collection.Storage().General().SetServiceCollection(collection)
+	collection.Storage().Peer().SetServiceCollection(collection)
+	collection.Tracker().SetServiceCollection(collection)
+	collection.Worker().SetServiceCollection(collection)
+
+	collection.Connection().Boot()
+	collection.Storage().Connection().Boot()
+	collection.Storage().General().Boot()
+	collection.Storage().Peer().Boot()
+
+	return collection, func() {
+		collection.Storage().Connection().Shutdown()
+		collection.Storage().General().Shutdown()
+		collection.Storage().Peer().Shutdown()
+	}
+}
+
+// testSources returns the sources of the given network payloads joined to a
+// string to be easily comparable.
+func testSources(networkPayloads []objectspec.NetworkPayload) string {
+	var sources []string
+	for _, np := range networkPayloads {
+		sources = append(sources, np.GetSources()...)
+	}
+
+	return fmt.Sprintf("%v", sources)
+}
+
+func Test_Activator_Strategy_NoMatch(t *testing.T) {
+	serviceCollection, shutdown := testServiceCollection(t)
+	defer shutdown()
+
+	CLG := &testCLG{
+		calculate: func(ctx objectspec.Context, a int, b string) error {
+			return nil
+		},
+	}
+
+	testCases := []struct {
+		Queue []objectspec.NetworkPayload
+	}{
+		{
+			Queue: nil,
+		},
+		{
+			Queue: []objectspec.NetworkPayload{
+				testNetworkPayload(t, "tree-1", "a", 3),
+			},
+		},
+		{
+			Queue: []objectspec.NetworkPayload{
+				testNetworkPayload(t, "tree-1", "a", "foo"),
+				testNetworkPayload(t, "tree-1", "b", 3.8),
+			},
+		},
+	}
+
+	for name, strategy := range strategies {
+		for i, testCase := range testCases {
+			_, err := strategy(serviceCollection, CLG, testCase.Queue)
+			if !IsNetworkPayloadNotFound(err) {
+				t.Fatal("strategy", name, "case", i+1, "expected", true, "got", false)
+			}
+		}
+	}
+}
+
+func Test_Activator_ConfigurationStrategy(t *testing.T) {
+	serviceCollection, shutdown := testServiceCollection(t)
+	defer shutdown()
+
+	CLG := &testCLG{
+		calculate: func(ctx objectspec.Context, a int, b string) error {
+			return nil
+		},
+	}
+	queue := []objectspec.NetworkPayload{
+		testNetworkPayload(t, "tree-1", "b", "foo"),
+		testNetworkPayload(t, "tree-1", "c", 4),
+		testNetworkPayload(t, "tree-1", "a", 3),
+	}
+
+	// Without stored configuration nothing can be found.
+	_, err := ConfigurationStrategy(serviceCollection, CLG, queue)
+	if !IsNetworkPayloadNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	// The stored configuration decides about the network payloads being merged.
+	err = serviceCollection.Behaviour().SetActivateConfiguration(testBehaviourID, []string{"a", "b"})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	matches, err := ConfigurationStrategy(serviceCollection, CLG, queue)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if testSources(matches) != "[a b]" {
+		t.Fatal("expected", "[a b]", "got", testSources(matches))
+	}
+}
+
+func Test_Activator_ConnectionWeightStrategy(t *testing.T) {
+	serviceCollection, shutdown := testServiceCollection(t)
+	defer shutdown()
+
+	CLG := &testCLG{
+		calculate: func(ctx objectspec.Context, a int) error {
+			return nil
+		},
+	}
+
+	// The tracker connects source b to the requested CLG, the way it does when
+	// source b forwarded to the requested CLG before. Sources a and c are not
+	// connected at all.
+	err := serviceCollection.Tracker().CLGIDs(CLG, testNetworkPayload(t, "tree-0", "b", 5))
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	queue := []objectspec.NetworkPayload{
+		testNetworkPayload(t, "tree-1", "a", 3),
+		testNetworkPayload(t, "tree-1", "c", 4),
+		testNetworkPayload(t, "tree-1", "b", 5),
+	}
+
+	matches, err := ConnectionWeightStrategy(serviceCollection, CLG, queue)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if testSources(matches) != "[b]" {
+		t.Fatal("expected", "[b]", "got", testSources(matches))
+	}
+
+	// The chosen combination is stored as activation configuration.
+	behaviourIDsKey := fmt.Sprintf("activate:configuration:behaviour-id:%s:behaviour-ids", testBehaviourID)
+	str, err := serviceCollection.Storage().General().Get(behaviourIDsKey)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if str != "b" {
+		t.Fatal("expected", "b", "got", str)
+	}
+}
+
+func Test_Activator_FirstComeStrategy(t *testing.T) {
+	serviceCollection, shutdown := testServiceCollection(t)
+	defer shutdown()
+
+	CLG := &testCLG{
+		calculate: func(ctx objectspec.Context, a int, b string) error {
+			return nil
+		},
+	}
+
+	testCases := []struct {
+		Queue    []objectspec.NetworkPayload
+		Expected string
+	}{
+		{
+			Queue: []objectspec.NetworkPayload{
+				testNetworkPayload(t, "tree-1", "a", 3),
+				testNetworkPayload(t, "tree-1", "b", "foo"),
+				testNetworkPayload(t, "tree-1", "c", 4),
+				testNetworkPayload(t, "tree-1", "d", "bar"),
+			},
+			Expected: "[a b]",
+		},
+		{
+			Queue: []objectspec.NetworkPayload{
+				testNetworkPayload(t, "tree-1", "x", "foo"),
+				testNetworkPayload(t, "tree-1", "c", 4),
+				testNetworkPayload(t, "tree-1", "a", 3),
+				testNetworkPayload(t, "tree-1", "b", "bar"),
+			},
+			Expected: "[c x]",
+		},
+		{
+			Queue: []objectspec.NetworkPayload{
+				testNetworkPayload(t, "tree-1", "a", 3),
+				testNetworkPayload(t, "tree-1", "b", 3, "foo"),
+				testNetworkPayload(t, "tree-1", "c", "foo"),
+			},
+			Expected: "[b]",
+		},
+	}
+
+	for i, testCase := range testCases {
+		matches, err := FirstComeStrategy(serviceCollection, CLG, testCase.Queue)
+		if err != nil {
+			t.Fatal("case", i+1, "expected", nil, "got", err)
+		}
+		if testSources(matches) != testCase.Expected {
+			t.Fatal("case", i+1, "expected", testCase.Expected, "got", testSources(matches))
+		}
+	}
+}
+
+func Test_Activator_RandomStrategy(t *testing.T) {
+	serviceCollection, shutdown := testServiceCollection(t)
+	defer shutdown()
+
+	CLG := &testCLG{
+		calculate: func(ctx objectspec.Context, a int, b string) error {
+			return nil
+		},
+	}
+	queue := []objectspec.NetworkPayload{
+		testNetworkPayload(t, "tree-1", "a", 3),
+		testNetworkPayload(t, "tree-1", "b", "foo"),
+		testNetworkPayload(t, "tree-1", "c", 4),
+	}
+
+	for i := 0; i < 10; i++ {
+		matches, err := RandomStrategy(serviceCollection, CLG, queue)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		sources := testSources(matches)
+		if sources != "[a b]" && sources != "[c b]" {
+			t.Fatal("expected", "[a b] or [c b]", "got", sources)
+		}
+	}
+}
+
+func Test_Activator_RootStrategy(t *testing.T) {
+	serviceCollection, shutdown := testServiceCollection(t)
+	defer shutdown()
+
+	CLG := &testCLG{
+		calculate: func(ctx objectspec.Context, a int, b string) error {
+			return nil
+		},
+	}
+
+	// The network payload sent by b travelled one hop and thus was not created
+	// from input.
+	forwarded := testNetworkPayload(t, "tree-1", "b", "foo")
+	newNetworkPayloadConfig := networkpayload.DefaultConfig()
+	newNetworkPayloadConfig.Args = forwarded.GetArgs()
+	newNetworkPayloadConfig.Context = forwarded.GetContext()
+	newNetworkPayloadConfig.Depth = 1
+	newNetworkPayloadConfig.Destination = forwarded.GetDestination()
+	newNetworkPayloadConfig.ID = forwarded.GetID()
+	newNetworkPayloadConfig.Sources = forwarded.GetSources()
+	forwarded, err := networkpayload.New(newNetworkPayloadConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	queue := []objectspec.NetworkPayload{
+		testNetworkPayload(t, "tree-1", "a", 3),
+		forwarded,
+	}
+	_, err = RootStrategy(serviceCollection, CLG, queue)
+	if !IsNetworkPayloadNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	queue = append(queue, testNetworkPayload(t, "tree-1", "c", "bar"))
+	matches, err := RootStrategy(serviceCollection, CLG, queue)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if testSources(matches) != "[a c]" {
+		t.Fatal("expected", "[a c]", "got", testSources(matches))
+	}
+
+	// The chosen combination is not stored as activation configuration.
+	_, err = serviceCollection.Behaviour().ActivateConfiguration(testBehaviourID)
+	if !behaviour.IsNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+func Test_Activator_SameCLGTreeStrategy(t *testing.T) {
+	serviceCollection, shutdown := testServiceCollection(t)
+	defer shutdown()
+
+	CLG := &testCLG{
+		calculate: func(ctx objectspec.Context, a int, b string) error {
+			return nil
+		},
+	}
+
+	testCases := []struct {
+		Queue    []objectspec.NetworkPayload
+		Expected string
+	}{
+		{
+			Queue: []objectspec.NetworkPayload{
+				testNetworkPayload(t, "tree-1", "a", 3),
+				testNetworkPayload(t, "tree-2", "b", "foo"),
+				testNetworkPayload(t, "tree-1", "c", "bar"),
+				testNetworkPayload(t, "tree-2", "d", 4),
+			},
+			Expected: "[d b]",
+		},
+		{
+			Queue: []objectspec.NetworkPayload{
+				testNetworkPayload(t, "tree-2", "d", 4),
+				testNetworkPayload(t, "tree-1", "a", 3),
+				testNetworkPayload(t, "tree-2", "b", "foo"),
+				testNetworkPayload(t, "tree-1", "c", "bar"),
+			},
+			Expected: "[a c]",
+		},
+	}
+
+	for i, testCase := range testCases {
+		matches, err := SameCLGTreeStrategy(serviceCollection, CLG, testCase.Queue)
+		if err != nil {
+			t.Fatal("case", i+1, "expected", nil, "got", err)
+		}
+		if testSources(matches) != testCase.Expected {
+			t.Fatal("case", i+1, "expected", testCase.Expected, "got", testSources(matches))
+		}
+	}
+
+	// Network payloads of other CLG trees are not merged, even if they would
+	// satisfy the interface of the requested CLG.
+	queue := []objectspec.NetworkPayload{
+		testNetworkPayload(t, "tree-1", "a", 3),
+		testNetworkPayload(t, "tree-2", "b", "foo"),
+	}
+	_, err := SameCLGTreeStrategy(serviceCollection, CLG, queue)
+	if !IsNetworkPayloadNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+func Test_Activator_RegisterStrategy(t *testing.T) {
+	var executed bool
+	RegisterStrategy("test", func(serviceCollection servicespec.ServiceCollection, CLG servicespec.CLGService, queue []objectspec.NetworkPayload) ([]objectspec.NetworkPayload, error) {
+		executed = true
+		return nil, maskAny(networkPayloadNotFoundError)
+	})
+	defer func() {
+		strategyMutex.Lock()
+		delete(strategies, "test")
+		strategyMutex.Unlock()
+	}()
+
+	newConfig := DefaultConfig()
+	newConfig.Strategies = []string{"test"}
+	newService, err := New(newConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	for _, strategy := range newService.(*service).strategies {
+		strategy(nil, nil, nil)
+	}
+	if !executed {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected", "panic", "got", nil)
+		}
+	}()
+	RegisterStrategy("test", RandomStrategy)
+}
+
+func Test_Activator_New_Error_Strategies(t *testing.T) {
+	newConfig := DefaultConfig()
+	newConfig.Strategies = nil
+	_, err := New(newConfig)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	newConfig = DefaultConfig()
+	newConfig.Strategies = []string{StrategyConfiguration, "unknown"}
+	_, err = New(newConfig)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
@@ -0,0 +1,44 @@
+package behaviour
+
+import (
+	"fmt"
+
+	"github.com/juju/errgo"
+)
+
+var (
+	maskAny = errgo.MaskFunc(errgo.Any)
+)
+
+func maskAnyf(err error, f string, v ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	f = fmt.Sprintf("%s: %s", err.Error(), f)
+	newErr := errgo.WithCausef(nil, errgo.Cause(err), f, v...)
+	newErr.(*errgo.Err).SetLocation(1)
+
+	return newErr
+}
+
+var invalidBehaviourIDError = errgo.New("invalid behaviour ID")
+
+// IsInvalidBehaviourID asserts invalidBehaviourIDError.
+func IsInvalidBehaviourID(err error) bool {
+	return errgo.Cause(err) == invalidBehaviourIDError
+}
+
+var invalidCLGKindError = errgo.New("invalid CLG kind")
+
+// IsInvalidCLGKind asserts invalidCLGKindError.
+func IsInvalidCLGKind(err error) bool {
+	return errgo.Cause(err) == invalidCLGKindError
+}
+
+var notFoundError = errgo.New("not found")
+
+// IsNotFound asserts notFoundError.
+func IsNotFound(err error) bool {
+	return errgo.Cause(err) == notFoundError
+}
@@ -0,0 +1,28 @@
+package behaviour
+
+import (
+	"fmt"
+)
+
+func activateConfigurationKey(behaviourID string) string {
+	return fmt.Sprintf("activate:configuration:behaviour-id:%s:behaviour-ids", behaviourID)
+}
+
+func forwardConfigurationKey(behaviourID string) string {
+	return fmt.Sprintf("forward:configuration:behaviour-id:%s:behaviour-ids", behaviourID)
+}
+
+// forwardSourcesKey is the key of the set holding the behaviour IDs whose
+// forward configuration contains the given behaviour ID. It is used to remove
+// dangling references when deleting the given behaviour ID.
+func forwardSourcesKey(behaviourID string) string {
+	return fmt.Sprintf("forward:configuration:behaviour-id:%s:source-behaviour-ids", behaviourID)
+}
+
+func kindKey(clgKind string) string {
+	return fmt.Sprintf("clg-kind:%s:behaviour-ids", clgKind)
+}
+
+func metadataKey(behaviourID string) string {
+	return fmt.Sprintf("behaviour-id:%s:metadata", behaviourID)
+}
@@ -0,0 +1,211 @@
+// Package behaviour implements a registry of behaviour IDs. See
+// servicespec.BehaviourService.
+package behaviour
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	servicespec "github.com/the-anna-project/annad/spec/service"
+	storagecollection "github.com/the-anna-project/annad/storage/collection"
+)
+
+// New creates a new behaviour service.
+func New() servicespec.BehaviourService {
+	return &service{
+		// Dependencies.
+		serviceCollection: nil,
+
+		// Settings.
+		metadata: map[string]string{},
+	}
+}
+
+type service struct {
+	// Dependencies.
+
+	serviceCollection servicespec.ServiceCollection
+
+	// Settings.
+
+	metadata map[string]string
+}
+
+func (s *service) ActivateConfiguration(behaviourID string) ([]string, error) {
+	s.Service().Log().Line("func", "ActivateConfiguration")
+
+	str, err := s.Service().Storage().General().Get(activateConfigurationKey(behaviourID))
+	if storagecollection.IsNotFound(err) {
+		return nil, maskAnyf(notFoundError, "activate configuration of behaviour ID '%s'", behaviourID)
+	} else if err != nil {
+		return nil, maskAny(err)
+	}
+
+	return strings.Split(str, ","), nil
+}
+
+func (s *service) AddForwardConfiguration(behaviourID string, behaviourIDs []string) error {
+	s.Service().Log().Line("func", "AddForwardConfiguration")
+
+	for _, b := range behaviourIDs {
+		err := s.Service().Storage().General().PushToSet(forwardConfigurationKey(behaviourID), b)
+		if err != nil {
+			return maskAny(err)
+		}
+		err = s.Service().Storage().General().PushToSet(forwardSourcesKey(b), behaviourID)
+		if err != nil {
+			return maskAny(err)
+		}
+	}
+
+	return nil
+}
+
+func (s *service) Boot() {
+	id, err := s.Service().ID().New()
+	if err != nil {
+		panic(err)
+	}
+	s.metadata = map[string]string{
+		"id":   id,
+		"name": "behaviour",
+		"type": "service",
+	}
+}
+
+func (s *service) Create(behaviourID, clgKind, clgTreeID string) error {
+	s.Service().Log().Line("func", "Create")
+
+	if behaviourID == "" {
+		return maskAnyf(invalidBehaviourIDError, "must not be empty")
+	}
+	if clgKind == "" {
+		return maskAnyf(invalidCLGKindError, "must not be empty")
+	}
+
+	metadata := map[string]string{
+		"clg-kind":    clgKind,
+		"clg-tree-id": clgTreeID,
+		"created":     strconv.FormatInt(time.Now().Unix(), 10),
+	}
+	err := s.Service().Storage().General().SetStringMap(metadataKey(behaviourID), metadata)
+	if err != nil {
+		return maskAny(err)
+	}
+	err = s.Service().Storage().General().PushToSet(kindKey(clgKind), behaviourID)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
+
+func (s *service) Delete(behaviourID string) error {
+	s.Service().Log().Line("func", "Delete")
+
+	metadata, err := s.Search(behaviourID)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	err = s.Service().Storage().General().RemoveFromSet(kindKey(metadata["clg-kind"]), behaviourID)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	// Other behaviours must not forward network payloads to the deleted
+	// behaviour anymore. The behaviours the deleted behaviour forwarded network
+	// payloads to do not need to know about it anymore either.
+	sources, err := s.Service().Storage().General().GetAllFromSet(forwardSourcesKey(behaviourID))
+	if err != nil {
+		return maskAny(err)
+	}
+	for _, b := range sources {
+		err := s.Service().Storage().General().RemoveFromSet(forwardConfigurationKey(b), behaviourID)
+		if err != nil {
+			return maskAny(err)
+		}
+	}
+	destinations, err := s.Service().Storage().General().GetAllFromSet(forwardConfigurationKey(behaviourID))
+	if err != nil {
+		return maskAny(err)
+	}
+	for _, b := range destinations {
+		err := s.Service().Storage().General().RemoveFromSet(forwardSourcesKey(b), behaviourID)
+		if err != nil {
+			return maskAny(err)
+		}
+	}
+
+	for _, key := range []string{metadataKey(behaviourID), activateConfigurationKey(behaviourID), forwardConfigurationKey(behaviourID), forwardSourcesKey(behaviourID)} {
+		err := s.Service().Storage().General().Remove(key)
+		if err != nil {
+			return maskAny(err)
+		}
+	}
+
+	return nil
+}
+
+func (s *service) ForwardConfiguration(behaviourID string) ([]string, error) {
+	s.Service().Log().Line("func", "ForwardConfiguration")
+
+	behaviourIDs, err := s.Service().Storage().General().GetAllFromSet(forwardConfigurationKey(behaviourID))
+	if err != nil {
+		return nil, maskAny(err)
+	}
+	if len(behaviourIDs) == 0 {
+		return nil, maskAnyf(notFoundError, "forward configuration of behaviour ID '%s'", behaviourID)
+	}
+
+	return behaviourIDs, nil
+}
+
+func (s *service) Metadata() map[string]string {
+	return s.metadata
+}
+
+func (s *service) Search(behaviourID string) (map[string]string, error) {
+	s.Service().Log().Line("func", "Search")
+
+	metadata, err := s.Service().Storage().General().GetStringMap(metadataKey(behaviourID))
+	if err != nil {
+		return nil, maskAny(err)
+	}
+	if len(metadata) == 0 {
+		return nil, maskAnyf(notFoundError, "behaviour ID '%s'", behaviourID)
+	}
+
+	return metadata, nil
+}
+
+func (s *service) SearchByKind(clgKind string) ([]string, error) {
+	s.Service().Log().Line("func", "SearchByKind")
+
+	behaviourIDs, err := s.Service().Storage().General().GetAllFromSet(kindKey(clgKind))
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	return behaviourIDs, nil
+}
+
+func (s *service) Service() servicespec.ServiceCollection {
+	return s.serviceCollection
+}
+
+func (s *service) SetActivateConfiguration(behaviourID string, behaviourIDs []string) error {
+	s.Service().Log().Line("func", "SetActivateConfiguration")
+
+	err := s.Service().Storage().General().Set(activateConfigurationKey(behaviourID), strings.Join(behaviourIDs, ","))
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
+
+func (s *service) SetServiceCollection(sc servicespec.ServiceCollection) {
+	s.serviceCollection = sc
+}
@@ -0,0 +1,230 @@
+package behaviour
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	kitlog "github.com/go-kit/kit/log"
+
+	servicecollection "github.com/the-anna-project/annad/collection/collection"
+	"github.com/the-anna-project/annad/id"
+	memoryinstrumentor "github.com/the-anna-project/annad/instrumentor/memory"
+	"github.com/the-anna-project/annad/log"
+	"github.com/the-anna-project/annad/random"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+	storagecollection "github.com/the-anna-project/annad/storage/collection"
+	memorystorage "github.com/the-anna-project/annad/storage/service/memory"
+)
+
+// testService creates a new behaviour service backed by memory storage. The
+// returned function shuts the storage down.
+func testService() (servicespec.BehaviourService, func()) {
+	newLogService := log.New()
+	newLogService.SetRootLogger(kitlog.NewNopLogger())
+
+	newStorageCollection := storagecollection.New()
+	newStorageCollection.SetGeneralService(memorystorage.New())
+
+	collection := servicecollection.New()
+	collection.SetBehaviourService(New())
+	collection.SetIDService(id.New())
+	collection.SetInstrumentorService(memoryinstrumentor.New())
+	collection.SetLogService(newLogService)
+	collection.SetRandomService(random.New())
+	collection.SetStorageCollection(newStorageCollection)
+
+	collection.Behaviour().SetServiceCollection(collection)
+	collection.ID().SetServiceCollection(collection)
+	collection.Log().SetServiceCollection(collection)
+	collection.Random().SetServiceCollection(collection)
+	collection.Storage().General().SetServiceCollection(collection)
+
+	collection.Storage().General().Boot()
+	collection.Behaviour().Boot()
+
+	return collection.Behaviour(), func() {
+		collection.Storage().General().Shutdown()
+	}
+}
+
+func Test_Behaviour_Create_Search(t *testing.T) {
+	newService, shutdown := testService()
+	defer shutdown()
+
+	_, err := newService.Search("b1")
+	if !IsNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	err = newService.Create("b1", "sum", "tree-1")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	metadata, err := newService.Search("b1")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if metadata["clg-kind"] != "sum" {
+		t.Fatal("expected", "sum", "got", metadata["clg-kind"])
+	}
+	if metadata["clg-tree-id"] != "tree-1" {
+		t.Fatal("expected", "tree-1", "got", metadata["clg-tree-id"])
+	}
+	if metadata["created"] == "" {
+		t.Fatal("expected", "creation time", "got", "")
+	}
+}
+
+func Test_Behaviour_Create_Error(t *testing.T) {
+	newService, shutdown := testService()
+	defer shutdown()
+
+	err := newService.Create("", "sum", "tree-1")
+	if !IsInvalidBehaviourID(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+	err = newService.Create("b1", "", "tree-1")
+	if !IsInvalidCLGKind(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+func Test_Behaviour_SearchByKind_Delete(t *testing.T) {
+	newService, shutdown := testService()
+	defer shutdown()
+
+	for _, b := range []string{"b1", "b2"} {
+		err := newService.Create(b, "sum", "tree-1")
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+	err := newService.Create("b3", "divide", "tree-1")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	behaviourIDs, err := newService.SearchByKind("sum")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	sort.Strings(behaviourIDs)
+	if !reflect.DeepEqual(behaviourIDs, []string{"b1", "b2"}) {
+		t.Fatal("expected", []string{"b1", "b2"}, "got", behaviourIDs)
+	}
+
+	err = newService.SetActivateConfiguration("b1", []string{"b3"})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	err = newService.AddForwardConfiguration("b1", []string{"b2"})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	err = newService.AddForwardConfiguration("b3", []string{"b1", "b2"})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	err = newService.Delete("b1")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	behaviourIDs, err = newService.SearchByKind("sum")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if !reflect.DeepEqual(behaviourIDs, []string{"b2"}) {
+		t.Fatal("expected", []string{"b2"}, "got", behaviourIDs)
+	}
+	_, err = newService.Search("b1")
+	if !IsNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+	_, err = newService.ActivateConfiguration("b1")
+	if !IsNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+	_, err = newService.ForwardConfiguration("b1")
+	if !IsNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	// Other behaviours do not forward network payloads to the deleted behaviour
+	// anymore.
+	behaviourIDs, err = newService.ForwardConfiguration("b3")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if !reflect.DeepEqual(behaviourIDs, []string{"b2"}) {
+		t.Fatal("expected", []string{"b2"}, "got", behaviourIDs)
+	}
+
+	// A behaviour ID being registered again starts without references.
+	err = newService.Create("b1", "sum", "tree-2")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	err = newService.Delete("b2")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	_, err = newService.ForwardConfiguration("b3")
+	if !IsNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+	err = newService.Delete("b1")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	err = newService.Delete("b1")
+	if !IsNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+func Test_Behaviour_Configuration(t *testing.T) {
+	newService, shutdown := testService()
+	defer shutdown()
+
+	_, err := newService.ActivateConfiguration("b1")
+	if !IsNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+	_, err = newService.ForwardConfiguration("b1")
+	if !IsNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	err = newService.SetActivateConfiguration("b1", []string{"b3", "b2"})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	behaviourIDs, err := newService.ActivateConfiguration("b1")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	// The order of the activate configuration is preserved.
+	if !reflect.DeepEqual(behaviourIDs, []string{"b3", "b2"}) {
+		t.Fatal("expected", []string{"b3", "b2"}, "got", behaviourIDs)
+	}
+
+	err = newService.AddForwardConfiguration("b1", []string{"b2"})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	err = newService.AddForwardConfiguration("b1", []string{"b3", "b2"})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	behaviourIDs, err = newService.ForwardConfiguration("b1")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	sort.Strings(behaviourIDs)
+	if !reflect.DeepEqual(behaviourIDs, []string{"b2", "b3"}) {
+		t.Fatal("expected", []string{"b2", "b3"}, "got", behaviourIDs)
+	}
+}
@@ -0,0 +1,44 @@
+package bus
+
+import (
+	"fmt"
+
+	"github.com/juju/errgo"
+)
+
+var (
+	maskAny = errgo.MaskFunc(errgo.Any)
+)
+
+func maskAnyf(err error, f string, v ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	f = fmt.Sprintf("%s: %s", err.Error(), f)
+	newErr := errgo.WithCausef(nil, errgo.Cause(err), f, v...)
+	newErr.(*errgo.Err).SetLocation(1)
+
+	return newErr
+}
+
+var invalidConfigError = errgo.New("invalid config")
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return errgo.Cause(err) == invalidConfigError
+}
+
+var alreadySubscribedError = errgo.New("already subscribed")
+
+// IsAlreadySubscribed asserts alreadySubscribedError.
+func IsAlreadySubscribed(err error) bool {
+	return errgo.Cause(err) == alreadySubscribedError
+}
+
+var busShutDownError = errgo.New("bus shut down")
+
+// IsBusShutDown asserts busShutDownError.
+func IsBusShutDown(err error) bool {
+	return errgo.Cause(err) == busShutDownError
+}
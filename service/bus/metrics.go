@@ -0,0 +1,39 @@
+package bus
+
+import (
+	"strings"
+
+	objectspec "github.com/the-anna-project/annad/spec/object"
+)
+
+// metrics receives the given network events until the bus shuts down. The
+// durations and errors of the stages of the network are emitted per kind of
+// network event as follows.
+//
+//	<prefix>_bus_events_<kind>_durations_histogram_milliseconds
+//
+//	    Holds the durations of network events of the given kind, if
+//	    measured.
+//
+//	<prefix>_bus_events_<kind>_errors_total
+//
+//	    Holds the number of network events of the given kind carrying an
+//	    error.
+func (s *service) metrics(networkEvents <-chan objectspec.NetworkEvent) {
+	for networkEvent := range networkEvents {
+		kind := strings.Replace(networkEvent.GetKind(), "-", "_", -1)
+
+		if networkEvent.GetDuration() > 0 {
+			h, err := s.Service().Instrumentor().GetHistogram(s.Service().Instrumentor().NewKey("bus", "events", kind, "durations", "histogram", "milliseconds"))
+			if err != nil {
+				s.Service().Log().Line("msg", maskAny(err))
+				continue
+			}
+			h.Observe(networkEvent.GetDuration().Seconds() * 1000)
+		}
+
+		if networkEvent.GetError() != "" {
+			s.increment(s.Service().Instrumentor().NewKey("bus", "events", kind, "errors", "total"), 1)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+// Package bus implements an internal publish/subscribe bus carrying network
+// events. See servicespec.BusService.
+package bus
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+)
+
+// Config represents the configuration used to create a new bus service.
+type Config struct {
+	// Settings.
+
+	// BufferSize is the number of network events buffered for each subscriber.
+	// Network events published while the buffer of a subscriber is full are
+	// dropped for this subscriber, unless it is a blocking subscriber.
+	BufferSize int
+	// Tracing defines whether each network event published on the bus is
+	// logged. See tracer.
+	Tracing bool
+}
+
+// DefaultConfig provides a default configuration to create a new bus service
+// by best effort.
+func DefaultConfig() Config {
+	newConfig := Config{
+		// Settings.
+		BufferSize: 1000,
+		Tracing:    false,
+	}
+
+	return newConfig
+}
+
+// New creates a new bus service.
+func New(config Config) (servicespec.BusService, error) {
+	// Settings.
+	if config.BufferSize <= 0 {
+		return nil, maskAnyf(invalidConfigError, "buffer size must be greater than 0")
+	}
+
+	newService := &service{
+		// Dependencies.
+		serviceCollection: nil,
+
+		// Settings.
+		bufferSize:  config.BufferSize,
+		metadata:    map[string]string{},
+		mutex:       sync.RWMutex{},
+		shutDown:    false,
+		subscribers: map[string]*subscriber{},
+		tracing:     config.Tracing,
+	}
+
+	return newService, nil
+}
+
+type service struct {
+	// Dependencies.
+
+	serviceCollection servicespec.ServiceCollection
+
+	// Settings.
+
+	bufferSize int
+	metadata   map[string]string
+	// mutex guards the subscribers against being closed while network events
+	// are published to them. Blocking subscribers are released before the
+	// mutex is locked, because publishers might wait for them while holding
+	// the mutex.
+	mutex       sync.RWMutex
+	shutDown    bool
+	subscribers map[string]*subscriber
+	tracing     bool
+}
+
+// subscriber represents a single subscription of the bus.
+type subscriber struct {
+	// blocking defines whether publishers wait for the subscriber to receive
+	// network events, instead of dropping them.
+	blocking bool
+	// dropped is the number of network events dropped for the subscriber. It
+	// must only be accessed atomically.
+	dropped int64
+	events  chan objectspec.NetworkEvent
+	// released is closed as soon as the subscriber is about to be removed. It
+	// stops publishers from waiting for a blocking subscriber.
+	released    chan struct{}
+	releaseOnce sync.Once
+}
+
+// release stops publishers from waiting for the subscriber.
+func (s *subscriber) release() {
+	s.releaseOnce.Do(func() {
+		close(s.released)
+	})
+}
+
+func (s *service) Boot() {
+	id, err := s.Service().ID().New()
+	if err != nil {
+		panic(err)
+	}
+	s.metadata = map[string]string{
+		"id":   id,
+		"name": "bus",
+		"type": "service",
+	}
+
+	// Metrics and tracing subscribe like any other subscriber. They never block
+	// publishers and stop as soon as the bus shuts down.
+	networkEvents, err := s.Subscribe("metrics")
+	if err != nil {
+		panic(err)
+	}
+	go s.metrics(networkEvents)
+
+	if s.tracing {
+		networkEvents, err := s.Subscribe("tracing")
+		if err != nil {
+			panic(err)
+		}
+		go s.tracer(networkEvents)
+	}
+}
+
+func (s *service) Dropped(name string) int64 {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	sub, ok := s.subscribers[name]
+	if !ok {
+		return 0
+	}
+
+	return atomic.LoadInt64(&sub.dropped)
+}
+
+func (s *service) Metadata() map[string]string {
+	return s.metadata
+}
+
+func (s *service) Publish(networkEvent objectspec.NetworkEvent) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var dropped int
+	for name, sub := range s.subscribers {
+		if sub.blocking {
+			select {
+			case sub.events <- networkEvent:
+			case <-sub.released:
+			}
+			continue
+		}
+
+		select {
+		case sub.events <- networkEvent:
+		default:
+			atomic.AddInt64(&sub.dropped, 1)
+			s.increment(s.Service().Instrumentor().NewKey("bus", "subscribers", name, "dropped", "counter", "total"), 1)
+			dropped++
+		}
+	}
+
+	s.count(strings.Replace(networkEvent.GetKind(), "-", "_", -1), 1)
+	if dropped > 0 {
+		s.count("dropped", dropped)
+	}
+}
+
+func (s *service) Service() servicespec.ServiceCollection {
+	return s.serviceCollection
+}
+
+func (s *service) SetServiceCollection(sc servicespec.ServiceCollection) {
+	s.serviceCollection = sc
+}
+
+func (s *service) Shutdown() {
+	s.Service().Log().Line("func", "Shutdown")
+
+	s.mutex.RLock()
+	for _, sub := range s.subscribers {
+		sub.release()
+	}
+	s.mutex.RUnlock()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for name, sub := range s.subscribers {
+		close(sub.events)
+		delete(s.subscribers, name)
+	}
+	s.shutDown = true
+}
+
+func (s *service) Subscribe(name string) (<-chan objectspec.NetworkEvent, error) {
+	events, err := s.subscribe(name, false)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	return events, nil
+}
+
+func (s *service) SubscribeBlocking(name string) (<-chan objectspec.NetworkEvent, error) {
+	events, err := s.subscribe(name, true)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	return events, nil
+}
+
+func (s *service) Unsubscribe(name string) {
+	s.mutex.RLock()
+	sub, ok := s.subscribers[name]
+	s.mutex.RUnlock()
+	if !ok {
+		return
+	}
+	sub.release()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// The subscriber might have been removed concurrently, and the name might
+	// even have been used again.
+	if s.subscribers[name] != sub {
+		return
+	}
+	close(sub.events)
+	delete(s.subscribers, name)
+}
+
+// count increments the counter of network events identified by the given
+// name by the given delta.
+func (s *service) count(name string, delta int) {
+	s.increment(s.Service().Instrumentor().NewKey("bus", "events", name, "counter", "total"), delta)
+}
+
+// increment increments the counter identified by the given key by the given
+// delta.
+func (s *service) increment(key string, delta int) {
+	c, err := s.Service().Instrumentor().GetCounter(key)
+	if err != nil {
+		s.Service().Log().Line("msg", maskAny(err))
+		return
+	}
+	c.IncrBy(float64(delta))
+}
+
+// subscribe implements Subscribe and SubscribeBlocking.
+func (s *service) subscribe(name string, blocking bool) (<-chan objectspec.NetworkEvent, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.shutDown {
+		return nil, maskAny(busShutDownError)
+	}
+	if _, ok := s.subscribers[name]; ok {
+		return nil, maskAnyf(alreadySubscribedError, "name: %s", name)
+	}
+
+	sub := &subscriber{
+		blocking: blocking,
+		dropped:  0,
+		events:   make(chan objectspec.NetworkEvent, s.bufferSize),
+		released: make(chan struct{}),
+	}
+	s.subscribers[name] = sub
+
+	return sub.events, nil
+}
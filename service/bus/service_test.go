@@ -0,0 +1,384 @@
+package bus
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+
+	servicecollection "github.com/the-anna-project/annad/collection/collection"
+	memoryinstrumentor "github.com/the-anna-project/annad/instrumentor/memory"
+	"github.com/the-anna-project/annad/log"
+	"github.com/the-anna-project/annad/object/networkevent"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+)
+
+// testService creates a new bus service having the given buffer size.
+func testService(t *testing.T, bufferSize int) servicespec.BusService {
+	newConfig := DefaultConfig()
+	newConfig.BufferSize = bufferSize
+	newService, err := New(newConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	newLogService := log.New()
+	newLogService.SetRootLogger(kitlog.NewNopLogger())
+
+	collection := servicecollection.New()
+	collection.SetBusService(newService)
+	collection.SetInstrumentorService(memoryinstrumentor.New())
+	collection.SetLogService(newLogService)
+
+	collection.Bus().SetServiceCollection(collection)
+	collection.Log().SetServiceCollection(collection)
+
+	return collection.Bus()
+}
+
+// testInstrumentor records the samples observed and the deltas counted per
+// key.
+type testInstrumentor struct {
+	servicespec.InstrumentorService
+
+	mutex   sync.Mutex
+	samples map[string][]float64
+}
+
+func (i *testInstrumentor) GetCounter(key string) (objectspec.InstrumentorCounter, error) {
+	return &testMetric{instrumentor: i, key: key}, nil
+}
+
+func (i *testInstrumentor) GetHistogram(key string) (objectspec.InstrumentorHistogram, error) {
+	return &testMetric{instrumentor: i, key: key}, nil
+}
+
+func (i *testInstrumentor) NewKey(s ...string) string {
+	return strings.Join(s, "_")
+}
+
+// get returns the samples recorded for the given key.
+func (i *testInstrumentor) get(key string) []float64 {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	return i.samples[key]
+}
+
+// testMetric records its samples within its test instrumentor.
+type testMetric struct {
+	instrumentor *testInstrumentor
+	key          string
+}
+
+func (m *testMetric) IncrBy(delta float64) {
+	m.Observe(delta)
+}
+
+func (m *testMetric) Observe(sample float64) {
+	m.instrumentor.mutex.Lock()
+	defer m.instrumentor.mutex.Unlock()
+
+	m.instrumentor.samples[m.key] = append(m.instrumentor.samples[m.key], sample)
+}
+
+// testServiceWithInstrumentor creates a new bus service like testService, but
+// uses a test instrumentor and logs to the returned buffer.
+func testServiceWithInstrumentor(t *testing.T, tracing bool) (*service, *testInstrumentor, *bytes.Buffer) {
+	newConfig := DefaultConfig()
+	newConfig.Tracing = tracing
+	newService, err := New(newConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	newInstrumentor := &testInstrumentor{
+		InstrumentorService: memoryinstrumentor.New(),
+		samples:             map[string][]float64{},
+	}
+	var newBuffer bytes.Buffer
+	newLogService := log.New()
+	newLogService.SetRootLogger(kitlog.NewLogfmtLogger(kitlog.NewSyncWriter(&newBuffer)))
+
+	collection := servicecollection.New()
+	collection.SetBusService(newService)
+	collection.SetInstrumentorService(newInstrumentor)
+	collection.SetLogService(newLogService)
+
+	collection.Bus().SetServiceCollection(collection)
+	collection.Log().SetServiceCollection(collection)
+
+	return newService.(*service), newInstrumentor, &newBuffer
+}
+
+// testNetworkEvent creates a new network event of the given kind.
+func testNetworkEvent(t *testing.T, kind string) objectspec.NetworkEvent {
+	newConfig := networkevent.DefaultConfig()
+	newConfig.CLGTreeID = "tree-1"
+	newConfig.Kind = kind
+	newNetworkEvent, err := networkevent.New(newConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	return newNetworkEvent
+}
+
+func Test_Bus_New_Error_BufferSize(t *testing.T) {
+	newConfig := DefaultConfig()
+	newConfig.BufferSize = 0
+	_, err := New(newConfig)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+func Test_Bus_Publish(t *testing.T) {
+	newService := testService(t, 2)
+
+	a, err := newService.Subscribe("a")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	b, err := newService.Subscribe("b")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	// Subscriber a does not consume anything, while subscriber b does. The
+	// buffer of subscriber a overflows. Publishing does not block.
+	for _, kind := range []string{networkevent.KindActivated, networkevent.KindCalculated, networkevent.KindForwarded} {
+		newService.Publish(testNetworkEvent(t, kind))
+
+		networkEvent := <-b
+		if networkEvent.GetKind() != kind {
+			t.Fatal("expected", kind, "got", networkEvent.GetKind())
+		}
+	}
+
+	if newService.Dropped("a") != 1 {
+		t.Fatal("expected", 1, "got", newService.Dropped("a"))
+	}
+	if newService.Dropped("b") != 0 {
+		t.Fatal("expected", 0, "got", newService.Dropped("b"))
+	}
+	for _, kind := range []string{networkevent.KindActivated, networkevent.KindCalculated} {
+		networkEvent := <-a
+		if networkEvent.GetKind() != kind {
+			t.Fatal("expected", kind, "got", networkEvent.GetKind())
+		}
+	}
+}
+
+func Test_Bus_Publish_Blocking(t *testing.T) {
+	newService := testService(t, 1)
+
+	a, err := newService.SubscribeBlocking("a")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	// The buffer of subscriber a is full after the first network event. The
+	// second network event is not dropped, but publishing waits until
+	// subscriber a received the first one.
+	newService.Publish(testNetworkEvent(t, networkevent.KindActivated))
+	published := make(chan struct{})
+	go func() {
+		newService.Publish(testNetworkEvent(t, networkevent.KindCalculated))
+		close(published)
+	}()
+	select {
+	case <-published:
+		t.Fatal("expected", "publishing to wait", "got", "published")
+	case <-time.After(10 * time.Millisecond):
+	}
+	for _, kind := range []string{networkevent.KindActivated, networkevent.KindCalculated} {
+		networkEvent := <-a
+		if networkEvent.GetKind() != kind {
+			t.Fatal("expected", kind, "got", networkEvent.GetKind())
+		}
+	}
+	<-published
+	if newService.Dropped("a") != 0 {
+		t.Fatal("expected", 0, "got", newService.Dropped("a"))
+	}
+
+	// Unsubscribing releases publishers waiting for subscriber a.
+	newService.Publish(testNetworkEvent(t, networkevent.KindActivated))
+	published = make(chan struct{})
+	go func() {
+		newService.Publish(testNetworkEvent(t, networkevent.KindCalculated))
+		close(published)
+	}()
+	newService.Unsubscribe("a")
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatal("expected", "published", "got", "timeout")
+	}
+}
+
+func Test_Bus_Subscribe_Error(t *testing.T) {
+	newService := testService(t, 1)
+
+	_, err := newService.Subscribe("a")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	_, err = newService.Subscribe("a")
+	if !IsAlreadySubscribed(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	newService.Shutdown()
+	_, err = newService.Subscribe("b")
+	if !IsBusShutDown(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+func Test_Bus_Unsubscribe(t *testing.T) {
+	newService := testService(t, 1)
+
+	a, err := newService.Subscribe("a")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	b, err := newService.Subscribe("b")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	newService.Unsubscribe("a")
+	if _, ok := <-a; ok {
+		t.Fatal("expected", false, "got", true)
+	}
+
+	// Publishing to the remaining subscribers still works. The name of the
+	// subscriber which unsubscribed can be used again.
+	newService.Publish(testNetworkEvent(t, networkevent.KindActivated))
+	if _, ok := <-b; !ok {
+		t.Fatal("expected", true, "got", false)
+	}
+	_, err = newService.Subscribe("a")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	newService.Shutdown()
+	if _, ok := <-b; ok {
+		t.Fatal("expected", false, "got", true)
+	}
+}
+
+func Test_Bus_Metrics(t *testing.T) {
+	newService, newInstrumentor, _ := testServiceWithInstrumentor(t, false)
+
+	networkEvents, err := newService.Subscribe("metrics")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		newService.metrics(networkEvents)
+		close(done)
+	}()
+
+	newConfig := networkevent.DefaultConfig()
+	newConfig.CLGTreeID = "tree-1"
+	newConfig.Duration = 3 * time.Millisecond
+	newConfig.Error = "test error"
+	newConfig.Kind = networkevent.KindTreeFinished
+	newNetworkEvent, err := networkevent.New(newConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	newService.Publish(newNetworkEvent)
+	newService.Publish(testNetworkEvent(t, networkevent.KindActivated))
+
+	// Shutting down closes the subscription, so the metrics stop as soon as
+	// they handled all network events.
+	newService.Shutdown()
+	<-done
+
+	durations := newInstrumentor.get(newInstrumentor.NewKey("bus", "events", "tree_finished", "durations", "histogram", "milliseconds"))
+	if len(durations) != 1 || durations[0] != 3 {
+		t.Fatal("expected", []float64{3}, "got", durations)
+	}
+	errors := newInstrumentor.get(newInstrumentor.NewKey("bus", "events", "tree_finished", "errors", "total"))
+	if len(errors) != 1 {
+		t.Fatal("expected", 1, "got", len(errors))
+	}
+	durations = newInstrumentor.get(newInstrumentor.NewKey("bus", "events", "activated", "durations", "histogram", "milliseconds"))
+	if len(durations) != 0 {
+		t.Fatal("expected", 0, "got", len(durations))
+	}
+}
+
+func Test_Bus_Publish_Dropped(t *testing.T) {
+	newService, newInstrumentor, _ := testServiceWithInstrumentor(t, false)
+
+	_, err := newService.Subscribe("a")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	_, err = newService.Subscribe("b")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	// The buffers of both subscribers hold 1000 network events. Each network
+	// event published beyond is dropped for each subscriber.
+	for i := 0; i < 1002; i++ {
+		newService.Publish(testNetworkEvent(t, networkevent.KindActivated))
+	}
+
+	for _, name := range []string{"a", "b"} {
+		dropped := newInstrumentor.get(newInstrumentor.NewKey("bus", "subscribers", name, "dropped", "counter", "total"))
+		if len(dropped) != 2 {
+			t.Fatal("expected", 2, "got", len(dropped))
+		}
+	}
+	dropped := newInstrumentor.get(newInstrumentor.NewKey("bus", "events", "dropped", "counter", "total"))
+	if len(dropped) != 2 || dropped[0] != 2 {
+		t.Fatal("expected", []float64{2, 2}, "got", dropped)
+	}
+}
+
+func Test_Bus_Tracing(t *testing.T) {
+	newService, _, newBuffer := testServiceWithInstrumentor(t, true)
+
+	networkEvents, err := newService.Subscribe("tracing")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		newService.tracer(networkEvents)
+		close(done)
+	}()
+
+	newService.Publish(testNetworkEvent(t, networkevent.KindActivated))
+	newService.Publish(testNetworkEvent(t, networkevent.KindCalculated))
+	newService.Shutdown()
+	<-done
+
+	var lines []string
+	for _, l := range strings.Split(newBuffer.String(), "\n") {
+		if strings.Contains(l, "msg=\"network event\"") {
+			lines = append(lines, l)
+		}
+	}
+	if len(lines) != 2 {
+		t.Fatal("expected", 2, "got", len(lines))
+	}
+	for i, kind := range []string{networkevent.KindActivated, networkevent.KindCalculated} {
+		if !strings.Contains(lines[i], "kind="+kind) || !strings.Contains(lines[i], "clg-tree-id=tree-1") {
+			t.Fatal("case", i+1, "expected", kind, "got", lines[i])
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package bus
+
+import (
+	objectspec "github.com/the-anna-project/annad/spec/object"
+)
+
+// tracer receives the given network events until the bus shuts down. Each
+// network event is logged, so the way of input through the neural network can
+// be followed stage by stage.
+func (s *service) tracer(networkEvents <-chan objectspec.NetworkEvent) {
+	for networkEvent := range networkEvents {
+		s.Service().Log().Line(
+			"msg", "network event",
+			"kind", networkEvent.GetKind(),
+			"clg-tree-id", networkEvent.GetCLGTreeID(),
+			"clg-name", networkEvent.GetCLGName(),
+			"behaviour-id", networkEvent.GetBehaviourID(),
+			"session-id", networkEvent.GetSessionID(),
+			"duration", networkEvent.GetDuration(),
+			"error", networkEvent.GetError(),
+		)
+	}
+}
@@ -2,9 +2,17 @@
 // neural network.
 //
 // Note that this package defines a go generate statement to generate fully
-// functional source code for all CLGs.
+// functional source code for all CLGs. The CLG generator lives in the clggen
+// directory of this repository.
 //
-//go:generate clggen generate --clg-dir=. --template-dir=../../template
+// Besides the package name, the templates are rendered using the signature of
+// the calculate method of each CLG. Inputs lists the argument types excluding
+// the context. Outputs lists the output types excluding a trailing error.
+// ReturnsError tells whether the calculate method returns an error. That way
+// each CLG gets a typed adapter, which is called by the network without any
+// reflection.
+//
+//go:generate go run ../../clggen generate --clg-dir=. --template-dir=../../template
 //
 package clg
 
@@ -2,8 +2,9 @@ package divide
 
 // This file is generated by the CLG generator. Don't edit it manually. The CLG
 // generator is invoked by go generate. For more information about the usage of
-// the CLG generator check https://github.com/xh3b4sd/clggen or have a look at
-// the clg package. There is the go generate statement placed to invoke clggen.
+// the CLG generator check the clggen directory of this repository or have a
+// look at the clg package. There is the go generate statement placed to invoke
+// clggen.
 
 import (
 	"fmt"
@@ -3,9 +3,13 @@
 package divide
 
 import (
-	objectspec "github.com/the-anna-project/spec/object"
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
+// pure marks the divide CLG as pure. The quotient only depends on the given
+// arguments, so the network memoizes the results.
+const pure = true
+
 // calculate creates the quotient of the given float64s.
 func (s *service) calculate(ctx objectspec.Context, a, b float64) float64 {
 	return a / b
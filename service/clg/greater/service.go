@@ -3,9 +3,13 @@
 package greater
 
 import (
-	objectspec "github.com/the-anna-project/spec/object"
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
+// pure marks the greater CLG as pure. The greater number only depends on the
+// given arguments, so the network memoizes the results.
+const pure = true
+
 // calculate returns the number that is greater than the other.
 func (s *service) calculate(ctx objectspec.Context, a, b float64) float64 {
 	if a > b {
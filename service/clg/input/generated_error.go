@@ -2,8 +2,9 @@ package input
 
 // This file is generated by the CLG generator. Don't edit it manually. The CLG
 // generator is invoked by go generate. For more information about the usage of
-// the CLG generator check https://github.com/xh3b4sd/clggen or have a look at
-// the clg package. There is the go generate statement placed to invoke clggen.
+// the CLG generator check the clggen directory of this repository or have a
+// look at the clg package. There is the go generate statement placed to invoke
+// clggen.
 
 import (
 	"fmt"
@@ -27,6 +28,13 @@ func maskAnyf(err error, f string, v ...interface{}) error {
 	return newErr
 }
 
+var invalidArgumentsError = errgo.New("invalid arguments")
+
+// IsInvalidArguments asserts invalidArgumentsError.
+func IsInvalidArguments(err error) bool {
+	return errgo.Cause(err) == invalidArgumentsError
+}
+
 var invalidConfigError = errgo.New("invalid config")
 
 // IsInvalidConfig asserts invalidConfigError.
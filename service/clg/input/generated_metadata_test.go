@@ -2,17 +2,41 @@ package input
 
 // This file is generated by the CLG generator. Don't edit it manually. The CLG
 // generator is invoked by go generate. For more information about the usage of
-// the CLG generator check https://github.com/xh3b4sd/clggen or have a look at
-// the clg package. There is the go generate statement placed to invoke clggen.
+// the CLG generator check the clggen directory of this repository or have a
+// look at the clg package. There is the go generate statement placed to invoke
+// clggen.
 
 import (
+	"strconv"
 	"testing"
+
+	servicecollection "github.com/the-anna-project/annad/collection/collection"
+	"github.com/the-anna-project/annad/id"
+	"github.com/the-anna-project/annad/random"
 )
 
-func Test_CLG_GetMetadata(t *testing.T) {
-	newCLG := MustNew()
+func Test_CLG_Metadata(t *testing.T) {
+	newServiceCollection := servicecollection.New()
+	newServiceCollection.SetIDService(id.New())
+	newServiceCollection.SetRandomService(random.New())
+	newServiceCollection.ID().SetServiceCollection(newServiceCollection)
+	newServiceCollection.Random().SetServiceCollection(newServiceCollection)
+
+	newCLG := New()
+	newCLG.SetServiceCollection(newServiceCollection)
+	newCLG.Boot()
 
-	if len(newCLG.GetMetadata()) == 0 {
-		t.Fatal("expected", "metadata", "got", "nothing")
+	metadata := newCLG.Metadata()
+	if metadata["id"] == "" {
+		t.Fatal("expected", "id", "got", "nothing")
+	}
+	if metadata["kind"] != "input" {
+		t.Fatal("expected", "input", "got", metadata["kind"])
+	}
+	if metadata["name"] != "clg" {
+		t.Fatal("expected", "clg", "got", metadata["name"])
+	}
+	if metadata["pure"] != strconv.FormatBool(pure) {
+		t.Fatal("expected", strconv.FormatBool(pure), "got", metadata["pure"])
 	}
 }
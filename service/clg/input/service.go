@@ -5,10 +5,14 @@ package input
 import (
 	"fmt"
 
-	objectspec "github.com/the-anna-project/spec/object"
-	storagecollection "github.com/the-anna-project/storage/collection"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	storagecollection "github.com/the-anna-project/annad/storage/collection"
 )
 
+// pure marks the input CLG as impure, because it reads and writes information
+// IDs using the storage and modifies the given context.
+const pure = false
+
 // calculate fetches the information ID associated with the given information
 // sequence. In case the information sequence is not found within the underlying
 // storage, a new information ID is generated and used to store the given
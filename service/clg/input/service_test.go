@@ -2,147 +2,125 @@ package input
 
 import (
 	"fmt"
-	"reflect"
 	"testing"
 
-	redigo "github.com/garyburd/redigo/redis"
-	"github.com/rafaeljusto/redigomock"
+	kitlog "github.com/go-kit/kit/log"
 
+	servicecollection "github.com/the-anna-project/annad/collection/collection"
+	"github.com/the-anna-project/annad/id"
+	memoryinstrumentor "github.com/the-anna-project/annad/instrumentor/memory"
+	"github.com/the-anna-project/annad/log"
 	"github.com/the-anna-project/annad/object/context"
-	"github.com/the-anna-project/annad/object/networkpayload"
-	objectspec "github.com/the-anna-project/spec/object"
-	servicespec "github.com/the-anna-project/spec/service"
-	storagecollection "github.com/the-anna-project/storage/collection"
-	redisstorage "github.com/the-anna-project/storage/service/redis"
+	"github.com/the-anna-project/annad/random"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+	storagecollection "github.com/the-anna-project/annad/storage/collection"
+	memorystorage "github.com/the-anna-project/annad/storage/service/memory"
 )
 
-type testErrorIDService struct{}
+type testErrorIDService struct {
+	servicespec.IDService
+}
 
 // New is only a test implementation of servicespec.IDService to do nothing but
 // returning some error we can check against.
-func (f *testErrorIDService) New() (string, error) {
+func (s *testErrorIDService) New() (string, error) {
 	return "", maskAny(invalidConfigError)
 }
 
-func (f *testErrorIDService) WithType(idType servicespec.IDType) (string, error) {
-	return "", nil
+type testIDService struct {
+	servicespec.IDService
 }
 
-type testIDService struct{}
-
 // New is only a test implementation of servicespec.IDService to do nothing but
-// returning some error we can check against.
-func (f *testIDService) New() (string, error) {
+// returning the same ID we can check against.
+func (s *testIDService) New() (string, error) {
 	return "new-ID", nil
 }
 
-func (f *testIDService) WithType(idType servicespec.IDType) (string, error) {
-	return "", nil
-}
-
-type testServiceCollection struct {
-	IDService servicespec.ID
-}
-
-func (c *testServiceCollection) FS() servicespec.FSService {
-	return nil
-}
-
-func (c *testServiceCollection) ID() servicespec.IDService {
-	return c.IDService
-}
-
-func (c *testServiceCollection) Permutation() servicespec.PermutationService {
-	return nil
-}
-
-func (c *testServiceCollection) Random() servicespec.RandomService {
-	return nil
-}
-
-func (c *testServiceCollection) Shutdown() {
-}
-
-func (c *testServiceCollection) TextInput() servicespec.TextInputService {
-	return nil
-}
+type testErrorStorageService struct {
+	servicespec.StorageService
 
-func (c *testServiceCollection) TextOutput() servicespec.TextOutputService {
-	return nil
+	// GetErrorKey is the key for which Get returns an error.
+	GetErrorKey string
+	// SetErrorKey is the key for which Set returns an error.
+	SetErrorKey string
 }
 
-func testMustNewErrorServiceCollection(t *testing.T) servicespec.ServiceCollection {
-	return &testServiceCollection{
-		IDService: &testErrorIDService{},
+// Get is only a test implementation of servicespec.StorageService to return
+// some error we can check against when GetErrorKey is requested.
+func (s *testErrorStorageService) Get(key string) (string, error) {
+	if key == s.GetErrorKey {
+		return "", maskAny(invalidConfigError)
 	}
-}
 
-func testMustNewServiceCollection(t *testing.T) servicespec.ServiceCollection {
-	return &testServiceCollection{
-		IDService: &testIDService{},
-	}
+	return s.StorageService.Get(key)
 }
 
-func testMustNewStorageCollection(t *testing.T) servicespec.StorageCollection {
-	storageCollection := storagecollection.New()
+// Set is only a test implementation of servicespec.StorageService to return
+// some error we can check against when SetErrorKey is requested.
+func (s *testErrorStorageService) Set(key, value string) error {
+	if key == s.SetErrorKey {
+		return maskAny(invalidConfigError)
+	}
 
-	return storageCollection
+	return s.StorageService.Set(key, value)
 }
 
-func testMustNewStorageCollectionWithConn(t *testing.T, c redigo.Conn) servicespec.StorageCollection {
-	newStorageService := func() servicespec.StorageService {
-		storageService := redisstorage.New()
-		newPoolConfig := redisstorage.DefaultPoolConfig()
-		newMockDialConfig := redisstorage.DefaultMockDialConfig()
-		newMockDialConfig.RedisConn = c
-		newPoolConfig.Dial = redisstorage.NewMockDial(newMockDialConfig)
-		newPool := redisstorage.NewPool(newPoolConfig)
-		storageService.SetPool(newPool)
-
-		return storageService
+// testMustNew creates a new input CLG service using the given ID service. The
+// general storage is an in-memory storage, which is wrapped by the given error
+// storage service, if any. The returned function shuts down the storage.
+func testMustNew(t *testing.T, idService servicespec.IDService, errorStorageService *testErrorStorageService) (*service, func()) {
+	newLogService := log.New()
+	newLogService.SetRootLogger(kitlog.NewNopLogger())
+
+	newServiceCollection := servicecollection.New()
+	newServiceCollection.SetIDService(id.New())
+	newServiceCollection.SetInstrumentorService(memoryinstrumentor.New())
+	newServiceCollection.SetLogService(newLogService)
+	newServiceCollection.SetRandomService(random.New())
+
+	newServiceCollection.ID().SetServiceCollection(newServiceCollection)
+	newServiceCollection.Log().SetServiceCollection(newServiceCollection)
+	newServiceCollection.Random().SetServiceCollection(newServiceCollection)
+
+	newStorageService := memorystorage.New()
+	newStorageService.SetServiceCollection(newServiceCollection)
+	newStorageService.Boot()
+
+	newStorageCollection := storagecollection.New()
+	if errorStorageService != nil {
+		errorStorageService.StorageService = newStorageService
+		newStorageCollection.SetGeneralService(errorStorageService)
+	} else {
+		newStorageCollection.SetGeneralService(newStorageService)
 	}
+	newServiceCollection.SetStorageCollection(newStorageCollection)
 
-	storageCollection := storagecollection.New()
-	storageCollection.SetConnection(newStorageService())
-	storageCollection.SetFeatureStorage(newStorageService())
-	storageCollection.SetGeneralStorage(newStorageService())
+	// The storage is booted using a real ID service. The CLG uses the given one.
+	newServiceCollection.SetIDService(idService)
 
-	return newStorageCollection
-}
+	newCLG := New()
+	newCLG.SetServiceCollection(newServiceCollection)
 
-func testMustNew(t *testing.T, ctx objectspec.Context, input string) objectspec.NetworkPayload {
-	newNetworkPayloadConfig := networkpayload.DefaultConfig()
-	newNetworkPayloadConfig.Args = []reflect.Value{reflect.ValueOf(input)}
-	newNetworkPayloadConfig.Context = ctx
-	newNetworkPayloadConfig.Destination = "destination"
-	newNetworkPayloadConfig.Sources = []string{"source"}
-	newNetworkPayload, err := networkpayload.New(newNetworkPayloadConfig)
-	if err != nil {
-		t.Fatal("expected", nil, "got", err)
-	}
-
-	return newNetworkPayload
+	return newCLG.(*service), newStorageService.Shutdown
 }
 
 func Test_CLG_Input_KnownInputSequence(t *testing.T) {
-	newCLG := MustNew()
+	newCLG, shutdown := testMustNew(t, &testIDService{}, nil)
+	defer shutdown()
 	newCtx := context.MustNew()
-	newStorageCollection := testMustNewStorageCollection(t)
 
 	// Create record for the test input.
 	informationID := "123"
 	newInput := "test input"
 	informationIDKey := fmt.Sprintf("information-sequence:%s:information-id", newInput)
-	err := newStorageCollection.General().Set(informationIDKey, informationID)
+	err := newCLG.Service().Storage().General().Set(informationIDKey, informationID)
 	if err != nil {
 		t.Fatal("expected", nil, "got", err)
 	}
 
-	// Set prepared storage to CLG we want to test.
-	newCLG.(*clg).StorageCollection = newStorageCollection
-
 	// Execute CLG.
-	err = newCLG.(*clg).calculate(newCtx, newInput)
+	err = newCLG.calculate(newCtx, newInput)
 	if err != nil {
 		t.Fatal("expected", nil, "got", err)
 	}
@@ -155,21 +133,16 @@ func Test_CLG_Input_KnownInputSequence(t *testing.T) {
 }
 
 func Test_CLG_Input_UnknownInputSequence(t *testing.T) {
-	newCLG := MustNew()
+	newCLG, shutdown := testMustNew(t, &testIDService{}, nil)
+	defer shutdown()
 	newCtx := context.MustNew()
-	newServiceCollection := testMustNewServiceCollection(t)
-	newStorageCollection := testMustNewStorageCollection(t)
 
 	// Note we do not create a record for the test input. This test is about an
 	// unknown input sequence.
 	newInput := "test input"
 
-	// Set prepared storage to CLG we want to test.
-	newCLG.(*clg).ServiceCollection = newServiceCollection
-	newCLG.(*clg).StorageCollection = newStorageCollection
-
 	// Execute CLG.
-	err := newCLG.(*clg).calculate(newCtx, newInput)
+	err := newCLG.calculate(newCtx, newInput)
 	if err != nil {
 		t.Fatal("expected", nil, "got", err)
 	}
@@ -177,47 +150,42 @@ func Test_CLG_Input_UnknownInputSequence(t *testing.T) {
 	// Check if the information ID was set to the context.
 	injectedInformationID, _ := newCtx.GetInformationID()
 	if injectedInformationID != "new-ID" {
-		t.Fatal("expected", true, "got", false)
+		t.Fatal("expected", "new-ID", "got", injectedInformationID)
 	}
 }
 
 func Test_CLG_Input_DataProperlyStored(t *testing.T) {
-	newCLG := MustNew()
+	newCLG, shutdown := testMustNew(t, &testIDService{}, nil)
+	defer shutdown()
 	newCtx := context.MustNew()
-	newStorageCollection := testMustNewStorageCollection(t)
-	newServiceCollection := testMustNewServiceCollection(t)
 
 	// Note we do not create a record for the test input. This test is about an
 	// unknown input sequence.
 	newInput := "test input"
-	// Our test ID factory always returns the same ID. That way we are able to
+	// Our test ID service always returns the same ID. That way we are able to
 	// check for the ID being used during the test.
-	newID, err := newServiceCollection.ID().New()
+	newID, err := newCLG.Service().ID().New()
 	if err != nil {
 		t.Fatal("expected", nil, "got", err)
 	}
 
-	// Set prepared storage to CLG we want to test.
-	newCLG.(*clg).ServiceCollection = newServiceCollection
-	newCLG.(*clg).StorageCollection = newStorageCollection
-
 	// Execute CLG.
-	err = newCLG.(*clg).calculate(newCtx, newInput)
+	err = newCLG.calculate(newCtx, newInput)
 	if err != nil {
 		t.Fatal("expected", nil, "got", err)
 	}
 
 	informationIDKey := fmt.Sprintf("information-sequence:%s:information-id", newInput)
-	storedID, err := newStorageCollection.General().Get(informationIDKey)
+	storedID, err := newCLG.Service().Storage().General().Get(informationIDKey)
 	if err != nil {
 		t.Fatal("expected", nil, "got", err)
 	}
-	if storedID != string(newID) {
+	if storedID != newID {
 		t.Fatal("expected", newID, "got", storedID)
 	}
 
 	informationSequenceKey := fmt.Sprintf("information-id:%s:information-sequence", newID)
-	storedInput, err := newStorageCollection.General().Get(informationSequenceKey)
+	storedInput, err := newCLG.Service().Storage().General().Get(informationSequenceKey)
 	if err != nil {
 		t.Fatal("expected", nil, "got", err)
 	}
@@ -227,107 +195,62 @@ func Test_CLG_Input_DataProperlyStored(t *testing.T) {
 }
 
 func Test_CLG_Input_IDServiceError(t *testing.T) {
-	newCLG := MustNew()
+	newCLG, shutdown := testMustNew(t, &testErrorIDService{}, nil)
+	defer shutdown()
 	newCtx := context.MustNew()
-	newStorageCollection := testMustNewStorageCollection(t)
-	newServiceCollection := testMustNewErrorServiceCollection(t)
 
 	// Note we do not create a record for the test input. This test is about an
 	// unknown input sequence.
 	newInput := "test input"
 
-	// Set prepared storage to CLG we want to test.
-	newCLG.(*clg).ServiceCollection = newServiceCollection
-	newCLG.(*clg).StorageCollection = newStorageCollection
-
 	// Execute CLG.
-	err := newCLG.(*clg).calculate(newCtx, newInput)
+	err := newCLG.calculate(newCtx, newInput)
 	if !IsInvalidConfig(err) {
 		t.Fatal("expected", true, "got", false)
 	}
 }
 
 func Test_CLG_Input_SetInformationIDError(t *testing.T) {
-	newCLG := MustNew()
-	newCtx := context.MustNew()
-	newServiceCollection := testMustNewServiceCollection(t)
-
-	// Prepare the storage connection to fake a returned error.
+	// Prepare the storage to fake a returned error.
 	newInput := "test input"
 	informationIDKey := fmt.Sprintf("information-sequence:%s:information-id", newInput)
-	// Our test ID factory always returns the same ID. That way we are able to
-	// check for the ID being used during the test.
-	newID, err := newServiceCollection.ID().New()
-	if err != nil {
-		t.Fatal("expected", nil, "got", err)
-	}
-
-	c := redigomock.NewConn()
-	c.Command("GET", "prefix:"+informationIDKey).ExpectError(redigo.ErrNil)
-	c.Command("SET", "prefix:"+informationIDKey, string(newID)).ExpectError(invalidConfigError)
-	newStorageCollection := testMustNewStorageCollectionWithConn(t, c)
-
-	// Set prepared storage to CLG we want to test.
-	newCLG.(*clg).StorageCollection = newStorageCollection
-	newCLG.(*clg).ServiceCollection = newServiceCollection
+	newCLG, shutdown := testMustNew(t, &testIDService{}, &testErrorStorageService{SetErrorKey: informationIDKey})
+	defer shutdown()
+	newCtx := context.MustNew()
 
 	// Execute CLG.
-	err = newCLG.(*clg).calculate(newCtx, newInput)
+	err := newCLG.calculate(newCtx, newInput)
 	if !IsInvalidConfig(err) {
 		t.Fatal("expected", true, "got", false)
 	}
 }
 
 func Test_CLG_Input_SetInformationSequenceError(t *testing.T) {
-	newCLG := MustNew()
-	newCtx := context.MustNew()
-	newServiceCollection := testMustNewServiceCollection(t)
-
-	// Prepare the storage connection to fake a returned error.
+	// Prepare the storage to fake a returned error. Our test ID service always
+	// returns the same ID. That way we know the key being used during the test.
 	newInput := "test input"
-	informationIDKey := fmt.Sprintf("information-sequence:%s:information-id", newInput)
-	// Our test ID factory always returns the same ID. That way we are able to
-	// check for the ID being used during the test.
-	newID, err := newServiceCollection.ID().New()
-	if err != nil {
-		t.Fatal("expected", nil, "got", err)
-	}
-	informationSequenceKey := fmt.Sprintf("information-id:%s:information-sequence", newID)
-
-	c := redigomock.NewConn()
-	c.Command("GET", "prefix:"+informationIDKey).ExpectError(redigo.ErrNil)
-	c.Command("SET", "prefix:"+informationIDKey, string(newID)).Expect("OK")
-	c.Command("SET", "prefix:"+informationSequenceKey, newInput).ExpectError(invalidConfigError)
-	newStorageCollection := testMustNewStorageCollectionWithConn(t, c)
-
-	// Set prepared storage to CLG we want to test.
-	newCLG.(*clg).StorageCollection = newStorageCollection
-	newCLG.(*clg).ServiceCollection = newServiceCollection
+	informationSequenceKey := fmt.Sprintf("information-id:%s:information-sequence", "new-ID")
+	newCLG, shutdown := testMustNew(t, &testIDService{}, &testErrorStorageService{SetErrorKey: informationSequenceKey})
+	defer shutdown()
+	newCtx := context.MustNew()
 
 	// Execute CLG.
-	err = newCLG.(*clg).calculate(newCtx, newInput)
+	err := newCLG.calculate(newCtx, newInput)
 	if !IsInvalidConfig(err) {
 		t.Fatal("expected", true, "got", false)
 	}
 }
 
 func Test_CLG_Input_GetInformationIDError(t *testing.T) {
-	newCLG := MustNew()
-	newCtx := context.MustNew()
-
+	// Prepare the storage to fake a returned error.
 	newInput := "test input"
 	informationIDKey := fmt.Sprintf("information-sequence:%s:information-id", newInput)
-
-	// Prepare the storage connection to fake a returned error.
-	c := redigomock.NewConn()
-	c.Command("GET", "prefix:"+informationIDKey).ExpectError(invalidConfigError)
-	newStorageCollection := testMustNewStorageCollectionWithConn(t, c)
-
-	// Set prepared storage to CLG we want to test.
-	newCLG.SetStorageCollection(newStorageCollection)
+	newCLG, shutdown := testMustNew(t, &testIDService{}, &testErrorStorageService{GetErrorKey: informationIDKey})
+	defer shutdown()
+	newCtx := context.MustNew()
 
 	// Execute CLG.
-	err := newCLG.(*clg).calculate(newCtx, newInput)
+	err := newCLG.calculate(newCtx, newInput)
 	if !IsInvalidConfig(err) {
 		t.Fatal("expected", true, "got", false)
 	}
@@ -2,11 +2,14 @@ package isbetween
 
 // This file is generated by the CLG generator. Don't edit it manually. The CLG
 // generator is invoked by go generate. For more information about the usage of
-// the CLG generator check https://github.com/xh3b4sd/clggen or have a look at
-// the clg package. There is the go generate statement placed to invoke clggen.
+// the CLG generator check the clggen directory of this repository or have a
+// look at the clg package. There is the go generate statement placed to invoke
+// clggen.
 
 import (
-	servicespec "github.com/the-anna-project/spec/service"
+	"strconv"
+
+	servicespec "github.com/the-anna-project/annad/spec/service"
 )
 
 // New creates a new isbetween CLG service.
@@ -33,6 +36,7 @@ func (s *service) Boot() {
 		"id":   id,
 		"kind": "isbetween",
 		"name": "clg",
+		"pure": strconv.FormatBool(pure),
 		"type": "service",
 	}
 }
@@ -3,9 +3,13 @@
 package isbetween
 
 import (
-	objectspec "github.com/the-anna-project/spec/object"
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
+// pure marks the isbetween CLG as pure. Whether the number is within the range
+// only depends on the given arguments, so the network memoizes the results.
+const pure = true
+
 // calculate checks whether a given number lies between two given numbers.
 func (s *service) calculate(ctx objectspec.Context, n, min, max float64) bool {
 	if n < min {
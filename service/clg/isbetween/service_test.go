@@ -93,10 +93,10 @@ func Test_CLG_IsBetween(t *testing.T) {
 		},
 	}
 
-	newCLG := MustNew()
+	newCLG := New()
 
 	for i, testCase := range testCases {
-		b := newCLG.(*clg).calculate(context.MustNew(), testCase.N, testCase.Min, testCase.Max)
+		b := newCLG.(*service).calculate(context.MustNew(), testCase.N, testCase.Min, testCase.Max)
 		if b != testCase.Expected {
 			t.Fatal("case", i+1, "expected", testCase.Expected, "got", b)
 		}
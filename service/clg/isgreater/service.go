@@ -3,9 +3,13 @@
 package isgreater
 
 import (
-	objectspec "github.com/the-anna-project/spec/object"
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
+// pure marks the isgreater CLG as pure. Whether the first number is greater
+// only depends on the given arguments, so the network memoizes the results.
+const pure = true
+
 // calculate checks whether the first given number is greater than the other.
 func (s *service) calculate(ctx objectspec.Context, a, b float64) bool {
 	return a > b
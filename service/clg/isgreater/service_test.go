@@ -49,10 +49,10 @@ func Test_CLG_IsGreater(t *testing.T) {
 		},
 	}
 
-	newCLG := MustNew()
+	newCLG := New()
 
 	for i, testCase := range testCases {
-		b := newCLG.(*clg).calculate(context.MustNew(), testCase.A, testCase.B)
+		b := newCLG.(*service).calculate(context.MustNew(), testCase.A, testCase.B)
 		if b != testCase.Expected {
 			t.Fatal("case", i+1, "expected", testCase.Expected, "got", b)
 		}
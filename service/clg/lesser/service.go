@@ -3,9 +3,13 @@
 package lesser
 
 import (
-	objectspec "github.com/the-anna-project/spec/object"
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
+// pure marks the lesser CLG as pure. The lesser number only depends on the
+// given arguments, so the network memoizes the results.
+const pure = true
+
 // calculate returns the number that is lesser than the other.
 func (s *service) calculate(ctx objectspec.Context, a, b float64) float64 {
 	if a < b {
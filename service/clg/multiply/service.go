@@ -3,9 +3,13 @@
 package multiply
 
 import (
-	objectspec "github.com/the-anna-project/spec/object"
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
+// pure marks the multiply CLG as pure. The product only depends on the given
+// arguments, so the network memoizes the results.
+const pure = true
+
 // calculate creates the product of the given float64s.
 func (s *service) calculate(ctx objectspec.Context, a, b float64) float64 {
 	return a * b
@@ -44,10 +44,10 @@ func Test_CLG_Multiply(t *testing.T) {
 		},
 	}
 
-	newCLG := MustNew()
+	newCLG := New()
 
 	for i, testCase := range testCases {
-		f := newCLG.(*clg).calculate(context.MustNew(), testCase.A, testCase.B)
+		f := newCLG.(*service).calculate(context.MustNew(), testCase.A, testCase.B)
 		if f != testCase.Expected {
 			t.Fatal("case", i+1, "expected", testCase.Expected, "got", f)
 		}
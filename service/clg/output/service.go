@@ -3,43 +3,53 @@
 // output matches the provided expectation, if any expectation given. The
 // output CLG is handled in a special way because it determines the end of all
 // requested calculations within the neural network. After the output CLG has
-// been executed, the calculated output is returned back to the requesting
-// client.
+// been executed successfully, the network gathers the calculated output as
+// candidate of the CLG tree, which is returned back to the requesting client
+// together with the other candidates of the CLG tree.
 package output
 
 import (
-	"encoding/json"
 	"fmt"
 	"reflect"
 
 	"github.com/the-anna-project/annad/object/networkpayload"
-	textoutputobject "github.com/the-anna-project/output/object/text"
-	objectspec "github.com/the-anna-project/spec/object"
+	"github.com/the-anna-project/annad/service/matcher"
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
+// pure marks the output CLG as impure, because it records matches and retries
+// the CLG tree.
+const pure = false
+
 // TODO there is no CLG to read from the certenty pyramid
 
 func (s *service) forwardNetworkPayload(ctx objectspec.Context, informationSequence string) error {
 	// Find the original information sequence using the information ID from the
-	// context.
-	informationID, ok := ctx.GetInformationID()
-	if !ok {
-		return maskAnyf(invalidInformationIDError, "must not be empty")
-	}
-	informationSequenceKey := fmt.Sprintf("information-id:%s:information-sequence", informationID)
-	informationSequence, err := s.Service().Storage().General().Get(informationSequenceKey)
-	if err != nil {
-		return maskAny(err)
+	// context. Echo requests bypass the input CLG and thus do not carry an
+	// information ID. Then the given information sequence is the original one.
+	if informationID, ok := ctx.GetInformationID(); ok {
+		informationSequenceKey := fmt.Sprintf("information-id:%s:information-sequence", informationID)
+		var err error
+		informationSequence, err = s.Service().Storage().General().Get(informationSequenceKey)
+		if err != nil {
+			return maskAny(err)
+		}
 	}
 
 	// Find the first behaviour ID using the CLG tree ID from the context. The
-	// behaviour ID we are looking up here is the ID of the initial input CLG.
+	// behaviour ID we are looking up here is the ID of the CLG the CLG tree
+	// started with. That is usually the input CLG, or the output CLG in case of
+	// echo requests.
 	clgTreeID, ok := ctx.GetCLGTreeID()
 	if !ok {
 		return maskAnyf(invalidCLGTreeIDError, "must not be empty")
 	}
 	firstBehaviourIDKey := fmt.Sprintf("clg-tree-id:%s:first-behaviour-id", clgTreeID)
-	inputBehaviourID, err := s.Service().Storage().General().Get(firstBehaviourIDKey)
+	firstBehaviourID, err := s.Service().Storage().General().Get(firstBehaviourIDKey)
+	if err != nil {
+		return maskAny(err)
+	}
+	behaviourMetadata, err := s.Service().Behaviour().Search(firstBehaviourID)
 	if err != nil {
 		return maskAny(err)
 	}
@@ -54,30 +64,42 @@ func (s *service) forwardNetworkPayload(ctx objectspec.Context, informationSeque
 	// Create a new contect using the given context and adapt the new context with
 	// the information of the current scope.
 	newCtx := ctx.Clone()
-	newCtx.SetBehaviourID(inputBehaviourID)
-	newCtx.SetCLGName("input")
+	newCtx.SetBehaviourID(firstBehaviourID)
+	newCtx.SetCLGName(behaviourMetadata["clg-kind"])
 	newCtx.SetCLGTreeID(clgTreeID)
 	// We do not need to set the expectation because it never changes.
 	// We do not need to set the session ID because it never changes.
 
+	// The new network payload re-enters the CLG tree. It comes from the current
+	// output CLG and enters the behaviour the CLG tree started with. The CLG tree
+	// already passed both, which is what the path records. The network permits
+	// entering the first behaviour again because the output CLG is its only
+	// source, which makes this a re-entry edge, and starts the path over.
+	path := []string{firstBehaviourID}
+	if outputBehaviourID != firstBehaviourID {
+		path = append(path, outputBehaviourID)
+	}
+
 	// Create a new network payload.
+	networkPayloadID, err := s.Service().ID().New()
+	if err != nil {
+		return maskAny(err)
+	}
 	newNetworkPayloadConfig := networkpayload.DefaultConfig()
 	newNetworkPayloadConfig.Args = []reflect.Value{reflect.ValueOf(informationSequence)}
 	newNetworkPayloadConfig.Context = newCtx
-	newNetworkPayloadConfig.Destination = string(inputBehaviourID)
+	newNetworkPayloadConfig.Destination = string(firstBehaviourID)
+	newNetworkPayloadConfig.ID = networkPayloadID
+	newNetworkPayloadConfig.Path = path
 	newNetworkPayloadConfig.Sources = []string{string(outputBehaviourID)}
 	newNetworkPayload, err := networkpayload.New(newNetworkPayloadConfig)
 	if err != nil {
 		return maskAny(err)
 	}
 
-	// Write the transformed network payload to the queue.
-	networkPayloadKey := fmt.Sprintf("events:network-payload")
-	b, err := json.Marshal(newNetworkPayload)
-	if err != nil {
-		return maskAny(err)
-	}
-	err = s.Service().Storage().General().PushToList(networkPayloadKey, string(b))
+	// Retry the CLG tree using the new network payload. The network delays and
+	// limits the retries of each CLG tree.
+	err = s.Service().Network().Retry(newNetworkPayload)
 	if err != nil {
 		return maskAny(err)
 	}
@@ -91,46 +113,55 @@ func (s *service) calculate(ctx objectspec.Context, informationSequence string)
 	// calculated. This then means we are probably not in a training situation.
 	expectation, ok := ctx.GetExpectation()
 	if !ok {
-		err := s.sendTextOutput(ctx, informationSequence)
-		if err != nil {
-			return maskAny(err)
-		}
-
 		return nil
 	}
 
 	// There is an expectation provided. Thus we are going to check the calculated
-	// output against it. In case the provided expectation does match the
-	// calculated result, we simply return it.
-	calculatedOutput := expectation.GetOutput()
-	if informationSequence == calculatedOutput {
-		err := s.sendTextOutput(ctx, informationSequence)
-		if err != nil {
-			return maskAny(err)
-		}
+	// output against it, using the matcher the expectation names. The score of
+	// the match is recorded for the CLG tree, so the outputs coming closest to
+	// the expectation are known even if none of them meets it.
+	result, err := matcher.Match(expectation, informationSequence)
+	if err != nil {
+		return maskAny(err)
+	}
+	err = s.recordMatch(ctx, informationSequence, result)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	// In case the calculated output meets the provided expectation, we simply
+	// return it. The network ends the CLG tree as soon as the output CLG
+	// succeeded, because there is nothing left to calculate.
+	if result.Matched {
+		return nil
 	}
 
 	// The calculated output did not match the given expectation. That means we
 	// need to calculate some new output to match the given expectation. To do so
 	// we create a new network payload and assign the input CLG of the current CLG
 	// tree to it by queueing the new network payload in the underlying storage.
-	err := s.forwardNetworkPayload(ctx, informationSequence)
+	err = s.forwardNetworkPayload(ctx, informationSequence)
 	if err != nil {
 		return maskAny(err)
 	}
 
 	// The calculated output did not match the given expectation. We return an
 	// error to let the neural network know about it.
-	return maskAnyf(expectationNotMetError, "'%s' != '%s'", informationSequence, calculatedOutput)
+	return maskAnyf(expectationNotMetError, "'%s' != '%s' (score %f)", informationSequence, expectation.GetOutput(), result.Score)
 }
 
-func (s *service) sendTextOutput(ctx objectspec.Context, informationSequence string) error {
-	// Return the calculated output to the requesting client, if the
-	// current CLG is the output CLG.
-	textOutputObject := textoutputobject.New()
-	textOutputObject.SetOutput(informationSequence)
-
-	s.Service().Output().Text().Channel() <- textOutputObject
+// recordMatch writes the given calculated output to the scored set of matches
+// of the current CLG tree, scored by the given match result.
+func (s *service) recordMatch(ctx objectspec.Context, informationSequence string, result matcher.Result) error {
+	clgTreeID, ok := ctx.GetCLGTreeID()
+	if !ok {
+		return maskAnyf(invalidCLGTreeIDError, "must not be empty")
+	}
+	matchesKey := fmt.Sprintf("clg-tree-id:%s:matches", clgTreeID)
+	err := s.Service().Storage().General().SetElementByScore(matchesKey, informationSequence, result.Score)
+	if err != nil {
+		return maskAny(err)
+	}
 
 	return nil
 }
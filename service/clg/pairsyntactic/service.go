@@ -6,10 +6,14 @@ import (
 	"fmt"
 	"strings"
 
-	objectspec "github.com/the-anna-project/spec/object"
-	storagecollection "github.com/the-anna-project/storage/collection"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	storagecollection "github.com/the-anna-project/annad/storage/collection"
 )
 
+// pure marks the pairsyntactic CLG as impure, because it pairs randomly chosen
+// features and stores the pairs using the storage.
+const pure = false
+
 // TODO there is nothing that reads pairs
 func (s *service) calculate(ctx objectspec.Context) error {
 	// The counter keeps track of the work already being done. We only increment
@@ -5,9 +5,13 @@ package readinformationid
 import (
 	"fmt"
 
-	objectspec "github.com/the-anna-project/spec/object"
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
+// pure marks the readinformationid CLG as impure, because it reads information
+// sequences using the storage.
+const pure = false
+
 // calculate fetches the information sequence stored under a specific
 // information ID. The information ID is provided by the given context.
 func (s *service) calculate(ctx objectspec.Context) (string, error) {
@@ -6,10 +6,14 @@ import (
 	"fmt"
 	"strings"
 
-	objectspec "github.com/the-anna-project/spec/object"
-	storagecollection "github.com/the-anna-project/storage/collection"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	storagecollection "github.com/the-anna-project/annad/storage/collection"
 )
 
+// pure marks the readseparator CLG as impure, because it chooses a random
+// separator and stores it using the storage.
+const pure = false
+
 func (s *service) calculate(ctx objectspec.Context) (string, error) {
 	behaviourID, ok := ctx.GetBehaviourID()
 	if !ok {
@@ -6,9 +6,13 @@ import (
 	"fmt"
 	"strconv"
 
-	objectspec "github.com/the-anna-project/spec/object"
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
+// pure marks the round CLG as pure. The rounded number only depends on the
+// given arguments, so the network memoizes the results.
+const pure = true
+
 func (s *service) calculate(ctx objectspec.Context, f float64, p int) (float64, error) {
 	rounded, err := strconv.ParseFloat(fmt.Sprintf(fmt.Sprintf("%%.%df", p), f), 64)
 	if err != nil {
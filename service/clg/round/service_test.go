@@ -54,10 +54,10 @@ func Test_CLG_Round(t *testing.T) {
 		},
 	}
 
-	newCLG := MustNew()
+	newCLG := New()
 
 	for i, testCase := range testCases {
-		f, err := newCLG.(*clg).calculate(context.MustNew(), testCase.Float, testCase.Precision)
+		f, err := newCLG.(*service).calculate(context.MustNew(), testCase.Float, testCase.Precision)
 		if err != nil {
 			t.Fatal("case", i+1, "expected", nil, "got", err)
 		}
@@ -68,8 +68,8 @@ func Test_CLG_Round(t *testing.T) {
 }
 
 func Test_CLG_Round_Error_NegativePrecision(t *testing.T) {
-	newCLG := MustNew()
-	_, err := newCLG.(*clg).calculate(context.MustNew(), 3.4465, -3)
+	newCLG := New()
+	_, err := newCLG.(*service).calculate(context.MustNew(), 3.4465, -3)
 	if !IsParseFloatSyntax(err) {
 		t.Fatal("case", "expected", true, "got", false)
 	}
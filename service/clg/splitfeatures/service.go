@@ -21,9 +21,13 @@ import (
 	"encoding/json"
 	"fmt"
 
-	objectspec "github.com/the-anna-project/spec/object"
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
+// pure marks the splitfeatures CLG as impure, because it registers features
+// using the feature service.
+const pure = false
+
 const (
 	// FeatureSize represents the number of characters a feature consists of. E.g.
 	// a FeatureSize of 4 results in features being registered which are 4
@@ -3,9 +3,13 @@
 package sum
 
 import (
-	objectspec "github.com/the-anna-project/spec/object"
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
+// pure marks the sum CLG as pure. The sum only depends on the given arguments,
+// so the network memoizes the results.
+const pure = true
+
 // calculate creates the sum of the given float64s.
 func (s *service) calculate(ctx objectspec.Context, a, b float64) float64 {
 	return a + b
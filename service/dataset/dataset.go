@@ -0,0 +1,232 @@
+// Package dataset implements running datasets of examples against the neural
+// network. An example is a text input together with the output expected for
+// it. Datasets are run against a network booted within the current process,
+// or against a running daemon. See Network.
+package dataset
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	expectationobject "github.com/the-anna-project/annad/input/object/expectation"
+	"github.com/the-anna-project/annad/service/matcher"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+)
+
+const (
+	// FormatCSV is the format of datasets having one example per line, made of
+	// the comma separated input, expectation and optional budget.
+	FormatCSV = "csv"
+	// FormatJSONL is the format of datasets having one JSON encoded example per
+	// line. See Example.
+	FormatJSONL = "jsonl"
+)
+
+// Example represents a single example of a dataset.
+type Example struct {
+	// Backoff is the delay before the neural network retries to meet the
+	// expectation the first time. In case it is 0, the backoff the neural
+	// network is configured with is used.
+	Backoff time.Duration `json:"-"`
+
+	// Budget is the duration the neural network is given to meet the
+	// expectation of the example. In case it is 0, the budget the runner is
+	// configured with is used.
+	Budget time.Duration `json:"-"`
+
+	// Echo makes the neural network respond with the input directly, without
+	// depending on any learned behaviour.
+	Echo bool `json:"echo,omitempty"`
+
+	// Expectation is the output the neural network is expected to calculate.
+	Expectation string `json:"expectation"`
+
+	// Input is the text input sent to the neural network.
+	Input string `json:"input"`
+
+	// Matcher is the name of the matcher checking the output against the
+	// expectation, e.g. regex. Empty means the output must equal the
+	// expectation. Outputs are the alternative expectations of the one-of
+	// matcher. Threshold is the tolerance of the numeric matcher, or the
+	// maximum edit distance of the edit-distance matcher.
+	Matcher   string   `json:"matcher,omitempty"`
+	Outputs   []string `json:"outputs,omitempty"`
+	Threshold float64  `json:"threshold,omitempty"`
+
+	// Retries is the maximum number of retries of the neural network to meet
+	// the expectation. In case it is 0, the limit the neural network is
+	// configured with is used.
+	Retries int `json:"retries,omitempty"`
+}
+
+// UnmarshalJSON decodes the given JSON encoded example. The budget is given as
+// duration string, e.g. 30s.
+func (e *Example) UnmarshalJSON(b []byte) error {
+	type ExampleClone Example
+
+	aux := &struct {
+		*ExampleClone
+		Backoff string `json:"backoff,omitempty"`
+		Budget  string `json:"budget,omitempty"`
+	}{
+		ExampleClone: (*ExampleClone)(e),
+	}
+	err := json.Unmarshal(b, &aux)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	e.Backoff, err = parseDuration(aux.Backoff)
+	if err != nil {
+		return maskAny(err)
+	}
+	e.Budget, err = parseDuration(aux.Budget)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
+
+// expectation returns the expectation of the example sent to the neural
+// network.
+func (e Example) expectation() objectspec.Expectation {
+	expectation := expectationobject.New(e.Expectation)
+	expectation.SetMatcher(e.Matcher)
+	expectation.SetOutputs(e.Outputs)
+	expectation.SetThreshold(e.Threshold)
+
+	return expectation
+}
+
+// Format returns the format of the dataset stored in the given file, based on
+// its extension. Files not ending with .csv are considered to be JSON Lines.
+func Format(file string) string {
+	if strings.ToLower(filepath.Ext(file)) == ".csv" {
+		return FormatCSV
+	}
+
+	return FormatJSONL
+}
+
+// Read reads all examples of the dataset provided by the given reader, which
+// must be encoded using the given format. Each example must provide an input
+// and an expectation.
+func Read(r io.Reader, format string) ([]Example, error) {
+	var examples []Example
+	var err error
+
+	switch format {
+	case FormatCSV:
+		examples, err = readCSV(r)
+	case FormatJSONL:
+		examples, err = readJSONL(r)
+	default:
+		return nil, maskAnyf(invalidFormatError, "'%s'", format)
+	}
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	for i, e := range examples {
+		if e.Input == "" {
+			return nil, maskAnyf(invalidExampleError, "example %d: input must not be empty", i+1)
+		}
+		if e.Expectation == "" {
+			return nil, maskAnyf(invalidExampleError, "example %d: expectation must not be empty", i+1)
+		}
+		if e.Backoff < 0 {
+			return nil, maskAnyf(invalidExampleError, "example %d: backoff must not be negative", i+1)
+		}
+		if e.Budget < 0 {
+			return nil, maskAnyf(invalidExampleError, "example %d: budget must not be negative", i+1)
+		}
+		if e.Retries < 0 {
+			return nil, maskAnyf(invalidExampleError, "example %d: retries must not be negative", i+1)
+		}
+		err := matcher.Validate(e.expectation())
+		if err != nil {
+			return nil, maskAnyf(invalidExampleError, "example %d: %s", i+1, err)
+		}
+	}
+
+	return examples, nil
+}
+
+// parseDuration parses the given duration of an example, e.g. its budget. An
+// empty duration is 0.
+func parseDuration(duration string) (time.Duration, error) {
+	if duration == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(duration)
+	if err != nil {
+		return 0, maskAny(err)
+	}
+
+	return d, nil
+}
+
+// readCSV reads examples having the columns input, expectation and budget.
+// The budget column is optional. A header line naming the columns is skipped.
+func readCSV(r io.Reader) ([]Example, error) {
+	var examples []Example
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, maskAnyf(invalidExampleError, "%s", err)
+		}
+
+		if line == 1 && len(record) >= 2 && record[0] == "input" && record[1] == "expectation" {
+			continue
+		}
+
+		if len(record) < 2 || len(record) > 3 {
+			return nil, maskAnyf(invalidExampleError, "example %d: expected 2 or 3 columns, got %d", len(examples)+1, len(record))
+		}
+		e := Example{
+			Expectation: record[1],
+			Input:       record[0],
+		}
+		if len(record) == 3 {
+			e.Budget, err = parseDuration(record[2])
+			if err != nil {
+				return nil, maskAnyf(invalidExampleError, "example %d: %s", len(examples)+1, err)
+			}
+		}
+
+		examples = append(examples, e)
+	}
+
+	return examples, nil
+}
+
+// readJSONL reads one JSON encoded example per line. Empty lines are skipped.
+func readJSONL(r io.Reader) ([]Example, error) {
+	var examples []Example
+
+	decoder := json.NewDecoder(r)
+	for {
+		var e Example
+		err := decoder.Decode(&e)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, maskAnyf(invalidExampleError, "example %d: %s", len(examples)+1, err)
+		}
+
+		examples = append(examples, e)
+	}
+
+	return examples, nil
+}
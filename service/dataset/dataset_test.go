@@ -0,0 +1,102 @@
+package dataset
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_Dataset_Read_CSV(t *testing.T) {
+	r := bytes.NewBufferString("input,expectation,budget\nhello,world,\n\"a, b\",c,5s\n")
+	examples, err := Read(r, FormatCSV)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	expected := []Example{
+		{Expectation: "world", Input: "hello"},
+		{Budget: 5 * time.Second, Expectation: "c", Input: "a, b"},
+	}
+	if len(examples) != len(expected) {
+		t.Fatal("expected", len(expected), "got", len(examples))
+	}
+	for i := range expected {
+		if !reflect.DeepEqual(examples[i], expected[i]) {
+			t.Fatal("expected", expected[i], "got", examples[i])
+		}
+	}
+}
+
+func Test_Dataset_Read_JSONL(t *testing.T) {
+	r := bytes.NewBufferString(`{"input":"hello","expectation":"world"}` + "\n\n" + `{"input":"a","expectation":"b","budget":"1m","echo":true}` + "\n" + `{"input":"hi","expectation":"hello","matcher":"one-of","outputs":["hey"]}` + "\n" + `{"input":"pi","expectation":"3.14","matcher":"numeric","threshold":0.01,"retries":3,"backoff":"5ms"}` + "\n")
+	examples, err := Read(r, FormatJSONL)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	expected := []Example{
+		{Expectation: "world", Input: "hello"},
+		{Budget: time.Minute, Echo: true, Expectation: "b", Input: "a"},
+		{Expectation: "hello", Input: "hi", Matcher: "one-of", Outputs: []string{"hey"}},
+		{Backoff: 5 * time.Millisecond, Expectation: "3.14", Input: "pi", Matcher: "numeric", Retries: 3, Threshold: 0.01},
+	}
+	if len(examples) != len(expected) {
+		t.Fatal("expected", len(expected), "got", len(examples))
+	}
+	for i := range expected {
+		if !reflect.DeepEqual(examples[i], expected[i]) {
+			t.Fatal("expected", expected[i], "got", examples[i])
+		}
+	}
+}
+
+func Test_Dataset_Read_Error(t *testing.T) {
+	testCases := []struct {
+		Dataset string
+		Format  string
+	}{
+		{Dataset: "hello\n", Format: FormatCSV},
+		{Dataset: "hello,world,soon\n", Format: FormatCSV},
+		{Dataset: "hello,world,-1s\n", Format: FormatCSV},
+		{Dataset: ",world\n", Format: FormatCSV},
+		{Dataset: `{"input":"hello"}`, Format: FormatJSONL},
+		{Dataset: `{"input":"hello","expectation":"world","budget":"soon"}`, Format: FormatJSONL},
+		{Dataset: `{"input":`, Format: FormatJSONL},
+		{Dataset: `{"input":"hello","expectation":"world","backoff":"-1s"}`, Format: FormatJSONL},
+		{Dataset: `{"input":"hello","expectation":"world","retries":-1}`, Format: FormatJSONL},
+		{Dataset: `{"input":"hello","expectation":"world","matcher":"unknown"}`, Format: FormatJSONL},
+		{Dataset: `{"input":"pi","expectation":"[0-9","matcher":"regex"}`, Format: FormatJSONL},
+	}
+
+	for i, testCase := range testCases {
+		_, err := Read(bytes.NewBufferString(testCase.Dataset), testCase.Format)
+		if !IsInvalidExample(err) {
+			t.Fatal("case", i+1, "expected", true, "got", err)
+		}
+	}
+
+	_, err := Read(bytes.NewBufferString(""), "xml")
+	if !IsInvalidFormat(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+func Test_Dataset_Format(t *testing.T) {
+	testCases := []struct {
+		File     string
+		Expected string
+	}{
+		{File: "dataset.csv", Expected: FormatCSV},
+		{File: "DATASET.CSV", Expected: FormatCSV},
+		{File: "dataset.jsonl", Expected: FormatJSONL},
+		{File: "dataset", Expected: FormatJSONL},
+	}
+
+	for i, testCase := range testCases {
+		output := Format(testCase.File)
+		if output != testCase.Expected {
+			t.Fatal("case", i+1, "expected", testCase.Expected, "got", output)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package dataset
+
+import (
+	"fmt"
+
+	"github.com/juju/errgo"
+)
+
+var (
+	maskAny = errgo.MaskFunc(errgo.Any)
+)
+
+func maskAnyf(err error, f string, v ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	f = fmt.Sprintf("%s: %s", err.Error(), f)
+	newErr := errgo.WithCausef(nil, errgo.Cause(err), f, v...)
+	newErr.(*errgo.Err).SetLocation(1)
+
+	return newErr
+}
+
+var invalidConfigError = errgo.New("invalid config")
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return errgo.Cause(err) == invalidConfigError
+}
+
+var invalidExampleError = errgo.New("invalid example")
+
+// IsInvalidExample asserts invalidExampleError.
+func IsInvalidExample(err error) bool {
+	return errgo.Cause(err) == invalidExampleError
+}
+
+var invalidFormatError = errgo.New("invalid format")
+
+// IsInvalidFormat asserts invalidFormatError.
+func IsInvalidFormat(err error) bool {
+	return errgo.Cause(err) == invalidFormatError
+}
+
+var networkClosedError = errgo.New("network closed")
+
+// IsNetworkClosed asserts networkClosedError.
+func IsNetworkClosed(err error) bool {
+	return errgo.Cause(err) == networkClosedError
+}
@@ -0,0 +1,188 @@
+package dataset
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"time"
+)
+
+// Evaluation represents the summary of the results of running a dataset
+// against a neural network that does not learn. See Evaluate.
+type Evaluation struct {
+	// CLGs describes how much each CLG was involved in running the examples,
+	// ordered by the number of examples it was involved in.
+	CLGs []CLGInvolvement `json:"clgs"`
+
+	// Confusions lists the pairs of expectation and output that did not match,
+	// ordered by how often they occurred.
+	Confusions []Confusion `json:"confusions"`
+
+	// ExactMatches is the number of examples whose output met their
+	// expectation, as checked by the matcher of the example, and
+	// ExactMatchRate the fraction of all examples.
+	ExactMatches   int     `json:"exact_matches"`
+	ExactMatchRate float64 `json:"exact_match_rate"`
+
+	// Examples is the number of examples being evaluated.
+	Examples int `json:"examples"`
+
+	// Latency describes the distribution of the latencies of all examples.
+	Latency Latency `json:"latency"`
+}
+
+// CLGInvolvement describes how much a single CLG was involved in running the
+// examples of a dataset.
+type CLGInvolvement struct {
+	// Calculations is the number of calculations the CLG executed over all
+	// examples.
+	Calculations int `json:"calculations"`
+
+	// Examples is the number of examples the CLG executed at least one
+	// calculation for.
+	Examples int `json:"examples"`
+
+	// Name is the name of the CLG, e.g. sum.
+	Name string `json:"name"`
+}
+
+// Confusion describes an output the neural network sent instead of the
+// expected one.
+type Confusion struct {
+	// Count is the number of examples the output was sent for instead of the
+	// expectation.
+	Count int `json:"count"`
+
+	Expectation string `json:"expectation"`
+	Output      string `json:"output"`
+}
+
+// Latency describes the distribution of latencies using percentiles.
+type Latency struct {
+	Max time.Duration
+	P50 time.Duration
+	P90 time.Duration
+	P99 time.Duration
+}
+
+// MarshalJSON encodes the latency using duration strings, e.g. 1.5s.
+func (l Latency) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(map[string]string{
+		"max": l.Max.String(),
+		"p50": l.P50.String(),
+		"p90": l.P90.String(),
+		"p99": l.P99.String(),
+	})
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	return b, nil
+}
+
+// Evaluate summarizes the given results.
+func Evaluate(results []Result) Evaluation {
+	evaluation := Evaluation{
+		CLGs:       []CLGInvolvement{},
+		Confusions: []Confusion{},
+		Examples:   len(results),
+	}
+
+	clgs := map[string]*CLGInvolvement{}
+	counts := map[Confusion]int{}
+	var latencies []time.Duration
+
+	for _, r := range results {
+		latencies = append(latencies, r.Latency)
+
+		for name, n := range r.Calculations {
+			if _, ok := clgs[name]; !ok {
+				clgs[name] = &CLGInvolvement{Name: name}
+			}
+			clgs[name].Calculations += n
+			clgs[name].Examples++
+		}
+
+		if r.Solved {
+			evaluation.ExactMatches++
+			continue
+		}
+		counts[Confusion{Expectation: r.Example.Expectation, Output: r.Output}]++
+	}
+
+	if evaluation.Examples != 0 {
+		evaluation.ExactMatchRate = float64(evaluation.ExactMatches) / float64(evaluation.Examples)
+	}
+
+	for _, c := range clgs {
+		evaluation.CLGs = append(evaluation.CLGs, *c)
+	}
+	sort.Sort(clgInvolvements(evaluation.CLGs))
+
+	for c, n := range counts {
+		c.Count = n
+		evaluation.Confusions = append(evaluation.Confusions, c)
+	}
+	sort.Sort(confusions(evaluation.Confusions))
+
+	sort.Sort(durations(latencies))
+	evaluation.Latency = Latency{
+		Max: percentile(latencies, 100),
+		P50: percentile(latencies, 50),
+		P90: percentile(latencies, 90),
+		P99: percentile(latencies, 99),
+	}
+
+	return evaluation
+}
+
+// percentile returns the given percentile of the given sorted latencies using
+// the nearest rank method.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
+
+// clgInvolvements orders CLG involvements by the number of examples, most
+// first, and then by name.
+type clgInvolvements []CLGInvolvement
+
+func (c clgInvolvements) Len() int      { return len(c) }
+func (c clgInvolvements) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+func (c clgInvolvements) Less(i, j int) bool {
+	if c[i].Examples != c[j].Examples {
+		return c[i].Examples > c[j].Examples
+	}
+	return c[i].Name < c[j].Name
+}
+
+// confusions orders confusions by count, most first, and then by expectation
+// and output.
+type confusions []Confusion
+
+func (c confusions) Len() int      { return len(c) }
+func (c confusions) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+func (c confusions) Less(i, j int) bool {
+	if c[i].Count != c[j].Count {
+		return c[i].Count > c[j].Count
+	}
+	if c[i].Expectation != c[j].Expectation {
+		return c[i].Expectation < c[j].Expectation
+	}
+	return c[i].Output < c[j].Output
+}
+
+// durations orders durations ascending.
+type durations []time.Duration
+
+func (d durations) Len() int           { return len(d) }
+func (d durations) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+func (d durations) Less(i, j int) bool { return d[i] < d[j] }
@@ -0,0 +1,89 @@
+package dataset
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_Evaluate(t *testing.T) {
+	results := []Result{
+		{
+			Calculations: map[string]int{"input": 1, "output": 1},
+			Example:      Example{Expectation: "a", Input: "a"},
+			Latency:      10 * time.Millisecond,
+			Output:       "a",
+			Solved:       true,
+		},
+		{
+			Calculations: map[string]int{"input": 1, "sum": 2, "output": 2},
+			Example:      Example{Expectation: "b", Input: "b"},
+			Latency:      30 * time.Millisecond,
+			Output:       "c",
+		},
+		{
+			Calculations: map[string]int{"input": 1},
+			Example:      Example{Expectation: "b", Input: "b"},
+			Latency:      20 * time.Millisecond,
+			Output:       "c",
+		},
+		{
+			Example: Example{Expectation: "d", Input: "d"},
+			Latency: 40 * time.Millisecond,
+		},
+	}
+
+	evaluation := Evaluate(results)
+
+	if evaluation.Examples != 4 {
+		t.Fatal("expected", 4, "got", evaluation.Examples)
+	}
+	if evaluation.ExactMatches != 1 {
+		t.Fatal("expected", 1, "got", evaluation.ExactMatches)
+	}
+	if evaluation.ExactMatchRate != 0.25 {
+		t.Fatal("expected", 0.25, "got", evaluation.ExactMatchRate)
+	}
+
+	expectedLatency := Latency{
+		Max: 40 * time.Millisecond,
+		P50: 20 * time.Millisecond,
+		P90: 40 * time.Millisecond,
+		P99: 40 * time.Millisecond,
+	}
+	if evaluation.Latency != expectedLatency {
+		t.Fatal("expected", expectedLatency, "got", evaluation.Latency)
+	}
+
+	expectedCLGs := []CLGInvolvement{
+		{Calculations: 3, Examples: 3, Name: "input"},
+		{Calculations: 3, Examples: 2, Name: "output"},
+		{Calculations: 2, Examples: 1, Name: "sum"},
+	}
+	if !reflect.DeepEqual(evaluation.CLGs, expectedCLGs) {
+		t.Fatal("expected", expectedCLGs, "got", evaluation.CLGs)
+	}
+
+	expectedConfusions := []Confusion{
+		{Count: 2, Expectation: "b", Output: "c"},
+		{Count: 1, Expectation: "d", Output: ""},
+	}
+	if !reflect.DeepEqual(evaluation.Confusions, expectedConfusions) {
+		t.Fatal("expected", expectedConfusions, "got", evaluation.Confusions)
+	}
+}
+
+func Test_Evaluate_Empty(t *testing.T) {
+	evaluation := Evaluate(nil)
+
+	b, err := json.Marshal(evaluation)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	expected := `{"clgs":[],"confusions":[],"exact_matches":0,"exact_match_rate":0,"examples":0,"latency":{"max":"0s","p50":"0s","p90":"0s","p99":"0s"}}`
+	if string(b) != expected {
+		t.Fatal("expected", expected, "got", string(b))
+	}
+}
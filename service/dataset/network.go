@@ -0,0 +1,388 @@
+package dataset
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	textinputobject "github.com/the-anna-project/annad/input/object/text"
+	"github.com/the-anna-project/annad/object/networkevent"
+	textendpoint "github.com/the-anna-project/annad/server/service/text"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+)
+
+const (
+	// subscriberName is the name a local network subscribes to the bus with.
+	subscriberName = "dataset"
+)
+
+// Event represents the parts of a network event a runner needs to follow the
+// CLG tree of an example.
+type Event struct {
+	// CLGName is the name of the CLG the network event is associated with, if
+	// any.
+	CLGName string
+	// CLGTreeID is the ID of the CLG tree the network event belongs to.
+	CLGTreeID string
+	// Error is the error message of the network event, if any.
+	Error string
+	// Kind is the kind of the network event. See the Kind constants of the
+	// networkevent package.
+	Kind string
+	// Output is the output sent to the client. It is only set for network
+	// events of kind output-emitted.
+	Output string
+	// SessionID is the ID of the session the network event belongs to.
+	SessionID string
+}
+
+// Network is the neural network a dataset is run against.
+type Network interface {
+	// Close stops receiving network events and releases all resources of the
+	// network. The neural network itself is not shut down.
+	Close() error
+	// Events returns the network events of the neural network. The returned
+	// channel is closed as soon as the network was closed or the neural
+	// network went away.
+	Events() <-chan Event
+	// Send sends the input of the given example to the neural network, being
+	// part of the session identified by the given session ID. The budget of the
+	// example limits the time the neural network calculates its answer.
+	Send(example Example, sessionID string) error
+}
+
+// NewLocalNetwork creates a network for the neural network driven by the
+// given service collection, running within the current process. The service
+// collection must be booted. Its endpoints are not used. The network consumes
+// all output the neural network sends, so no other consumer must read from
+// the output channel of the service collection.
+func NewLocalNetwork(serviceCollection servicespec.ServiceCollection) (Network, error) {
+	networkEvents, err := serviceCollection.Bus().Subscribe(subscriberName)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	newNetwork := &localNetwork{
+		closeOnce:         sync.Once{},
+		closer:            make(chan struct{}, 1),
+		events:            make(chan Event, 100),
+		serviceCollection: serviceCollection,
+	}
+
+	go func() {
+		defer close(newNetwork.events)
+
+		for e := range networkEvents {
+			select {
+			case <-newNetwork.closer:
+				return
+			case newNetwork.events <- newEvent(e):
+			}
+		}
+	}()
+
+	// The output is followed using output-emitted network events. The output
+	// channel must be drained anyway, because the output CLG blocks until its
+	// output was consumed.
+	go func() {
+		for {
+			select {
+			case <-newNetwork.closer:
+				return
+			case <-serviceCollection.Output().Text().Channel():
+			}
+		}
+	}()
+
+	return newNetwork, nil
+}
+
+type localNetwork struct {
+	closeOnce         sync.Once
+	closer            chan struct{}
+	events            chan Event
+	serviceCollection servicespec.ServiceCollection
+}
+
+func (n *localNetwork) Close() error {
+	n.closeOnce.Do(func() {
+		n.serviceCollection.Bus().Unsubscribe(subscriberName)
+		close(n.closer)
+	})
+
+	return nil
+}
+
+func (n *localNetwork) Events() <-chan Event {
+	return n.events
+}
+
+func (n *localNetwork) Send(example Example, sessionID string) error {
+	textInput := textinputobject.New()
+	textInput.SetBackoff(example.Backoff)
+	textInput.SetDeadline(example.Budget)
+	textInput.SetEcho(example.Echo)
+	textInput.SetExpectation(example.expectation())
+	textInput.SetInput(example.Input)
+	textInput.SetRetries(example.Retries)
+	textInput.SetSessionID(sessionID)
+
+	select {
+	case <-n.closer:
+		return maskAny(networkClosedError)
+	case n.serviceCollection.Input().Text().Channel() <- textInput:
+	}
+
+	return nil
+}
+
+// newEvent creates a new event describing the given network event.
+func newEvent(networkEvent objectspec.NetworkEvent) Event {
+	newEvent := Event{
+		CLGName:   networkEvent.GetCLGName(),
+		CLGTreeID: networkEvent.GetCLGTreeID(),
+		Error:     networkEvent.GetError(),
+		Kind:      networkEvent.GetKind(),
+		SessionID: networkEvent.GetSessionID(),
+	}
+
+	if newEvent.Kind == networkevent.KindOutputEmitted && networkEvent.GetNetworkPayload() != nil {
+		args := networkEvent.GetNetworkPayload().GetArgs()
+		if len(args) == 1 && args[0].Kind() == reflect.String {
+			newEvent.Output = args[0].String()
+		}
+	}
+
+	return newEvent
+}
+
+// RemoteConfig represents the configuration used to create a new remote
+// network.
+type RemoteConfig struct {
+	// Settings.
+
+	// MetricAddress is the host:port of the metric endpoint of the running
+	// daemon. The network events are streamed from there.
+	MetricAddress string
+
+	// TextAddress is the host:port of the text endpoint of the running daemon.
+	// The input of the examples is sent there.
+	TextAddress string
+
+	// Timeout is the duration to wait for the endpoints of the daemon to accept
+	// connections.
+	Timeout time.Duration
+}
+
+// DefaultRemoteConfig provides a default configuration to create a new remote
+// network by best effort.
+func DefaultRemoteConfig() RemoteConfig {
+	newConfig := RemoteConfig{
+		// Settings.
+		MetricAddress: "127.0.0.1:9120",
+		TextAddress:   "127.0.0.1:9119",
+		Timeout:       10 * time.Second,
+	}
+
+	return newConfig
+}
+
+// NewRemoteNetwork creates a network for the neural network of a running
+// daemon. Input is sent through the text endpoint of the daemon. Network
+// events are streamed from its metric endpoint. That way the output of
+// concurrently running examples is told apart using the session IDs of the
+// network events, even though the text endpoint streams output regardless of
+// the session it belongs to.
+func NewRemoteNetwork(config RemoteConfig) (Network, error) {
+	// Settings.
+	if config.MetricAddress == "" {
+		return nil, maskAnyf(invalidConfigError, "metric address must not be empty")
+	}
+	if config.TextAddress == "" {
+		return nil, maskAnyf(invalidConfigError, "text address must not be empty")
+	}
+	if config.Timeout <= 0 {
+		return nil, maskAnyf(invalidConfigError, "timeout must be greater than 0")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	kinds := []string{
+		networkevent.KindCalculated,
+		networkevent.KindOutputEmitted,
+		networkevent.KindTreeFinished,
+	}
+	url := fmt.Sprintf("http://%s/events?kind=%s", config.MetricAddress, strings.Join(kinds, ","))
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		cancel()
+		return nil, maskAny(err)
+	}
+	response, err := http.DefaultClient.Do(request.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, maskAny(err)
+	}
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		cancel()
+		return nil, maskAnyf(networkClosedError, "event stream responded with status %d", response.StatusCode)
+	}
+
+	conn, err := grpc.Dial(config.TextAddress, grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(config.Timeout))
+	if err != nil {
+		response.Body.Close()
+		cancel()
+		return nil, maskAny(err)
+	}
+	stream, err := textendpoint.NewTextEndpointClient(conn).StreamText(ctx)
+	if err != nil {
+		conn.Close()
+		response.Body.Close()
+		cancel()
+		return nil, maskAny(err)
+	}
+
+	newNetwork := &remoteNetwork{
+		cancel:    cancel,
+		closeOnce: sync.Once{},
+		conn:      conn,
+		events:    make(chan Event, 100),
+		mutex:     sync.Mutex{},
+		stream:    stream,
+	}
+
+	// The metric endpoint streams one JSON encoded network event per line.
+	go func() {
+		defer close(newNetwork.events)
+		defer response.Body.Close()
+
+		decoder := json.NewDecoder(response.Body)
+		for {
+			var e remoteEvent
+			err := decoder.Decode(&e)
+			if err != nil {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case newNetwork.events <- e.Event():
+			}
+		}
+	}()
+
+	// The output is followed using output-emitted network events. The text
+	// stream must be drained anyway, because the text endpoint blocks until its
+	// output was sent.
+	go func() {
+		for {
+			_, err := stream.Recv()
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	return newNetwork, nil
+}
+
+type remoteNetwork struct {
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+	conn      *grpc.ClientConn
+	events    chan Event
+	// mutex guards the text stream, because gRPC streams must not be sent to
+	// concurrently.
+	mutex  sync.Mutex
+	stream textendpoint.TextEndpoint_StreamTextClient
+}
+
+func (n *remoteNetwork) Close() error {
+	var err error
+
+	n.closeOnce.Do(func() {
+		n.mutex.Lock()
+		n.stream.CloseSend()
+		n.mutex.Unlock()
+
+		n.cancel()
+		err = n.conn.Close()
+	})
+
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
+
+func (n *remoteNetwork) Events() <-chan Event {
+	return n.events
+}
+
+func (n *remoteNetwork) Send(example Example, sessionID string) error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	// A backoff or budget of 0 means the default of the neural network is used.
+	var backoff string
+	if example.Backoff > 0 {
+		backoff = example.Backoff.String()
+	}
+	var deadline string
+	if example.Budget > 0 {
+		deadline = example.Budget.String()
+	}
+
+	err := n.stream.Send(&textendpoint.StreamTextRequest{
+		Backoff:              backoff,
+		Deadline:             deadline,
+		Echo:                 example.Echo,
+		Expectation:          example.Expectation,
+		ExpectationMatcher:   example.Matcher,
+		ExpectationOutputs:   example.Outputs,
+		ExpectationThreshold: example.Threshold,
+		Input:                example.Input,
+		Retries:              int64(example.Retries),
+		SessionID:            sessionID,
+	})
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
+
+// remoteEvent represents a network event as encoded by the metric endpoint.
+type remoteEvent struct {
+	CLGName   string `json:"clg_name"`
+	CLGTreeID string `json:"clg_tree_id"`
+	Error     string `json:"error"`
+	Kind      string `json:"kind"`
+	Output    string `json:"output"`
+	SessionID string `json:"session_id"`
+}
+
+// Event returns the event described by the remote event.
+func (e remoteEvent) Event() Event {
+	newEvent := Event{
+		CLGName:   e.CLGName,
+		CLGTreeID: e.CLGTreeID,
+		Error:     e.Error,
+		Kind:      e.Kind,
+		Output:    e.Output,
+		SessionID: e.SessionID,
+	}
+
+	return newEvent
+}
@@ -0,0 +1,346 @@
+package dataset
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/the-anna-project/annad/object/networkevent"
+	"github.com/the-anna-project/annad/service/matcher"
+)
+
+// budgetGrace is the time the runner waits beyond the budget of an example
+// for the neural network to end the CLG tree of the example, before the runner
+// gives up the example itself.
+const budgetGrace = 5 * time.Second
+
+// RunnerConfig represents the configuration used to create a new runner.
+type RunnerConfig struct {
+	// Dependencies.
+
+	// Network is the neural network the examples are run against.
+	Network Network
+
+	// Settings.
+
+	// Budget is the duration the neural network is given to meet the
+	// expectation of an example, unless the example provides its own budget.
+	// The neural network ends the CLG tree of an example not being solved
+	// within its budget.
+	Budget time.Duration
+
+	// Concurrency is the maximum number of examples being run at the same time.
+	Concurrency int
+
+	// Epochs is the number of times all examples are run.
+	Epochs int
+
+	// SessionID is the prefix of the session IDs of the examples. Each example
+	// of each epoch is run within its own session.
+	SessionID string
+
+	// Shuffle causes the examples to be run in a different random order each
+	// epoch. The order is determined by the Seed.
+	Seed    int64
+	Shuffle bool
+}
+
+// DefaultRunnerConfig provides a default configuration to create a new runner
+// by best effort.
+func DefaultRunnerConfig() RunnerConfig {
+	newConfig := RunnerConfig{
+		// Dependencies.
+		Network: nil,
+
+		// Settings.
+		Budget:      30 * time.Second,
+		Concurrency: 1,
+		Epochs:      1,
+		SessionID:   "dataset",
+		Seed:        1,
+		Shuffle:     false,
+	}
+
+	return newConfig
+}
+
+// NewRunner creates a new configured runner.
+func NewRunner(config RunnerConfig) (*Runner, error) {
+	// Dependencies.
+	if config.Network == nil {
+		return nil, maskAnyf(invalidConfigError, "network must not be empty")
+	}
+
+	// Settings.
+	if config.Budget <= 0 {
+		return nil, maskAnyf(invalidConfigError, "budget must be greater than 0")
+	}
+	if config.Concurrency < 1 {
+		return nil, maskAnyf(invalidConfigError, "concurrency must be greater than 0")
+	}
+	if config.Epochs < 1 {
+		return nil, maskAnyf(invalidConfigError, "epochs must be greater than 0")
+	}
+	if config.SessionID == "" {
+		return nil, maskAnyf(invalidConfigError, "session ID must not be empty")
+	}
+
+	newRunner := &Runner{
+		budget:        config.Budget,
+		closer:        make(chan struct{}),
+		concurrency:   config.Concurrency,
+		epochs:        config.Epochs,
+		mutex:         sync.Mutex{},
+		network:       config.Network,
+		rand:          rand.New(rand.NewSource(config.Seed)),
+		sessionID:     config.SessionID,
+		shuffle:       config.Shuffle,
+		subscriptions: map[string]*subscription{},
+	}
+
+	go newRunner.dispatch()
+
+	return newRunner, nil
+}
+
+// Runner runs the examples of a dataset against the neural network and
+// follows the CLG tree created for each of them.
+type Runner struct {
+	// Dependencies.
+
+	network Network
+
+	// Settings.
+
+	budget time.Duration
+	// closer is closed as soon as the network stopped delivering network
+	// events. Then examples still running are given up.
+	closer      chan struct{}
+	concurrency int
+	epochs      int
+	// mutex guards subscriptions.
+	mutex         sync.Mutex
+	rand          *rand.Rand
+	sessionID     string
+	shuffle       bool
+	subscriptions map[string]*subscription
+}
+
+// Result represents the outcome of running a single example.
+type Result struct {
+	// Attempts is the number of times the output CLG checked calculated output
+	// against the expectation of the example, including the successful check.
+	Attempts int
+
+	// CLGTreeID is the ID of the CLG tree which answered the example, if any.
+	CLGTreeID string
+
+	// Calculations is the number of calculations per CLG name the neural
+	// network executed while running the example, including failed ones.
+	Calculations map[string]int
+
+	// Epoch is the epoch the example was run in, starting with 1.
+	Epoch int
+
+	// Example is the example being run and Index its position within the
+	// dataset, starting with 0.
+	Example Example
+	Index   int
+
+	// Latency is the duration it took until the example was answered or given
+	// up.
+	Latency time.Duration
+
+	// Output is the output sent to the client, if any.
+	Output string
+
+	// Solved is true in case the output met the expectation of the example.
+	Solved bool
+}
+
+// subscription routes the events of a single session to the example being
+// run within it.
+type subscription struct {
+	// done is closed as soon as the example does not wait for events anymore.
+	done   chan struct{}
+	events chan Event
+}
+
+// Run runs the given examples against the neural network for the configured
+// number of epochs. The results are returned in the order the examples were
+// run, epoch by epoch. Run must not be called concurrently.
+func (r *Runner) Run(examples []Example) ([]Result, error) {
+	var results []Result
+	for epoch := 1; epoch <= r.epochs; epoch++ {
+		order := make([]int, len(examples))
+		for i := range order {
+			order[i] = i
+		}
+		if r.shuffle {
+			order = r.rand.Perm(len(examples))
+		}
+
+		epochResults, err := r.runEpoch(epoch, examples, order)
+		if err != nil {
+			return nil, maskAny(err)
+		}
+		results = append(results, epochResults...)
+	}
+
+	return results, nil
+}
+
+// dispatch routes the events of the network to the subscriptions of the
+// examples being run, based on the session IDs of the events.
+func (r *Runner) dispatch() {
+	defer close(r.closer)
+
+	for e := range r.network.Events() {
+		r.mutex.Lock()
+		sub, ok := r.subscriptions[e.SessionID]
+		r.mutex.Unlock()
+		if !ok {
+			continue
+		}
+
+		select {
+		case <-sub.done:
+		case sub.events <- e:
+		}
+	}
+}
+
+// runEpoch runs the given examples in the given order, using as many workers
+// as configured by the concurrency.
+func (r *Runner) runEpoch(epoch int, examples []Example, order []int) ([]Result, error) {
+	results := make([]Result, len(order))
+	errors := make(chan error, len(order))
+	jobs := make(chan int, len(order))
+	for i := range order {
+		jobs <- i
+	}
+	close(jobs)
+
+	var wg sync.WaitGroup
+	for w := 0; w < r.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				index := order[i]
+				result, err := r.runExample(epoch, index, examples[index])
+				if err != nil {
+					errors <- maskAny(err)
+					return
+				}
+				results[i] = result
+			}
+		}()
+	}
+	wg.Wait()
+
+	select {
+	case err := <-errors:
+		return nil, maskAny(err)
+	default:
+	}
+
+	return results, nil
+}
+
+// runExample sends the given example to the neural network and waits until
+// its CLG tree answered, ended, or the budget of the example is used up.
+func (r *Runner) runExample(epoch, index int, example Example) (Result, error) {
+	sessionID := fmt.Sprintf("%s-%d-%d", r.sessionID, epoch, index)
+	sub := r.subscribe(sessionID)
+	defer r.unsubscribe(sessionID, sub)
+
+	result := Result{
+		Calculations: map[string]int{},
+		Epoch:        epoch,
+		Example:      example,
+		Index:        index,
+	}
+
+	// The neural network ends the CLG tree of the example as soon as its budget
+	// is used up and answers with whatever it gathered so far.
+	request := example
+	if request.Budget == 0 {
+		request.Budget = r.budget
+	}
+	start := time.Now()
+	timeout := time.After(request.Budget + budgetGrace)
+
+	err := r.network.Send(request, sessionID)
+	if err != nil {
+		return Result{}, maskAny(err)
+	}
+
+	for {
+		select {
+		case <-r.closer:
+			return Result{}, maskAnyf(networkClosedError, "example %d of epoch %d was not answered", index+1, epoch)
+		case <-timeout:
+			result.Latency = time.Since(start)
+			return result, nil
+		case e := <-sub.events:
+			switch e.Kind {
+			case networkevent.KindCalculated:
+				result.Calculations[e.CLGName]++
+				// The output CLG fails as long as the expectation is not met.
+				if e.CLGName == "output" && e.Error != "" {
+					result.Attempts++
+				}
+			case networkevent.KindOutputEmitted:
+				// The network sends the candidates of the output CLG as soon as the
+				// expectation is met. Other output is the terminal response of a CLG
+				// tree exhausting its budget.
+				if e.CLGName == "output" {
+					result.Attempts++
+				}
+				result.CLGTreeID = e.CLGTreeID
+				result.Latency = time.Since(start)
+				result.Output = e.Output
+				// Only output of the output CLG is calculated by the neural network and
+				// can thus meet the expectation.
+				if e.CLGName == "output" {
+					match, err := matcher.Match(example.expectation(), e.Output)
+					if err != nil {
+						return Result{}, maskAny(err)
+					}
+					result.Solved = match.Matched
+				}
+				return result, nil
+			case networkevent.KindTreeFinished:
+				// The CLG tree ended without its output being observed, e.g. because
+				// the bus dropped network events.
+				result.CLGTreeID = e.CLGTreeID
+				result.Latency = time.Since(start)
+				return result, nil
+			}
+		}
+	}
+}
+
+func (r *Runner) subscribe(sessionID string) *subscription {
+	sub := &subscription{
+		done:   make(chan struct{}),
+		events: make(chan Event, 100),
+	}
+
+	r.mutex.Lock()
+	r.subscriptions[sessionID] = sub
+	r.mutex.Unlock()
+
+	return sub
+}
+
+func (r *Runner) unsubscribe(sessionID string, sub *subscription) {
+	close(sub.done)
+
+	r.mutex.Lock()
+	delete(r.subscriptions, sessionID)
+	r.mutex.Unlock()
+}
@@ -0,0 +1,227 @@
+package dataset
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/the-anna-project/annad/service/network"
+	"github.com/the-anna-project/annad/testkit"
+)
+
+// testBudgetEvents is the number of network events each CLG tree of the test
+// network is allowed to cause.
+const testBudgetEvents = 10
+
+// testRun runs the given examples against a new test kit, using a runner
+// configured with the given number of epochs and concurrency.
+func testRun(t *testing.T, examples []Example, epochs, concurrency int) []Result {
+	newNetworkConfig := network.DefaultConfig()
+	newNetworkConfig.BudgetEvents = testBudgetEvents
+	newNetworkConfig.RetryBackoff = 0
+
+	return testRunWithNetwork(t, newNetworkConfig, examples, epochs, concurrency)
+}
+
+// testRunWithNetwork works like testRun, but the network of the test kit is
+// created using the given configuration.
+func testRunWithNetwork(t *testing.T, newNetworkConfig network.Config, examples []Example, epochs, concurrency int) []Result {
+	newNetworkService, err := network.New(newNetworkConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	newConfig := testkit.DefaultConfig()
+	newConfig.NetworkService = newNetworkService
+	newKit, err := testkit.New(newConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	newKit.Boot()
+	defer newKit.Shutdown()
+
+	newNetwork, err := NewLocalNetwork(newKit.Service())
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	defer newNetwork.Close()
+
+	newRunnerConfig := DefaultRunnerConfig()
+	newRunnerConfig.Budget = 10 * time.Second
+	newRunnerConfig.Concurrency = concurrency
+	newRunnerConfig.Epochs = epochs
+	newRunnerConfig.Network = newNetwork
+	newRunnerConfig.Shuffle = true
+	newRunner, err := NewRunner(newRunnerConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	results, err := newRunner.Run(examples)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	return results
+}
+
+func Test_Runner_Run(t *testing.T) {
+	examples := []Example{
+		{Echo: true, Expectation: "hello", Input: "hello"},
+		{Echo: true, Expectation: "goodbye", Input: "world"},
+		{Echo: true, Expectation: "foo", Input: "foo"},
+	}
+
+	results := testRun(t, examples, 2, 2)
+	if len(results) != 6 {
+		t.Fatal("expected", 6, "got", len(results))
+	}
+
+	seen := map[int]int{}
+	for _, r := range results {
+		seen[r.Index]++
+		if !reflect.DeepEqual(r.Example, examples[r.Index]) {
+			t.Fatal("expected", examples[r.Index], "got", r.Example)
+		}
+		if r.Epoch != 1 && r.Epoch != 2 {
+			t.Fatal("expected", "epoch 1 or 2", "got", r.Epoch)
+		}
+
+		if r.Index == 1 {
+			// The echoed input never meets the expectation. The output CLG retries
+			// until the budget of the CLG tree is exhausted.
+			if r.Solved {
+				t.Fatal("expected", false, "got", true)
+			}
+			if r.Output != "no answer within budget" {
+				t.Fatal("expected", "no answer within budget", "got", r.Output)
+			}
+			if r.Attempts != testBudgetEvents {
+				t.Fatal("expected", testBudgetEvents, "got", r.Attempts)
+			}
+			continue
+		}
+
+		if !r.Solved {
+			t.Fatal("expected", true, "got", false)
+		}
+		if r.Output != r.Example.Expectation {
+			t.Fatal("expected", r.Example.Expectation, "got", r.Output)
+		}
+		if r.Attempts != 1 {
+			t.Fatal("expected", 1, "got", r.Attempts)
+		}
+		if r.Calculations["output"] != 1 {
+			t.Fatal("expected", 1, "got", r.Calculations["output"])
+		}
+		if r.CLGTreeID == "" {
+			t.Fatal("expected", "CLG tree ID", "got", "")
+		}
+	}
+	for i := range examples {
+		if seen[i] != 2 {
+			t.Fatal("expected", 2, "got", seen[i])
+		}
+	}
+}
+
+func Test_Runner_Run_Matcher(t *testing.T) {
+	examples := []Example{
+		{Echo: true, Expectation: "hello", Input: "HELLO", Matcher: "case-insensitive"},
+		{Echo: true, Expectation: "[a-z]+", Input: "foo", Matcher: "regex"},
+		{Echo: true, Expectation: "3.14", Input: "3.141", Matcher: "numeric", Threshold: 0.01},
+		{Echo: true, Expectation: "hi", Input: "hey", Matcher: "one-of", Outputs: []string{"hello", "hey"}},
+		{Echo: true, Expectation: "world", Input: "word", Matcher: "edit-distance", Threshold: 1},
+	}
+
+	results := testRun(t, examples, 1, 1)
+	if len(results) != len(examples) {
+		t.Fatal("expected", len(examples), "got", len(results))
+	}
+	for _, r := range results {
+		if !r.Solved {
+			t.Fatal("expected", true, "got", false)
+		}
+		if r.Output != r.Example.Input {
+			t.Fatal("expected", r.Example.Input, "got", r.Output)
+		}
+		if r.Attempts != 1 {
+			t.Fatal("expected", 1, "got", r.Attempts)
+		}
+	}
+}
+
+func Test_Runner_Run_Retries(t *testing.T) {
+	// The echoed input never meets the expectation. As soon as the output CLG
+	// exceeds the retry limit, the closest output is sent instead.
+	examples := []Example{
+		{Backoff: time.Millisecond, Echo: true, Expectation: "world", Input: "wrld", Matcher: "edit-distance", Retries: 2},
+	}
+
+	newNetworkConfig := network.DefaultConfig()
+	results := testRunWithNetwork(t, newNetworkConfig, examples, 1, 1)
+	if len(results) != 1 {
+		t.Fatal("expected", 1, "got", len(results))
+	}
+	if results[0].Solved {
+		t.Fatal("expected", false, "got", true)
+	}
+	if results[0].Output != "wrld" {
+		t.Fatal("expected", "wrld", "got", results[0].Output)
+	}
+	if results[0].Attempts != 3 {
+		t.Fatal("expected", 3, "got", results[0].Attempts)
+	}
+}
+
+func Test_Runner_Run_Budget(t *testing.T) {
+	// The input CLG does not know how to answer, so the network ends the CLG
+	// tree as soon as the budget of the example is used up, instead of waiting
+	// for its own deadline.
+	examples := []Example{
+		{Budget: 100 * time.Millisecond, Expectation: "world", Input: "hello"},
+	}
+
+	results := testRun(t, examples, 1, 1)
+	if len(results) != 1 {
+		t.Fatal("expected", 1, "got", len(results))
+	}
+	if results[0].Solved {
+		t.Fatal("expected", false, "got", true)
+	}
+	if results[0].Output != "no answer within budget" {
+		t.Fatal("expected", "no answer within budget", "got", results[0].Output)
+	}
+	if results[0].Latency < 100*time.Millisecond {
+		t.Fatal("expected", ">= 100ms", "got", results[0].Latency)
+	}
+	if results[0].Latency >= 5*time.Second {
+		t.Fatal("expected", "less than 5s", "got", results[0].Latency)
+	}
+}
+
+func Test_Runner_Run_Frozen(t *testing.T) {
+	// The frozen network did not learn anything. Thus the input CLG does not
+	// forward anywhere and the CLG tree ends right away, instead of waiting for
+	// the deadline of its budget.
+	examples := []Example{
+		{Expectation: "world", Input: "hello"},
+	}
+
+	newNetworkConfig := network.DefaultConfig()
+	newNetworkConfig.BudgetDeadline = time.Minute
+	newNetworkConfig.Frozen = true
+	results := testRunWithNetwork(t, newNetworkConfig, examples, 1, 1)
+	if len(results) != 1 {
+		t.Fatal("expected", 1, "got", len(results))
+	}
+	if results[0].Output != "no learned path" {
+		t.Fatal("expected", "no learned path", "got", results[0].Output)
+	}
+	if results[0].Calculations["input"] != 1 {
+		t.Fatal("expected", 1, "got", results[0].Calculations["input"])
+	}
+	if results[0].Latency >= 5*time.Second {
+		t.Fatal("expected", "less than 5s", "got", results[0].Latency)
+	}
+}
@@ -2,6 +2,8 @@ package feature
 
 import (
 	"strings"
+
+	"github.com/the-anna-project/annad/helper"
 )
 
 func seqCombinations(sequence, separator string, minLength, maxLength int) []string {
@@ -14,7 +16,7 @@ func seqCombinations(sequence, separator string, minLength, maxLength int) []str
 
 	for i := range splitted {
 		comb := splitted[i]
-		if !containsString(combs, comb) && matchesLength(comb, minLength, maxLength) {
+		if !helper.ContainsString(combs, comb) && matchesLength(comb, minLength, maxLength) {
 			combs = append(combs, comb)
 		}
 
@@ -27,7 +29,7 @@ func seqCombinations(sequence, separator string, minLength, maxLength int) []str
 			}
 
 			comb := strings.Join(splitted[i:j], separator)
-			if !containsString(combs, comb) && matchesLength(comb, minLength, maxLength) {
+			if !helper.ContainsString(combs, comb) && matchesLength(comb, minLength, maxLength) {
 				combs = append(combs, comb)
 			}
 		}
@@ -67,13 +69,3 @@ func seqPositions(sequence, seq string) [][]float64 {
 
 	return positions
 }
-
-func containsString(combs []string, comb string) bool {
-	for _, c := range combs {
-		if c == comb {
-			return true
-		}
-	}
-
-	return false
-}
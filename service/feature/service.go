@@ -5,9 +5,10 @@ package feature
 import (
 	"strings"
 
+	"github.com/the-anna-project/annad/helper"
 	"github.com/the-anna-project/annad/object/feature"
-	objectspec "github.com/the-anna-project/spec/object"
-	servicespec "github.com/the-anna-project/spec/service"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
 )
 
 // New creates a new feature service. The feature service tries to detect all
@@ -53,7 +54,7 @@ func (s *service) Scan(config servicespec.ScanConfig) ([]objectspec.Feature, err
 	var allSeqs []string
 	for _, sequence := range config.Sequences {
 		for _, seq := range seqCombinations(sequence, config.Separator, config.MinLength, config.MaxLength) {
-			if !containsString(allSeqs, seq) {
+			if !helper.ContainsString(allSeqs, seq) {
 				allSeqs = append(allSeqs, seq)
 			}
 		}
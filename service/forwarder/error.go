@@ -42,3 +42,17 @@ var invalidBehaviourIDError = errgo.New("invalid behaviour ID")
 func IsInvalidBehaviourID(err error) bool {
 	return errgo.Cause(err) == invalidBehaviourIDError
 }
+
+var invalidCLGNameError = errgo.New("invalid CLG name")
+
+// IsInvalidCLGName asserts invalidCLGNameError.
+func IsInvalidCLGName(err error) bool {
+	return errgo.Cause(err) == invalidCLGNameError
+}
+
+var invalidCLGTreeIDError = errgo.New("invalid CLG tree ID")
+
+// IsInvalidCLGTreeID asserts invalidCLGTreeIDError.
+func IsInvalidCLGTreeID(err error) bool {
+	return errgo.Cause(err) == invalidCLGTreeIDError
+}
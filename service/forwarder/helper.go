@@ -0,0 +1,12 @@
+package forwarder
+
+// appendPath returns a copy of the given path having the given behaviour ID
+// appended. The given path is not modified. That way network payloads being
+// forwarded to multiple CLGs never share the underlying array of their paths.
+func appendPath(path []string, behaviourID string) []string {
+	newPath := make([]string, 0, len(path)+1)
+	newPath = append(newPath, path...)
+	newPath = append(newPath, behaviourID)
+
+	return newPath
+}
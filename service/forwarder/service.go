@@ -1,13 +1,14 @@
 package forwarder
 
 import (
-	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/the-anna-project/annad/object/networkpayload"
-	objectspec "github.com/the-anna-project/spec/object"
-	servicespec "github.com/the-anna-project/spec/service"
-	storagecollection "github.com/the-anna-project/storage/collection"
+	"github.com/the-anna-project/annad/service/behaviour"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+	storagecollection "github.com/the-anna-project/annad/storage/collection"
 )
 
 // New creates a new forwarder service.
@@ -53,6 +54,10 @@ func (s *service) Forward(CLG servicespec.CLGService, networkPayload objectspec.
 		s.GetNetworkPayloads,
 		s.NewNetworkpayloads,
 	}
+	if s.Service().Network().Frozen() {
+		// A frozen network does not create new forward configurations.
+		lookups = lookups[:1]
+	}
 
 	// Execute one lookup after another. As soon as we find some behaviour IDs, we
 	// use them to forward the given network payload.
@@ -76,15 +81,11 @@ func (s *service) Forward(CLG servicespec.CLGService, networkPayload objectspec.
 	// TODO create event collection with network service to handle network events.
 	//
 	// Forward the found network payloads to other CLGs by adding them to the
-	// queue so other processes can fetch them.
+	// queue so other processes can fetch them. The network schedules the queued
+	// network payloads according to their priority.
 	for _, np := range newNetworkPayloads {
-		networkPayloadKey := fmt.Sprintf("events:network-payload")
-		b, err := json.Marshal(np)
-		if err != nil {
-			return maskAny(err)
-		}
 		// TODO store asynchronuously
-		err = s.Service().Storage().General().PushToSet(networkPayloadKey, string(b))
+		err = s.Service().Network().Queue(np)
 		if err != nil {
 			return maskAny(err)
 		}
@@ -93,6 +94,27 @@ func (s *service) Forward(CLG servicespec.CLGService, networkPayload objectspec.
 	return nil
 }
 
+// isQuarantined checks whether the CLG identified by the given CLG name is
+// currently quarantined by the network. Quarantined CLGs are taken out of the
+// forwarding choices. The lookups check for quarantine before creating
+// anything, so no behaviours or forward configurations are stored for them.
+func (s *service) isQuarantined(clgName string) (bool, error) {
+	quarantineKey := fmt.Sprintf("clg-name:%s:quarantined-until", clgName)
+	value, err := s.Service().Storage().General().Get(quarantineKey)
+	if storagecollection.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, maskAny(err)
+	}
+
+	until, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return false, maskAny(err)
+	}
+
+	return time.Now().Before(until), nil
+}
+
 func (s *service) MaxSignals() int {
 	return s.maxSignals
 }
@@ -106,9 +128,8 @@ func (s *service) GetNetworkPayloads(CLG servicespec.CLGService, networkPayload
 	if !ok {
 		return nil, maskAnyf(invalidBehaviourIDError, "must not be empty")
 	}
-	behaviourIDsKey := fmt.Sprintf("forward:configuration:behaviour-id:%s:behaviour-ids", behaviourID)
-	newBehaviourIDs, err := s.Service().Storage().General().GetAllFromSet(behaviourIDsKey)
-	if storagecollection.IsNotFound(err) {
+	newBehaviourIDs, err := s.Service().Behaviour().ForwardConfiguration(behaviourID)
+	if behaviour.IsNotFound(err) {
 		// No configuration of behaviour IDs is stored. Thus we return an error.
 		// Eventually some other lookup is able to find sufficient network payloads.
 		return nil, maskAny(networkPayloadsNotFoundError)
@@ -116,6 +137,20 @@ func (s *service) GetNetworkPayloads(CLG servicespec.CLGService, networkPayload
 		return nil, maskAny(err)
 	}
 
+	// The forwarded context names the CLG of the known behaviour IDs. In case
+	// it is quarantined, the known behaviour IDs are not used for now.
+	clgName, ok := ctx.GetCLGName()
+	if !ok {
+		return nil, maskAnyf(invalidCLGNameError, "must not be empty")
+	}
+	quarantined, err := s.isQuarantined(clgName)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+	if quarantined {
+		return nil, maskAny(networkPayloadsNotFoundError)
+	}
+
 	// Create a list of new network payloads.
 	var newNetworkPayloads []objectspec.NetworkPayload
 	for _, behaviourID := range newBehaviourIDs {
@@ -124,10 +159,17 @@ func (s *service) GetNetworkPayloads(CLG servicespec.CLGService, networkPayload
 		newCtx.SetBehaviourID(behaviourID)
 
 		// Create a new network payload.
+		networkPayloadID, err := s.Service().ID().New()
+		if err != nil {
+			return nil, maskAny(err)
+		}
 		newNetworkPayloadConfig := networkpayload.DefaultConfig()
 		newNetworkPayloadConfig.Args = networkPayload.GetArgs()
 		newNetworkPayloadConfig.Context = newCtx
+		newNetworkPayloadConfig.Depth = networkPayload.GetDepth() + 1
 		newNetworkPayloadConfig.Destination = string(behaviourID)
+		newNetworkPayloadConfig.ID = networkPayloadID
+		newNetworkPayloadConfig.Path = appendPath(networkPayload.GetPath(), networkPayload.GetDestination())
 		newNetworkPayloadConfig.Sources = []string{networkPayload.GetDestination()}
 		newNetworkPayload, err := networkpayload.New(newNetworkPayloadConfig)
 		if err != nil {
@@ -147,6 +189,21 @@ func (s *service) Metadata() map[string]string {
 func (s *service) NewNetworkpayloads(CLG servicespec.CLGService, networkPayload objectspec.NetworkPayload) ([]objectspec.NetworkPayload, error) {
 	ctx := networkPayload.GetContext()
 
+	// As long as no other CLG name is paired with new behaviour IDs, they are
+	// instances of the CLG the forwarded context names. In case it is
+	// quarantined, no new behaviour IDs are created at all.
+	clgName, ok := ctx.GetCLGName()
+	if !ok {
+		return nil, maskAnyf(invalidCLGNameError, "must not be empty")
+	}
+	quarantined, err := s.isQuarantined(clgName)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+	if quarantined {
+		return nil, maskAny(networkPayloadsNotFoundError)
+	}
+
 	// Decide how many new behaviour IDs should be created. This defines the
 	// number of signals being forwarded to other CLGs. Here we want to make a
 	// pseudo random decision. CreateMax takes a max paramater which is exclusive.
@@ -170,19 +227,27 @@ func (s *service) NewNetworkpayloads(CLG servicespec.CLGService, networkPayload
 	// TODO find a CLG name that can be connected to the current CLG for each new
 	// behaviour ID and pair these combinations (network event tracker)
 
-	// Store each new behaviour ID in the underlying storage.
+	// Register each new behaviour ID and store it as forward configuration of
+	// the current CLG.
 	behaviourID, ok := ctx.GetBehaviourID()
 	if !ok {
 		return nil, maskAnyf(invalidBehaviourIDError, "must not be empty")
 	}
-	behaviourIDsKey := fmt.Sprintf("forward:configuration:behaviour-id:%s:behaviour-ids", behaviourID)
-	for _, behaviourID := range newBehaviourIDs {
+	clgTreeID, ok := ctx.GetCLGTreeID()
+	if !ok {
+		return nil, maskAnyf(invalidCLGTreeIDError, "must not be empty")
+	}
+	for _, newBehaviourID := range newBehaviourIDs {
 		// TODO store asynchronuously
-		err = s.Service().Storage().General().PushToSet(behaviourIDsKey, behaviourID)
+		err = s.Service().Behaviour().Create(newBehaviourID, clgName, clgTreeID)
 		if err != nil {
 			return nil, maskAny(err)
 		}
 	}
+	err = s.Service().Behaviour().AddForwardConfiguration(behaviourID, newBehaviourIDs)
+	if err != nil {
+		return nil, maskAny(err)
+	}
 
 	// Create a list of new network payloads.
 	var newNetworkPayloads []objectspec.NetworkPayload
@@ -193,10 +258,17 @@ func (s *service) NewNetworkpayloads(CLG servicespec.CLGService, networkPayload
 		// TODO set the paired CLG name to the new context
 
 		// Create a new network payload.
+		networkPayloadID, err := s.Service().ID().New()
+		if err != nil {
+			return nil, maskAny(err)
+		}
 		newNetworkPayloadConfig := networkpayload.DefaultConfig()
 		newNetworkPayloadConfig.Args = networkPayload.GetArgs()
 		newNetworkPayloadConfig.Context = newCtx
+		newNetworkPayloadConfig.Depth = networkPayload.GetDepth() + 1
 		newNetworkPayloadConfig.Destination = string(behaviourID)
+		newNetworkPayloadConfig.ID = networkPayloadID
+		newNetworkPayloadConfig.Path = appendPath(networkPayload.GetPath(), networkPayload.GetDestination())
 		newNetworkPayloadConfig.Sources = []string{networkPayload.GetDestination()}
 		newNetworkPayload, err := networkpayload.New(newNetworkPayloadConfig)
 		if err != nil {
@@ -0,0 +1,129 @@
+// Package id implements a hash based ID service creating reproducible IDs. It
+// is used in deterministic mode instead of the default ID service, which
+// consumes random numbers. That way the creation of IDs does not change the
+// sequence of random numbers other services draw. See servicespec.IDService.
+package id
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync/atomic"
+
+	servicespec "github.com/the-anna-project/annad/spec/service"
+)
+
+// Config represents the configuration used to create a new ID service.
+type Config struct {
+	// Settings.
+
+	// IDType is the number of hex characters of the IDs created by New. The
+	// default matches the IDs created by the default ID service.
+	IDType int
+
+	// Seed is hashed together with a counter to create IDs. ID services using
+	// the same seed create the same sequence of IDs.
+	Seed int64
+}
+
+// DefaultConfig provides a default configuration to create a new ID service by
+// best effort.
+func DefaultConfig() Config {
+	newConfig := Config{
+		// Settings.
+		IDType: 16,
+		Seed:   1,
+	}
+
+	return newConfig
+}
+
+// New creates a new ID service.
+func New(config Config) (servicespec.IDService, error) {
+	// Settings.
+	if config.IDType < 1 {
+		return nil, maskAnyf(invalidConfigError, "ID type must be greater than 0")
+	}
+	if config.Seed == 0 {
+		return nil, maskAnyf(invalidConfigError, "seed must not be 0")
+	}
+
+	newService := &service{
+		// Dependencies.
+		serviceCollection: nil,
+
+		// Settings.
+		counter:  0,
+		idType:   config.IDType,
+		metadata: map[string]string{},
+		seed:     config.Seed,
+	}
+
+	return newService, nil
+}
+
+type service struct {
+	// Dependencies.
+
+	serviceCollection servicespec.ServiceCollection
+
+	// Settings.
+
+	// counter is the number of IDs created so far. It must only be accessed
+	// atomically.
+	counter  int64
+	idType   int
+	metadata map[string]string
+	seed     int64
+}
+
+func (s *service) Boot() {
+	id, err := s.Service().ID().New()
+	if err != nil {
+		panic(err)
+	}
+	s.metadata = map[string]string{
+		"id":   id,
+		"name": "id",
+		"type": "service",
+	}
+}
+
+func (s *service) Metadata() map[string]string {
+	return s.metadata
+}
+
+func (s *service) New() (string, error) {
+	ID, err := s.WithType(s.idType)
+	if err != nil {
+		return "", maskAny(err)
+	}
+
+	return ID, nil
+}
+
+func (s *service) Service() servicespec.ServiceCollection {
+	return s.serviceCollection
+}
+
+func (s *service) SetServiceCollection(sc servicespec.ServiceCollection) {
+	s.serviceCollection = sc
+}
+
+func (s *service) WithType(idType int) (string, error) {
+	if idType < 1 {
+		return "", maskAnyf(invalidIDTypeError, "must be greater than 0")
+	}
+
+	n := atomic.AddInt64(&s.counter, 1)
+
+	// Each hash provides 64 hex characters. Longer IDs are made of multiple
+	// hashes, each of them identified by its block number.
+	var ID string
+	for block := 0; len(ID) < idType; block++ {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%d:%d", s.seed, n, block)))
+		ID += hex.EncodeToString(sum[:])
+	}
+
+	return ID[:idType], nil
+}
@@ -0,0 +1,80 @@
+package id
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testService(t *testing.T, seed int64) *service {
+	newConfig := DefaultConfig()
+	newConfig.Seed = seed
+	newService, err := New(newConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	return newService.(*service)
+}
+
+func Test_ID_New_Error_Config(t *testing.T) {
+	newConfig := DefaultConfig()
+	newConfig.Seed = 0
+	_, err := New(newConfig)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	newConfig = DefaultConfig()
+	newConfig.IDType = 0
+	_, err = New(newConfig)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+func Test_ID_New_Seed(t *testing.T) {
+	newIDs := func(seed int64) []string {
+		newService := testService(t, seed)
+
+		var IDs []string
+		for i := 0; i < 3; i++ {
+			ID, err := newService.New()
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			IDs = append(IDs, ID)
+		}
+
+		return IDs
+	}
+
+	IDs1 := newIDs(1)
+	if IDs1[0] == IDs1[1] || IDs1[1] == IDs1[2] {
+		t.Fatal("expected", "unique IDs", "got", IDs1)
+	}
+	if !reflect.DeepEqual(IDs1, newIDs(1)) {
+		t.Fatal("expected", "same IDs", "got", "different IDs")
+	}
+	if reflect.DeepEqual(IDs1, newIDs(2)) {
+		t.Fatal("expected", "different IDs", "got", "same IDs")
+	}
+}
+
+func Test_ID_WithType(t *testing.T) {
+	newService := testService(t, 1)
+
+	for _, idType := range []int{1, 16, 64, 65, 512} {
+		ID, err := newService.WithType(idType)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		if len(ID) != idType {
+			t.Fatal("expected", idType, "got", len(ID))
+		}
+	}
+
+	_, err := newService.WithType(0)
+	if !IsInvalidIDType(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
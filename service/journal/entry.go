@@ -0,0 +1,121 @@
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	// KindFlags is the kind of the entry journals start with. It holds the
+	// flags the recorded process was booted with.
+	KindFlags = "flags"
+	// KindID is the kind of entries recording IDs being created.
+	KindID = "id"
+	// KindInput is the kind of entries recording text input the network
+	// received.
+	KindInput = "input"
+	// KindOutput is the kind of entries recording text output sent to the
+	// client.
+	KindOutput = "output"
+	// KindRandom is the kind of entries recording random numbers being drawn.
+	KindRandom = "random"
+)
+
+// Entry represents a single record of a journal. Journals are written as one
+// JSON encoded entry per line. Which fields are set depends on the kind of the
+// entry.
+type Entry struct {
+	// Kind is the kind of the entry. See the Kind constants.
+	Kind string `json:"kind"`
+
+	// Flags maps flag names to their values. It is set for entries of kind
+	// flags.
+	Flags map[string]string `json:"flags,omitempty"`
+
+	// ID is the created ID and IDType the type it was created with. They are
+	// set for entries of kind id.
+	ID     string `json:"id,omitempty"`
+	IDType int    `json:"id_type,omitempty"`
+
+	// Echo, Expectation, Input and SessionID describe the text input the
+	// network received. They are set for entries of kind input. SessionID is
+	// set for entries of kind output as well.
+	Echo        bool   `json:"echo,omitempty"`
+	Expectation string `json:"expectation,omitempty"`
+	Input       string `json:"input,omitempty"`
+	SessionID   string `json:"session_id,omitempty"`
+
+	// Retries, Backoff and Deadline describe the budget the text input asked
+	// for. Zero values mean the network's defaults were used. They are set for
+	// entries of kind input.
+	Retries  int           `json:"retries,omitempty"`
+	Backoff  time.Duration `json:"backoff,omitempty"`
+	Deadline time.Duration `json:"deadline,omitempty"`
+
+	// Matcher, Outputs and Threshold describe how output is checked against
+	// the Expectation. They are set for entries of kind input providing an
+	// expectation.
+	Matcher   string   `json:"matcher,omitempty"`
+	Outputs   []string `json:"outputs,omitempty"`
+	Threshold float64  `json:"threshold,omitempty"`
+
+	// Output is the text output sent to the client. It is set for entries of
+	// kind output.
+	Output string `json:"output,omitempty"`
+
+	// Max is the exclusive upper bound the Numbers were drawn with. They are set
+	// for entries of kind random.
+	Max     int   `json:"max,omitempty"`
+	Numbers []int `json:"numbers,omitempty"`
+}
+
+// Read reads all entries of the journal provided by the given reader.
+func Read(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+
+	decoder := json.NewDecoder(r)
+	for {
+		var e Entry
+		err := decoder.Decode(&e)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, maskAny(err)
+		}
+
+		switch e.Kind {
+		case KindFlags, KindID, KindInput, KindOutput, KindRandom:
+		default:
+			return nil, maskAnyf(invalidEntryError, "entry %d has unknown kind '%s'", len(entries)+1, e.Kind)
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+// Flags returns the flags recorded by the given journal entries. That is the
+// first entry of kind flags, if any.
+func Flags(entries []Entry) map[string]string {
+	for _, e := range entries {
+		if e.Kind == KindFlags {
+			return e.Flags
+		}
+	}
+
+	return nil
+}
+
+// Summary returns a short summary of the given journal entries, counting them
+// by kind.
+func Summary(entries []Entry) string {
+	counts := map[string]int{}
+	for _, e := range entries {
+		counts[e.Kind]++
+	}
+
+	return fmt.Sprintf("%d inputs, %d outputs, %d random draws, %d IDs", counts[KindInput], counts[KindOutput], counts[KindRandom], counts[KindID])
+}
@@ -0,0 +1,51 @@
+package journal
+
+import (
+	"fmt"
+
+	"github.com/juju/errgo"
+)
+
+var (
+	maskAny = errgo.MaskFunc(errgo.Any)
+)
+
+func maskAnyf(err error, f string, v ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	f = fmt.Sprintf("%s: %s", err.Error(), f)
+	newErr := errgo.WithCausef(nil, errgo.Cause(err), f, v...)
+	newErr.(*errgo.Err).SetLocation(1)
+
+	return newErr
+}
+
+var invalidConfigError = errgo.New("invalid config")
+
+// IsInvalidConfig asserts invalidConfigError.
+func IsInvalidConfig(err error) bool {
+	return errgo.Cause(err) == invalidConfigError
+}
+
+var divergenceError = errgo.New("divergence")
+
+// IsDivergence asserts divergenceError.
+func IsDivergence(err error) bool {
+	return errgo.Cause(err) == divergenceError
+}
+
+var invalidEntryError = errgo.New("invalid entry")
+
+// IsInvalidEntry asserts invalidEntryError.
+func IsInvalidEntry(err error) bool {
+	return errgo.Cause(err) == invalidEntryError
+}
+
+var recorderClosedError = errgo.New("recorder closed")
+
+// IsRecorderClosed asserts recorderClosedError.
+func IsRecorderClosed(err error) bool {
+	return errgo.Cause(err) == recorderClosedError
+}
@@ -0,0 +1,130 @@
+package journal
+
+import (
+	"sync"
+
+	servicecollection "github.com/the-anna-project/annad/collection/collection"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+)
+
+// IDService returns an ID service recording all IDs the given ID service
+// creates. The given random service must be the one not being recorded. See
+// SetServiceCollection of the returned ID service.
+func (r *Recorder) IDService(idService servicespec.IDService, randomService servicespec.RandomService) servicespec.IDService {
+	return &recordingIDService{
+		IDService:         idService,
+		mutex:             sync.Mutex{},
+		randomService:     randomService,
+		recorder:          r,
+		serviceCollection: nil,
+	}
+}
+
+type recordingIDService struct {
+	servicespec.IDService
+
+	// mutex makes creating and recording IDs atomic. That way concurrently
+	// created IDs are recorded in the order they were created. Note that this
+	// order is only reproducible as long as the network handles one input and
+	// one network event at a time, which the boot command ensures when
+	// recording.
+	mutex         sync.Mutex
+	randomService servicespec.RandomService
+	recorder      *Recorder
+
+	serviceCollection servicespec.ServiceCollection
+}
+
+func (s *recordingIDService) New() (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ID, err := s.IDService.New()
+	if err != nil {
+		return "", maskAny(err)
+	}
+	err = s.recorder.Record(Entry{Kind: KindID, ID: ID})
+	if err != nil {
+		return "", maskAny(err)
+	}
+
+	return ID, nil
+}
+
+func (s *recordingIDService) Service() servicespec.ServiceCollection {
+	return s.serviceCollection
+}
+
+// SetServiceCollection sets the given service collection for the recording ID
+// service. The wrapped ID service is given its own service collection instead.
+// There the random service is not recorded. Otherwise the random numbers drawn
+// to create IDs would be recorded as well, but not consumed when the IDs are
+// replayed.
+func (s *recordingIDService) SetServiceCollection(serviceCollection servicespec.ServiceCollection) {
+	s.serviceCollection = serviceCollection
+
+	collection := servicecollection.New()
+	collection.SetIDService(s)
+	collection.SetRandomService(s.randomService)
+	s.IDService.SetServiceCollection(collection)
+}
+
+func (s *recordingIDService) WithType(idType int) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ID, err := s.IDService.WithType(idType)
+	if err != nil {
+		return "", maskAny(err)
+	}
+	err = s.recorder.Record(Entry{Kind: KindID, ID: ID, IDType: idType})
+	if err != nil {
+		return "", maskAny(err)
+	}
+
+	return ID, nil
+}
+
+// IDService returns an ID service serving the IDs recorded by the journal. As
+// soon as the replay diverged, or the recorded IDs are used up, IDs are
+// created using the ID service the replayer was configured with.
+func (r *Replayer) IDService() servicespec.IDService {
+	return &replayIDService{
+		IDService: r.idService,
+		replayer:  r,
+	}
+}
+
+type replayIDService struct {
+	servicespec.IDService
+
+	replayer *Replayer
+}
+
+func (s *replayIDService) New() (string, error) {
+	ID, ok := s.replayer.id(0)
+	if !ok {
+		ID, err := s.IDService.New()
+		if err != nil {
+			return "", maskAny(err)
+		}
+
+		return ID, nil
+	}
+
+	return ID, nil
+}
+
+func (s *replayIDService) WithType(idType int) (string, error) {
+	ID, ok := s.replayer.id(idType)
+	if !ok {
+		ID, err := s.IDService.WithType(idType)
+		if err != nil {
+			return "", maskAny(err)
+		}
+
+		return ID, nil
+	}
+
+	return ID, nil
+}
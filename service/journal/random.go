@@ -0,0 +1,106 @@
+package journal
+
+import (
+	"sync"
+
+	servicespec "github.com/the-anna-project/annad/spec/service"
+)
+
+// RandomService returns a random service recording all random numbers the
+// given random service draws.
+func (r *Recorder) RandomService(randomService servicespec.RandomService) servicespec.RandomService {
+	return &recordingRandomService{
+		RandomService: randomService,
+		mutex:         sync.Mutex{},
+		recorder:      r,
+	}
+}
+
+type recordingRandomService struct {
+	servicespec.RandomService
+
+	// mutex makes drawing and recording random numbers atomic. That way
+	// concurrent draws are recorded in the order they were drawn. Note that this
+	// order is only reproducible as long as the network handles one input and
+	// one network event at a time, which the boot command ensures when
+	// recording.
+	mutex    sync.Mutex
+	recorder *Recorder
+}
+
+func (s *recordingRandomService) CreateMax(max int) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	n, err := s.RandomService.CreateMax(max)
+	if err != nil {
+		return 0, maskAny(err)
+	}
+	err = s.recorder.Record(Entry{Kind: KindRandom, Max: max, Numbers: []int{n}})
+	if err != nil {
+		return 0, maskAny(err)
+	}
+
+	return n, nil
+}
+
+func (s *recordingRandomService) CreateNMax(n, max int) ([]int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	numbers, err := s.RandomService.CreateNMax(n, max)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+	err = s.recorder.Record(Entry{Kind: KindRandom, Max: max, Numbers: numbers})
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	return numbers, nil
+}
+
+// RandomService returns a random service serving the random numbers recorded
+// by the journal. As soon as the replay diverged, or the recorded random
+// numbers are used up, random numbers are drawn using the random service the
+// replayer was configured with.
+func (r *Replayer) RandomService() servicespec.RandomService {
+	return &replayRandomService{
+		RandomService: r.randomService,
+		replayer:      r,
+	}
+}
+
+type replayRandomService struct {
+	servicespec.RandomService
+
+	replayer *Replayer
+}
+
+func (s *replayRandomService) CreateMax(max int) (int, error) {
+	numbers, ok := s.replayer.random(1, max)
+	if !ok {
+		n, err := s.RandomService.CreateMax(max)
+		if err != nil {
+			return 0, maskAny(err)
+		}
+
+		return n, nil
+	}
+
+	return numbers[0], nil
+}
+
+func (s *replayRandomService) CreateNMax(n, max int) ([]int, error) {
+	numbers, ok := s.replayer.random(n, max)
+	if !ok {
+		numbers, err := s.RandomService.CreateNMax(n, max)
+		if err != nil {
+			return nil, maskAny(err)
+		}
+
+		return numbers, nil
+	}
+
+	return numbers, nil
+}
@@ -0,0 +1,163 @@
+// Package journal implements the recording and replaying of network sessions.
+// A journal holds the flags the network was booted with, followed by the text
+// input the network received, the random numbers drawn, the IDs created and
+// the text output sent to the client, in the order they happened. Replaying a
+// journal feeds the recorded input into a new network, which uses the journal
+// as the source of random numbers and IDs, and checks that the same output is
+// produced.
+package journal
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	objectspec "github.com/the-anna-project/annad/spec/object"
+)
+
+// RecorderConfig represents the configuration used to create a new recorder.
+type RecorderConfig struct {
+	// Settings.
+
+	// Flags maps the names of the flags the recorded network was booted with to
+	// their values. They are written as the first entry of the journal.
+	Flags map[string]string
+
+	// Writer is the journal the recorder writes its entries to. In case it
+	// implements io.Closer, it is closed together with the recorder.
+	Writer io.Writer
+}
+
+// DefaultRecorderConfig provides a default configuration to create a new
+// recorder by best effort.
+func DefaultRecorderConfig() RecorderConfig {
+	newConfig := RecorderConfig{
+		// Settings.
+		Flags:  map[string]string{},
+		Writer: nil,
+	}
+
+	return newConfig
+}
+
+// NewRecorder creates a new recorder and writes the given flags to its
+// journal.
+func NewRecorder(config RecorderConfig) (*Recorder, error) {
+	// Settings.
+	if config.Flags == nil {
+		return nil, maskAnyf(invalidConfigError, "flags must not be empty")
+	}
+	if config.Writer == nil {
+		return nil, maskAnyf(invalidConfigError, "writer must not be empty")
+	}
+
+	newRecorder := &Recorder{
+		closed:  false,
+		encoder: json.NewEncoder(config.Writer),
+		mutex:   sync.Mutex{},
+		writer:  config.Writer,
+	}
+
+	err := newRecorder.Record(Entry{Kind: KindFlags, Flags: config.Flags})
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	return newRecorder, nil
+}
+
+// Recorder writes the entries of a journal. Random numbers and IDs are
+// recorded by the services returned by RandomService and IDService. Text input
+// and output are recorded by the network, which uses the recorder as its
+// journal. See network.Journal.
+type Recorder struct {
+	// closed is true as soon as the recorder was closed. Entries recorded
+	// afterwards are rejected.
+	closed  bool
+	encoder *json.Encoder
+	// mutex guards the encoder, so entries written concurrently do not
+	// interleave. It guards closed as well.
+	mutex  sync.Mutex
+	writer io.Writer
+}
+
+// Close closes the writer of the journal, if it is an io.Closer. Services
+// still running after the recorder was closed fail to record with
+// recorderClosedError.
+func (r *Recorder) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.closed = true
+
+	if c, ok := r.writer.(io.Closer); ok {
+		err := c.Close()
+		if err != nil {
+			return maskAny(err)
+		}
+	}
+
+	return nil
+}
+
+// Record writes the given entry to the journal.
+func (r *Recorder) Record(entry Entry) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return maskAny(recorderClosedError)
+	}
+
+	err := r.encoder.Encode(entry)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
+
+// RecordInput records the given text input. Echo requests, expectations,
+// retries, backoffs and deadlines are recorded as well, so the replayer is able
+// to send the same request. See network.Journal.
+func (r *Recorder) RecordInput(textInput objectspec.TextInput) error {
+	entry := Entry{
+		Kind:      KindInput,
+		Backoff:   textInput.Backoff(),
+		Deadline:  textInput.Deadline(),
+		Echo:      textInput.Echo(),
+		Input:     textInput.Input(),
+		Retries:   textInput.Retries(),
+		SessionID: textInput.SessionID(),
+	}
+	if expectation := textInput.Expectation(); expectation != nil {
+		entry.Expectation = expectation.GetOutput()
+		entry.Matcher = expectation.GetMatcher()
+		entry.Outputs = expectation.GetOutputs()
+		entry.Threshold = expectation.GetThreshold()
+	}
+
+	err := r.Record(entry)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
+
+// RecordOutput records the given text output sent to the client of the given
+// session. See network.Journal.
+func (r *Recorder) RecordOutput(sessionID string, textOutput objectspec.TextOutput) error {
+	entry := Entry{
+		Kind:      KindOutput,
+		Output:    textOutput.Output(),
+		SessionID: sessionID,
+	}
+
+	err := r.Record(entry)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
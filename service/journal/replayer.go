@@ -0,0 +1,239 @@
+package journal
+
+import (
+	"sync"
+	"time"
+
+	expectationobject "github.com/the-anna-project/annad/input/object/expectation"
+	textinputobject "github.com/the-anna-project/annad/input/object/text"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+)
+
+// ReplayerConfig represents the configuration used to create a new replayer.
+type ReplayerConfig struct {
+	// Dependencies.
+
+	// IDService and RandomService are used to create IDs and random numbers as
+	// soon as the replay diverged from the journal, or the recorded ones are
+	// used up.
+	IDService     servicespec.IDService
+	RandomService servicespec.RandomService
+
+	// Settings.
+
+	// Entries are the entries of the journal being replayed.
+	Entries []Entry
+
+	// Timeout is the duration the replayer waits for each recorded output.
+	Timeout time.Duration
+}
+
+// DefaultReplayerConfig provides a default configuration to create a new
+// replayer by best effort.
+func DefaultReplayerConfig() ReplayerConfig {
+	newConfig := ReplayerConfig{
+		// Dependencies.
+		IDService:     nil,
+		RandomService: nil,
+
+		// Settings.
+		Entries: nil,
+		Timeout: time.Minute,
+	}
+
+	return newConfig
+}
+
+// NewReplayer creates a new replayer.
+func NewReplayer(config ReplayerConfig) (*Replayer, error) {
+	// Dependencies.
+	if config.IDService == nil {
+		return nil, maskAnyf(invalidConfigError, "ID service must not be empty")
+	}
+	if config.RandomService == nil {
+		return nil, maskAnyf(invalidConfigError, "random service must not be empty")
+	}
+
+	// Settings.
+	if config.Timeout <= 0 {
+		return nil, maskAnyf(invalidConfigError, "timeout must be greater than 0")
+	}
+
+	newReplayer := &Replayer{
+		// Dependencies.
+		idService:     config.IDService,
+		randomService: config.RandomService,
+
+		// Settings.
+		cursors:    map[string]int{},
+		divergence: nil,
+		diverged:   make(chan struct{}),
+		entries:    config.Entries,
+		mutex:      sync.Mutex{},
+		timeout:    config.Timeout,
+	}
+
+	return newReplayer, nil
+}
+
+// Replayer replays the entries of a journal. The services returned by
+// RandomService and IDService serve the recorded random numbers and IDs in the
+// order they were recorded. Replay feeds the recorded input into the network
+// and checks the output against the recorded one. The first mismatch is the
+// divergence of the replay.
+type Replayer struct {
+	// Dependencies.
+
+	idService     servicespec.IDService
+	randomService servicespec.RandomService
+
+	// Settings.
+
+	// cursors maps entry kinds to the index of the entry to be checked next for
+	// the respective kind.
+	cursors    map[string]int
+	divergence error
+	// diverged is closed as soon as the replay diverged.
+	diverged chan struct{}
+	entries  []Entry
+	// mutex guards the cursors and the divergence.
+	mutex   sync.Mutex
+	timeout time.Duration
+}
+
+// Replay feeds the recorded input into the network of the given service
+// collection and checks that the recorded output is produced, in the order
+// both were recorded. The first divergence of the replay is returned as
+// divergenceError. Replay must be called after the given service collection
+// was booted using the services returned by RandomService and IDService.
+func (r *Replayer) Replay(serviceCollection servicespec.ServiceCollection) error {
+	for i, e := range r.entries {
+		switch e.Kind {
+		case KindInput:
+			textInput := textinputobject.New()
+			textInput.SetEcho(e.Echo)
+			if e.Expectation != "" {
+				expectation := expectationobject.New(e.Expectation)
+				expectation.SetMatcher(e.Matcher)
+				expectation.SetOutputs(e.Outputs)
+				expectation.SetThreshold(e.Threshold)
+				textInput.SetExpectation(expectation)
+			}
+			textInput.SetBackoff(e.Backoff)
+			textInput.SetDeadline(e.Deadline)
+			textInput.SetInput(e.Input)
+			textInput.SetRetries(e.Retries)
+			textInput.SetSessionID(e.SessionID)
+
+			select {
+			case <-r.diverged:
+				return maskAny(r.Divergence())
+			case serviceCollection.Input().Text().Channel() <- textInput:
+			}
+		case KindOutput:
+			select {
+			case <-r.diverged:
+				return maskAny(r.Divergence())
+			case textOutput := <-serviceCollection.Output().Text().Channel():
+				if textOutput.Output() != e.Output {
+					r.diverge("entry %d: expected output '%s', got '%s'", i+1, e.Output, textOutput.Output())
+					return maskAny(r.Divergence())
+				}
+			case <-time.After(r.timeout):
+				r.diverge("entry %d: expected output '%s', got none within %s", i+1, e.Output, r.timeout)
+				return maskAny(r.Divergence())
+			}
+		}
+	}
+
+	return maskAny(r.Divergence())
+}
+
+// Divergence returns the first divergence of the replay as divergenceError,
+// if any.
+func (r *Replayer) Divergence() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.divergence
+}
+
+// diverge records the divergence described by the given format and arguments,
+// unless the replay already diverged.
+func (r *Replayer) diverge(f string, v ...interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.divergeLocked(f, v...)
+}
+
+// divergeLocked is like diverge, but must only be called while holding mutex.
+func (r *Replayer) divergeLocked(f string, v ...interface{}) {
+	if r.divergence != nil {
+		return
+	}
+
+	r.divergence = maskAnyf(divergenceError, f, v...)
+	close(r.diverged)
+}
+
+// id returns the next recorded ID, which must have been created using the
+// given ID type. An ID type of 0 represents the default ID type. The returned
+// bool is false in case the replay diverged, or there is no ID left.
+func (r *Replayer) id(idType int) (string, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	i, e, ok := r.next(KindID)
+	if !ok {
+		return "", false
+	}
+	if e.IDType != idType {
+		r.divergeLocked("entry %d: expected ID of type %d, got ID of type %d", i+1, e.IDType, idType)
+		return "", false
+	}
+
+	return e.ID, true
+}
+
+// next returns the next entry of the given kind and its index. The returned
+// bool is false in case the replay diverged, or there is no entry of the given
+// kind left. Running out of entries is no divergence on its own. The recording
+// might have been stopped while the network was still busy. Services asking
+// for more then fall back to the services the replayer was configured with.
+// Divergent behaviour caused by that shows up in the output. next must only be
+// called while holding mutex.
+func (r *Replayer) next(kind string) (int, Entry, bool) {
+	if r.divergence != nil {
+		return 0, Entry{}, false
+	}
+
+	for i := r.cursors[kind]; i < len(r.entries); i++ {
+		if r.entries[i].Kind == kind {
+			r.cursors[kind] = i + 1
+			return i, r.entries[i], true
+		}
+	}
+	r.cursors[kind] = len(r.entries)
+
+	return 0, Entry{}, false
+}
+
+// random returns the next recorded random numbers, which must have been drawn
+// as n numbers below max. The returned bool is false in case the replay
+// diverged, or there are no random numbers left.
+func (r *Replayer) random(n, max int) ([]int, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	i, e, ok := r.next(KindRandom)
+	if !ok {
+		return nil, false
+	}
+	if len(e.Numbers) != n || e.Max != max {
+		r.divergeLocked("entry %d: expected %d random numbers below %d, got %d below %d", i+1, len(e.Numbers), e.Max, n, max)
+		return nil, false
+	}
+
+	return e.Numbers, true
+}
@@ -0,0 +1,293 @@
+package journal
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/the-anna-project/annad/id"
+	expectationobject "github.com/the-anna-project/annad/input/object/expectation"
+	textinputobject "github.com/the-anna-project/annad/input/object/text"
+	"github.com/the-anna-project/annad/random"
+	"github.com/the-anna-project/annad/service/matcher"
+	"github.com/the-anna-project/annad/service/network"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+	"github.com/the-anna-project/annad/testkit"
+)
+
+// testNetworkService creates a new network service recording its text input
+// and output using the given recorder. The network handles one input and one
+// network event at a time, so the random numbers and IDs are drawn in the same
+// order when replaying.
+func testNetworkService(t *testing.T, recorder *Recorder) servicespec.NetworkService {
+	newNetworkConfig := network.DefaultConfig()
+	newNetworkConfig.Journal = recorder
+	newNetworkConfig.WorkersEvent = 1
+	newNetworkConfig.WorkersInput = 1
+	newNetworkService, err := network.New(newNetworkConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	return newNetworkService
+}
+
+// testRecord sends each of the given text inputs to a new test kit and records
+// a journal of it. The test kit uses the default random and ID services, so
+// the recorded random numbers and IDs are not reproducible without the
+// journal.
+func testRecord(t *testing.T, textInputs ...objectspec.TextInput) []Entry {
+	var buf bytes.Buffer
+
+	newRecorderConfig := DefaultRecorderConfig()
+	newRecorderConfig.Flags = map[string]string{"network.budget.events": "10"}
+	newRecorderConfig.Writer = &buf
+	newRecorder, err := NewRecorder(newRecorderConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	randomService := random.New()
+	newConfig := testkit.DefaultConfig()
+	newConfig.IDService = newRecorder.IDService(id.New(), randomService)
+	newConfig.NetworkService = testNetworkService(t, newRecorder)
+	newConfig.RandomService = newRecorder.RandomService(randomService)
+	newKit, err := testkit.New(newConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	newKit.Boot()
+
+	// The output is recorded before it is sent, so it is part of the journal as
+	// soon as it is received.
+	for _, textInput := range textInputs {
+		newKit.PushInput(textInput)
+		_, err := newKit.WaitForOutput(time.Second)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	newKit.Shutdown()
+	err = newRecorder.Close()
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	entries, err := Read(&buf)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	return entries
+}
+
+// testEchoInputs creates an echo request for each of the given inputs.
+func testEchoInputs(inputs ...string) []objectspec.TextInput {
+	var textInputs []objectspec.TextInput
+	for _, input := range inputs {
+		textInputs = append(textInputs, testkit.NewTextInput(input, true))
+	}
+
+	return textInputs
+}
+
+// testReplay replays the given journal entries using a new test kit. The
+// replay is recorded as well and the entries of its journal are returned.
+func testReplay(t *testing.T, entries []Entry) ([]Entry, error) {
+	var buf bytes.Buffer
+
+	newRecorderConfig := DefaultRecorderConfig()
+	newRecorderConfig.Writer = &buf
+	newRecorder, err := NewRecorder(newRecorderConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	newReplayerConfig := DefaultReplayerConfig()
+	newReplayerConfig.Entries = entries
+	newReplayerConfig.IDService = id.New()
+	newReplayerConfig.RandomService = random.New()
+	newReplayerConfig.Timeout = time.Second
+	newReplayer, err := NewReplayer(newReplayerConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	newConfig := testkit.DefaultConfig()
+	newConfig.IDService = newReplayer.IDService()
+	newConfig.NetworkService = testNetworkService(t, newRecorder)
+	newConfig.RandomService = newReplayer.RandomService()
+	newKit, err := testkit.New(newConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	newKit.Boot()
+
+	replayErr := newReplayer.Replay(newKit.Service())
+
+	newKit.Shutdown()
+	err = newRecorder.Close()
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	replayed, err := Read(&buf)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	return replayed, replayErr
+}
+
+func Test_Journal_Record(t *testing.T) {
+	entries := testRecord(t, testEchoInputs("hello", "world")...)
+
+	if entries[0].Kind != KindFlags {
+		t.Fatal("expected", KindFlags, "got", entries[0].Kind)
+	}
+	if Flags(entries)["network.budget.events"] != "10" {
+		t.Fatal("expected", "10", "got", Flags(entries)["network.budget.events"])
+	}
+
+	var sequence []string
+	for _, e := range entries {
+		switch e.Kind {
+		case KindInput:
+			if !e.Echo || e.SessionID != testkit.SessionID {
+				t.Fatal("expected", "echo input of test kit session", "got", e)
+			}
+			sequence = append(sequence, fmt.Sprintf("%s:%s", e.Kind, e.Input))
+		case KindOutput:
+			sequence = append(sequence, fmt.Sprintf("%s:%s", e.Kind, e.Output))
+		case KindID:
+			if len(e.ID) != id.Hex128 {
+				t.Fatal("expected", id.Hex128, "got", len(e.ID))
+			}
+		}
+	}
+	expected := []string{"input:hello", "output:hello", "input:world", "output:world"}
+	if fmt.Sprint(sequence) != fmt.Sprint(expected) {
+		t.Fatal("expected", expected, "got", sequence)
+	}
+}
+
+func Test_Journal_Replay(t *testing.T) {
+	entries := testRecord(t, testEchoInputs("hello", "world")...)
+
+	_, err := testReplay(t, entries)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+}
+
+func Test_Journal_Replay_Budget(t *testing.T) {
+	// The echo request never meets its expectation. Thus the output CLG retries
+	// until the retries are exhausted. Echo requests cause a single network
+	// event at a time, so the retries draw their random numbers and IDs in the
+	// same order, no matter when their backoffs pass.
+	expectation := expectationobject.New("world")
+	expectation.SetMatcher(matcher.Exact)
+	textInput := textinputobject.New()
+	textInput.SetBackoff(time.Millisecond)
+	textInput.SetDeadline(time.Second)
+	textInput.SetEcho(true)
+	textInput.SetExpectation(expectation)
+	textInput.SetInput("hello")
+	textInput.SetRetries(2)
+	textInput.SetSessionID(testkit.SessionID)
+
+	entries := testRecord(t, textInput)
+
+	var inputs []Entry
+	for _, e := range entries {
+		if e.Kind == KindInput {
+			inputs = append(inputs, e)
+		}
+	}
+	expected := Entry{
+		Kind:        KindInput,
+		Backoff:     time.Millisecond,
+		Deadline:    time.Second,
+		Echo:        true,
+		Expectation: "world",
+		Input:       "hello",
+		Matcher:     matcher.Exact,
+		Retries:     2,
+		SessionID:   testkit.SessionID,
+	}
+	if len(inputs) != 1 || !reflect.DeepEqual(inputs[0], expected) {
+		t.Fatal("expected", expected, "got", inputs)
+	}
+
+	// The replay sends the recorded requests, including their retries, backoffs
+	// and deadlines. Thus recording the replay results in the same input and
+	// output.
+	replayed, err := testReplay(t, entries)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	var recorded, replayedSequence []Entry
+	for _, e := range entries {
+		if e.Kind == KindInput || e.Kind == KindOutput {
+			recorded = append(recorded, e)
+		}
+	}
+	for _, e := range replayed {
+		if e.Kind == KindInput || e.Kind == KindOutput {
+			replayedSequence = append(replayedSequence, e)
+		}
+	}
+	if !reflect.DeepEqual(replayedSequence, recorded) {
+		t.Fatal("expected", recorded, "got", replayedSequence)
+	}
+}
+
+func Test_Journal_Replay_Divergence_Output(t *testing.T) {
+	entries := testRecord(t, testEchoInputs("hello", "world")...)
+
+	var index int
+	for i, e := range entries {
+		if e.Kind == KindOutput && e.Output == "world" {
+			entries[i].Output = "goodbye"
+			index = i
+		}
+	}
+
+	_, err := testReplay(t, entries)
+	if !IsDivergence(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+	expected := fmt.Sprintf("divergence: entry %d: expected output 'goodbye', got 'world'", index+1)
+	if err.Error() != expected {
+		t.Fatal("expected", expected, "got", err.Error())
+	}
+}
+
+func Test_Journal_Replay_Divergence_Random(t *testing.T) {
+	entries := testRecord(t, testEchoInputs("hello")...)
+
+	// The first recorded ID is created by the random service while booting. A
+	// different ID type diverges right away.
+	for i, e := range entries {
+		if e.Kind == KindID {
+			entries[i].IDType = id.Hex512
+			break
+		}
+	}
+
+	_, err := testReplay(t, entries)
+	if !IsDivergence(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+func Test_Journal_Read_Error_Kind(t *testing.T) {
+	_, err := Read(bytes.NewBufferString(`{"kind":"flags"}` + "\n" + `{"kind":"unknown"}` + "\n"))
+	if !IsInvalidEntry(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
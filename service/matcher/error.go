@@ -0,0 +1,37 @@
+package matcher
+
+import (
+	"fmt"
+
+	"github.com/juju/errgo"
+)
+
+var (
+	maskAny = errgo.MaskFunc(errgo.Any)
+)
+
+func maskAnyf(err error, f string, v ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	f = fmt.Sprintf("%s: %s", err.Error(), f)
+	newErr := errgo.WithCausef(nil, errgo.Cause(err), f, v...)
+	newErr.(*errgo.Err).SetLocation(1)
+
+	return newErr
+}
+
+var invalidExpectationError = errgo.New("invalid expectation")
+
+// IsInvalidExpectation asserts invalidExpectationError.
+func IsInvalidExpectation(err error) bool {
+	return errgo.Cause(err) == invalidExpectationError
+}
+
+var matcherNotFoundError = errgo.New("matcher not found")
+
+// IsMatcherNotFound asserts matcherNotFoundError.
+func IsMatcherNotFound(err error) bool {
+	return errgo.Cause(err) == matcherNotFoundError
+}
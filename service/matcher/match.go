@@ -0,0 +1,139 @@
+package matcher
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	objectspec "github.com/the-anna-project/annad/spec/object"
+)
+
+// matched is the result of a matcher only telling apart matches and misses.
+func matched(ok bool) Result {
+	if ok {
+		return Result{Matched: true, Score: 1}
+	}
+
+	return Result{}
+}
+
+// MatchCaseInsensitive matches in case the output equals the expected output,
+// ignoring case.
+func MatchCaseInsensitive(expectation objectspec.Expectation, output string) (Result, error) {
+	return matched(strings.EqualFold(output, expectation.GetOutput())), nil
+}
+
+// MatchEditDistance matches in case the output can be turned into the expected
+// output using at most threshold edits. An edit inserts, deletes or
+// substitutes a single character. The score is the fraction of characters not
+// needing to be edited. The threshold must not be negative.
+func MatchEditDistance(expectation objectspec.Expectation, output string) (Result, error) {
+	threshold := expectation.GetThreshold()
+	if threshold < 0 {
+		return Result{}, maskAnyf(invalidExpectationError, "threshold must not be negative")
+	}
+
+	a, b := []rune(output), []rune(expectation.GetOutput())
+	distance := editDistance(a, b)
+
+	length := len(a)
+	if len(b) > length {
+		length = len(b)
+	}
+	score := 1.0
+	if length != 0 {
+		score = 1 - float64(distance)/float64(length)
+	}
+
+	return Result{Matched: float64(distance) <= threshold, Score: score}, nil
+}
+
+// MatchExact matches in case the output equals the expected output.
+func MatchExact(expectation objectspec.Expectation, output string) (Result, error) {
+	return matched(output == expectation.GetOutput()), nil
+}
+
+// MatchNumeric matches in case the output is a number differing from the
+// expected number by at most threshold. The score decreases the more the
+// difference exceeds the threshold. Output not being a number scores 0. The
+// expected output must be a number and the threshold must not be negative.
+func MatchNumeric(expectation objectspec.Expectation, output string) (Result, error) {
+	expected, err := strconv.ParseFloat(strings.TrimSpace(expectation.GetOutput()), 64)
+	if err != nil {
+		return Result{}, maskAnyf(invalidExpectationError, "output '%s' must be a number", expectation.GetOutput())
+	}
+	threshold := expectation.GetThreshold()
+	if threshold < 0 {
+		return Result{}, maskAnyf(invalidExpectationError, "threshold must not be negative")
+	}
+
+	calculated, err := strconv.ParseFloat(strings.TrimSpace(output), 64)
+	if err != nil {
+		return Result{}, nil
+	}
+
+	exceeded := math.Max(math.Abs(calculated-expected)-threshold, 0)
+
+	return Result{Matched: exceeded == 0, Score: 1 / (1 + exceeded)}, nil
+}
+
+// MatchOneOf matches in case the output equals the expected output or any of
+// the alternative outputs of the expectation.
+func MatchOneOf(expectation objectspec.Expectation, output string) (Result, error) {
+	if output == expectation.GetOutput() {
+		return matched(true), nil
+	}
+	for _, o := range expectation.GetOutputs() {
+		if output == o {
+			return matched(true), nil
+		}
+	}
+
+	return matched(false), nil
+}
+
+// MatchRegex matches in case the whole output matches the regular expression
+// given as expected output. The regular expression must be valid.
+func MatchRegex(expectation objectspec.Expectation, output string) (Result, error) {
+	re, err := regexp.Compile("^(?:" + expectation.GetOutput() + ")$")
+	if err != nil {
+		return Result{}, maskAnyf(invalidExpectationError, "%s", err)
+	}
+
+	return matched(re.MatchString(output)), nil
+}
+
+// editDistance returns the Levenshtein distance of the given strings.
+func editDistance(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minimum(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+// minimum returns the smallest of the given numbers.
+func minimum(n int, others ...int) int {
+	for _, o := range others {
+		if o < n {
+			n = o
+		}
+	}
+
+	return n
+}
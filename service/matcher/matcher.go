@@ -0,0 +1,117 @@
+// Package matcher implements checking output calculated by the neural network
+// against the expectation of a request. Matchers are registered by name. Each
+// request chooses the matcher used for its expectation. Each match results in
+// a score describing how close the output is to meeting the expectation, so
+// that near misses can be told apart from outputs being far off.
+package matcher
+
+import (
+	"fmt"
+	"sync"
+
+	objectspec "github.com/the-anna-project/annad/spec/object"
+)
+
+const (
+	// CaseInsensitive is the name of the matcher comparing output and expected
+	// output ignoring case. See MatchCaseInsensitive.
+	CaseInsensitive = "case-insensitive"
+	// EditDistance is the name of the matcher permitting a maximum number of
+	// edits between output and expected output. See MatchEditDistance.
+	EditDistance = "edit-distance"
+	// Exact is the name of the matcher comparing output and expected output
+	// exactly. It is used for expectations not naming any matcher. See
+	// MatchExact.
+	Exact = "exact"
+	// Numeric is the name of the matcher comparing numbers using a tolerance.
+	// See MatchNumeric.
+	Numeric = "numeric"
+	// OneOf is the name of the matcher accepting any of a set of expected
+	// outputs. See MatchOneOf.
+	OneOf = "one-of"
+	// Regex is the name of the matcher checking output against a regular
+	// expression. See MatchRegex.
+	Regex = "regex"
+)
+
+// Result represents the outcome of checking output against an expectation.
+type Result struct {
+	// Matched is true in case the output meets the expectation.
+	Matched bool
+	// Score rates how close the output is to meeting the expectation, ranging
+	// from 0, not close at all, to 1. Matchers only telling apart matches and
+	// misses score 1 for matches and 0 for misses.
+	Score float64
+}
+
+// Matcher checks the given output against the given expectation. Matchers
+// return an error asserted by IsInvalidExpectation in case the expectation
+// cannot be used by the matcher, regardless of the output. E.g. the expected
+// output of the regex matcher must be a valid regular expression.
+type Matcher func(expectation objectspec.Expectation, output string) (Result, error)
+
+var (
+	matcherMutex sync.RWMutex
+	matchers     = map[string]Matcher{
+		CaseInsensitive: MatchCaseInsensitive,
+		EditDistance:    MatchEditDistance,
+		Exact:           MatchExact,
+		Numeric:         MatchNumeric,
+		OneOf:           MatchOneOf,
+		Regex:           MatchRegex,
+	}
+)
+
+// Register makes the given matcher available to expectations using the given
+// name. Register panics in case the given matcher is nil or a matcher with the
+// given name is already registered.
+func Register(name string, matcher Matcher) {
+	matcherMutex.Lock()
+	defer matcherMutex.Unlock()
+
+	if matcher == nil {
+		panic(fmt.Sprintf("matcher '%s' must not be nil", name))
+	}
+	if _, ok := matchers[name]; ok {
+		panic(fmt.Sprintf("matcher '%s' already registered", name))
+	}
+
+	matchers[name] = matcher
+}
+
+// Match checks the given output against the given expectation using the
+// matcher the expectation names. Expectations not naming any matcher are
+// checked using the exact matcher. In case the named matcher is not
+// registered, an error asserted by IsMatcherNotFound is returned.
+func Match(expectation objectspec.Expectation, output string) (Result, error) {
+	name := expectation.GetMatcher()
+	if name == "" {
+		name = Exact
+	}
+
+	matcherMutex.RLock()
+	matcher, ok := matchers[name]
+	matcherMutex.RUnlock()
+	if !ok {
+		return Result{}, maskAnyf(matcherNotFoundError, "'%s'", name)
+	}
+
+	result, err := matcher(expectation, output)
+	if err != nil {
+		return Result{}, maskAny(err)
+	}
+
+	return result, nil
+}
+
+// Validate checks whether the given expectation can be used by the matcher it
+// names. This way invalid expectations are rejected before any output is
+// calculated for them.
+func Validate(expectation objectspec.Expectation) error {
+	_, err := Match(expectation, "")
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
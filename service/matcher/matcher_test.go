@@ -0,0 +1,192 @@
+package matcher
+
+import (
+	"testing"
+
+	expectationobject "github.com/the-anna-project/annad/input/object/expectation"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+)
+
+func testExpectation(matcher, output string, outputs []string, threshold float64) objectspec.Expectation {
+	expectation := expectationobject.New(output)
+	expectation.SetMatcher(matcher)
+	expectation.SetOutputs(outputs)
+	expectation.SetThreshold(threshold)
+
+	return expectation
+}
+
+func Test_Matcher_Match(t *testing.T) {
+	testCases := []struct {
+		Expectation objectspec.Expectation
+		Output      string
+		Expected    Result
+	}{
+		// The exact matcher is used in case the expectation does not name any.
+		{
+			Expectation: testExpectation("", "foo", nil, 0),
+			Output:      "foo",
+			Expected:    Result{Matched: true, Score: 1},
+		},
+		{
+			Expectation: testExpectation("", "foo", nil, 0),
+			Output:      "Foo",
+			Expected:    Result{},
+		},
+		{
+			Expectation: testExpectation(Exact, "foo", nil, 0),
+			Output:      "foo ",
+			Expected:    Result{},
+		},
+		{
+			Expectation: testExpectation(CaseInsensitive, "foo", nil, 0),
+			Output:      "FoO",
+			Expected:    Result{Matched: true, Score: 1},
+		},
+		{
+			Expectation: testExpectation(CaseInsensitive, "foo", nil, 0),
+			Output:      "bar",
+			Expected:    Result{},
+		},
+		// The regular expression must match the whole output.
+		{
+			Expectation: testExpectation(Regex, "[0-9]+|none", nil, 0),
+			Output:      "42",
+			Expected:    Result{Matched: true, Score: 1},
+		},
+		{
+			Expectation: testExpectation(Regex, "[0-9]+|none", nil, 0),
+			Output:      "42 apples",
+			Expected:    Result{},
+		},
+		{
+			Expectation: testExpectation(Numeric, "3.5", nil, 0.5),
+			Output:      " 3.9",
+			Expected:    Result{Matched: true, Score: 1},
+		},
+		{
+			Expectation: testExpectation(Numeric, "3", nil, 0),
+			Output:      "3.0",
+			Expected:    Result{Matched: true, Score: 1},
+		},
+		{
+			Expectation: testExpectation(Numeric, "2", nil, 1),
+			Output:      "4",
+			Expected:    Result{Score: 0.5},
+		},
+		{
+			Expectation: testExpectation(Numeric, "2", nil, 1),
+			Output:      "five",
+			Expected:    Result{},
+		},
+		{
+			Expectation: testExpectation(OneOf, "hi", []string{"hello", "hey"}, 0),
+			Output:      "hey",
+			Expected:    Result{Matched: true, Score: 1},
+		},
+		{
+			Expectation: testExpectation(OneOf, "hi", []string{"hello", "hey"}, 0),
+			Output:      "hi",
+			Expected:    Result{Matched: true, Score: 1},
+		},
+		{
+			Expectation: testExpectation(OneOf, "hi", []string{"hello", "hey"}, 0),
+			Output:      "howdy",
+			Expected:    Result{},
+		},
+		{
+			Expectation: testExpectation(EditDistance, "kitten", nil, 3),
+			Output:      "sitting",
+			Expected:    Result{Matched: true, Score: 1 - 3.0/7.0},
+		},
+		{
+			Expectation: testExpectation(EditDistance, "kitten", nil, 2),
+			Output:      "sitting",
+			Expected:    Result{Score: 1 - 3.0/7.0},
+		},
+		{
+			Expectation: testExpectation(EditDistance, "", nil, 0),
+			Output:      "",
+			Expected:    Result{Matched: true, Score: 1},
+		},
+		{
+			Expectation: testExpectation(EditDistance, "aß", nil, 1),
+			Output:      "as",
+			Expected:    Result{Matched: true, Score: 0.5},
+		},
+	}
+
+	for i, testCase := range testCases {
+		result, err := Match(testCase.Expectation, testCase.Output)
+		if err != nil {
+			t.Fatal("case", i+1, "expected", nil, "got", err)
+		}
+		if result != testCase.Expected {
+			t.Fatal("case", i+1, "expected", testCase.Expected, "got", result)
+		}
+	}
+}
+
+func Test_Matcher_Validate(t *testing.T) {
+	testCases := []struct {
+		Expectation  objectspec.Expectation
+		ErrorMatcher func(err error) bool
+	}{
+		{
+			Expectation:  testExpectation("", "foo", nil, 0),
+			ErrorMatcher: nil,
+		},
+		{
+			Expectation:  testExpectation("unknown", "foo", nil, 0),
+			ErrorMatcher: IsMatcherNotFound,
+		},
+		{
+			Expectation:  testExpectation(Regex, "[0-9", nil, 0),
+			ErrorMatcher: IsInvalidExpectation,
+		},
+		{
+			Expectation:  testExpectation(Numeric, "many", nil, 0),
+			ErrorMatcher: IsInvalidExpectation,
+		},
+		{
+			Expectation:  testExpectation(Numeric, "1", nil, -1),
+			ErrorMatcher: IsInvalidExpectation,
+		},
+		{
+			Expectation:  testExpectation(EditDistance, "foo", nil, -1),
+			ErrorMatcher: IsInvalidExpectation,
+		},
+	}
+
+	for i, testCase := range testCases {
+		err := Validate(testCase.Expectation)
+		if testCase.ErrorMatcher == nil {
+			if err != nil {
+				t.Fatal("case", i+1, "expected", nil, "got", err)
+			}
+		} else if !testCase.ErrorMatcher(err) {
+			t.Fatal("case", i+1, "expected", true, "got", false)
+		}
+	}
+}
+
+func Test_Matcher_Register(t *testing.T) {
+	Register("test-length", func(expectation objectspec.Expectation, output string) (Result, error) {
+		return matched(len(output) == len(expectation.GetOutput())), nil
+	})
+
+	result, err := Match(testExpectation("test-length", "foo", nil, 0), "bar")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if !result.Matched {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected", "panic", "got", nil)
+		}
+	}()
+	Register(Exact, MatchExact)
+}
@@ -0,0 +1,159 @@
+package network
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// addEventListener starts one additional event listener. Each event listener
+// has its own canceler, so it can be stopped individually using
+// removeEventListener.
+func (s *service) addEventListener() {
+	canceler := make(chan struct{}, 1)
+
+	s.eventListenerMutex.Lock()
+	select {
+	case <-s.closer:
+		// The network is shutting down. No event listener must be started
+		// anymore.
+		s.eventListenerMutex.Unlock()
+		return
+	default:
+	}
+	s.eventListeners = append(s.eventListeners, canceler)
+	s.eventListenerMutex.Unlock()
+
+	s.incrGauge(s.Service().Instrumentor().NewKey("network", "workers", "event", "gauge"), 1)
+
+	go func() {
+		// Create a new execute config for the worker service to execute the
+		// event listener.
+		executeConfig := s.Service().Worker().ExecuteConfig()
+		executeConfig.SetActions([]func(canceler <-chan struct{}) error{s.EventListener})
+		executeConfig.SetCanceler(canceler)
+		executeConfig.SetNumWorkers(1)
+		err := s.Service().Worker().Execute(executeConfig)
+		if err != nil && !IsWorkerCanceled(err) {
+			s.Service().Log().Line("msg", maskAny(err))
+		}
+	}()
+}
+
+// autoscaler is a worker pool function which periodically adjusts the number
+// of event listeners using desiredEventListeners.
+func (s *service) autoscaler(canceler <-chan struct{}) error {
+	ticker := time.NewTicker(s.workersAutoscaleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-canceler:
+			return maskAny(workerCanceledError)
+		case <-ticker.C:
+			// Fetch the measurements of the past interval and reset them for the
+			// next one.
+			var latency time.Duration
+			duration := atomic.SwapInt64(&s.eventHandlerDuration, 0)
+			count := atomic.SwapInt64(&s.eventHandlerCount, 0)
+			if count > 0 {
+				latency = time.Duration(duration / count)
+			}
+
+			current := s.numEventListeners()
+			desired := s.desiredEventListeners(current, len(s.events), latency)
+
+			for i := current; i < desired; i++ {
+				s.addEventListener()
+			}
+			for i := desired; i < current; i++ {
+				s.removeEventListener()
+			}
+		}
+	}
+}
+
+// desiredEventListeners calculates the number of event listeners the
+// autoscaler scales to. The decision is made based on the current number of
+// event listeners, the number of scheduled network events waiting to be
+// handled and the average latency of the event handler. The result is always
+// within the configured bounds.
+//
+//	backlog, fast handlers
+//
+//	    There are more network events waiting than event listeners and the
+//	    event handler is faster than WorkersAutoscaleLatency. The number of
+//	    event listeners is doubled.
+//
+//	backlog, slow handlers
+//
+//	    There are more network events waiting than event listeners, but the
+//	    event handler is slower than WorkersAutoscaleLatency. Adding more
+//	    event listeners might only cause more contention. Thus only one
+//	    event listener is added.
+//
+//	idle
+//
+//	    There are no network events waiting. One event listener is removed.
+func (s *service) desiredEventListeners(current, queueDepth int, latency time.Duration) int {
+	desired := current
+
+	if queueDepth > current {
+		if latency <= s.workersAutoscaleLatency {
+			desired = current * 2
+		} else {
+			desired = current + 1
+		}
+	} else if queueDepth == 0 {
+		desired = current - 1
+	}
+
+	if desired < s.workersAutoscaleMin {
+		desired = s.workersAutoscaleMin
+	}
+	if desired > s.workersAutoscaleMax {
+		desired = s.workersAutoscaleMax
+	}
+
+	return desired
+}
+
+// incrGauge increments the gauge identified by the given key by the given
+// delta, which might be negative to decrement the gauge. Errors are only
+// logged, because failing instrumentation must not affect the neural network.
+func (s *service) incrGauge(key string, delta float64) {
+	g, err := s.Service().Instrumentor().GetGauge(key)
+	if err != nil {
+		s.Service().Log().Line("msg", maskAny(err))
+		return
+	}
+	g.IncrBy(delta)
+}
+
+// numEventListeners returns the number of event listeners currently running.
+func (s *service) numEventListeners() int {
+	s.eventListenerMutex.Lock()
+	defer s.eventListenerMutex.Unlock()
+
+	return len(s.eventListeners)
+}
+
+// removeEventListener stops the event listener being started most recently.
+// The event listener finishes the network event it is currently handling, if
+// any. The returned bool is false in case there was no event listener left to
+// be stopped.
+func (s *service) removeEventListener() bool {
+	s.eventListenerMutex.Lock()
+	if len(s.eventListeners) == 0 {
+		s.eventListenerMutex.Unlock()
+		return false
+	}
+	canceler := s.eventListeners[len(s.eventListeners)-1]
+	s.eventListeners = s.eventListeners[:len(s.eventListeners)-1]
+	s.eventListenerMutex.Unlock()
+
+	close(canceler)
+
+	s.incrGauge(s.Service().Instrumentor().NewKey("network", "workers", "event", "gauge"), -1)
+
+	return true
+}
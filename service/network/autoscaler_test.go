@@ -0,0 +1,100 @@
+package network
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Network_desiredEventListeners(t *testing.T) {
+	newConfig := DefaultConfig()
+	newConfig.WorkersAutoscale = true
+	newConfig.WorkersAutoscaleLatency = 100 * time.Millisecond
+	newConfig.WorkersAutoscaleMax = 32
+	newConfig.WorkersAutoscaleMin = 2
+	newService, err := New(newConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	testCases := []struct {
+		Current    int
+		QueueDepth int
+		Latency    time.Duration
+		Expected   int
+	}{
+		// Backlog and fast handlers double the event listeners.
+		{
+			Current:    10,
+			QueueDepth: 100,
+			Latency:    10 * time.Millisecond,
+			Expected:   20,
+		},
+		// Backlog and slow handlers add one event listener.
+		{
+			Current:    10,
+			QueueDepth: 100,
+			Latency:    time.Second,
+			Expected:   11,
+		},
+		// Growing is bound to the maximum.
+		{
+			Current:    20,
+			QueueDepth: 100,
+			Latency:    10 * time.Millisecond,
+			Expected:   32,
+		},
+		// Idle event listeners are removed one by one.
+		{
+			Current:    10,
+			QueueDepth: 0,
+			Latency:    0,
+			Expected:   9,
+		},
+		// Shrinking is bound to the minimum.
+		{
+			Current:    2,
+			QueueDepth: 0,
+			Latency:    0,
+			Expected:   2,
+		},
+		// Event listeners keeping up with the scheduled network events are kept.
+		{
+			Current:    10,
+			QueueDepth: 5,
+			Latency:    time.Second,
+			Expected:   10,
+		},
+	}
+
+	for i, testCase := range testCases {
+		output := newService.(*service).desiredEventListeners(testCase.Current, testCase.QueueDepth, testCase.Latency)
+		if output != testCase.Expected {
+			t.Fatal("case", i+1, "expected", testCase.Expected, "got", output)
+		}
+	}
+}
+
+func Test_Network_New_Error_Workers(t *testing.T) {
+	newConfig := DefaultConfig()
+	newConfig.WorkersEvent = 0
+	_, err := New(newConfig)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	newConfig = DefaultConfig()
+	newConfig.WorkersInput = 0
+	_, err = New(newConfig)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	newConfig = DefaultConfig()
+	newConfig.WorkersAutoscale = true
+	newConfig.WorkersAutoscaleMax = 1
+	newConfig.WorkersAutoscaleMin = 2
+	_, err = New(newConfig)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
@@ -0,0 +1,569 @@
+package network
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/the-anna-project/annad/object/context"
+	"github.com/the-anna-project/annad/object/networkevent"
+	"github.com/the-anna-project/annad/object/networkpayload"
+	textoutputobject "github.com/the-anna-project/annad/output/object/text"
+	apispec "github.com/the-anna-project/annad/spec/api"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+)
+
+const (
+	// budgetExhaustedOutput is the terminal response sent to the client in case
+	// a CLG tree exhausted its budget without answering.
+	budgetExhaustedOutput = "no answer within budget"
+)
+
+// terminalResponse describes the response sent to the client in case a CLG
+// tree ends without having answered.
+type terminalResponse struct {
+	// Code is the API response code of the terminal response. An empty code
+	// sends the output like data calculated by the neural network.
+	Code string
+	// Output is the output sent to the client.
+	Output string
+	// Reason describes why the CLG tree ended, e.g. exceeded events.
+	Reason string
+
+	// Attempts and Score describe the best effort made to meet the expectation
+	// of the request, in case the CLG tree gave up on it. See exhaustBudget.
+	Attempts int
+	Score    float64
+}
+
+// budgetExceeded checks the given budget of a CLG tree against the given
+// point in time. In case the budget is exceeded, the name of the exceeded
+// limit is returned. Otherwise the returned string is empty.
+func budgetExceeded(budget map[string]string, now time.Time) (string, error) {
+	deadline, err := time.Parse(time.RFC3339Nano, budget["deadline"])
+	if err != nil {
+		return "", maskAny(err)
+	}
+	if !now.Before(deadline) {
+		return "deadline", nil
+	}
+
+	events, err := strconv.Atoi(budget["events"])
+	if err != nil {
+		return "", maskAny(err)
+	}
+	consumed, err := strconv.Atoi(budget["consumed"])
+	if err != nil {
+		return "", maskAny(err)
+	}
+	if consumed >= events {
+		return "events", nil
+	}
+
+	return "", nil
+}
+
+// consumeBudget consumes one network event of the budget of the CLG tree the
+// given network payload belongs to. In case the budget is exhausted, the CLG
+// tree ends and budgetExhaustedError is returned. Network events of CLG trees
+// which already ended are dropped the same way.
+func (s *service) consumeBudget(networkPayload objectspec.NetworkPayload) error {
+	clgTreeID, ok := networkPayload.GetContext().GetCLGTreeID()
+	if !ok {
+		return maskAnyf(invalidCLGTreeIDError, "must not be empty")
+	}
+
+	s.budgetMutex.Lock()
+	defer s.unlockBudget()
+
+	budgetKey := fmt.Sprintf("clg-tree-id:%s:budget", clgTreeID)
+	budget, err := s.Service().Storage().General().GetStringMap(budgetKey)
+	if err != nil {
+		return maskAny(err)
+	}
+	if len(budget) == 0 {
+		// The CLG tree was created without budget. There is nothing to consume.
+		return nil
+	}
+	if budget["ended"] == "true" {
+		return maskAnyf(budgetExhaustedError, "CLG tree '%s' already ended", clgTreeID)
+	}
+
+	exceeded, err := budgetExceeded(budget, time.Now())
+	if err != nil {
+		return maskAny(err)
+	}
+	if exceeded != "" {
+		err := s.endBudget(clgTreeID, budget["session-id"], exceeded)
+		if err != nil {
+			return maskAny(err)
+		}
+
+		return maskAnyf(budgetExhaustedError, "CLG tree '%s' exceeded its %s", clgTreeID, exceeded)
+	}
+
+	consumed, err := strconv.Atoi(budget["consumed"])
+	if err != nil {
+		return maskAny(err)
+	}
+	err = s.Service().Storage().General().SetStringMap(budgetKey, map[string]string{"consumed": strconv.Itoa(consumed + 1)})
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
+
+// endBudget marks the budget of the CLG tree identified by the given CLG tree
+// ID as ended, because the given limit of the budget was exceeded. See
+// endCLGTree.
+func (s *service) endBudget(clgTreeID, sessionID, exceeded string) error {
+	response := terminalResponse{
+		Code:   apispec.CodeBudgetExhausted,
+		Output: budgetExhaustedOutput,
+		Reason: fmt.Sprintf("exceeded %s", exceeded),
+	}
+	err := s.endCLGTree(clgTreeID, sessionID, response)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
+
+// endCLGTree marks the budget of the CLG tree identified by the given CLG tree
+// ID as ended. In case the CLG tree did not yet answer, its candidates are
+// sent to the client. Without candidates, the given terminal response is sent
+// instead. The end of the CLG tree is published using the bus service. The
+// terminal response is published using the network payload ID created by
+// newBudget. endCLGTree must only be called while holding budgetMutex.
+func (s *service) endCLGTree(clgTreeID, sessionID string, response terminalResponse) error {
+	budgetKey := fmt.Sprintf("clg-tree-id:%s:budget", clgTreeID)
+	err := s.Service().Storage().General().SetStringMap(budgetKey, map[string]string{"ended": "true"})
+	if err != nil {
+		return maskAny(err)
+	}
+
+	s.Service().Log().Line("msg", "CLG tree '%s' ended because it %s", clgTreeID, response.Reason)
+
+	// CLG trees having candidates gathered answer with them right away, instead
+	// of waiting for the window to pass. Only CLG trees which did not answer
+	// at all receive the terminal response.
+	answered, err := s.answerCandidates(clgTreeID, sessionID)
+	if err != nil {
+		return maskAny(err)
+	}
+	if !answered {
+		textOutputObject := textoutputobject.New()
+		textOutputObject.SetAttempts(response.Attempts)
+		textOutputObject.SetCode(response.Code)
+		textOutputObject.SetOutput(response.Output)
+		textOutputObject.SetScore(response.Score)
+
+		s.sendOutput(sessionID, textOutputObject)
+
+		// Subscribers of the bus learn about the terminal response the same way
+		// they learn about output sent by the output CLG.
+		ctx := context.MustNew()
+		ctx.SetCLGTreeID(clgTreeID)
+		ctx.SetSessionID(sessionID)
+
+		budget, err := s.Service().Storage().General().GetStringMap(budgetKey)
+		if err != nil {
+			return maskAny(err)
+		}
+		newNetworkPayloadConfig := networkpayload.DefaultConfig()
+		newNetworkPayloadConfig.Args = []reflect.Value{reflect.ValueOf(response.Output)}
+		newNetworkPayloadConfig.Context = ctx
+		newNetworkPayloadConfig.ID = budget["end-id"]
+		newNetworkPayloadConfig.Sources = []string{s.Metadata()["id"]}
+		newNetworkPayload, err := networkpayload.New(newNetworkPayloadConfig)
+		if err != nil {
+			return maskAny(err)
+		}
+		s.publishBudget(networkevent.KindOutputEmitted, newNetworkPayload)
+	}
+
+	// Subscribers like the tracker learn about the end of the CLG tree. CLG
+	// trees which did not answer carry an error.
+	newNetworkEventConfig := networkevent.DefaultConfig()
+	newNetworkEventConfig.CLGTreeID = clgTreeID
+	newNetworkEventConfig.Kind = networkevent.KindTreeFinished
+	newNetworkEventConfig.SessionID = sessionID
+	if !answered {
+		newNetworkEventConfig.Error = fmt.Sprintf("%s: %s", response.Output, response.Reason)
+	}
+	newNetworkEvent, err := networkevent.New(newNetworkEventConfig)
+	if err != nil {
+		return maskAny(err)
+	}
+	s.budgetNetworkEvents = append(s.budgetNetworkEvents, newNetworkEvent)
+
+	return nil
+}
+
+// exhaustBudget ends the CLG tree the given network payload belongs to, in
+// case it exceeded its retry limit. Then the client receives a retries
+// exhausted response carrying the output coming closest to the expectation, as
+// recorded by the output CLG, together with its score and the number of
+// attempts made to meet the expectation. exhaustBudget is called after the
+// output CLG failed, so the failure is published before the CLG tree ends.
+func (s *service) exhaustBudget(networkPayload objectspec.NetworkPayload) error {
+	clgTreeID, ok := networkPayload.GetContext().GetCLGTreeID()
+	if !ok {
+		return maskAnyf(invalidCLGTreeIDError, "must not be empty")
+	}
+
+	s.budgetMutex.Lock()
+	defer s.unlockBudget()
+
+	budgetKey := fmt.Sprintf("clg-tree-id:%s:budget", clgTreeID)
+	budget, err := s.Service().Storage().General().GetStringMap(budgetKey)
+	if err != nil {
+		return maskAny(err)
+	}
+	if len(budget) == 0 || budget["ended"] == "true" {
+		return nil
+	}
+
+	attempts, err := strconv.Atoi(budget["attempts"])
+	if err != nil {
+		return maskAny(err)
+	}
+	retries, err := strconv.Atoi(budget["retries"])
+	if err != nil {
+		return maskAny(err)
+	}
+	if attempts <= retries {
+		return nil
+	}
+
+	response := terminalResponse{
+		Attempts: attempts,
+		Code:     apispec.CodeRetriesExhausted,
+		Reason:   fmt.Sprintf("exceeded its limit of %d retries", retries),
+	}
+	matchesKey := fmt.Sprintf("clg-tree-id:%s:matches", clgTreeID)
+	result, err := s.Service().Storage().General().GetHighestScoredElements(matchesKey, 1)
+	if err != nil {
+		return maskAny(err)
+	}
+	if len(result) == 2 {
+		response.Output = result[0]
+		response.Score, err = strconv.ParseFloat(result[1], 64)
+		if err != nil {
+			return maskAny(err)
+		}
+	}
+	err = s.endCLGTree(clgTreeID, budget["session-id"], response)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
+
+// expireBudget ends the budget of the CLG tree identified by the given CLG
+// tree ID, if it did not already end. This is called as soon as the deadline
+// of the CLG tree passed. That way CLG trees not causing any further network
+// events end as well.
+func (s *service) expireBudget(clgTreeID string) error {
+	s.budgetMutex.Lock()
+	defer s.unlockBudget()
+
+	budgetKey := fmt.Sprintf("clg-tree-id:%s:budget", clgTreeID)
+	budget, err := s.Service().Storage().General().GetStringMap(budgetKey)
+	if err != nil {
+		return maskAny(err)
+	}
+	if len(budget) == 0 || budget["ended"] == "true" {
+		return nil
+	}
+
+	err = s.endBudget(clgTreeID, budget["session-id"], "deadline")
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
+
+// forwardBudget checks whether the budget of the CLG tree the given network
+// payload belongs to permits forwarding. Forwarding is not permitted in case
+// the CLG tree already ended, or the forwarded network payloads would exceed
+// the maximum depth of the CLG tree.
+func (s *service) forwardBudget(networkPayload objectspec.NetworkPayload) (bool, error) {
+	clgTreeID, ok := networkPayload.GetContext().GetCLGTreeID()
+	if !ok {
+		return false, maskAnyf(invalidCLGTreeIDError, "must not be empty")
+	}
+
+	budgetKey := fmt.Sprintf("clg-tree-id:%s:budget", clgTreeID)
+	budget, err := s.Service().Storage().General().GetStringMap(budgetKey)
+	if err != nil {
+		return false, maskAny(err)
+	}
+	if len(budget) == 0 {
+		return true, nil
+	}
+	if budget["ended"] == "true" {
+		return false, nil
+	}
+
+	depth, err := strconv.Atoi(budget["depth"])
+	if err != nil {
+		return false, maskAny(err)
+	}
+	if networkPayload.GetDepth() >= depth {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// meetBudget ends the CLG tree the given network payload belongs to, because
+// the output CLG calculated output meeting the expectation of the request.
+// The output CLG already answered, so there is no terminal response.
+func (s *service) meetBudget(networkPayload objectspec.NetworkPayload) error {
+	clgTreeID, ok := networkPayload.GetContext().GetCLGTreeID()
+	if !ok {
+		return maskAnyf(invalidCLGTreeIDError, "must not be empty")
+	}
+
+	s.budgetMutex.Lock()
+	defer s.unlockBudget()
+
+	budgetKey := fmt.Sprintf("clg-tree-id:%s:budget", clgTreeID)
+	budget, err := s.Service().Storage().General().GetStringMap(budgetKey)
+	if err != nil {
+		return maskAny(err)
+	}
+	if len(budget) == 0 || budget["ended"] == "true" {
+		return nil
+	}
+
+	err = s.endCLGTree(clgTreeID, budget["session-id"], terminalResponse{Reason: "met its expectation"})
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
+
+// newBudget writes the budget of the CLG tree identified by the given CLG
+// tree ID to the underlying storage. The budget limits the depth of the CLG
+// tree, the number of network events it may cause, the time it may take and
+// how often it is retried. The given text input may define its own deadline,
+// retry limit and backoff. Its session ID is kept to describe the CLG tree
+// when it ends. The IDs of the network payloads announcing the answer and the
+// end of the CLG tree are created right away, while the input worker handles
+// the text input. CLG trees may answer and end within timers, which must not
+// create IDs themselves. Otherwise the IDs created by the network workers
+// would depend on the time timers fire, which breaks the deterministic mode.
+// See answerCandidates and endCLGTree.
+func (s *service) newBudget(clgTreeID string, textInput objectspec.TextInput) error {
+	retries := s.retryLimit
+	if textInput.Retries() > 0 {
+		retries = textInput.Retries()
+	}
+	backoff := s.retryBackoff
+	if textInput.Backoff() > 0 {
+		backoff = textInput.Backoff()
+	}
+	deadline := s.budgetDeadline
+	if textInput.Deadline() > 0 {
+		deadline = textInput.Deadline()
+	}
+
+	answerID, err := s.Service().ID().New()
+	if err != nil {
+		return maskAny(err)
+	}
+	endID, err := s.Service().ID().New()
+	if err != nil {
+		return maskAny(err)
+	}
+
+	budgetKey := fmt.Sprintf("clg-tree-id:%s:budget", clgTreeID)
+	budget := map[string]string{
+		"answer-id":  answerID,
+		"attempts":   "0",
+		"backoff":    backoff.String(),
+		"consumed":   "0",
+		"deadline":   time.Now().Add(deadline).Format(time.RFC3339Nano),
+		"depth":      strconv.Itoa(s.budgetDepth),
+		"end-id":     endID,
+		"ended":      "false",
+		"events":     strconv.Itoa(s.budgetEvents),
+		"pending":    "0",
+		"retries":    strconv.Itoa(retries),
+		"session-id": textInput.SessionID(),
+	}
+	err = s.Service().Storage().General().SetStringMap(budgetKey, budget)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	time.AfterFunc(deadline, func() {
+		err := s.expireBudget(clgTreeID)
+		if err != nil {
+			s.Service().Log().Line("msg", maskAny(err))
+		}
+	})
+
+	return nil
+}
+
+// queueBudget counts the given network payload as pending within the budget
+// of the CLG tree it belongs to. See releaseBudget.
+func (s *service) queueBudget(networkPayload objectspec.NetworkPayload) error {
+	clgTreeID, ok := networkPayload.GetContext().GetCLGTreeID()
+	if !ok {
+		return maskAnyf(invalidCLGTreeIDError, "must not be empty")
+	}
+
+	s.budgetMutex.Lock()
+	defer s.unlockBudget()
+
+	budgetKey := fmt.Sprintf("clg-tree-id:%s:budget", clgTreeID)
+	budget, err := s.Service().Storage().General().GetStringMap(budgetKey)
+	if err != nil {
+		return maskAny(err)
+	}
+	if len(budget) == 0 || budget["ended"] == "true" {
+		return nil
+	}
+
+	pending, err := strconv.Atoi(budget["pending"])
+	if err != nil {
+		return maskAny(err)
+	}
+	err = s.Service().Storage().General().SetStringMap(budgetKey, map[string]string{"pending": strconv.Itoa(pending + 1)})
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
+
+// releaseBudget counts the given network payload as handled within the budget
+// of the CLG tree it belongs to. A frozen network never learns new paths. So
+// as soon as none of the network payloads of a CLG tree is pending anymore,
+// the CLG tree ends right away, instead of waiting for its deadline. In case
+// it did not answer, no learned path leads to the output.
+func (s *service) releaseBudget(networkPayload objectspec.NetworkPayload) error {
+	clgTreeID, ok := networkPayload.GetContext().GetCLGTreeID()
+	if !ok {
+		return maskAnyf(invalidCLGTreeIDError, "must not be empty")
+	}
+
+	s.budgetMutex.Lock()
+	defer s.unlockBudget()
+
+	budgetKey := fmt.Sprintf("clg-tree-id:%s:budget", clgTreeID)
+	budget, err := s.Service().Storage().General().GetStringMap(budgetKey)
+	if err != nil {
+		return maskAny(err)
+	}
+	if len(budget) == 0 || budget["ended"] == "true" {
+		return nil
+	}
+
+	pending, err := strconv.Atoi(budget["pending"])
+	if err != nil {
+		return maskAny(err)
+	}
+	if pending > 0 {
+		pending--
+	}
+	err = s.Service().Storage().General().SetStringMap(budgetKey, map[string]string{"pending": strconv.Itoa(pending)})
+	if err != nil {
+		return maskAny(err)
+	}
+
+	if pending > 0 || !s.Frozen() {
+		return nil
+	}
+
+	response := terminalResponse{
+		Code:   apispec.CodeNoLearnedPath,
+		Output: apispec.TextNoLearnedPath,
+		Reason: "has no pending network payloads left",
+	}
+	err = s.endCLGTree(clgTreeID, budget["session-id"], response)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
+
+// retryBackoff returns the delay before the given retry of a CLG tree, given
+// the delay before its first retry. The delay doubles with each retry, but
+// never exceeds the given maximum.
+func retryBackoff(backoff, backoffMax time.Duration, retry int) time.Duration {
+	delay := backoff
+	for i := 1; i < retry && delay < backoffMax; i++ {
+		delay *= 2
+	}
+	if delay > backoffMax {
+		delay = backoffMax
+	}
+
+	return delay
+}
+
+// retryBudget counts one more retry of the CLG tree the given network payload
+// belongs to and returns the delay before the retry. In case the CLG tree
+// already ended, or exceeds its retry limit, false is returned. See
+// exhaustBudget.
+func (s *service) retryBudget(networkPayload objectspec.NetworkPayload) (time.Duration, bool, error) {
+	clgTreeID, ok := networkPayload.GetContext().GetCLGTreeID()
+	if !ok {
+		return 0, false, maskAnyf(invalidCLGTreeIDError, "must not be empty")
+	}
+
+	s.budgetMutex.Lock()
+	defer s.unlockBudget()
+
+	budgetKey := fmt.Sprintf("clg-tree-id:%s:budget", clgTreeID)
+	budget, err := s.Service().Storage().General().GetStringMap(budgetKey)
+	if err != nil {
+		return 0, false, maskAny(err)
+	}
+	if len(budget) == 0 {
+		// The CLG tree was created without budget. Its retries are not limited.
+		return 0, true, nil
+	}
+	if budget["ended"] == "true" {
+		return 0, false, nil
+	}
+
+	// Each retry follows an output not meeting the expectation. So the attempts
+	// made to meet the expectation are counted here.
+	attempts, err := strconv.Atoi(budget["attempts"])
+	if err != nil {
+		return 0, false, maskAny(err)
+	}
+	attempts++
+	err = s.Service().Storage().General().SetStringMap(budgetKey, map[string]string{"attempts": strconv.Itoa(attempts)})
+	if err != nil {
+		return 0, false, maskAny(err)
+	}
+
+	retries, err := strconv.Atoi(budget["retries"])
+	if err != nil {
+		return 0, false, maskAny(err)
+	}
+	if attempts > retries {
+		return 0, false, nil
+	}
+
+	backoff, err := time.ParseDuration(budget["backoff"])
+	if err != nil {
+		return 0, false, maskAny(err)
+	}
+
+	return retryBackoff(backoff, s.retryBackoffMax, attempts), true, nil
+}
@@ -0,0 +1,334 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	kitlog "github.com/go-kit/kit/log"
+
+	servicecollection "github.com/the-anna-project/annad/collection/collection"
+	textinputobject "github.com/the-anna-project/annad/input/object/text"
+	memoryinstrumentor "github.com/the-anna-project/annad/instrumentor/memory"
+	"github.com/the-anna-project/annad/log"
+	"github.com/the-anna-project/annad/object/networkevent"
+	outputcollection "github.com/the-anna-project/annad/output/collection"
+	textoutputservice "github.com/the-anna-project/annad/output/service/text"
+	"github.com/the-anna-project/annad/service/bus"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+)
+
+// testCountingIDService counts the IDs created using the wrapped ID service.
+type testCountingIDService struct {
+	servicespec.IDService
+
+	count int
+}
+
+func (s *testCountingIDService) New() (string, error) {
+	s.count++
+
+	return s.IDService.New()
+}
+
+func Test_Network_budgetExceeded(t *testing.T) {
+	now := time.Now()
+
+	testCases := []struct {
+		Budget   map[string]string
+		Expected string
+	}{
+		{
+			Budget: map[string]string{
+				"consumed": "0",
+				"deadline": now.Add(time.Second).Format(time.RFC3339Nano),
+				"events":   "10",
+			},
+			Expected: "",
+		},
+		{
+			Budget: map[string]string{
+				"consumed": "9",
+				"deadline": now.Add(time.Second).Format(time.RFC3339Nano),
+				"events":   "10",
+			},
+			Expected: "",
+		},
+		{
+			Budget: map[string]string{
+				"consumed": "10",
+				"deadline": now.Add(time.Second).Format(time.RFC3339Nano),
+				"events":   "10",
+			},
+			Expected: "events",
+		},
+		{
+			Budget: map[string]string{
+				"consumed": "0",
+				"deadline": now.Format(time.RFC3339Nano),
+				"events":   "10",
+			},
+			Expected: "deadline",
+		},
+		{
+			Budget: map[string]string{
+				"consumed": "10",
+				"deadline": now.Add(-time.Second).Format(time.RFC3339Nano),
+				"events":   "10",
+			},
+			Expected: "deadline",
+		},
+	}
+
+	for i, testCase := range testCases {
+		output, err := budgetExceeded(testCase.Budget, now)
+		if err != nil {
+			t.Fatal("case", i+1, "expected", nil, "got", err)
+		}
+		if output != testCase.Expected {
+			t.Fatal("case", i+1, "expected", testCase.Expected, "got", output)
+		}
+	}
+}
+
+func Test_Network_budgetExceeded_Error(t *testing.T) {
+	_, err := budgetExceeded(map[string]string{}, time.Now())
+	if err == nil {
+		t.Fatal("expected", "error", "got", nil)
+	}
+}
+
+func Test_Network_retryBackoff(t *testing.T) {
+	testCases := []struct {
+		Backoff    time.Duration
+		BackoffMax time.Duration
+		Retry      int
+		Expected   time.Duration
+	}{
+		{Backoff: 10 * time.Millisecond, BackoffMax: time.Second, Retry: 1, Expected: 10 * time.Millisecond},
+		{Backoff: 10 * time.Millisecond, BackoffMax: time.Second, Retry: 2, Expected: 20 * time.Millisecond},
+		{Backoff: 10 * time.Millisecond, BackoffMax: time.Second, Retry: 4, Expected: 80 * time.Millisecond},
+		{Backoff: 10 * time.Millisecond, BackoffMax: time.Second, Retry: 8, Expected: time.Second},
+		{Backoff: 10 * time.Millisecond, BackoffMax: time.Second, Retry: 1000, Expected: time.Second},
+		{Backoff: 2 * time.Second, BackoffMax: time.Second, Retry: 1, Expected: time.Second},
+		{Backoff: 0, BackoffMax: time.Second, Retry: 5, Expected: 0},
+	}
+
+	for i, testCase := range testCases {
+		output := retryBackoff(testCase.Backoff, testCase.BackoffMax, testCase.Retry)
+		if output != testCase.Expected {
+			t.Fatal("case", i+1, "expected", testCase.Expected, "got", output)
+		}
+	}
+}
+
+func Test_Network_New_Error_Budget(t *testing.T) {
+	newConfig := DefaultConfig()
+	newConfig.BudgetDeadline = 0
+	_, err := New(newConfig)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	newConfig = DefaultConfig()
+	newConfig.BudgetDepth = 0
+	_, err = New(newConfig)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	newConfig = DefaultConfig()
+	newConfig.BudgetEvents = 0
+	_, err = New(newConfig)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+func Test_Network_New_Error_Retry(t *testing.T) {
+	newConfig := DefaultConfig()
+	newConfig.RetryBackoff = -1
+	_, err := New(newConfig)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	newConfig = DefaultConfig()
+	newConfig.RetryBackoffMax = newConfig.RetryBackoff - 1
+	_, err = New(newConfig)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	newConfig = DefaultConfig()
+	newConfig.RetryLimit = -1
+	_, err = New(newConfig)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+func Test_Network_unlockBudget(t *testing.T) {
+	newLogService := log.New()
+	newLogService.SetRootLogger(kitlog.NewNopLogger())
+
+	newBusConfig := bus.DefaultConfig()
+	newBusConfig.BufferSize = 1
+	newBusService, err := bus.New(newBusConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	newService, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	collection := servicecollection.New()
+	collection.SetBusService(newBusService)
+	collection.SetInstrumentorService(memoryinstrumentor.New())
+	collection.SetLogService(newLogService)
+	collection.SetNetworkService(newService)
+	collection.Bus().SetServiceCollection(collection)
+	collection.Log().SetServiceCollection(collection)
+	collection.Network().SetServiceCollection(collection)
+
+	// The blocking subscriber does not receive anything yet. Its buffer only
+	// fits the first network event.
+	networkEvents, err := collection.Bus().SubscribeBlocking("tracker")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	networkPayload := testNetworkPayload(t, "foo")
+	networkPayload.GetContext().SetCLGTreeID("tree-1")
+
+	s := newService.(*service)
+	s.budgetMutex.Lock()
+	s.publishBudget(networkevent.KindOutputEmitted, networkPayload)
+	s.publishBudget(networkevent.KindTreeFinished, networkPayload)
+	unlocked := make(chan struct{})
+	go func() {
+		s.unlockBudget()
+		close(unlocked)
+	}()
+
+	// Publishing the second network event waits for the blocking subscriber,
+	// but budgetMutex is already released.
+	locked := make(chan struct{})
+	go func() {
+		s.budgetMutex.Lock()
+		s.budgetMutex.Unlock()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+	case <-time.After(time.Second):
+		t.Fatal("expected", "budgetMutex to be released", "got", "timeout")
+	}
+	select {
+	case <-unlocked:
+		t.Fatal("expected", "publishing to wait", "got", "published")
+	default:
+	}
+
+	for _, kind := range []string{networkevent.KindOutputEmitted, networkevent.KindTreeFinished} {
+		networkEvent := <-networkEvents
+		if networkEvent.GetKind() != kind {
+			t.Fatal("expected", kind, "got", networkEvent.GetKind())
+		}
+	}
+	<-unlocked
+}
+
+func Test_Network_endCLGTree_IDs(t *testing.T) {
+	s, _, shutdown := testEventService(t, DefaultConfig(), nil)
+	defer shutdown()
+
+	newBusService, err := bus.New(bus.DefaultConfig())
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	s.Service().SetBusService(newBusService)
+	s.Service().Bus().SetServiceCollection(s.Service())
+	newOutputCollection := outputcollection.New()
+	newOutputCollection.SetTextService(textoutputservice.New())
+	s.Service().SetOutputCollection(newOutputCollection)
+	s.Service().Output().Text().SetServiceCollection(s.Service())
+	s.Service().Output().Text().Boot()
+
+	networkEvents, err := s.Service().Bus().Subscribe("test")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	// CLG trees answering with their candidates use the answer ID. All other CLG
+	// trees use the end ID.
+	testCases := []struct {
+		CLGTreeID   string
+		Candidates  bool
+		ExpectedKey string
+	}{
+		{
+			CLGTreeID:   "tree-1",
+			Candidates:  false,
+			ExpectedKey: "end-id",
+		},
+		{
+			CLGTreeID:   "tree-2",
+			Candidates:  true,
+			ExpectedKey: "answer-id",
+		},
+	}
+
+	for i, testCase := range testCases {
+		textInput := textinputobject.New()
+		textInput.SetDeadline(time.Hour)
+		textInput.SetSessionID("session-id")
+		err := s.newBudget(testCase.CLGTreeID, textInput)
+		if err != nil {
+			t.Fatal("case", i+1, "expected", nil, "got", err)
+		}
+		if testCase.Candidates {
+			b, err := json.Marshal(candidate{BehaviourIDs: []string{"behaviour-id"}, Score: 1})
+			if err != nil {
+				t.Fatal("case", i+1, "expected", nil, "got", err)
+			}
+			err = s.Service().Storage().General().SetStringMap(candidatesKey(testCase.CLGTreeID), map[string]string{"foo": string(b)})
+			if err != nil {
+				t.Fatal("case", i+1, "expected", nil, "got", err)
+			}
+		}
+		budget, err := s.Service().Storage().General().GetStringMap(fmt.Sprintf("clg-tree-id:%s:budget", testCase.CLGTreeID))
+		if err != nil {
+			t.Fatal("case", i+1, "expected", nil, "got", err)
+		}
+
+		// CLG trees ending within timers must not create IDs.
+		idService := &testCountingIDService{IDService: s.Service().ID()}
+		s.Service().SetIDService(idService)
+		s.budgetMutex.Lock()
+		err = s.endCLGTree(testCase.CLGTreeID, "session-id", terminalResponse{Reason: "expired"})
+		s.unlockBudget()
+		if err != nil {
+			t.Fatal("case", i+1, "expected", nil, "got", err)
+		}
+		if idService.count != 0 {
+			t.Fatal("case", i+1, "expected", 0, "got", idService.count)
+		}
+		s.Service().SetIDService(idService.IDService)
+
+		networkEvent := <-networkEvents
+		if networkEvent.GetKind() != networkevent.KindOutputEmitted {
+			t.Fatal("case", i+1, "expected", networkevent.KindOutputEmitted, "got", networkEvent.GetKind())
+		}
+		if networkEvent.GetNetworkPayload().GetID() != budget[testCase.ExpectedKey] {
+			t.Fatal("case", i+1, "expected", budget[testCase.ExpectedKey], "got", networkEvent.GetNetworkPayload().GetID())
+		}
+		networkEvent = <-networkEvents
+		if networkEvent.GetKind() != networkevent.KindTreeFinished {
+			t.Fatal("case", i+1, "expected", networkevent.KindTreeFinished, "got", networkEvent.GetKind())
+		}
+		<-s.Service().Output().Text().Channel()
+	}
+}
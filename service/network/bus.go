@@ -0,0 +1,53 @@
+package network
+
+import (
+	"time"
+
+	"github.com/the-anna-project/annad/object/networkevent"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+)
+
+// publish publishes a network event of the given kind being associated with
+// the given network payload using the bus service. The given duration and
+// error describe the stage of the network event, if any. Publishing never
+// fails the network event, so errors are only logged.
+func (s *service) publish(kind string, networkPayload objectspec.NetworkPayload, duration time.Duration, err error) {
+	newNetworkEventConfig := networkevent.NetworkPayloadConfig(kind, networkPayload)
+	newNetworkEventConfig.Duration = duration
+	if err != nil {
+		newNetworkEventConfig.Error = err.Error()
+	}
+	newNetworkEvent, err := networkevent.New(newNetworkEventConfig)
+	if err != nil {
+		s.Service().Log().Line("msg", maskAny(err))
+		return
+	}
+
+	s.Service().Bus().Publish(newNetworkEvent)
+}
+
+// publishBudget works like publish, but must only be called while holding
+// budgetMutex. The network event is published as soon as budgetMutex is
+// released. Publishing waits for blocking subscribers like the tracker, which
+// must not stall event listeners waiting for budgetMutex.
+func (s *service) publishBudget(kind string, networkPayload objectspec.NetworkPayload) {
+	newNetworkEvent, err := networkevent.New(networkevent.NetworkPayloadConfig(kind, networkPayload))
+	if err != nil {
+		s.Service().Log().Line("msg", maskAny(err))
+		return
+	}
+
+	s.budgetNetworkEvents = append(s.budgetNetworkEvents, newNetworkEvent)
+}
+
+// unlockBudget releases budgetMutex and publishes the network events published
+// while holding it, in the order they were published. See publishBudget.
+func (s *service) unlockBudget() {
+	networkEvents := s.budgetNetworkEvents
+	s.budgetNetworkEvents = nil
+	s.budgetMutex.Unlock()
+
+	for _, e := range networkEvents {
+		s.Service().Bus().Publish(e)
+	}
+}
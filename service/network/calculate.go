@@ -2,130 +2,84 @@ package network
 
 import (
 	"reflect"
+	"runtime/debug"
+	"time"
+
+	"github.com/the-anna-project/annad/object/networkpayload"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
 )
 
-// filterError removes the last element of the given list. Thus filterError
-// must only be used if the last element returned by a CLG implements the error
-// interface. In case the last element is a non-nil error, this error is
-// returned and the given list is discarded.
-func filterError(values []reflect.Value) ([]reflect.Value, error) {
-	if len(values) == 0 {
-		return nil, nil
+// execute calls the given CLG using the context and arguments of the given
+// network payload. Arguments not matching the input types of the CLG are
+// rejected by the CLG before its calculate function is called. The call is
+// sandboxed. A panic of the CLG is recovered and returned as clgPanicError
+// including the stack trace of the panic. A CLG not returning within
+// CLGTimeout causes clgTimeoutError to be returned. Note that Go does not
+// provide any way to stop the goroutine of a blocking CLG. It is abandoned and
+// its results are discarded as soon as it returns, if ever.
+func (s *service) execute(CLG servicespec.CLGService, networkPayload objectspec.NetworkPayload) ([]reflect.Value, error) {
+	type result struct {
+		outputs []reflect.Value
+		err     error
 	}
+	results := make(chan result, 1)
 
-	lastArg := values[len(values)-1]
-	if lastArg.Type().String() == "error" {
-		if lastArg.IsValid() && !lastArg.IsNil() {
-			switch lastArg.Kind() {
-			case reflect.Interface:
-				fallthrough
-			case reflect.Ptr:
-				if err, ok := lastArg.Interface().(error); ok {
-					return nil, err
-				}
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				results <- result{err: maskAnyf(clgPanicError, "%v\n%s", r, debug.Stack())}
 			}
-		} else {
-			return values[:len(values)-1], nil
+		}()
+
+		var args []interface{}
+		for _, v := range networkPayload.GetArgs() {
+			args = append(args, v.Interface())
 		}
-	}
+		outputs, err := CLG.Call(networkPayload.GetContext(), args)
+		if err != nil {
+			results <- result{err: err}
+			return
+		}
+
+		var values []reflect.Value
+		for _, o := range outputs {
+			values = append(values, reflect.ValueOf(o))
+		}
+		results <- result{outputs: values}
+	}()
 
-	return values, nil
+	select {
+	case r := <-results:
+		if r.err != nil {
+			return nil, maskAny(r.err)
+		}
+		return r.outputs, nil
+	case <-time.After(s.clgTimeout):
+		return nil, maskAnyf(clgTimeoutError, "CLG '%s' did not return within %s", CLG.Metadata()["kind"], s.clgTimeout)
+	}
 }
 
-// TODO test
-//
-// import (
-// 	"fmt"
-// 	"reflect"
-// 	"testing"
-// )
-//
-// func Test_CLG_filterError_noValues(t *testing.T) {
-// 	out, err := filterError(nil)
-// 	if err != nil {
-// 		t.Fatal("expected", nil, "got", err)
-// 	}
-//
-// 	if len(out) != 0 {
-// 		t.Fatal("expected", 2, "got", len(out))
-// 	}
-// }
-//
-// func Test_CLG_filterError_noError(t *testing.T) {
-// 	in := []reflect.Value{
-// 		reflect.ValueOf("one"),
-// 		reflect.ValueOf("two"),
-// 		reflect.ValueOf("three"),
-// 	}
-//
-// 	out, err := filterError(in)
-// 	if err != nil {
-// 		t.Fatal("expected", nil, "got", err)
-// 	}
-//
-// 	if len(out) != 2 {
-// 		t.Fatal("expected", 2, "got", len(out))
-// 	}
-// }
-//
-// func Test_CLG_filterError_nilError(t *testing.T) {
-// 	var err error
-// 	in := []reflect.Value{
-// 		reflect.ValueOf("one"),
-// 		reflect.ValueOf("two"),
-// 		reflect.ValueOf(err),
-// 	}
-//
-// 	out, err := filterError(in)
-// 	if err != nil {
-// 		t.Fatal("expected", nil, "got", err)
-// 	}
-//
-// 	if len(out) != 2 {
-// 		t.Fatal("expected", 2, "got", len(out))
-// 	}
-// }
-//
-// func Test_CLG_filterError_errgoError(t *testing.T) {
-// 	in := []reflect.Value{
-// 		reflect.ValueOf("one"),
-// 		reflect.ValueOf("two"),
-// 		reflect.ValueOf(invalidConfigError),
-// 	}
-//
-// 	_, err := filterError(in)
-// 	if !IsInvalidConfig(err) {
-// 		t.Fatal("expected", nil, "got", err)
-// 	}
-// }
-//
-// func Test_CLG_filterError_fmtError(t *testing.T) {
-// 	fmtErr := fmt.Errorf("test error")
-// 	in := []reflect.Value{
-// 		reflect.ValueOf("one"),
-// 		reflect.ValueOf("two"),
-// 		reflect.ValueOf(fmtErr),
-// 	}
-//
-// 	_, err := filterError(in)
-// 	if err == nil {
-// 		t.Fatal("expected", fmtErr, "got", nil)
-// 	}
-// 	msg := err.Error()
-// 	if msg != "test error" {
-// 		t.Fatal("expected", "test error", "got", msg)
-// 	}
-// }
-//
-// func Test_CLG_filterError_CLG(t *testing.T) {
-// 	in := []reflect.Value{
-// 		reflect.ValueOf("one"),
-// 		reflect.ValueOf("two"),
-// 		reflect.ValueOf(MustNew()),
-// 	}
-//
-// 	_, err := filterError(in)
-// 	if err != nil {
-// 		t.Fatal("expected", nil, "got", err)
-// 	}
-// }
+// newCalculatedPayload creates the network payload carrying the given outputs
+// of the CLG execution caused by the given network payload. Everything except
+// the arguments and the ID is taken from the given network payload.
+func (s *service) newCalculatedPayload(outputs []reflect.Value, networkPayload objectspec.NetworkPayload) (objectspec.NetworkPayload, error) {
+	networkPayloadID, err := s.Service().ID().New()
+	if err != nil {
+		return nil, maskAny(err)
+	}
+	newNetworkPayloadConfig := networkpayload.DefaultConfig()
+	newNetworkPayloadConfig.Args = outputs
+	newNetworkPayloadConfig.Context = networkPayload.GetContext()
+	newNetworkPayloadConfig.Depth = networkPayload.GetDepth()
+	newNetworkPayloadConfig.Destination = networkPayload.GetDestination()
+	newNetworkPayloadConfig.ID = networkPayloadID
+	newNetworkPayloadConfig.Path = networkPayload.GetPath()
+	newNetworkPayloadConfig.Sources = networkPayload.GetSources()
+	newNetworkPayload, err := networkpayload.New(newNetworkPayloadConfig)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	return newNetworkPayload, nil
+}
@@ -0,0 +1,193 @@
+package network
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/the-anna-project/annad/object/context"
+	"github.com/the-anna-project/annad/object/networkpayload"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+)
+
+type testCLG struct {
+	calculate interface{}
+	pure      bool
+}
+
+func (c *testCLG) Boot() {}
+
+// Call behaves like the adapters of CLGs. Arguments not matching
+// the input types are rejected before the calculate function is called.
+func (c *testCLG) Call(ctx objectspec.Context, args []interface{}) ([]interface{}, error) {
+	var argTypes []string
+	for _, a := range args {
+		argTypes = append(argTypes, reflect.TypeOf(a).String())
+	}
+	if !reflect.DeepEqual(argTypes, c.InputTypes()) {
+		return nil, fmt.Errorf("invalid arguments: expected %v, got %v", c.InputTypes(), argTypes)
+	}
+
+	inputs := []reflect.Value{reflect.ValueOf(ctx)}
+	for _, a := range args {
+		inputs = append(inputs, reflect.ValueOf(a))
+	}
+	var outputs []interface{}
+	for _, o := range reflect.ValueOf(c.calculate).Call(inputs) {
+		if err, ok := o.Interface().(error); ok {
+			return nil, err
+		} else if o.Type().String() == "error" {
+			continue
+		}
+		outputs = append(outputs, o.Interface())
+	}
+
+	return outputs, nil
+}
+
+func (c *testCLG) GetCalculate() interface{} {
+	return c.calculate
+}
+
+func (c *testCLG) InputTypes() []string {
+	t := reflect.TypeOf(c.calculate)
+
+	var types []string
+	for i := 1; i < t.NumIn(); i++ {
+		types = append(types, t.In(i).String())
+	}
+
+	return types
+}
+
+func (c *testCLG) Metadata() map[string]string {
+	return map[string]string{"kind": "test", "pure": strconv.FormatBool(c.pure)}
+}
+
+func (c *testCLG) OutputTypes() []string {
+	t := reflect.TypeOf(c.calculate)
+
+	var types []string
+	for i := 0; i < t.NumOut(); i++ {
+		if t.Out(i).String() != "error" {
+			types = append(types, t.Out(i).String())
+		}
+	}
+
+	return types
+}
+
+func (c *testCLG) Service() servicespec.ServiceCollection {
+	return nil
+}
+
+func (c *testCLG) SetServiceCollection(serviceCollection servicespec.ServiceCollection) {}
+
+func testNetworkPayload(t *testing.T, args ...interface{}) objectspec.NetworkPayload {
+	var values []reflect.Value
+	for _, a := range args {
+		values = append(values, reflect.ValueOf(a))
+	}
+
+	newNetworkPayloadConfig := networkpayload.DefaultConfig()
+	newNetworkPayloadConfig.Args = values
+	newNetworkPayloadConfig.Context = context.MustNew()
+	newNetworkPayload, err := networkpayload.New(newNetworkPayloadConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	return newNetworkPayload
+}
+
+func Test_Network_execute(t *testing.T) {
+	newService, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	CLG := &testCLG{
+		calculate: func(ctx objectspec.Context, a, b int) (int, error) {
+			return a + b, nil
+		},
+	}
+	outputs, err := newService.(*service).execute(CLG, testNetworkPayload(t, 3, 4))
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if len(outputs) != 1 || outputs[0].Int() != 7 {
+		t.Fatal("expected", 7, "got", outputs)
+	}
+}
+
+func Test_Network_execute_Error_Panic(t *testing.T) {
+	newService, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	// The CLG panics itself.
+	CLG := &testCLG{
+		calculate: func(ctx objectspec.Context) error {
+			panic("test panic")
+		},
+	}
+	_, err = newService.(*service).execute(CLG, testNetworkPayload(t))
+	if !IsCLGPanic(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+}
+
+func Test_Network_execute_Error_Arguments(t *testing.T) {
+	newService, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	// The CLG's interface is not satisfied by the network payload. The CLG
+	// rejects the arguments instead of panicking.
+	var called bool
+	CLG := &testCLG{
+		calculate: func(ctx objectspec.Context, a int) error {
+			called = true
+			return nil
+		},
+	}
+	_, err = newService.(*service).execute(CLG, testNetworkPayload(t, "foo"))
+	if err == nil {
+		t.Fatal("expected", "error", "got", nil)
+	}
+	if IsCLGPanic(err) {
+		t.Fatal("expected", false, "got", true)
+	}
+	if called {
+		t.Fatal("expected", false, "got", true)
+	}
+}
+
+func Test_Network_execute_Error_Timeout(t *testing.T) {
+	newConfig := DefaultConfig()
+	newConfig.CLGTimeout = 10 * time.Millisecond
+	newService, err := New(newConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	block := make(chan struct{})
+	defer close(block)
+
+	CLG := &testCLG{
+		calculate: func(ctx objectspec.Context) error {
+			<-block
+			return nil
+		},
+	}
+	_, err = newService.(*service).execute(CLG, testNetworkPayload(t))
+	if !IsCLGTimeout(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
@@ -0,0 +1,289 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	connectionservice "github.com/the-anna-project/annad/connection/service"
+	"github.com/the-anna-project/annad/object/context"
+	"github.com/the-anna-project/annad/object/networkevent"
+	"github.com/the-anna-project/annad/object/networkpayload"
+	textoutputobject "github.com/the-anna-project/annad/output/object/text"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+	storagecollection "github.com/the-anna-project/annad/storage/collection"
+)
+
+// candidate is the part of a candidate being stored for a CLG tree. The output
+// of the candidate is the field of the string map holding all candidates of
+// the CLG tree. See candidatesKey.
+type candidate struct {
+	BehaviourIDs []string `json:"behaviour_ids"`
+	Score        float64  `json:"score"`
+}
+
+// candidates orders candidates by score, highest first, and then by output.
+type candidates []objectspec.Candidate
+
+func (c candidates) Len() int      { return len(c) }
+func (c candidates) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+func (c candidates) Less(i, j int) bool {
+	if c[i].Score != c[j].Score {
+		return c[i].Score > c[j].Score
+	}
+	return c[i].Output < c[j].Output
+}
+
+// candidateScore returns the confidence in an output, given the connection
+// weights along the path which calculated the output and the success rate of
+// similar paths. Each weight w rates its hop by w/(1+w), so that unconnected
+// hops rate 0 and heavy connections approach 1. The mean rating of all hops is
+// scaled by the success rate. Paths without hops are rated by their success
+// rate only.
+func candidateScore(weights []float64, successRate float64) float64 {
+	if len(weights) == 0 {
+		return successRate
+	}
+
+	var sum float64
+	for _, w := range weights {
+		if w > 0 {
+			sum += w / (1 + w)
+		}
+	}
+
+	return sum / float64(len(weights)) * successRate
+}
+
+func candidatesKey(clgTreeID string) string {
+	return fmt.Sprintf("clg-tree-id:%s:candidates", clgTreeID)
+}
+
+// answerCandidates sends the candidates gathered for the CLG tree identified
+// by the given CLG tree ID to the client, ranked by their score. Only the
+// best candidates up to CandidatesLimit are sent. The output of the response
+// is the output of the best candidate. In case the CLG tree already answered,
+// nothing is sent again. The returned bool tells whether the CLG tree
+// answered. The answer is published using the network payload ID created by
+// newBudget. answerCandidates must only be called while holding budgetMutex.
+func (s *service) answerCandidates(clgTreeID, sessionID string) (bool, error) {
+	answeredKey := fmt.Sprintf("clg-tree-id:%s:answered", clgTreeID)
+	_, err := s.Service().Storage().General().Get(answeredKey)
+	if err == nil {
+		return true, nil
+	} else if !storagecollection.IsNotFound(err) {
+		return false, maskAny(err)
+	}
+
+	stored, err := s.Service().Storage().General().GetStringMap(candidatesKey(clgTreeID))
+	if err != nil {
+		return false, maskAny(err)
+	}
+	if len(stored) == 0 {
+		return false, nil
+	}
+
+	var ranked []objectspec.Candidate
+	for output, value := range stored {
+		var c candidate
+		err := json.Unmarshal([]byte(value), &c)
+		if err != nil {
+			return false, maskAny(err)
+		}
+		ranked = append(ranked, objectspec.Candidate{BehaviourIDs: c.BehaviourIDs, Output: output, Score: c.Score})
+	}
+	sort.Sort(candidates(ranked))
+	if len(ranked) > s.candidatesLimit {
+		ranked = ranked[:s.candidatesLimit]
+	}
+	best := ranked[0]
+
+	// Mark the CLG tree as answered. That way the network knows that the client
+	// already received output when the CLG tree ends.
+	err = s.Service().Storage().General().Set(answeredKey, "true")
+	if err != nil {
+		return false, maskAny(err)
+	}
+
+	textOutputObject := textoutputobject.New()
+	textOutputObject.SetCandidates(ranked)
+	textOutputObject.SetCLGTreeID(clgTreeID)
+	textOutputObject.SetOutput(best.Output)
+
+	s.sendOutput(sessionID, textOutputObject)
+
+	// Let subscribers of the bus know that the CLG tree answered. The network
+	// payload of the network event carries the output of the best candidate,
+	// as sent by the output CLG which calculated it.
+	behaviourID := best.BehaviourIDs[len(best.BehaviourIDs)-1]
+	ctx := context.MustNew()
+	ctx.SetBehaviourID(behaviourID)
+	ctx.SetCLGName("output")
+	ctx.SetCLGTreeID(clgTreeID)
+	ctx.SetSessionID(sessionID)
+
+	budgetKey := fmt.Sprintf("clg-tree-id:%s:budget", clgTreeID)
+	budget, err := s.Service().Storage().General().GetStringMap(budgetKey)
+	if err != nil {
+		return false, maskAny(err)
+	}
+	newNetworkPayloadConfig := networkpayload.DefaultConfig()
+	newNetworkPayloadConfig.Args = []reflect.Value{reflect.ValueOf(best.Output)}
+	newNetworkPayloadConfig.Context = ctx
+	newNetworkPayloadConfig.Destination = behaviourID
+	newNetworkPayloadConfig.ID = budget["answer-id"]
+	newNetworkPayloadConfig.Sources = []string{behaviourID}
+	newNetworkPayload, err := networkpayload.New(newNetworkPayloadConfig)
+	if err != nil {
+		return false, maskAny(err)
+	}
+	s.publishBudget(networkevent.KindOutputEmitted, newNetworkPayload)
+
+	return true, nil
+}
+
+// expireCandidates answers the CLG tree identified by the given CLG tree ID
+// with its candidates, as soon as the window for gathering them passed.
+func (s *service) expireCandidates(clgTreeID, sessionID string) error {
+	s.budgetMutex.Lock()
+	defer s.unlockBudget()
+
+	_, err := s.answerCandidates(clgTreeID, sessionID)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
+
+// gatherCandidate records the output calculated by the given output CLG for
+// the given network payload as candidate of its CLG tree. The first candidate
+// of a CLG tree opens the window for gathering further candidates. When the
+// window passed, or the CLG tree ends, the gathered candidates are sent to the
+// client. Candidates of CLG trees which already answered or ended are dropped.
+func (s *service) gatherCandidate(CLG servicespec.CLGService, networkPayload objectspec.NetworkPayload) error {
+	ctx := networkPayload.GetContext()
+	clgTreeID, ok := ctx.GetCLGTreeID()
+	if !ok {
+		return maskAnyf(invalidCLGTreeIDError, "must not be empty")
+	}
+	sessionID, _ := ctx.GetSessionID()
+
+	args := networkPayload.GetArgs()
+	if len(args) != 1 || args[0].Kind() != reflect.String {
+		return maskAnyf(invalidNetworkPayloadError, "output CLG must receive one string")
+	}
+	output := args[0].String()
+
+	score, behaviourIDs, err := s.scoreCandidate(CLG, networkPayload)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	s.budgetMutex.Lock()
+	defer s.unlockBudget()
+
+	budgetKey := fmt.Sprintf("clg-tree-id:%s:budget", clgTreeID)
+	budget, err := s.Service().Storage().General().GetStringMap(budgetKey)
+	if err != nil {
+		return maskAny(err)
+	}
+	if budget["ended"] == "true" {
+		return nil
+	}
+	answeredKey := fmt.Sprintf("clg-tree-id:%s:answered", clgTreeID)
+	_, err = s.Service().Storage().General().Get(answeredKey)
+	if err == nil {
+		return nil
+	} else if !storagecollection.IsNotFound(err) {
+		return maskAny(err)
+	}
+
+	// Multiple branches of the CLG tree may calculate the same output. Then the
+	// output is only a candidate once, using the best scored path.
+	stored, err := s.Service().Storage().General().GetStringMap(candidatesKey(clgTreeID))
+	if err != nil {
+		return maskAny(err)
+	}
+	if value, ok := stored[output]; ok {
+		var c candidate
+		err := json.Unmarshal([]byte(value), &c)
+		if err != nil {
+			return maskAny(err)
+		}
+		if c.Score >= score {
+			return nil
+		}
+	}
+	b, err := json.Marshal(candidate{BehaviourIDs: behaviourIDs, Score: score})
+	if err != nil {
+		return maskAny(err)
+	}
+	err = s.Service().Storage().General().SetStringMap(candidatesKey(clgTreeID), map[string]string{output: string(b)})
+	if err != nil {
+		return maskAny(err)
+	}
+
+	if len(stored) != 0 {
+		// The window was already opened by the first candidate.
+		return nil
+	}
+	if s.candidatesWindow == 0 {
+		_, err := s.answerCandidates(clgTreeID, sessionID)
+		if err != nil {
+			return maskAny(err)
+		}
+
+		return nil
+	}
+	time.AfterFunc(s.candidatesWindow, func() {
+		err := s.expireCandidates(clgTreeID, sessionID)
+		if err != nil {
+			s.Service().Log().Line("msg", maskAny(err))
+		}
+	})
+
+	return nil
+}
+
+// scoreCandidate returns the score of the output the given output CLG
+// calculated for the given network payload, together with the behaviour IDs
+// along the path which calculated it. See candidateScore.
+func (s *service) scoreCandidate(CLG servicespec.CLGService, networkPayload objectspec.NetworkPayload) (float64, []string, error) {
+	var behaviourIDs []string
+	behaviourIDs = append(behaviourIDs, networkPayload.GetPath()...)
+	behaviourIDs = append(behaviourIDs, networkPayload.GetDestination())
+
+	// Each hop between two behaviours weighs as much as the connection the
+	// tracker created between their peers within the behaviour layer.
+	// Behaviours not being connected weigh nothing.
+	var weights []float64
+	for i := 1; i < len(behaviourIDs); i++ {
+		var w float64
+		peerA := s.Service().Layer().Behaviour().PeerKey(behaviourIDs[i-1])
+		peerB := s.Service().Layer().Behaviour().PeerKey(behaviourIDs[i])
+		metadata, err := s.Service().Connection().Search(peerA, peerB)
+		if connectionservice.IsNotFound(err) {
+			// The behaviours are not connected.
+		} else if err != nil {
+			return 0, nil, maskAny(err)
+		} else {
+			w, err = strconv.ParseFloat(metadata["weight"], 64)
+			if err != nil {
+				return 0, nil, maskAny(err)
+			}
+		}
+		weights = append(weights, w)
+	}
+
+	successRate, err := s.Service().Tracker().PathSuccessRate(CLG, networkPayload)
+	if err != nil {
+		return 0, nil, maskAny(err)
+	}
+
+	return candidateScore(weights, successRate), behaviourIDs, nil
+}
@@ -0,0 +1,252 @@
+package network
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	kitlog "github.com/go-kit/kit/log"
+
+	servicecollection "github.com/the-anna-project/annad/collection/collection"
+	connectionservice "github.com/the-anna-project/annad/connection/service"
+	"github.com/the-anna-project/annad/id"
+	memoryinstrumentor "github.com/the-anna-project/annad/instrumentor/memory"
+	layercollection "github.com/the-anna-project/annad/layer/collection"
+	layerservice "github.com/the-anna-project/annad/layer/service"
+	"github.com/the-anna-project/annad/log"
+	"github.com/the-anna-project/annad/object/context"
+	"github.com/the-anna-project/annad/object/networkpayload"
+	peerservice "github.com/the-anna-project/annad/peer/service"
+	positionservice "github.com/the-anna-project/annad/position/service"
+	"github.com/the-anna-project/annad/random"
+	"github.com/the-anna-project/annad/service/behaviour"
+	"github.com/the-anna-project/annad/service/tracker"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	storagecollection "github.com/the-anna-project/annad/storage/collection"
+	memorystorage "github.com/the-anna-project/annad/storage/service/memory"
+	workerservice "github.com/the-anna-project/annad/worker/service"
+)
+
+func Test_Network_candidateScore(t *testing.T) {
+	testCases := []struct {
+		Weights     []float64
+		SuccessRate float64
+		Expected    float64
+	}{
+		// Paths without hops, like the ones of echo requests, are only rated by
+		// their success rate.
+		{
+			Weights:     nil,
+			SuccessRate: 0.5,
+			Expected:    0.5,
+		},
+		{
+			Weights:     []float64{1, 1},
+			SuccessRate: 1,
+			Expected:    0.5,
+		},
+		{
+			Weights:     []float64{1, 3},
+			SuccessRate: 0.5,
+			Expected:    0.3125,
+		},
+		// Unconnected hops weigh nothing.
+		{
+			Weights:     []float64{3, 0},
+			SuccessRate: 1,
+			Expected:    0.375,
+		},
+		{
+			Weights:     []float64{0, 0},
+			SuccessRate: 1,
+			Expected:    0,
+		},
+	}
+
+	for i, testCase := range testCases {
+		output := candidateScore(testCase.Weights, testCase.SuccessRate)
+		if output != testCase.Expected {
+			t.Fatal("case", i+1, "expected", testCase.Expected, "got", output)
+		}
+	}
+}
+
+func Test_Network_candidates(t *testing.T) {
+	ranked := []objectspec.Candidate{
+		{Output: "b", Score: 0.25},
+		{Output: "c", Score: 0.5},
+		{Output: "a", Score: 0.25},
+		{Output: "d", Score: 0.75},
+	}
+	sort.Sort(candidates(ranked))
+
+	expected := []objectspec.Candidate{
+		{Output: "d", Score: 0.75},
+		{Output: "c", Score: 0.5},
+		{Output: "a", Score: 0.25},
+		{Output: "b", Score: 0.25},
+	}
+	if !reflect.DeepEqual(ranked, expected) {
+		t.Fatal("expected", expected, "got", ranked)
+	}
+}
+
+func Test_Network_New_Error_Candidates(t *testing.T) {
+	newConfig := DefaultConfig()
+	newConfig.CandidatesLimit = 0
+	_, err := New(newConfig)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	newConfig = DefaultConfig()
+	newConfig.CandidatesWindow = -1
+	_, err = New(newConfig)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+// Test_Network_scoreCandidate ensures candidates are scored using the
+// connections the tracker creates while CLG trees are executed.
+func Test_Network_scoreCandidate(t *testing.T) {
+	newConnectionConfig := connectionservice.DefaultConfig()
+	newConnectionConfig.Weight = 1
+	newConnectionService, err := connectionservice.New(newConnectionConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	newLayerCollection := layercollection.New()
+	for _, kind := range []string{layerservice.KindBehaviour, layerservice.KindPosition} {
+		newLayerConfig := layerservice.DefaultConfig()
+		newLayerConfig.Kind = kind
+		newLayerService, err := layerservice.New(newLayerConfig)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		switch kind {
+		case layerservice.KindBehaviour:
+			newLayerCollection.SetBehaviourService(newLayerService)
+		case layerservice.KindPosition:
+			newLayerCollection.SetPositionService(newLayerService)
+		}
+	}
+
+	newLogService := log.New()
+	newLogService.SetRootLogger(kitlog.NewNopLogger())
+
+	newPositionConfig := positionservice.DefaultConfig()
+	newPositionConfig.DimensionCount = 3
+	newPositionConfig.DimensionDepth = 1000000
+	newPositionService, err := positionservice.New(newPositionConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	newService, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	newStorageCollection := storagecollection.New()
+	newStorageCollection.SetConnectionService(memorystorage.New())
+	newStorageCollection.SetGeneralService(memorystorage.New())
+	newStorageCollection.SetPeerService(memorystorage.New())
+
+	newTrackerService, err := tracker.New(tracker.DefaultConfig())
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	collection := servicecollection.New()
+	collection.SetBehaviourService(behaviour.New())
+	collection.SetConnectionService(newConnectionService)
+	collection.SetIDService(id.New())
+	collection.SetInstrumentorService(memoryinstrumentor.New())
+	collection.SetLayerCollection(newLayerCollection)
+	collection.SetLogService(newLogService)
+	collection.SetNetworkService(newService)
+	collection.SetPeerService(peerservice.New())
+	collection.SetPositionService(newPositionService)
+	collection.SetRandomService(random.New())
+	collection.SetStorageCollection(newStorageCollection)
+	collection.SetTrackerService(newTrackerService)
+	collection.SetWorkerService(workerservice.New())
+
+	collection.Behaviour().SetServiceCollection(collection)
+	collection.Connection().SetServiceCollection(collection)
+	collection.ID().SetServiceCollection(collection)
+	collection.Layer().Behaviour().SetServiceCollection(collection)
+	collection.Layer().Position().SetServiceCollection(collection)
+	collection.Log().SetServiceCollection(collection)
+	collection.Network().SetServiceCollection(collection)
+	collection.Peer().SetServiceCollection(collection)
+	collection.Position().SetServiceCollection(collection)
+	collection.Random().SetServiceCollection(collection)
+	collection.Storage().Connection().SetServiceCollection(collection)
+	collection.Storage().General().SetServiceCollection(collection)
+	collection.Storage().Peer().SetServiceCollection(collection)
+	collection.Tracker().SetServiceCollection(collection)
+	collection.Worker().SetServiceCollection(collection)
+
+	collection.Connection().Boot()
+	collection.Storage().Connection().Boot()
+	collection.Storage().General().Boot()
+	collection.Storage().Peer().Boot()
+	defer collection.Storage().Connection().Shutdown()
+	defer collection.Storage().General().Shutdown()
+	defer collection.Storage().Peer().Shutdown()
+
+	CLG := &testCLG{
+		calculate: func(ctx objectspec.Context, s string) string {
+			return s
+		},
+	}
+	err = collection.Behaviour().Create("behaviour-a", "test", "clg-tree-id")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	err = collection.Behaviour().Create("behaviour-b", "test", "clg-tree-id")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	ctx := context.MustNew()
+	ctx.SetCLGTreeID("clg-tree-id")
+
+	// Behaviour a forwarded to behaviour b, which is tracked.
+	newNetworkPayloadConfig := networkpayload.DefaultConfig()
+	newNetworkPayloadConfig.Args = []reflect.Value{reflect.ValueOf("hello")}
+	newNetworkPayloadConfig.Context = ctx
+	newNetworkPayloadConfig.Destination = "behaviour-b"
+	newNetworkPayloadConfig.Sources = []string{"behaviour-a"}
+	newNetworkPayload, err := networkpayload.New(newNetworkPayloadConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	err = collection.Tracker().Track(CLG, newNetworkPayload)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	// Behaviour b calculates a candidate having passed behaviour a.
+	newNetworkPayloadConfig.Path = []string{"behaviour-a"}
+	newNetworkPayload, err = networkpayload.New(newNetworkPayloadConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	score, behaviourIDs, err := newService.(*service).scoreCandidate(CLG, newNetworkPayload)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if !reflect.DeepEqual(behaviourIDs, []string{"behaviour-a", "behaviour-b"}) {
+		t.Fatal("expected", []string{"behaviour-a", "behaviour-b"}, "got", behaviourIDs)
+	}
+	// The path pattern of the candidate was never counted, so its success rate
+	// is unknown.
+	expected := candidateScore([]float64{1}, 0.5)
+	if score != expected {
+		t.Fatal("expected", expected, "got", score)
+	}
+}
@@ -0,0 +1,82 @@
+package network
+
+import (
+	"github.com/the-anna-project/annad/helper"
+	"github.com/the-anna-project/annad/object/networkpayload"
+	"github.com/the-anna-project/annad/service/behaviour"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+)
+
+// detectCycle checks whether the given network payload is about to enter a
+// behaviour it already passed within its CLG tree. To do so the destination of
+// the network payload is looked up in its path. Cycles are only permitted
+// along re-entry edges, which are configured in ReentryEdges. See isReentry.
+// When passing a re-entry edge, the path of the network payload starts over and
+// the new network payload is returned. All other cycles cause
+// cycleDetectedError to be returned and are counted by the instrumentor.
+func (s *service) detectCycle(networkPayload objectspec.NetworkPayload) (objectspec.NetworkPayload, error) {
+	behaviourID := networkPayload.GetDestination()
+	if !helper.ContainsString(networkPayload.GetPath(), behaviourID) {
+		return networkPayload, nil
+	}
+
+	ctx := networkPayload.GetContext()
+	clgName, ok := ctx.GetCLGName()
+	if !ok {
+		return nil, maskAnyf(invalidCLGNameError, "must not be empty")
+	}
+	reentry, err := s.isReentry(clgName, networkPayload)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+	if reentry {
+		// The network payload only starts its path over. It keeps its ID, because
+		// it replaces the given network payload.
+		newNetworkPayloadConfig := networkpayload.DefaultConfig()
+		newNetworkPayloadConfig.Args = networkPayload.GetArgs()
+		newNetworkPayloadConfig.Context = ctx
+		newNetworkPayloadConfig.Depth = networkPayload.GetDepth()
+		newNetworkPayloadConfig.Destination = networkPayload.GetDestination()
+		newNetworkPayloadConfig.ID = networkPayload.GetID()
+		newNetworkPayloadConfig.Path = nil
+		newNetworkPayloadConfig.Sources = networkPayload.GetSources()
+		newNetworkPayload, err := networkpayload.New(newNetworkPayloadConfig)
+		if err != nil {
+			return nil, maskAny(err)
+		}
+
+		return newNetworkPayload, nil
+	}
+
+	c, err := s.Service().Instrumentor().GetCounter(s.Service().Instrumentor().NewKey("network", "cycles", "counter", "total"))
+	if err != nil {
+		return nil, maskAny(err)
+	}
+	c.IncrBy(1)
+
+	clgTreeID, _ := ctx.GetCLGTreeID()
+
+	return nil, maskAnyf(cycleDetectedError, "behaviour ID '%s' of CLG '%s' already passed within CLG tree '%s'", behaviourID, clgName, clgTreeID)
+}
+
+// isReentry checks whether the given network payload, which is sent to a CLG
+// of the given name, travels along a re-entry edge. That is the case when the
+// network payload was sent by exactly one source, and the CLG of this source is
+// configured in ReentryEdges to re-enter the CLG of the destination. Merged
+// network payloads have multiple sources and thus never re-enter. Neither do
+// network payloads of unknown sources.
+func (s *service) isReentry(clgName string, networkPayload objectspec.NetworkPayload) (bool, error) {
+	sources := networkPayload.GetSources()
+	if len(sources) != 1 {
+		return false, nil
+	}
+
+	behaviourMetadata, err := s.Service().Behaviour().Search(sources[0])
+	if behaviour.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, maskAny(err)
+	}
+
+	return helper.ContainsString(s.reentryEdges[behaviourMetadata["clg-kind"]], clgName), nil
+}
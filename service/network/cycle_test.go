@@ -0,0 +1,193 @@
+package network
+
+import (
+	"testing"
+
+	kitlog "github.com/go-kit/kit/log"
+
+	servicecollection "github.com/the-anna-project/annad/collection/collection"
+	"github.com/the-anna-project/annad/id"
+	memoryinstrumentor "github.com/the-anna-project/annad/instrumentor/memory"
+	"github.com/the-anna-project/annad/log"
+	"github.com/the-anna-project/annad/object/context"
+	"github.com/the-anna-project/annad/object/networkpayload"
+	"github.com/the-anna-project/annad/random"
+	"github.com/the-anna-project/annad/service/behaviour"
+	storagecollection "github.com/the-anna-project/annad/storage/collection"
+	memorystorage "github.com/the-anna-project/annad/storage/service/memory"
+)
+
+func Test_Network_detectCycle(t *testing.T) {
+	newLogService := log.New()
+	newLogService.SetRootLogger(kitlog.NewNopLogger())
+
+	newService, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	newStorageCollection := storagecollection.New()
+	newStorageCollection.SetGeneralService(memorystorage.New())
+
+	collection := servicecollection.New()
+	collection.SetBehaviourService(behaviour.New())
+	collection.SetIDService(id.New())
+	collection.SetInstrumentorService(memoryinstrumentor.New())
+	collection.SetLogService(newLogService)
+	collection.SetNetworkService(newService)
+	collection.SetRandomService(random.New())
+	collection.SetStorageCollection(newStorageCollection)
+
+	collection.Behaviour().SetServiceCollection(collection)
+	collection.ID().SetServiceCollection(collection)
+	collection.Log().SetServiceCollection(collection)
+	collection.Network().SetServiceCollection(collection)
+	collection.Random().SetServiceCollection(collection)
+	collection.Storage().General().SetServiceCollection(collection)
+
+	collection.Storage().General().Boot()
+	defer collection.Storage().General().Shutdown()
+
+	// The CLG tree started with the input CLG, went through the sum CLG and
+	// reached the output CLG.
+	for behaviourID, clgKind := range map[string]string{"input-1": "input", "output-1": "output", "sum-1": "sum"} {
+		err := collection.Behaviour().Create(behaviourID, clgKind, "clg-tree-id")
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	testCases := []struct {
+		CLGName       string
+		Destination   string
+		Path          []string
+		Sources       []string
+		ExpectedPath  []string
+		ErrorExpected bool
+	}{
+		// There is no path, thus there is no cycle.
+		{
+			CLGName:       "sum",
+			Destination:   "sum-1",
+			Path:          nil,
+			Sources:       []string{"input-1"},
+			ExpectedPath:  nil,
+			ErrorExpected: false,
+		},
+		// The destination was not yet passed, thus there is no cycle.
+		{
+			CLGName:       "output",
+			Destination:   "output-1",
+			Path:          []string{"input-1", "sum-1"},
+			Sources:       []string{"sum-1"},
+			ExpectedPath:  []string{"input-1", "sum-1"},
+			ErrorExpected: false,
+		},
+		// The output CLG retries the CLG tree by re-entering the input CLG. That
+		// is a re-entry edge, thus the path starts over.
+		{
+			CLGName:       "input",
+			Destination:   "input-1",
+			Path:          []string{"input-1", "sum-1", "output-1"},
+			Sources:       []string{"output-1"},
+			ExpectedPath:  nil,
+			ErrorExpected: false,
+		},
+		// Echo requests start their CLG trees with the output CLG, which their
+		// retries re-enter.
+		{
+			CLGName:       "output",
+			Destination:   "output-1",
+			Path:          []string{"output-1"},
+			Sources:       []string{"output-1"},
+			ExpectedPath:  nil,
+			ErrorExpected: false,
+		},
+		// The cycle passes through the output CLG, but the output CLG does not
+		// re-enter the input CLG. Thus the cycle is detected.
+		{
+			CLGName:       "sum",
+			Destination:   "sum-1",
+			Path:          []string{"input-1", "sum-1", "output-1"},
+			Sources:       []string{"output-1"},
+			ExpectedPath:  nil,
+			ErrorExpected: true,
+		},
+		// The cycle enters the output CLG, but not from the output CLG. Thus the
+		// cycle is detected.
+		{
+			CLGName:       "output",
+			Destination:   "output-1",
+			Path:          []string{"input-1", "output-1", "sum-1"},
+			Sources:       []string{"sum-1"},
+			ExpectedPath:  nil,
+			ErrorExpected: true,
+		},
+		// The cycle enters the input CLG, but not from the output CLG. Thus the
+		// cycle is detected.
+		{
+			CLGName:       "input",
+			Destination:   "input-1",
+			Path:          []string{"input-1", "sum-1"},
+			Sources:       []string{"sum-1"},
+			ExpectedPath:  nil,
+			ErrorExpected: true,
+		},
+		// Merged network payloads do not travel along a single edge, even if one
+		// of their sources is the output CLG. Thus the cycle is detected.
+		{
+			CLGName:       "input",
+			Destination:   "input-1",
+			Path:          []string{"input-1", "sum-1", "output-1"},
+			Sources:       []string{"output-1", "sum-1"},
+			ExpectedPath:  nil,
+			ErrorExpected: true,
+		},
+		// Sources not being registered never re-enter.
+		{
+			CLGName:       "input",
+			Destination:   "input-1",
+			Path:          []string{"input-1", "output-2"},
+			Sources:       []string{"output-2"},
+			ExpectedPath:  nil,
+			ErrorExpected: true,
+		},
+	}
+
+	for i, testCase := range testCases {
+		ctx := context.MustNew()
+		ctx.SetCLGName(testCase.CLGName)
+		newNetworkPayloadConfig := networkpayload.DefaultConfig()
+		newNetworkPayloadConfig.Context = ctx
+		newNetworkPayloadConfig.Destination = testCase.Destination
+		newNetworkPayloadConfig.ID = "network-payload-id"
+		newNetworkPayloadConfig.Path = testCase.Path
+		newNetworkPayloadConfig.Sources = testCase.Sources
+		newNetworkPayload, err := networkpayload.New(newNetworkPayloadConfig)
+		if err != nil {
+			t.Fatal("case", i+1, "expected", nil, "got", err)
+		}
+
+		output, err := newService.(*service).detectCycle(newNetworkPayload)
+		if testCase.ErrorExpected {
+			if !IsCycleDetected(err) {
+				t.Fatal("case", i+1, "expected", true, "got", false)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal("case", i+1, "expected", nil, "got", err)
+		}
+		if output.GetID() != "network-payload-id" {
+			t.Fatal("case", i+1, "expected", "network-payload-id", "got", output.GetID())
+		}
+		if len(output.GetPath()) != len(testCase.ExpectedPath) {
+			t.Fatal("case", i+1, "expected", testCase.ExpectedPath, "got", output.GetPath())
+		}
+		for j, behaviourID := range output.GetPath() {
+			if behaviourID != testCase.ExpectedPath[j] {
+				t.Fatal("case", i+1, "expected", testCase.ExpectedPath, "got", output.GetPath())
+			}
+		}
+	}
+}
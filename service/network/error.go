@@ -43,6 +43,13 @@ func IsInvalidInterface(err error) bool {
 	return errgo.Cause(err) == invalidInterfaceError
 }
 
+var eventNotFoundError = errgo.New("event not found")
+
+// IsEventNotFound asserts eventNotFoundError.
+func IsEventNotFound(err error) bool {
+	return errgo.Cause(err) == eventNotFoundError
+}
+
 var invalidBehaviourIDError = errgo.New("invalid behaviour ID")
 
 // IsInvalidBehaviourID asserts invalidBehaviourIDError.
@@ -84,3 +91,38 @@ var workerCanceledError = errgo.New("worker canceled")
 func IsWorkerCanceled(err error) bool {
 	return errgo.Cause(err) == workerCanceledError
 }
+
+var budgetExhaustedError = errgo.New("budget exhausted")
+
+// IsBudgetExhausted asserts budgetExhaustedError.
+func IsBudgetExhausted(err error) bool {
+	return errgo.Cause(err) == budgetExhaustedError
+}
+
+var cycleDetectedError = errgo.New("cycle detected")
+
+// IsCycleDetected asserts cycleDetectedError.
+func IsCycleDetected(err error) bool {
+	return errgo.Cause(err) == cycleDetectedError
+}
+
+var clgPanicError = errgo.New("CLG panic")
+
+// IsCLGPanic asserts clgPanicError.
+func IsCLGPanic(err error) bool {
+	return errgo.Cause(err) == clgPanicError
+}
+
+var clgQuarantinedError = errgo.New("CLG quarantined")
+
+// IsCLGQuarantined asserts clgQuarantinedError.
+func IsCLGQuarantined(err error) bool {
+	return errgo.Cause(err) == clgQuarantinedError
+}
+
+var clgTimeoutError = errgo.New("CLG timeout")
+
+// IsCLGTimeout asserts clgTimeoutError.
+func IsCLGTimeout(err error) bool {
+	return errgo.Cause(err) == clgTimeoutError
+}
@@ -0,0 +1,50 @@
+package network
+
+import (
+	objectspec "github.com/the-anna-project/annad/spec/object"
+)
+
+// Journal records the text input a network receives and the text output it
+// sends. The network calls it synchronously on its input and output paths, so
+// the journal holds each input before the network acts on it, and each output
+// before the client receives it. See journal.Recorder.
+type Journal interface {
+	// RecordInput records the given text input.
+	RecordInput(textInput objectspec.TextInput) error
+	// RecordOutput records the given text output sent to the client of the
+	// given session.
+	RecordOutput(sessionID string, textOutput objectspec.TextOutput) error
+}
+
+// recordInput records the given text input, if a journal is configured.
+// Failing to record is logged, but does not affect the client.
+func (s *service) recordInput(textInput objectspec.TextInput) {
+	if s.journal == nil {
+		return
+	}
+
+	err := s.journal.RecordInput(textInput)
+	if err != nil {
+		s.Service().Log().Line("msg", "%#v", maskAny(err))
+	}
+}
+
+// sendOutput records the given text output of the given session, if a journal
+// is configured, and sends it to the client. Failing to record is logged, but
+// does not affect the client. The text output is sent asynchronously to not
+// block event listeners waiting for budgetMutex.
+func (s *service) sendOutput(sessionID string, textOutput objectspec.TextOutput) {
+	if s.journal != nil {
+		err := s.journal.RecordOutput(sessionID, textOutput)
+		if err != nil {
+			s.Service().Log().Line("msg", "%#v", maskAny(err))
+		}
+	}
+
+	go func() {
+		select {
+		case <-s.closer:
+		case s.Service().Output().Text().Channel() <- textOutput:
+		}
+	}()
+}
@@ -0,0 +1,146 @@
+package network
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"reflect"
+	"sync"
+
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+)
+
+// clgCache is a LRU cache holding the outputs of pure CLG executions. It is
+// safe for concurrent use.
+type clgCache struct {
+	elements map[string]*list.Element
+	list     *list.List
+	mutex    sync.Mutex
+	size     int
+}
+
+// clgCacheEntry is the value of each list element of a clgCache.
+type clgCacheEntry struct {
+	key     string
+	outputs []reflect.Value
+}
+
+// newCLGCache creates a new clgCache holding up to size entries.
+func newCLGCache(size int) *clgCache {
+	return &clgCache{
+		elements: map[string]*list.Element{},
+		list:     list.New(),
+		size:     size,
+	}
+}
+
+// Add adds the given outputs to the cache using the given key. In case the
+// cache is full, the least recently used entry is evicted.
+func (c *clgCache) Add(key string, outputs []reflect.Value) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if e, ok := c.elements[key]; ok {
+		e.Value.(*clgCacheEntry).outputs = outputs
+		c.list.MoveToFront(e)
+		return
+	}
+
+	c.elements[key] = c.list.PushFront(&clgCacheEntry{key: key, outputs: outputs})
+
+	if c.list.Len() > c.size {
+		e := c.list.Back()
+		c.list.Remove(e)
+		delete(c.elements, e.Value.(*clgCacheEntry).key)
+	}
+}
+
+// Get returns the outputs cached using the given key, if any. The entry is
+// marked as being used most recently.
+func (c *clgCache) Get(key string) ([]reflect.Value, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	e, ok := c.elements[key]
+	if !ok {
+		return nil, false
+	}
+	c.list.MoveToFront(e)
+
+	return e.Value.(*clgCacheEntry).outputs, true
+}
+
+// Len returns the number of entries currently cached.
+func (c *clgCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.list.Len()
+}
+
+// cacheKey returns the key used to cache the outputs of the given CLG being
+// executed using the arguments of the given network payload. The returned
+// bool is false in case the execution must not be cached. That is the case
+// when the cache is disabled, the CLG is not marked as pure by its metadata,
+// or the arguments cannot be encoded reliably.
+func (s *service) cacheKey(CLG servicespec.CLGService, networkPayload objectspec.NetworkPayload) (string, bool) {
+	if s.clgCache == nil || CLG.Metadata()["pure"] != "true" {
+		return "", false
+	}
+
+	return encodeCLGArgs(CLG.Metadata()["kind"], networkPayload.GetArgs())
+}
+
+// countCache increments the counter of the given cache result of the CLG
+// identified by the given CLG name. The result is either hits or misses.
+func (s *service) countCache(clgName, result string) {
+	c, err := s.Service().Instrumentor().GetCounter(s.Service().Instrumentor().NewKey("network", "clg", clgName, "cache", result, "counter", "total"))
+	if err != nil {
+		s.Service().Log().Line("msg", maskAny(err))
+		return
+	}
+	c.IncrBy(1)
+}
+
+// encodeCLGArgs encodes the given CLG kind and arguments into a cache key.
+// Each argument is encoded together with its type, so 1 and 1.0 do not share
+// the same cache key. Only arguments of scalar kinds and slices of them are
+// encoded. Everything else might be mutable or lacks a stable representation
+// and causes the returned bool to be false.
+func encodeCLGArgs(clgKind string, args []reflect.Value) (string, bool) {
+	var buf bytes.Buffer
+	buf.WriteString(clgKind)
+
+	for _, a := range args {
+		if !isEncodable(a) {
+			return "", false
+		}
+		fmt.Fprintf(&buf, "|%T:%#v", a.Interface(), a.Interface())
+	}
+
+	return buf.String(), true
+}
+
+func isEncodable(value reflect.Value) bool {
+	if !value.IsValid() {
+		return false
+	}
+
+	switch value.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			if !isEncodable(value.Index(i)) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
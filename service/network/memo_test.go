@@ -0,0 +1,150 @@
+package network
+
+import (
+	"reflect"
+	"testing"
+
+	kitlog "github.com/go-kit/kit/log"
+
+	servicecollection "github.com/the-anna-project/annad/collection/collection"
+	"github.com/the-anna-project/annad/id"
+	memoryinstrumentor "github.com/the-anna-project/annad/instrumentor/memory"
+	"github.com/the-anna-project/annad/log"
+	"github.com/the-anna-project/annad/random"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+)
+
+func Test_Network_clgCache(t *testing.T) {
+	newCache := newCLGCache(2)
+
+	newCache.Add("a", []reflect.Value{reflect.ValueOf(1)})
+	newCache.Add("b", []reflect.Value{reflect.ValueOf(2)})
+
+	// Using a makes b the least recently used entry, which is evicted when c is
+	// added.
+	if _, ok := newCache.Get("a"); !ok {
+		t.Fatal("expected", true, "got", false)
+	}
+	newCache.Add("c", []reflect.Value{reflect.ValueOf(3)})
+
+	if newCache.Len() != 2 {
+		t.Fatal("expected", 2, "got", newCache.Len())
+	}
+	if _, ok := newCache.Get("b"); ok {
+		t.Fatal("expected", false, "got", true)
+	}
+	outputs, ok := newCache.Get("c")
+	if !ok {
+		t.Fatal("expected", true, "got", false)
+	}
+	if len(outputs) != 1 || outputs[0].Int() != 3 {
+		t.Fatal("expected", 3, "got", outputs)
+	}
+}
+
+func Test_Network_encodeCLGArgs(t *testing.T) {
+	testCases := []struct {
+		Args     []interface{}
+		Expected string
+		OK       bool
+	}{
+		{
+			Args:     nil,
+			Expected: "sum",
+			OK:       true,
+		},
+		{
+			Args:     []interface{}{3.0, 4.0},
+			Expected: "sum|float64:3|float64:4",
+			OK:       true,
+		},
+		{
+			Args:     []interface{}{3, 4.0},
+			Expected: "sum|int:3|float64:4",
+			OK:       true,
+		},
+		{
+			Args:     []interface{}{"a|b", []string{"c"}},
+			Expected: `sum|string:"a|b"|[]string:[]string{"c"}`,
+			OK:       true,
+		},
+		{
+			Args:     []interface{}{map[string]string{}},
+			Expected: "",
+			OK:       false,
+		},
+		{
+			Args:     []interface{}{&struct{}{}},
+			Expected: "",
+			OK:       false,
+		},
+	}
+
+	for i, testCase := range testCases {
+		var values []reflect.Value
+		for _, a := range testCase.Args {
+			values = append(values, reflect.ValueOf(a))
+		}
+
+		output, ok := encodeCLGArgs("sum", values)
+		if ok != testCase.OK {
+			t.Fatal("case", i+1, "expected", testCase.OK, "got", ok)
+		}
+		if output != testCase.Expected {
+			t.Fatal("case", i+1, "expected", testCase.Expected, "got", output)
+		}
+	}
+}
+
+func Test_Network_Calculate_Memoization(t *testing.T) {
+	newLogService := log.New()
+	newLogService.SetRootLogger(kitlog.NewNopLogger())
+
+	newService, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	collection := servicecollection.New()
+	collection.SetIDService(id.New())
+	collection.SetInstrumentorService(memoryinstrumentor.New())
+	collection.SetLogService(newLogService)
+	collection.SetNetworkService(newService)
+	collection.SetRandomService(random.New())
+	collection.ID().SetServiceCollection(collection)
+	collection.Log().SetServiceCollection(collection)
+	collection.Network().SetServiceCollection(collection)
+	collection.Random().SetServiceCollection(collection)
+
+	for _, pure := range []bool{true, false} {
+		var calls int
+		CLG := &testCLG{
+			calculate: func(ctx objectspec.Context, a, b float64) float64 {
+				calls++
+				return a + b
+			},
+			pure: pure,
+		}
+
+		for i := 0; i < 3; i++ {
+			newNetworkPayload, err := newService.Calculate(CLG, testNetworkPayload(t, float64(i%2), 4.0))
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			args := newNetworkPayload.GetArgs()
+			if len(args) != 1 || args[0].Float() != float64(i%2)+4 {
+				t.Fatal("expected", float64(i%2)+4, "got", args)
+			}
+		}
+
+		// The pure CLG is only executed once for each distinct set of
+		// arguments. The impure CLG is executed every time.
+		expected := 3
+		if pure {
+			expected = 2
+		}
+		if calls != expected {
+			t.Fatal("expected", expected, "got", calls)
+		}
+	}
+}
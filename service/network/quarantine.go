@@ -0,0 +1,95 @@
+package network
+
+import (
+	"fmt"
+	"time"
+
+	storagecollection "github.com/the-anna-project/annad/storage/collection"
+)
+
+// failCLG tracks the given failure of the CLG identified by the given CLG
+// name. Each failure is counted by the instrumentor. A CLG failing
+// CLGQuarantineThreshold times in a row is quarantined for
+// CLGQuarantineDuration.
+func (s *service) failCLG(clgName string, failure error) error {
+	var kind string
+	if IsCLGPanic(failure) {
+		kind = "panics"
+	} else {
+		kind = "timeouts"
+	}
+	c, err := s.Service().Instrumentor().GetCounter(s.Service().Instrumentor().NewKey("network", "clg", clgName, kind, "counter", "total"))
+	if err != nil {
+		return maskAny(err)
+	}
+	c.IncrBy(1)
+
+	s.clgFailureMutex.Lock()
+	s.clgFailures[clgName]++
+	failures := s.clgFailures[clgName]
+	if failures >= s.clgQuarantineThreshold {
+		delete(s.clgFailures, clgName)
+	}
+	s.clgFailureMutex.Unlock()
+
+	if failures < s.clgQuarantineThreshold {
+		return nil
+	}
+
+	err = s.quarantine(clgName)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	s.Service().Log().Line("msg", "CLG '%s' quarantined for %s after %d consecutive failures, last failure: %s", clgName, s.clgQuarantineDuration, failures, failure)
+
+	return nil
+}
+
+// isQuarantined checks whether the CLG identified by the given CLG name is
+// currently quarantined.
+func (s *service) isQuarantined(clgName string) (bool, error) {
+	quarantineKey := fmt.Sprintf("clg-name:%s:quarantined-until", clgName)
+	value, err := s.Service().Storage().General().Get(quarantineKey)
+	if storagecollection.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, maskAny(err)
+	}
+
+	until, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return false, maskAny(err)
+	}
+
+	return time.Now().Before(until), nil
+}
+
+// quarantine quarantines the CLG identified by the given CLG name for
+// CLGQuarantineDuration. The quarantine is written to the underlying storage,
+// so the forwarder takes quarantined CLGs out of its forwarding choices.
+func (s *service) quarantine(clgName string) error {
+	c, err := s.Service().Instrumentor().GetCounter(s.Service().Instrumentor().NewKey("network", "clg", clgName, "quarantines", "counter", "total"))
+	if err != nil {
+		return maskAny(err)
+	}
+	c.IncrBy(1)
+
+	quarantineKey := fmt.Sprintf("clg-name:%s:quarantined-until", clgName)
+	until := time.Now().Add(s.clgQuarantineDuration)
+	err = s.Service().Storage().General().Set(quarantineKey, until.Format(time.RFC3339Nano))
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
+
+// succeedCLG resets the consecutive failures of the CLG identified by the
+// given CLG name.
+func (s *service) succeedCLG(clgName string) {
+	s.clgFailureMutex.Lock()
+	defer s.clgFailureMutex.Unlock()
+
+	delete(s.clgFailures, clgName)
+}
@@ -1,6 +1,12 @@
 package network
 
 import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/the-anna-project/annad/object/networkpayload"
 	"github.com/the-anna-project/annad/service/clg/divide"
 	"github.com/the-anna-project/annad/service/clg/greater"
 	"github.com/the-anna-project/annad/service/clg/input"
@@ -17,9 +23,253 @@ import (
 	"github.com/the-anna-project/annad/service/clg/splitfeatures"
 	"github.com/the-anna-project/annad/service/clg/subtract"
 	"github.com/the-anna-project/annad/service/clg/sum"
-	servicespec "github.com/the-anna-project/spec/service"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+)
+
+const (
+	// eventBuffer is the number of network events being scheduled at most. As
+	// long as this number of scheduled network events is waiting to be handled,
+	// the event dispatcher stops scheduling further network events. These remain
+	// queued in the underlying storage.
+	eventBuffer = 10000
 )
 
+// eventDispatcher is a worker pool function which moves queued network events
+// into the scored set of scheduled network events. All network payloads sent
+// within the neural network are queued in the same list. See scheduleEvent.
+func (s *service) eventDispatcher(canceler <-chan struct{}) error {
+	dispatchEvent := func() error {
+		// Fetch the next network payload from the queue. This call blocks until one
+		// network payload was fetched from the queue. As soon as we receive the
+		// network payload, it is removed from the queue automatically, so it is not
+		// scheduled twice.
+		eventKey := fmt.Sprintf("event:network-payload")
+		element, err := s.Service().Storage().General().PopFromList(eventKey)
+		if err != nil {
+			return maskAny(err)
+		}
+		err = s.scheduleEvent(element)
+		if err != nil {
+			return maskAny(err)
+		}
+
+		// Signal one event listener to handle the scheduled network event.
+		select {
+		case <-canceler:
+		case s.events <- struct{}{}:
+		}
+
+		return nil
+	}
+
+	for {
+		select {
+		case <-canceler:
+			return maskAny(workerCanceledError)
+		default:
+			err := dispatchEvent()
+			if err != nil {
+				s.Service().Log().Line("msg", maskAny(err))
+			}
+		}
+	}
+}
+
+// eventPriority calculates the priority of the network event described by the
+// given network payload. The priority is the sum of the following rules.
+//
+//	session
+//
+//	    Network events of interactive sessions are prioritized using
+//	    PriorityInteractive. Network events of training sessions are
+//	    prioritized using PriorityTraining. A training session is
+//	    identified by the expectation provided with the original request.
+//
+//	depth
+//
+//	    Each hop the network payload travelled within its CLG tree adds
+//	    PriorityDepth.
+//
+//	CLG
+//
+//	    The CLG the network payload is dispatched to adds its priority as
+//	    configured in PriorityCLGs, if any.
+func (s *service) eventPriority(networkPayload objectspec.NetworkPayload) int {
+	var priority int
+
+	ctx := networkPayload.GetContext()
+
+	if _, ok := ctx.GetExpectation(); ok {
+		priority += s.priorityTraining
+	} else {
+		priority += s.priorityInteractive
+	}
+
+	priority += networkPayload.GetDepth() * s.priorityDepth
+
+	if clgName, ok := ctx.GetCLGName(); ok {
+		priority += s.priorityCLGs[clgName]
+	}
+
+	return priority
+}
+
+// eventScore combines the priority and the sequence number of a network event.
+// Network events having a higher score are handled first. The sequence number
+// is weighted using PriorityAging. That way network events having the same
+// priority are handled in the order they were scheduled. Further, network
+// events waiting for a long time eventually outrank network events of higher
+// priority being scheduled later.
+func (s *service) eventScore(priority int, sequence int64) float64 {
+	return float64(priority) - float64(sequence)/float64(s.priorityAging)
+}
+
+// nextEvent removes the scheduled network event having the highest score and
+// returns its network payload. Calls to nextEvent are synchronized, so
+// concurrent event listeners never receive the same network event.
+func (s *service) nextEvent() (string, error) {
+	s.eventMutex.Lock()
+	defer s.eventMutex.Unlock()
+
+	scheduledKey := fmt.Sprintf("event:network-payload:scheduled")
+	result, err := s.Service().Storage().General().GetHighestScoredElements(scheduledKey, 1)
+	if err != nil {
+		return "", maskAny(err)
+	}
+	if len(result) == 0 {
+		return "", maskAny(eventNotFoundError)
+	}
+	eventID := result[0]
+
+	eventKey := fmt.Sprintf("event:id:%s:scheduled", eventID)
+	event, err := s.Service().Storage().General().GetStringMap(eventKey)
+	if err != nil {
+		return "", maskAny(err)
+	}
+	err = s.Service().Storage().General().RemoveScoredElement(scheduledKey, eventID)
+	if err != nil {
+		return "", maskAny(err)
+	}
+	err = s.Service().Storage().General().Remove(eventKey)
+	if err != nil {
+		return "", maskAny(err)
+	}
+
+	return event["network-payload"], nil
+}
+
+// pushEvent pushes the given network payload to the queue of network events
+// the event dispatcher moves network events from. See Queue.
+func (s *service) pushEvent(networkPayload objectspec.NetworkPayload) error {
+	eventKey := fmt.Sprintf("event:network-payload")
+	b, err := json.Marshal(networkPayload)
+	if err != nil {
+		return maskAny(err)
+	}
+	err = s.Service().Storage().General().PushToList(eventKey, string(b))
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
+
+// recoverEvents looks up the network events which are already scheduled in the
+// underlying storage and returns their number. This is the case when the
+// network was shut down before all scheduled network events were handled. The
+// sequence number is restored to the highest one of the recovered network
+// events, so network events scheduled from now on do not outrank them. See
+// signalEvents.
+func (s *service) recoverEvents() (int, error) {
+	var recovered int
+	var sequence int64
+
+	scheduledKey := fmt.Sprintf("event:network-payload:scheduled")
+	err := s.Service().Storage().General().WalkScoredSet(scheduledKey, s.closer, func(eventID string, score float64) error {
+		eventKey := fmt.Sprintf("event:id:%s:scheduled", eventID)
+		event, err := s.Service().Storage().General().GetStringMap(eventKey)
+		if err != nil {
+			return maskAny(err)
+		}
+		n, err := strconv.ParseInt(event["sequence"], 10, 64)
+		if err != nil {
+			return maskAny(err)
+		}
+		if n > sequence {
+			sequence = n
+		}
+		recovered++
+
+		return nil
+	})
+	if err != nil {
+		return 0, maskAny(err)
+	}
+
+	if sequence > atomic.LoadInt64(&s.sequence) {
+		atomic.StoreInt64(&s.sequence, sequence)
+	}
+
+	return recovered, nil
+}
+
+// scheduleEvent schedules the network event described by the given network
+// payload, which is JSON encoded. Each scheduled network event is identified
+// by its own event ID, so equal network payloads are scheduled as distinct
+// network events. The event ID is the sequence number of the network event,
+// which is unique, also across restarts. See recoverEvents. No ID is created
+// using the ID service, because the event dispatcher runs concurrently to the
+// event listeners. Otherwise the IDs created by the event listeners would
+// depend on the time network events are scheduled, which breaks the
+// deterministic mode. The score of each scheduled network event is calculated
+// using eventPriority and eventScore. The event listeners then handle the
+// scheduled network events with respect to their scores. See nextEvent.
+func (s *service) scheduleEvent(element string) error {
+	networkPayload := networkpayload.MustNew()
+	err := json.Unmarshal([]byte(element), &networkPayload)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	sequence := atomic.AddInt64(&s.sequence, 1)
+	eventID := strconv.FormatInt(sequence, 10)
+
+	// The network payload is stored before the network event is scheduled, so
+	// event listeners never find a scheduled network event without network
+	// payload.
+	eventKey := fmt.Sprintf("event:id:%s:scheduled", eventID)
+	event := map[string]string{
+		"network-payload": element,
+		"sequence":        strconv.FormatInt(sequence, 10),
+	}
+	err = s.Service().Storage().General().SetStringMap(eventKey, event)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	score := s.eventScore(s.eventPriority(networkPayload), sequence)
+	scheduledKey := fmt.Sprintf("event:network-payload:scheduled")
+	err = s.Service().Storage().General().SetElementByScore(scheduledKey, eventID, score)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
+
+// signalEvents emits the given number of signals to the event listeners. It is
+// used to signal the network events found by recoverEvents.
+func (s *service) signalEvents(n int) {
+	for i := 0; i < n; i++ {
+		select {
+		case <-s.closer:
+			return
+		case s.events <- struct{}{}:
+		}
+	}
+}
+
 // newCLGs returns a list of all CLGs which are configured and ready to be used
 // within the neural network.
 func (s *service) newCLGs() map[string]servicespec.CLGService {
@@ -47,12 +297,12 @@ func (s *service) newCLGs() map[string]servicespec.CLGService {
 
 	newCLGs := map[string]servicespec.CLGService{}
 
+	// Each CLG needs to be booted to initialize its metadata. The CLGs are
+	// referenced by their kind, which is the CLG name used within contexts.
 	for _, CLG := range list {
-		newCLGs[CLG.Metadata()["name"]] = CLG
-	}
-
-	for name := range newCLGs {
-		newCLGs[name].SetServiceCollection(s.serviceCollection)
+		CLG.SetServiceCollection(s.serviceCollection)
+		CLG.Boot()
+		newCLGs[CLG.Metadata()["kind"]] = CLG
 	}
 
 	return newCLGs
@@ -0,0 +1,341 @@
+package network
+
+import (
+	"encoding/json"
+	"testing"
+
+	kitlog "github.com/go-kit/kit/log"
+
+	servicecollection "github.com/the-anna-project/annad/collection/collection"
+	"github.com/the-anna-project/annad/id"
+	memoryinstrumentor "github.com/the-anna-project/annad/instrumentor/memory"
+	"github.com/the-anna-project/annad/log"
+	"github.com/the-anna-project/annad/object/context"
+	"github.com/the-anna-project/annad/object/networkpayload"
+	"github.com/the-anna-project/annad/random"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+	storagecollection "github.com/the-anna-project/annad/storage/collection"
+	memorystorage "github.com/the-anna-project/annad/storage/service/memory"
+)
+
+type testExpectation struct{}
+
+func (e *testExpectation) GetMatcher() string {
+	return ""
+}
+
+func (e *testExpectation) GetOutput() string {
+	return "output"
+}
+
+func (e *testExpectation) GetOutputs() []string {
+	return nil
+}
+
+func (e *testExpectation) GetThreshold() float64 {
+	return 0
+}
+
+func (e *testExpectation) SetMatcher(matcher string)      {}
+func (e *testExpectation) SetOutputs(outputs []string)    {}
+func (e *testExpectation) SetThreshold(threshold float64) {}
+
+// testEventService creates a new network service using the given config. The
+// network service schedules network events within the given storage service.
+// In case the given storage service is nil, a new memory storage service is
+// created and booted. The returned function shuts it down again.
+func testEventService(t *testing.T, config Config, storageService servicespec.StorageService) (*service, servicespec.StorageService, func()) {
+	newLogService := log.New()
+	newLogService.SetRootLogger(kitlog.NewNopLogger())
+
+	newService, err := New(config)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	shutdown := func() {}
+	if storageService == nil {
+		storageService = memorystorage.New()
+	}
+	newStorageCollection := storagecollection.New()
+	newStorageCollection.SetGeneralService(storageService)
+
+	collection := servicecollection.New()
+	collection.SetIDService(id.New())
+	collection.SetInstrumentorService(memoryinstrumentor.New())
+	collection.SetLogService(newLogService)
+	collection.SetNetworkService(newService)
+	collection.SetRandomService(random.New())
+	collection.SetStorageCollection(newStorageCollection)
+
+	collection.ID().SetServiceCollection(collection)
+	collection.Log().SetServiceCollection(collection)
+	collection.Network().SetServiceCollection(collection)
+	collection.Random().SetServiceCollection(collection)
+
+	if storageService.Service() == nil {
+		collection.Storage().General().SetServiceCollection(collection)
+		collection.Storage().General().Boot()
+		shutdown = collection.Storage().General().Shutdown
+	}
+
+	return newService.(*service), storageService, shutdown
+}
+
+// testEventElement returns the JSON encoded network payload dispatched to the
+// given CLG and the given destination. The destination survives the encoding
+// and tells network events apart.
+func testEventElement(t *testing.T, clgName string, destination string) string {
+	newNetworkPayloadConfig := networkpayload.DefaultConfig()
+	newNetworkPayloadConfig.Destination = destination
+	newNetworkPayload, err := networkpayload.New(newNetworkPayloadConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	newNetworkPayload.GetContext().SetCLGName(clgName)
+	b, err := json.Marshal(newNetworkPayload)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	return string(b)
+}
+
+// testEventDestination returns the destination of the JSON encoded network
+// payload given by nextEvent.
+func testEventDestination(t *testing.T, element string) string {
+	networkPayload := networkpayload.MustNew()
+	err := json.Unmarshal([]byte(element), &networkPayload)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	return networkPayload.GetDestination()
+}
+
+func Test_Network_eventPriority(t *testing.T) {
+	newConfig := DefaultConfig()
+	newConfig.PriorityCLGs = map[string]int{"output": 10}
+	newConfig.PriorityDepth = 2
+	newConfig.PriorityInteractive = 5
+	newConfig.PriorityTraining = 1
+	newService, err := New(newConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	testCases := []struct {
+		CLGName     string
+		Depth       int
+		Expectation bool
+		Expected    int
+	}{
+		{
+			CLGName:     "sum",
+			Depth:       0,
+			Expectation: false,
+			Expected:    5,
+		},
+		{
+			CLGName:     "sum",
+			Depth:       0,
+			Expectation: true,
+			Expected:    1,
+		},
+		{
+			CLGName:     "sum",
+			Depth:       3,
+			Expectation: true,
+			Expected:    7,
+		},
+		{
+			CLGName:     "output",
+			Depth:       3,
+			Expectation: false,
+			Expected:    21,
+		},
+	}
+
+	for i, testCase := range testCases {
+		ctx := context.MustNew()
+		ctx.SetCLGName(testCase.CLGName)
+		if testCase.Expectation {
+			ctx.SetExpectation(&testExpectation{})
+		}
+		newNetworkPayloadConfig := networkpayload.DefaultConfig()
+		newNetworkPayloadConfig.Context = ctx
+		newNetworkPayloadConfig.Depth = testCase.Depth
+		newNetworkPayload, err := networkpayload.New(newNetworkPayloadConfig)
+		if err != nil {
+			t.Fatal("case", i+1, "expected", nil, "got", err)
+		}
+
+		output := newService.(*service).eventPriority(newNetworkPayload)
+		if output != testCase.Expected {
+			t.Fatal("case", i+1, "expected", testCase.Expected, "got", output)
+		}
+	}
+}
+
+func Test_Network_eventScore(t *testing.T) {
+	newConfig := DefaultConfig()
+	newConfig.PriorityAging = 10
+	newService, err := New(newConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	s := newService.(*service)
+
+	// Network events having the same priority are handled in the order they
+	// were scheduled.
+	if s.eventScore(3, 1) <= s.eventScore(3, 2) {
+		t.Fatal("expected", "first event to be handled first", "got", "second event")
+	}
+
+	// Network events having a higher priority are handled first.
+	if s.eventScore(3, 1) >= s.eventScore(4, 2) {
+		t.Fatal("expected", "second event to be handled first", "got", "first event")
+	}
+
+	// Network events waiting for a long time outrank network events of higher
+	// priority being scheduled later.
+	if s.eventScore(3, 1) <= s.eventScore(4, 12) {
+		t.Fatal("expected", "first event to be handled first", "got", "second event")
+	}
+}
+
+func Test_Network_New_Error_PriorityAging(t *testing.T) {
+	newConfig := DefaultConfig()
+	newConfig.PriorityAging = 0
+	_, err := New(newConfig)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+func Test_Network_nextEvent(t *testing.T) {
+	newConfig := DefaultConfig()
+	newConfig.PriorityCLGs = map[string]int{"output": 10, "sum": 5}
+	newService, _, shutdown := testEventService(t, newConfig, nil)
+	defer shutdown()
+
+	// Network events are handled by priority. Equal network payloads are
+	// scheduled as distinct network events.
+	testEvents := []struct {
+		CLGName     string
+		Destination string
+	}{
+		{CLGName: "divide", Destination: "a"},
+		{CLGName: "sum", Destination: "b"},
+		{CLGName: "output", Destination: "c"},
+		{CLGName: "output", Destination: "c"},
+		{CLGName: "sum", Destination: "d"},
+	}
+	// The event dispatcher runs concurrently to the event listeners, so
+	// scheduling network events must not create IDs.
+	idService := &testCountingIDService{IDService: newService.Service().ID()}
+	newService.Service().SetIDService(idService)
+	for _, e := range testEvents {
+		err := newService.scheduleEvent(testEventElement(t, e.CLGName, e.Destination))
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+	if idService.count != 0 {
+		t.Fatal("expected", 0, "got", idService.count)
+	}
+
+	for i, expected := range []string{"c", "c", "b", "d", "a"} {
+		element, err := newService.nextEvent()
+		if err != nil {
+			t.Fatal("case", i+1, "expected", nil, "got", err)
+		}
+		output := testEventDestination(t, element)
+		if output != expected {
+			t.Fatal("case", i+1, "expected", expected, "got", output)
+		}
+	}
+
+	_, err := newService.nextEvent()
+	if !IsEventNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+func Test_Network_nextEvent_Aging(t *testing.T) {
+	newConfig := DefaultConfig()
+	newConfig.PriorityAging = 2
+	newConfig.PriorityCLGs = map[string]int{"output": 3}
+	newService, _, shutdown := testEventService(t, newConfig, nil)
+	defer shutdown()
+
+	// A network event of low priority is starved by network events of higher
+	// priority, until it waited for long enough. Then it outranks network
+	// events of higher priority being scheduled later.
+	err := newService.scheduleEvent(testEventElement(t, "sum", "low"))
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	var served bool
+	for i := 0; i < 10; i++ {
+		err := newService.scheduleEvent(testEventElement(t, "output", "high"))
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		element, err := newService.nextEvent()
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		if testEventDestination(t, element) == "low" {
+			served = true
+			break
+		}
+	}
+	if !served {
+		t.Fatal("expected", "low", "got", "high")
+	}
+}
+
+func Test_Network_recoverEvents(t *testing.T) {
+	newService, storageService, shutdown := testEventService(t, DefaultConfig(), nil)
+	defer shutdown()
+
+	for _, destination := range []string{"a", "b", "c"} {
+		err := newService.scheduleEvent(testEventElement(t, "sum", destination))
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	// The network restarts using the same storage. The network events scheduled
+	// before are recovered and keep being handled before network events of the
+	// same priority being scheduled after the restart.
+	newService, _, _ = testEventService(t, DefaultConfig(), storageService)
+	recovered, err := newService.recoverEvents()
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if recovered != 3 {
+		t.Fatal("expected", 3, "got", recovered)
+	}
+	err = newService.scheduleEvent(testEventElement(t, "sum", "d"))
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	newService.events = make(chan struct{}, eventBuffer)
+	newService.signalEvents(recovered)
+	if len(newService.events) != 3 {
+		t.Fatal("expected", 3, "got", len(newService.events))
+	}
+	for i, expected := range []string{"a", "b", "c", "d"} {
+		element, err := newService.nextEvent()
+		if err != nil {
+			t.Fatal("case", i+1, "expected", nil, "got", err)
+		}
+		output := testEventDestination(t, element)
+		if output != expected {
+			t.Fatal("case", i+1, "expected", expected, "got", output)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package network_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	expectationobject "github.com/the-anna-project/annad/input/object/expectation"
+	"github.com/the-anna-project/annad/object/context"
+	"github.com/the-anna-project/annad/object/networkevent"
+	"github.com/the-anna-project/annad/service/clg/output"
+	"github.com/the-anna-project/annad/service/network"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+	"github.com/the-anna-project/annad/testkit"
+)
+
+// testRetryRecorder records the network payloads retried using the network
+// service before handing them to it.
+type testRetryRecorder struct {
+	servicespec.NetworkService
+
+	retried chan objectspec.NetworkPayload
+}
+
+func (r *testRetryRecorder) Retry(networkPayload objectspec.NetworkPayload) error {
+	r.retried <- networkPayload
+
+	return r.NetworkService.Retry(networkPayload)
+}
+
+// Test_Network_Reentry_OutputToInput drives the retry of the output CLG
+// through the network. The retried network payload enters the input CLG the
+// CLG tree started with, which it already passed. The output CLG re-entering
+// the input CLG is a re-entry edge, so the network payload is not dropped, but
+// its path starts over.
+func Test_Network_Reentry_OutputToInput(t *testing.T) {
+	newNetworkConfig := network.DefaultConfig()
+	newNetworkConfig.RetryBackoff = 0
+	newNetworkService, err := network.New(newNetworkConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	newRecorder := &testRetryRecorder{
+		NetworkService: newNetworkService,
+		retried:        make(chan objectspec.NetworkPayload, 1),
+	}
+
+	newConfig := testkit.DefaultConfig()
+	newConfig.NetworkService = newRecorder
+	newKit, err := testkit.New(newConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	newKit.Boot()
+	defer newKit.Shutdown()
+
+	events, err := newKit.Service().Bus().Subscribe("test")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	defer newKit.Service().Bus().Unsubscribe("test")
+
+	// The CLG tree started with the input CLG and reached the output CLG.
+	clgTreeID := "clg-tree-id"
+	inputBehaviourID := "input-behaviour-id"
+	outputBehaviourID := "output-behaviour-id"
+	err = newKit.Service().Behaviour().Create(inputBehaviourID, "input", clgTreeID)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	err = newKit.Service().Behaviour().Create(outputBehaviourID, "output", clgTreeID)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	err = newKit.Service().Storage().General().Set("clg-tree-id:"+clgTreeID+":first-behaviour-id", inputBehaviourID)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	ctx := context.MustNew()
+	ctx.SetBehaviourID(outputBehaviourID)
+	ctx.SetCLGName("output")
+	ctx.SetCLGTreeID(clgTreeID)
+	ctx.SetExpectation(expectationobject.New("goodbye"))
+	ctx.SetSessionID(testkit.SessionID)
+
+	newCLG := newKit.BootCLG(output.New())
+	_, err = newCLG.Call(ctx, []interface{}{"hello"})
+	if !output.IsExpectationNotMet(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	// The retried network payload records that the CLG tree already passed the
+	// input CLG it is about to enter.
+	retried := <-newRecorder.retried
+	if retried.GetDestination() != inputBehaviourID {
+		t.Fatal("expected", inputBehaviourID, "got", retried.GetDestination())
+	}
+	if !reflect.DeepEqual(retried.GetPath(), []string{inputBehaviourID, outputBehaviourID}) {
+		t.Fatal("expected", []string{inputBehaviourID, outputBehaviourID}, "got", retried.GetPath())
+	}
+
+	// The input CLG calculates the retried network payload, using a path which
+	// started over.
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e := <-events:
+			if e.GetCLGTreeID() != clgTreeID || e.GetCLGName() != "input" || e.GetKind() != networkevent.KindCalculated {
+				continue
+			}
+			if e.GetError() != "" {
+				t.Fatal("expected", "", "got", e.GetError())
+			}
+			if len(e.GetNetworkPayload().GetPath()) != 0 {
+				t.Fatal("expected", 0, "got", len(e.GetNetworkPayload().GetPath()))
+			}
+			return
+		case <-timeout:
+			t.Fatal("expected", "input CLG to calculate", "got", "timeout")
+		}
+	}
+}
@@ -10,38 +10,346 @@ import (
 	"fmt"
 	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/the-anna-project/annad/object/context"
+	"github.com/the-anna-project/annad/object/networkevent"
 	"github.com/the-anna-project/annad/object/networkpayload"
-	objectspec "github.com/the-anna-project/spec/object"
-	servicespec "github.com/the-anna-project/spec/service"
+	textoutputobject "github.com/the-anna-project/annad/output/object/text"
+	"github.com/the-anna-project/annad/service/activator"
+	"github.com/the-anna-project/annad/service/clg/output"
+	"github.com/the-anna-project/annad/service/matcher"
+	apispec "github.com/the-anna-project/annad/spec/api"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
 )
 
+// Config represents the configuration used to create a new network service.
+type Config struct {
+	// Dependencies.
+
+	// Journal records the text input the network receives and the text output
+	// it sends, if not nil. See Journal.
+	Journal Journal
+
+	// Settings.
+
+	// BudgetDeadline is the duration a CLG tree is allowed to calculate an
+	// answer for the input it was created for. When the deadline passed, the
+	// CLG tree ends.
+	BudgetDeadline time.Duration
+
+	// BudgetDepth is the maximum number of hops a network payload is allowed to
+	// travel within its CLG tree. Network payloads reaching this depth are not
+	// forwarded anymore.
+	BudgetDepth int
+
+	// BudgetEvents is the maximum number of network events a CLG tree is
+	// allowed to cause. When all network events are consumed, the CLG tree ends.
+	BudgetEvents int
+
+	// CandidatesLimit is the maximum number of candidates sent to the client
+	// for each CLG tree. Only the candidates having the highest scores are
+	// sent.
+	CandidatesLimit int
+
+	// CandidatesWindow is the duration the outputs of a CLG tree are gathered,
+	// starting with its first output. Then the gathered outputs are ranked and
+	// sent to the client as candidates of one response. Outputs calculated
+	// after the window passed are dropped. A window of 0 sends the first output
+	// right away.
+	CandidatesWindow time.Duration
+
+	// CLGQuarantineDuration is the duration a misbehaving CLG is quarantined.
+	// Network payloads are neither forwarded to quarantined CLGs, nor are
+	// quarantined CLGs executed.
+	CLGQuarantineDuration time.Duration
+
+	// CLGQuarantineThreshold is the number of consecutive failures after which
+	// a CLG is quarantined. Failures are panics and timeouts of CLG executions.
+	CLGQuarantineThreshold int
+
+	// CLGCacheSize is the maximum number of CLG results being memoized. Only
+	// the results of CLGs marked as pure by their metadata are memoized. A
+	// size of 0 disables memoization.
+	CLGCacheSize int
+
+	// CLGTimeout is the duration a CLG execution is allowed to take. CLG
+	// executions exceeding the timeout are considered failures.
+	CLGTimeout time.Duration
+
+	// Frozen defines whether the network only uses what it already learned.
+	// See servicespec.NetworkService.Frozen.
+	Frozen bool
+
+	// PriorityAging is the number of network events being scheduled after which
+	// a waiting network event gained one priority point compared to newly
+	// scheduled network events. This prevents network events of low priority
+	// from starving.
+	PriorityAging int
+
+	// PriorityCLGs maps CLG names to the priority added to network events being
+	// dispatched to the corresponding CLG. This can be used to prefer network
+	// events being close to the end of a CLG tree, like these of the output CLG.
+	PriorityCLGs map[string]int
+
+	// PriorityDepth is the priority added to a network event for each hop its
+	// network payload travelled within its CLG tree.
+	PriorityDepth int
+
+	// PriorityInteractive is the priority of network events belonging to
+	// interactive sessions. A session is considered interactive when the
+	// request does not provide any expectation.
+	PriorityInteractive int
+
+	// PriorityTraining is the priority of network events belonging to training
+	// sessions. A session is considered training when the request provides an
+	// expectation.
+	PriorityTraining int
+
+	// ReentryEdges marks the re-entry edges of CLG trees. It maps CLG names of
+	// sources to the CLG names of the destinations they may re-enter. Network
+	// payloads sent along these edges are allowed to enter behaviours even if
+	// they already passed them. This permits intended loops like the retry of
+	// the output CLG forwarding to the input CLG. Echo requests start their CLG
+	// trees with the output CLG, which their retries enter again. All other
+	// cycles are dropped.
+	ReentryEdges map[string][]string
+
+	// RetryBackoff is the delay before the output CLG retries a CLG tree the
+	// first time, because its output did not meet the expectation of the
+	// request. The delay doubles with each further retry. Requests may define
+	// their own backoff.
+	RetryBackoff time.Duration
+
+	// RetryBackoffMax is the maximum delay between two retries of a CLG tree.
+	RetryBackoffMax time.Duration
+
+	// RetryLimit is the maximum number of retries of a CLG tree. When the limit
+	// is reached, the CLG tree ends and the client receives the output coming
+	// closest to the expectation. Requests may define their own limit.
+	RetryLimit int
+
+	// WorkersAutoscale defines whether the number of event workers is scaled
+	// automatically. The autoscaler grows and shrinks the event workers based
+	// on the number of network events waiting to be handled and the latency of
+	// the event handler.
+	WorkersAutoscale bool
+
+	// WorkersAutoscaleInterval is the interval in which the autoscaler decides
+	// about the number of event workers.
+	WorkersAutoscaleInterval time.Duration
+
+	// WorkersAutoscaleLatency is the average event handler latency above which
+	// the autoscaler grows the event workers cautiously.
+	WorkersAutoscaleLatency time.Duration
+
+	// WorkersAutoscaleMax is the maximum number of event workers the autoscaler
+	// scales up to.
+	WorkersAutoscaleMax int
+
+	// WorkersAutoscaleMin is the minimum number of event workers the autoscaler
+	// scales down to.
+	WorkersAutoscaleMin int
+
+	// WorkersEvent is the number of event workers started when booting the
+	// network. In case the autoscaler is enabled, this is the initial number of
+	// event workers.
+	WorkersEvent int
+
+	// WorkersInput is the number of input workers.
+	WorkersInput int
+}
+
+// DefaultConfig provides a default configuration to create a new network
+// service by best effort.
+func DefaultConfig() Config {
+	newConfig := Config{
+		// Dependencies.
+		Journal: nil,
+
+		// Settings.
+		BudgetDeadline:         30 * time.Second,
+		BudgetDepth:            100,
+		BudgetEvents:           10000,
+		CandidatesLimit:        10,
+		CandidatesWindow:       100 * time.Millisecond,
+		CLGCacheSize:           10000,
+		CLGQuarantineDuration:  5 * time.Minute,
+		CLGQuarantineThreshold: 5,
+		CLGTimeout:             10 * time.Second,
+		Frozen:                 false,
+		PriorityAging:          100,
+		PriorityCLGs: map[string]int{
+			"output": 10,
+		},
+		PriorityDepth:       1,
+		PriorityInteractive: 10,
+		PriorityTraining:    0,
+		ReentryEdges: map[string][]string{
+			"output": {"input", "output"},
+		},
+		RetryBackoff:             10 * time.Millisecond,
+		RetryBackoffMax:          time.Second,
+		RetryLimit:               100,
+		WorkersAutoscale:         false,
+		WorkersAutoscaleInterval: 5 * time.Second,
+		WorkersAutoscaleLatency:  100 * time.Millisecond,
+		WorkersAutoscaleMax:      100,
+		WorkersAutoscaleMin:      1,
+		WorkersEvent:             10,
+		WorkersInput:             1,
+	}
+
+	return newConfig
+}
+
 // New creates a new network service.
-func New() servicespec.NetworkService {
-	return &service{
+func New(config Config) (servicespec.NetworkService, error) {
+	// Settings.
+	if config.BudgetDeadline <= 0 {
+		return nil, maskAnyf(invalidConfigError, "budget deadline must be greater than 0")
+	}
+	if config.BudgetDepth < 1 {
+		return nil, maskAnyf(invalidConfigError, "budget depth must be greater than 0")
+	}
+	if config.BudgetEvents < 1 {
+		return nil, maskAnyf(invalidConfigError, "budget events must be greater than 0")
+	}
+	if config.CandidatesLimit < 1 {
+		return nil, maskAnyf(invalidConfigError, "candidates limit must be greater than 0")
+	}
+	if config.CandidatesWindow < 0 {
+		return nil, maskAnyf(invalidConfigError, "candidates window must not be negative")
+	}
+	if config.CLGCacheSize < 0 {
+		return nil, maskAnyf(invalidConfigError, "CLG cache size must not be negative")
+	}
+	if config.CLGQuarantineDuration <= 0 {
+		return nil, maskAnyf(invalidConfigError, "CLG quarantine duration must be greater than 0")
+	}
+	if config.CLGQuarantineThreshold < 1 {
+		return nil, maskAnyf(invalidConfigError, "CLG quarantine threshold must be greater than 0")
+	}
+	if config.CLGTimeout <= 0 {
+		return nil, maskAnyf(invalidConfigError, "CLG timeout must be greater than 0")
+	}
+	if config.PriorityAging < 1 {
+		return nil, maskAnyf(invalidConfigError, "priority aging must be greater than 0")
+	}
+	if config.PriorityCLGs == nil {
+		return nil, maskAnyf(invalidConfigError, "priority CLGs must not be empty")
+	}
+	if config.RetryBackoff < 0 {
+		return nil, maskAnyf(invalidConfigError, "retry backoff must not be negative")
+	}
+	if config.RetryBackoffMax < config.RetryBackoff {
+		return nil, maskAnyf(invalidConfigError, "retry backoff maximum must not be lower than retry backoff")
+	}
+	if config.RetryLimit < 0 {
+		return nil, maskAnyf(invalidConfigError, "retry limit must not be negative")
+	}
+	if config.WorkersEvent < 1 {
+		return nil, maskAnyf(invalidConfigError, "event workers must be greater than 0")
+	}
+	if config.WorkersInput < 1 {
+		return nil, maskAnyf(invalidConfigError, "input workers must be greater than 0")
+	}
+	if config.WorkersAutoscale {
+		if config.WorkersAutoscaleInterval <= 0 {
+			return nil, maskAnyf(invalidConfigError, "autoscale interval must be greater than 0")
+		}
+		if config.WorkersAutoscaleMin < 1 {
+			return nil, maskAnyf(invalidConfigError, "autoscale minimum must be greater than 0")
+		}
+		if config.WorkersAutoscaleMax < config.WorkersAutoscaleMin {
+			return nil, maskAnyf(invalidConfigError, "autoscale maximum must not be lower than autoscale minimum")
+		}
+	}
+
+	var newCache *clgCache
+	if config.CLGCacheSize > 0 {
+		newCache = newCLGCache(config.CLGCacheSize)
+	}
+
+	newService := &service{
 		// Dependencies.
+		journal:           config.Journal,
 		serviceCollection: nil,
 
 		// Settings.
-		closer:       make(chan struct{}, 1),
-		metadata:     map[string]string{},
-		shutdownOnce: sync.Once{},
+		budgetDeadline:           config.BudgetDeadline,
+		budgetDepth:              config.BudgetDepth,
+		budgetEvents:             config.BudgetEvents,
+		candidatesLimit:          config.CandidatesLimit,
+		candidatesWindow:         config.CandidatesWindow,
+		clgCache:                 newCache,
+		clgFailures:              map[string]int{},
+		clgQuarantineDuration:    config.CLGQuarantineDuration,
+		clgQuarantineThreshold:   config.CLGQuarantineThreshold,
+		clgTimeout:               config.CLGTimeout,
+		closer:                   make(chan struct{}, 1),
+		metadata:                 map[string]string{},
+		priorityAging:            config.PriorityAging,
+		priorityCLGs:             config.PriorityCLGs,
+		priorityDepth:            config.PriorityDepth,
+		priorityInteractive:      config.PriorityInteractive,
+		priorityTraining:         config.PriorityTraining,
+		reentryEdges:             config.ReentryEdges,
+		retryBackoff:             config.RetryBackoff,
+		retryBackoffMax:          config.RetryBackoffMax,
+		retryLimit:               config.RetryLimit,
+		shutdownOnce:             sync.Once{},
+		workersAutoscale:         config.WorkersAutoscale,
+		workersAutoscaleInterval: config.WorkersAutoscaleInterval,
+		workersAutoscaleLatency:  config.WorkersAutoscaleLatency,
+		workersAutoscaleMax:      config.WorkersAutoscaleMax,
+		workersAutoscaleMin:      config.WorkersAutoscaleMin,
+		workersEvent:             config.WorkersEvent,
+		workersInput:             config.WorkersInput,
 	}
+
+	newService.SetFrozen(config.Frozen)
+
+	return newService, nil
 }
 
 type service struct {
 	// Dependencies.
 
+	journal           Journal
 	serviceCollection servicespec.ServiceCollection
 
 	// Settings.
 
-	bootOnce sync.Once
+	bootOnce       sync.Once
+	budgetDeadline time.Duration
+	budgetDepth    int
+	budgetEvents   int
+	// budgetMutex synchronizes the consumption of CLG tree budgets. That way
+	// concurrent event listeners never exceed the budget of a CLG tree.
+	budgetMutex sync.Mutex
+	// budgetNetworkEvents holds the network events published while holding
+	// budgetMutex. They are published as soon as budgetMutex is released. See
+	// unlockBudget.
+	budgetNetworkEvents []objectspec.NetworkEvent
+	candidatesLimit     int
+	candidatesWindow    time.Duration
+	// clgCache memoizes the results of pure CLGs. It is nil in case memoization
+	// is disabled.
+	clgCache *clgCache
+	// clgFailures provides a mapping of CLG names pointing to the number of
+	// consecutive failures of their corresponding CLG.
+	clgFailures map[string]int
+	// clgFailureMutex synchronizes the tracking of CLG failures.
+	clgFailureMutex        sync.Mutex
+	clgQuarantineDuration  time.Duration
+	clgQuarantineThreshold int
 	// CLGIDs provides a mapping of CLG names pointing to their corresponding CLG.
-	clgs   map[string]servicespec.CLGService
-	closer chan struct{}
+	clgs       map[string]servicespec.CLGService
+	clgTimeout time.Duration
+	closer     chan struct{}
 	// Delay causes each CLG execution to be delayed. This value represents a
 	// default value. A delay can be used harden the internal synchronization of
 	// the network. For instance some chaos monkey could be implemented to cause
@@ -52,9 +360,48 @@ type service struct {
 	// TODO implement the actual usage of the delay and make it dynamically
 	// configurable on demand like we already do with the log control.
 	delay time.Duration
-
-	metadata     map[string]string
-	shutdownOnce sync.Once
+	// eventHandlerCount is the number of network events handled since the
+	// autoscaler last decided about the number of event listeners.
+	eventHandlerCount int64
+	// eventHandlerDuration is the accumulated duration in nanoseconds of the
+	// network events handled since the autoscaler last decided about the number
+	// of event listeners.
+	eventHandlerDuration int64
+	// eventListeners holds one canceler for each running event listener.
+	eventListeners []chan struct{}
+	// eventListenerMutex synchronizes the modification of eventListeners.
+	eventListenerMutex sync.Mutex
+	// events receives one signal for each network event being scheduled by the
+	// event dispatcher. Each signal permits one event listener to handle the
+	// scheduled network event having the highest priority.
+	events chan struct{}
+	// eventMutex synchronizes the event listeners fetching scheduled network
+	// events. That way each network event is only handled once.
+	eventMutex sync.Mutex
+	// frozen is 1 in case the network is frozen, 0 otherwise. It is accessed
+	// atomically, because the network can be frozen at runtime.
+	frozen              int32
+	metadata            map[string]string
+	priorityAging       int
+	priorityCLGs        map[string]int
+	priorityDepth       int
+	priorityInteractive int
+	priorityTraining    int
+	reentryEdges        map[string][]string
+	retryBackoff        time.Duration
+	retryBackoffMax     time.Duration
+	retryLimit          int
+	// sequence is the sequence number of the network event being scheduled
+	// most recently.
+	sequence                 int64
+	shutdownOnce             sync.Once
+	workersAutoscale         bool
+	workersAutoscaleInterval time.Duration
+	workersAutoscaleLatency  time.Duration
+	workersAutoscaleMax      int
+	workersAutoscaleMin      int
+	workersEvent             int
+	workersInput             int
 }
 
 func (s *service) Activate(CLG servicespec.CLGService, networkPayload objectspec.NetworkPayload) (objectspec.NetworkPayload, error) {
@@ -84,6 +431,7 @@ func (s *service) Boot() {
 
 		s.clgs = s.newCLGs()
 		s.delay = 0
+		s.events = make(chan struct{}, eventBuffer)
 
 		go func() {
 			// Create a new execute config for the worker service to execute the
@@ -91,7 +439,7 @@ func (s *service) Boot() {
 			executeConfig := s.Service().Worker().ExecuteConfig()
 			executeConfig.SetActions([]func(canceler <-chan struct{}) error{s.InputListener})
 			executeConfig.SetCanceler(s.closer)
-			executeConfig.SetNumWorkers(1)
+			executeConfig.SetNumWorkers(s.workersInput)
 			err := s.Service().Worker().Execute(executeConfig)
 			if err != nil {
 				s.Service().Log().Line("msg", maskAny(err))
@@ -99,34 +447,99 @@ func (s *service) Boot() {
 		}()
 
 		go func() {
+			// Network events might have been scheduled before the network was
+			// booted. These are recovered before the event dispatcher schedules
+			// new network events, so the sequence of scheduled network events is
+			// continued. The recovered network events are signalled to the event
+			// listeners first.
+			recovered, err := s.recoverEvents()
+			if err != nil {
+				s.Service().Log().Line("msg", maskAny(err))
+			}
+			go s.signalEvents(recovered)
+
 			// Create a new execute config for the worker service to execute the
-			// event listener.
+			// event dispatcher. There must only be one event dispatcher to keep the
+			// sequence of scheduled network events.
 			executeConfig := s.Service().Worker().ExecuteConfig()
-			executeConfig.SetActions([]func(canceler <-chan struct{}) error{s.EventListener})
+			executeConfig.SetActions([]func(canceler <-chan struct{}) error{s.eventDispatcher})
 			executeConfig.SetCanceler(s.closer)
-			executeConfig.SetNumWorkers(10)
-			err := s.Service().Worker().Execute(executeConfig)
+			executeConfig.SetNumWorkers(1)
+			err = s.Service().Worker().Execute(executeConfig)
 			if err != nil {
 				s.Service().Log().Line("msg", maskAny(err))
 			}
 		}()
+
+		s.incrGauge(s.Service().Instrumentor().NewKey("network", "workers", "input", "gauge"), float64(s.workersInput))
+
+		// Start the event listeners. Each event listener is executed by its own
+		// worker, so the number of event listeners can be adjusted at runtime.
+		// When the network shuts down, all event listeners are stopped.
+		for i := 0; i < s.workersEvent; i++ {
+			s.addEventListener()
+		}
+		go func() {
+			<-s.closer
+			for s.removeEventListener() {
+			}
+		}()
+
+		if s.workersAutoscale {
+			go func() {
+				// Create a new execute config for the worker service to execute the
+				// autoscaler.
+				executeConfig := s.Service().Worker().ExecuteConfig()
+				executeConfig.SetActions([]func(canceler <-chan struct{}) error{s.autoscaler})
+				executeConfig.SetCanceler(s.closer)
+				executeConfig.SetNumWorkers(1)
+				err := s.Service().Worker().Execute(executeConfig)
+				if err != nil {
+					s.Service().Log().Line("msg", maskAny(err))
+				}
+			}()
+		}
 	})
 }
 
 func (s *service) Calculate(CLG servicespec.CLGService, networkPayload objectspec.NetworkPayload) (objectspec.NetworkPayload, error) {
 	s.Service().Log().Line("func", "Calculate")
 
-	outputs, err := filterError(reflect.ValueOf(CLG.GetCalculate()).Call(networkPayload.GetCLGInput()))
-	if err != nil {
+	// Execute the CLG in a sandbox. Panics and timeouts are tracked as failures
+	// of the CLG. A CLG failing too often is quarantined.
+	clgName := CLG.Metadata()["kind"]
+
+	// Pure CLGs always calculate the same outputs for the same arguments. So
+	// their outputs are looked up in the cache before executing them.
+	cacheKey, cacheable := s.cacheKey(CLG, networkPayload)
+	if cacheable {
+		if outputs, ok := s.clgCache.Get(cacheKey); ok {
+			s.countCache(clgName, "hits")
+			newNetworkPayload, err := s.newCalculatedPayload(outputs, networkPayload)
+			if err != nil {
+				return nil, maskAny(err)
+			}
+			return newNetworkPayload, nil
+		}
+		s.countCache(clgName, "misses")
+	}
+
+	outputs, err := s.execute(CLG, networkPayload)
+	if IsCLGPanic(err) || IsCLGTimeout(err) {
+		failErr := s.failCLG(clgName, err)
+		if failErr != nil {
+			s.Service().Log().Line("msg", maskAny(failErr))
+		}
+		return nil, maskAny(err)
+	} else if err != nil {
 		return nil, maskAny(err)
 	}
+	s.succeedCLG(clgName)
+	if cacheable {
+		s.clgCache.Add(cacheKey, outputs)
+	}
 
-	newNetworkPayloadConfig := networkpayload.DefaultConfig()
-	newNetworkPayloadConfig.Args = outputs
-	newNetworkPayloadConfig.Context = networkPayload.GetContext()
-	newNetworkPayloadConfig.Destination = networkPayload.GetDestination()
-	newNetworkPayloadConfig.Sources = networkPayload.GetSources()
-	newNetworkPayload, err := networkpayload.New(newNetworkPayloadConfig)
+	newNetworkPayload, err := s.newCalculatedPayload(outputs, networkPayload)
 	if err != nil {
 		return nil, maskAny(err)
 	}
@@ -136,13 +549,15 @@ func (s *service) Calculate(CLG servicespec.CLGService, networkPayload objectspe
 
 func (s *service) EventListener(canceler <-chan struct{}) error {
 	invokeEventHandler := func() error {
-		// Fetch the next network payload from the queue. This call blocks until one
-		// network payload was fetched from the queue. As soon as we receive the
-		// network payload, it is removed from the queue automatically, so it is not
-		// handled twice.
-		eventKey := fmt.Sprintf("event:network-payload")
-		element, err := s.Service().Storage().General().PopFromList(eventKey)
-		if err != nil {
+		// Fetch the network payload of the scheduled network event having the
+		// highest priority. As soon as we receive the network payload, it is
+		// removed from the scheduled network events, so it is not handled twice.
+		element, err := s.nextEvent()
+		if IsEventNotFound(err) {
+			// Another event listener already handled the network event we were
+			// signalled for. There is nothing to do.
+			return nil
+		} else if err != nil {
 			return maskAny(err)
 		}
 		networkPayload := networkpayload.MustNew()
@@ -167,8 +582,24 @@ func (s *service) EventListener(canceler <-chan struct{}) error {
 		// payload. Here we execute one distinct behaviour within its own scope. The
 		// CLG decides if and how it is activated, how it calculates its output, if
 		// any, and where to forward signals to, if any.
+		start := time.Now()
 		err = s.EventHandler(CLG, networkPayload)
-		if err != nil {
+		atomic.AddInt64(&s.eventHandlerDuration, int64(time.Since(start)))
+		atomic.AddInt64(&s.eventHandlerCount, 1)
+
+		// The network payload was handled, regardless of whether the CLG was
+		// activated. All network payloads it caused are already queued.
+		releaseErr := s.releaseBudget(networkPayload)
+		if releaseErr != nil {
+			s.Service().Log().Line("msg", maskAny(releaseErr))
+		}
+
+		if IsBudgetExhausted(err) || IsCLGQuarantined(err) {
+			// The CLG tree of the network payload ended, or the requested CLG is
+			// quarantined. The network event is dropped. The reason was already
+			// logged when the CLG tree ended, or the CLG was quarantined.
+			return nil
+		} else if err != nil {
 			return maskAny(err)
 		}
 
@@ -179,7 +610,7 @@ func (s *service) EventListener(canceler <-chan struct{}) error {
 		select {
 		case <-canceler:
 			return maskAny(workerCanceledError)
-		default:
+		case <-s.events:
 			err := invokeEventHandler()
 			if err != nil {
 				s.Service().Log().Line("msg", maskAny(err))
@@ -191,31 +622,99 @@ func (s *service) EventListener(canceler <-chan struct{}) error {
 func (s *service) EventHandler(CLG servicespec.CLGService, networkPayload objectspec.NetworkPayload) error {
 	var err error
 
+	// Detect cycles within the CLG tree the given network payload belongs to.
+	// Network payloads causing cycles are dropped, unless they enter a re-entry
+	// point of the CLG tree.
+	networkPayload, err = s.detectCycle(networkPayload)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	// Drop network payloads of quarantined CLGs.
+	clgName, ok := networkPayload.GetContext().GetCLGName()
+	if !ok {
+		return maskAnyf(invalidCLGNameError, "must not be empty")
+	}
+	quarantined, err := s.isQuarantined(clgName)
+	if err != nil {
+		return maskAny(err)
+	}
+	if quarantined {
+		return maskAnyf(clgQuarantinedError, "name: %s", clgName)
+	}
+
+	// Consume the budget of the CLG tree the given network payload belongs to.
+	// In case the budget is exhausted, the CLG tree ends and the network event
+	// is dropped.
+	err = s.consumeBudget(networkPayload)
+	if err != nil {
+		return maskAny(err)
+	}
+
 	// Activate if the CLG's interface is satisfied by the given
 	// network payload.
 	networkPayload, err = s.Activate(CLG, networkPayload)
-	if err != nil {
+	if activator.IsNetworkPayloadNotFound(err) {
+		// The interface of the CLG is not yet satisfied. The network payload is
+		// queued until other network payloads arrive, or it expires.
+		return nil
+	} else if err != nil {
 		return maskAny(err)
 	}
+	s.publish(networkevent.KindActivated, networkPayload, 0, nil)
 
 	// Calculate based on the CLG's implemented business logic.
-	networkPayload, err = s.Calculate(CLG, networkPayload)
+	start := time.Now()
+	calculatedNetworkPayload, err := s.Calculate(CLG, networkPayload)
 	if err != nil {
+		s.publish(networkevent.KindCalculated, networkPayload, time.Since(start), err)
+
+		// The output CLG fails in case the calculated output did not meet the
+		// expectation. Then the CLG tree ends if it exceeded its retry limit.
+		if output.IsExpectationNotMet(err) {
+			err := s.exhaustBudget(networkPayload)
+			if err != nil {
+				return maskAny(err)
+			}
+		}
+
 		return maskAny(err)
 	}
+	s.publish(networkevent.KindCalculated, calculatedNetworkPayload, time.Since(start), nil)
+
+	// The output CLG succeeds in case its output is worth answering. The output
+	// is gathered as candidate of the CLG tree. The candidates are ranked and
+	// sent to the client together.
+	if clgName == "output" {
+		err := s.gatherCandidate(CLG, networkPayload)
+		if err != nil {
+			return maskAny(err)
+		}
+	}
+	networkPayload = calculatedNetworkPayload
+
+	// The output CLG only succeeds for requests providing an expectation in case
+	// the calculated output met it. Then the CLG tree ends, because there is
+	// nothing left to calculate.
+	if _, ok := networkPayload.GetContext().GetExpectation(); ok && clgName == "output" {
+		err := s.meetBudget(networkPayload)
+		if err != nil {
+			return maskAny(err)
+		}
+
+		return nil
+	}
 
 	// Forward to other CLG's, if necessary.
 	err = s.Forward(CLG, networkPayload)
 	if err != nil {
 		return maskAny(err)
 	}
+	s.publish(networkevent.KindForwarded, networkPayload, 0, nil)
 
-	// Track the the given CLG and network payload to learn more about the
-	// connection paths created.
-	err = s.Track(CLG, networkPayload)
-	if err != nil {
-		return maskAny(err)
-	}
+	// The given CLG and network payload are tracked asynchronously by the
+	// tracker to learn more about the connection paths created. The tracker
+	// subscribes to the network events published above.
 
 	return nil
 }
@@ -223,7 +722,17 @@ func (s *service) EventHandler(CLG servicespec.CLGService, networkPayload object
 func (s *service) Forward(CLG servicespec.CLGService, networkPayload objectspec.NetworkPayload) error {
 	s.Service().Log().Line("func", "Forward")
 
-	err := s.Service().Forwarder().Forward(CLG, networkPayload)
+	// Forward only in case the budget of the CLG tree the given network payload
+	// belongs to permits it.
+	ok, err := s.forwardBudget(networkPayload)
+	if err != nil {
+		return maskAny(err)
+	}
+	if !ok {
+		return nil
+	}
+
+	err = s.Service().Forwarder().Forward(CLG, networkPayload)
 	if err != nil {
 		return maskAny(err)
 	}
@@ -231,6 +740,10 @@ func (s *service) Forward(CLG servicespec.CLGService, networkPayload objectspec.
 	return nil
 }
 
+func (s *service) Frozen() bool {
+	return atomic.LoadInt32(&s.frozen) == 1
+}
+
 func (s *service) InputListener(canceler <-chan struct{}) error {
 	CLG, ok := s.clgs["input"]
 	if !ok {
@@ -251,6 +764,10 @@ func (s *service) InputListener(canceler <-chan struct{}) error {
 }
 
 func (s *service) InputHandler(CLG servicespec.CLGService, textInput objectspec.TextInput) error {
+	// Record the text input before acting on it. That way the journal holds it
+	// before any random number or ID drawn because of it.
+	s.recordInput(textInput)
+
 	// In case the text request defines the echo flag, we overwrite the given CLG
 	// directly to the output CLG. This will cause the created network payload to
 	// be forwarded to the output CLG without indirection. Note that this should
@@ -264,6 +781,20 @@ func (s *service) InputHandler(CLG servicespec.CLGService, textInput objectspec.
 		}
 	}
 
+	// Reject expectations the matcher they name cannot use. Otherwise the
+	// output CLG would fail for every output calculated.
+	if expectation := textInput.Expectation(); expectation != nil {
+		err := matcher.Validate(expectation)
+		if err != nil {
+			textOutputObject := textoutputobject.New()
+			textOutputObject.SetCode(apispec.CodeError)
+			textOutputObject.SetOutput(err.Error())
+			s.sendOutput(textInput.SessionID(), textOutputObject)
+
+			return maskAny(err)
+		}
+	}
+
 	// Create new IDs for the new CLG tree and the input CLG.
 	clgTreeID, err := s.Service().ID().New()
 	if err != nil {
@@ -277,18 +808,23 @@ func (s *service) InputHandler(CLG servicespec.CLGService, textInput objectspec.
 	// Create a new context and adapt it using the information of the current scope.
 	ctx := context.MustNew()
 	ctx.SetBehaviourID(string(behaviourID))
-	ctx.SetCLGName(CLG.Metadata()["name"])
+	ctx.SetCLGName(CLG.Metadata()["kind"])
 	ctx.SetCLGTreeID(string(clgTreeID))
 	ctx.SetExpectation(textInput.Expectation())
 	ctx.SetSessionID(textInput.SessionID())
 
 	// We transform the received text request to a network payload to have a
 	// conventional data structure within the neural network.
+	networkPayloadID, err := s.Service().ID().New()
+	if err != nil {
+		return maskAny(err)
+	}
 	newNetworkPayloadConfig := networkpayload.DefaultConfig()
 	newNetworkPayloadConfig.Args = []reflect.Value{reflect.ValueOf(textInput.Input())}
 	newNetworkPayloadConfig.Context = ctx
 	// TODO destination and sources should be metadata objects
 	newNetworkPayloadConfig.Destination = behaviourID
+	newNetworkPayloadConfig.ID = networkPayloadID
 	newNetworkPayloadConfig.Sources = []string{s.Metadata()["id"]}
 	newNetworkPayload, err := networkpayload.New(newNetworkPayloadConfig)
 	if err != nil {
@@ -303,16 +839,37 @@ func (s *service) InputHandler(CLG servicespec.CLGService, textInput objectspec.
 		return maskAny(err)
 	}
 
-	// Write the transformed network payload to the queue.
-	eventKey := fmt.Sprintf("event:network-payload")
-	b, err := json.Marshal(newNetworkPayload)
+	// Write the seed of the random service in case the neural network runs in
+	// deterministic mode. That way the session a CLG tree belongs to can be
+	// replayed using the same seed.
+	if seed, ok := s.Service().Random().Metadata()["seed"]; ok {
+		seedKey := fmt.Sprintf("clg-tree-id:%s:seed", clgTreeID)
+		err = s.Service().Storage().General().Set(seedKey, seed)
+		if err != nil {
+			return maskAny(err)
+		}
+	}
+
+	// Register the behaviour ID of the requested CLG so other services are able
+	// to resolve it to the CLG kind.
+	err = s.Service().Behaviour().Create(string(behaviourID), CLG.Metadata()["kind"], string(clgTreeID))
+	if err != nil {
+		return maskAny(err)
+	}
+
+	// Write the budget of the new CLG tree. The budget is referenced by the CLG
+	// tree ID of the context and limits the neural activity caused by the input.
+	err = s.newBudget(string(clgTreeID), textInput)
 	if err != nil {
 		return maskAny(err)
 	}
-	err = s.Service().Storage().General().PushToList(eventKey, string(b))
+
+	// Write the transformed network payload to the queue.
+	err = s.Queue(newNetworkPayload)
 	if err != nil {
 		return maskAny(err)
 	}
+	s.publish(networkevent.KindInputReceived, newNetworkPayload, 0, nil)
 
 	return nil
 }
@@ -329,10 +886,72 @@ func (s *service) Shutdown() {
 	})
 }
 
+func (s *service) Queue(networkPayload objectspec.NetworkPayload) error {
+	err := s.queueBudget(networkPayload)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	err = s.pushEvent(networkPayload)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
+
+func (s *service) Retry(networkPayload objectspec.NetworkPayload) error {
+	delay, ok, err := s.retryBudget(networkPayload)
+	if err != nil {
+		return maskAny(err)
+	}
+	if !ok {
+		return nil
+	}
+	if delay == 0 {
+		err := s.Queue(networkPayload)
+		if err != nil {
+			return maskAny(err)
+		}
+
+		return nil
+	}
+
+	// The network payload is pending right away, but only pushed once the delay
+	// passed. That way the CLG tree does not run out of network payloads in the
+	// meantime.
+	err = s.queueBudget(networkPayload)
+	if err != nil {
+		return maskAny(err)
+	}
+	time.AfterFunc(delay, func() {
+		select {
+		case <-s.closer:
+			return
+		default:
+		}
+
+		err := s.pushEvent(networkPayload)
+		if err != nil {
+			s.Service().Log().Line("msg", maskAny(err))
+		}
+	})
+
+	return nil
+}
+
 func (s *service) Service() servicespec.ServiceCollection {
 	return s.serviceCollection
 }
 
+func (s *service) SetFrozen(frozen bool) {
+	var v int32
+	if frozen {
+		v = 1
+	}
+	atomic.StoreInt32(&s.frozen, v)
+}
+
 func (s *service) SetServiceCollection(sc servicespec.ServiceCollection) {
 	s.serviceCollection = sc
 }
@@ -0,0 +1,167 @@
+// Package random implements a seeded random service creating reproducible
+// pseudo random numbers. It is used in deterministic mode instead of the
+// default random service, which reads from crypto/rand. See
+// servicespec.RandomService.
+package random
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math/big"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+)
+
+// Config represents the configuration used to create a new random service.
+type Config struct {
+	// Settings.
+
+	// Seed is used to create the pseudo random numbers. Random services using
+	// the same seed create the same sequence of pseudo random numbers.
+	Seed int64
+
+	// Stream names the sequence of pseudo random numbers created using the
+	// seed. Random services using the same seed but different streams create
+	// independent sequences. That way subsystems drawing random numbers
+	// concurrently to the network workers do not change the sequence the
+	// network workers draw from. The empty stream is the default sequence of
+	// the seed.
+	Stream string
+}
+
+// DefaultConfig provides a default configuration to create a new random
+// service by best effort.
+func DefaultConfig() Config {
+	newConfig := Config{
+		// Settings.
+		Seed:   1,
+		Stream: "",
+	}
+
+	return newConfig
+}
+
+// New creates a new random service.
+func New(config Config) (servicespec.RandomService, error) {
+	// Settings.
+	if config.Seed == 0 {
+		return nil, maskAnyf(invalidConfigError, "seed must not be 0")
+	}
+
+	newService := &service{
+		// Dependencies.
+		serviceCollection: nil,
+
+		// Settings.
+		metadata: map[string]string{},
+		mutex:    sync.Mutex{},
+		seed:     config.Seed,
+		source:   rand.New(rand.NewSource(streamSeed(config.Seed, config.Stream))),
+		stream:   config.Stream,
+	}
+
+	return newService, nil
+}
+
+type service struct {
+	// Dependencies.
+
+	serviceCollection servicespec.ServiceCollection
+
+	// Settings.
+
+	metadata map[string]string
+	// mutex guards the source, which is not safe for concurrent use.
+	mutex  sync.Mutex
+	seed   int64
+	source *rand.Rand
+	stream string
+}
+
+func (s *service) Boot() {
+	id, err := s.Service().ID().New()
+	if err != nil {
+		panic(err)
+	}
+	s.metadata = map[string]string{
+		"id":     id,
+		"name":   "random",
+		"seed":   strconv.FormatInt(s.seed, 10),
+		"stream": s.stream,
+		"type":   "service",
+	}
+}
+
+func (s *service) CreateMax(max int) (int, error) {
+	if max <= 0 {
+		return 0, maskAnyf(invalidMaxError, "must be greater than 0")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.source.Intn(max), nil
+}
+
+func (s *service) CreateNMax(n, max int) ([]int, error) {
+	if max <= 0 {
+		return nil, maskAnyf(invalidMaxError, "must be greater than 0")
+	}
+
+	// The lock is held for all numbers so that concurrent callers do not
+	// interleave their draws.
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var result []int
+	for i := 0; i < n; i++ {
+		result = append(result, s.source.Intn(max))
+	}
+
+	return result, nil
+}
+
+func (s *service) Metadata() map[string]string {
+	return s.metadata
+}
+
+func (s *service) Service() servicespec.ServiceCollection {
+	return s.serviceCollection
+}
+
+// SetBackoffFactory is a noop. Creating seeded pseudo random numbers never
+// fails and thus is never retried.
+func (s *service) SetBackoffFactory(backoffFactory func() objectspec.Backoff) {}
+
+// SetRandFactory is a noop. Pseudo random numbers are always created using the
+// configured seed. Otherwise they would not be reproducible.
+func (s *service) SetRandFactory(randFactory func(randReader io.Reader, max *big.Int) (n *big.Int, err error)) {
+}
+
+func (s *service) SetServiceCollection(serviceCollection servicespec.ServiceCollection) {
+	s.serviceCollection = serviceCollection
+}
+
+// SetTimeout is a noop. Creating seeded pseudo random numbers never blocks and
+// thus never times out.
+func (s *service) SetTimeout(timeout time.Duration) {}
+
+// streamSeed returns the seed the source of the given stream is created with.
+// The default stream uses the given seed as it is. All other streams hash the
+// given seed together with their name.
+func streamSeed(seed int64, stream string) int64 {
+	if stream == "" {
+		return seed
+	}
+
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d:%s", seed, stream)
+
+	return int64(h.Sum64())
+}
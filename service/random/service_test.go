@@ -0,0 +1,108 @@
+package random
+
+import (
+	"reflect"
+	"testing"
+)
+
+func testService(t *testing.T, seed int64) *service {
+	newConfig := DefaultConfig()
+	newConfig.Seed = seed
+	newService, err := New(newConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	return newService.(*service)
+}
+
+func Test_Random_New_Error_Seed(t *testing.T) {
+	newConfig := DefaultConfig()
+	newConfig.Seed = 0
+	_, err := New(newConfig)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+func Test_Random_CreateNMax_Seed(t *testing.T) {
+	newNumbers := func(seed int64) []int {
+		newService := testService(t, seed)
+
+		numbers, err := newService.CreateNMax(10, 100)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		n, err := newService.CreateMax(100)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		return append(numbers, n)
+	}
+
+	numbers1 := newNumbers(1)
+	if len(numbers1) != 11 {
+		t.Fatal("expected", 11, "got", len(numbers1))
+	}
+	for _, n := range numbers1 {
+		if n < 0 || n >= 100 {
+			t.Fatal("expected", "[0 100)", "got", n)
+		}
+	}
+	if !reflect.DeepEqual(numbers1, newNumbers(1)) {
+		t.Fatal("expected", "same numbers", "got", "different numbers")
+	}
+	if reflect.DeepEqual(numbers1, newNumbers(2)) {
+		t.Fatal("expected", "different numbers", "got", "same numbers")
+	}
+}
+
+func Test_Random_CreateMax_Error_Max(t *testing.T) {
+	newService := testService(t, 1)
+
+	_, err := newService.CreateMax(0)
+	if !IsInvalidMax(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+	_, err = newService.CreateNMax(3, -1)
+	if !IsInvalidMax(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+func Test_Random_CreateNMax_Stream(t *testing.T) {
+	newNumbers := func(stream string) []int {
+		newConfig := DefaultConfig()
+		newConfig.Seed = 1
+		newConfig.Stream = stream
+		newService, err := New(newConfig)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		numbers, err := newService.CreateNMax(10, 100)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		return numbers
+	}
+
+	numbers1 := newNumbers("position")
+	if !reflect.DeepEqual(numbers1, newNumbers("position")) {
+		t.Fatal("expected", "same numbers", "got", "different numbers")
+	}
+	if reflect.DeepEqual(numbers1, newNumbers("")) {
+		t.Fatal("expected", "different numbers", "got", "same numbers")
+	}
+
+	// The default stream is the sequence of the seed.
+	numbers2, err := testService(t, 1).CreateNMax(10, 100)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if !reflect.DeepEqual(numbers2, newNumbers("")) {
+		t.Fatal("expected", "same numbers", "got", "different numbers")
+	}
+}
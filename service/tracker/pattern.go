@@ -0,0 +1,279 @@
+package tracker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/the-anna-project/annad/service/behaviour"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+)
+
+const (
+	// successRateKey is the key of the scored set holding all path patterns
+	// scored by their success rate.
+	successRateKey = "pattern:success-rate"
+
+	// supportKey is the key of the scored set holding all path patterns scored
+	// by their support.
+	supportKey = "pattern:support"
+
+	// unknownSuccessRate is the success rate of path patterns never counted.
+	// That is the smoothed success rate of path patterns without support.
+	unknownSuccessRate = 0.5
+)
+
+func (s *service) CLGPatterns(CLG servicespec.CLGService, networkPayload objectspec.NetworkPayload) error {
+	err := s.clgPatterns(CLG.Metadata()["kind"], networkPayload)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
+
+// clgPatterns implements CLGPatterns for the CLG of the given kind.
+func (s *service) clgPatterns(clgKind string, networkPayload objectspec.NetworkPayload) error {
+	patterns, err := s.networkPayloadPatterns(clgKind, networkPayload)
+	if err != nil {
+		return maskAny(err)
+	}
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	// Record the path patterns for the CLG tree. Each path pattern is only
+	// counted once per CLG tree, no matter how often it occurred.
+	clgTreeID, ok := networkPayload.GetContext().GetCLGTreeID()
+	if !ok {
+		return maskAnyf(invalidCLGTreeIDError, "must not be empty")
+	}
+	for _, p := range patterns {
+		err := s.Service().Storage().General().PushToSet(treePatternsKey(clgTreeID), p)
+		if err != nil {
+			return maskAny(err)
+		}
+	}
+
+	return nil
+}
+
+func (s *service) EndCLGTree(clgTreeID string, success bool) error {
+	s.Service().Log().Line("func", "EndCLGTree")
+
+	patterns, err := s.Service().Storage().General().GetAllFromSet(treePatternsKey(clgTreeID))
+	if err != nil {
+		return maskAny(err)
+	}
+
+	s.patternMutex.Lock()
+	defer s.patternMutex.Unlock()
+
+	for _, p := range patterns {
+		stats, err := s.Service().Storage().General().GetStringMap(patternStatsKey(p))
+		if err != nil {
+			return maskAny(err)
+		}
+		stats, err = countPattern(stats, success)
+		if err != nil {
+			return maskAny(err)
+		}
+		err = s.Service().Storage().General().SetStringMap(patternStatsKey(p), stats)
+		if err != nil {
+			return maskAny(err)
+		}
+
+		support, err := strconv.ParseFloat(stats["support"], 64)
+		if err != nil {
+			return maskAny(err)
+		}
+		err = s.Service().Storage().General().SetElementByScore(supportKey, p, support)
+		if err != nil {
+			return maskAny(err)
+		}
+		successRate, err := strconv.ParseFloat(stats["success-rate"], 64)
+		if err != nil {
+			return maskAny(err)
+		}
+		err = s.Service().Storage().General().SetElementByScore(successRateKey, p, successRate)
+		if err != nil {
+			return maskAny(err)
+		}
+	}
+
+	err = s.Service().Storage().General().Remove(treePatternsKey(clgTreeID))
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
+
+// networkPayloadPatterns returns the path patterns the given network payload
+// completes when entering the CLG of the given kind.
+func (s *service) networkPayloadPatterns(clgKind string, networkPayload objectspec.NetworkPayload) ([]string, error) {
+	// Resolve the CLG kinds of the behaviour IDs the network payload passed,
+	// starting with the most recent one. We only need as many CLG kinds as the
+	// longest path pattern requires. Behaviour IDs not being registered break
+	// the chain of CLG kinds.
+	var kinds []string
+	path := networkPayload.GetPath()
+	for i := len(path) - 1; i >= 0 && len(kinds) < s.patternLength-1; i-- {
+		behaviourMetadata, err := s.Service().Behaviour().Search(path[i])
+		if behaviour.IsNotFound(err) {
+			break
+		} else if err != nil {
+			return nil, maskAny(err)
+		}
+		kinds = append([]string{behaviourMetadata["clg-kind"]}, kinds...)
+	}
+	kinds = append(kinds, clgKind)
+
+	return pathPatterns(kinds, s.patternLength), nil
+}
+
+func (s *service) PathSuccessRate(CLG servicespec.CLGService, networkPayload objectspec.NetworkPayload) (float64, error) {
+	s.Service().Log().Line("func", "PathSuccessRate")
+
+	patterns, err := s.networkPayloadPatterns(CLG.Metadata()["kind"], networkPayload)
+	if err != nil {
+		return 0, maskAny(err)
+	}
+	if len(patterns) == 0 {
+		return unknownSuccessRate, nil
+	}
+
+	var sum float64
+	for _, p := range patterns {
+		stats, err := s.Service().Storage().General().GetStringMap(patternStatsKey(p))
+		if err != nil {
+			return 0, maskAny(err)
+		}
+		if stats["success-rate"] == "" {
+			// The path pattern was never counted.
+			sum += unknownSuccessRate
+			continue
+		}
+		successRate, err := strconv.ParseFloat(stats["success-rate"], 64)
+		if err != nil {
+			return 0, maskAny(err)
+		}
+		sum += successRate
+	}
+
+	return sum / float64(len(patterns)), nil
+}
+
+func (s *service) PatternStats(pattern string) (map[string]string, error) {
+	s.Service().Log().Line("func", "PatternStats")
+
+	stats, err := s.Service().Storage().General().GetStringMap(patternStatsKey(pattern))
+	if err != nil {
+		return nil, maskAny(err)
+	}
+	if len(stats) == 0 {
+		return nil, maskAnyf(notFoundError, "path pattern '%s'", pattern)
+	}
+
+	return stats, nil
+}
+
+func (s *service) PatternsBySuccessRate(maxPatterns int) ([]string, error) {
+	s.Service().Log().Line("func", "PatternsBySuccessRate")
+
+	patterns, err := s.highestScoredPatterns(successRateKey, maxPatterns)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	return patterns, nil
+}
+
+func (s *service) PatternsBySupport(maxPatterns int) ([]string, error) {
+	s.Service().Log().Line("func", "PatternsBySupport")
+
+	patterns, err := s.highestScoredPatterns(supportKey, maxPatterns)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	return patterns, nil
+}
+
+// highestScoredPatterns returns up to maxPatterns path patterns of the scored
+// set identified by the given key, where the highest scored path pattern is
+// the first in the returned list.
+func (s *service) highestScoredPatterns(key string, maxPatterns int) ([]string, error) {
+	if maxPatterns <= 0 {
+		return nil, nil
+	}
+
+	result, err := s.Service().Storage().General().GetHighestScoredElements(key, maxPatterns)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	// The result contains the scores as well. See the scheme described by
+	// servicespec.StorageService.GetHighestScoredElements.
+	var patterns []string
+	for i := 0; i < len(result) && len(patterns) < maxPatterns; i += 2 {
+		patterns = append(patterns, result[i])
+	}
+
+	return patterns, nil
+}
+
+// countPattern returns the given statistics of a path pattern having the
+// given outcome of a CLG tree counted. Missing statistics are treated as 0.
+func countPattern(stats map[string]string, success bool) (map[string]string, error) {
+	var counts []int
+	for _, field := range []string{"support", "success", "failure"} {
+		if stats[field] == "" {
+			counts = append(counts, 0)
+			continue
+		}
+		c, err := strconv.Atoi(stats[field])
+		if err != nil {
+			return nil, maskAny(err)
+		}
+		counts = append(counts, c)
+	}
+	support, successes, failures := counts[0]+1, counts[1], counts[2]
+	if success {
+		successes++
+	} else {
+		failures++
+	}
+	successRate := float64(successes+1) / float64(support+2)
+
+	newStats := map[string]string{
+		"failure":      strconv.Itoa(failures),
+		"success":      strconv.Itoa(successes),
+		"success-rate": strconv.FormatFloat(successRate, 'f', -1, 64),
+		"support":      strconv.Itoa(support),
+	}
+
+	return newStats, nil
+}
+
+// pathPatterns returns the path patterns ending with the last of the given CLG
+// kinds. Path patterns are made of at least two and up to maxLength CLG kinds,
+// joined by commas, starting with the shortest.
+func pathPatterns(kinds []string, maxLength int) []string {
+	var patterns []string
+
+	for n := 2; n <= maxLength && n <= len(kinds); n++ {
+		patterns = append(patterns, strings.Join(kinds[len(kinds)-n:], ","))
+	}
+
+	return patterns
+}
+
+func patternStatsKey(pattern string) string {
+	return fmt.Sprintf("pattern:%s:stats", pattern)
+}
+
+func treePatternsKey(clgTreeID string) string {
+	return fmt.Sprintf("clg-tree-id:%s:patterns", clgTreeID)
+}
@@ -0,0 +1,336 @@
+package tracker
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	kitlog "github.com/go-kit/kit/log"
+
+	servicecollection "github.com/the-anna-project/annad/collection/collection"
+	"github.com/the-anna-project/annad/id"
+	memoryinstrumentor "github.com/the-anna-project/annad/instrumentor/memory"
+	"github.com/the-anna-project/annad/log"
+	"github.com/the-anna-project/annad/object/context"
+	"github.com/the-anna-project/annad/object/networkpayload"
+	"github.com/the-anna-project/annad/random"
+	"github.com/the-anna-project/annad/service/behaviour"
+	"github.com/the-anna-project/annad/service/network"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+	storagecollection "github.com/the-anna-project/annad/storage/collection"
+	memorystorage "github.com/the-anna-project/annad/storage/service/memory"
+)
+
+type testCLG struct {
+	kind string
+}
+
+func (c *testCLG) Boot() {}
+
+func (c *testCLG) Call(ctx objectspec.Context, args []interface{}) ([]interface{}, error) {
+	return nil, nil
+}
+
+func (c *testCLG) GetCalculate() interface{} {
+	return nil
+}
+
+func (c *testCLG) InputTypes() []string {
+	return nil
+}
+
+func (c *testCLG) Metadata() map[string]string {
+	return map[string]string{"kind": c.kind}
+}
+
+func (c *testCLG) OutputTypes() []string {
+	return nil
+}
+
+func (c *testCLG) Service() servicespec.ServiceCollection {
+	return nil
+}
+
+func (c *testCLG) SetServiceCollection(serviceCollection servicespec.ServiceCollection) {}
+
+// testService creates a new tracker service backed by memory storage. The
+// returned function shuts the storage down.
+func testService(t *testing.T) (servicespec.TrackerService, func()) {
+	return testServiceWithNetwork(t, network.DefaultConfig())
+}
+
+// testServiceWithNetwork works like testService, but its service collection
+// holds a network service created using the given configuration.
+func testServiceWithNetwork(t *testing.T, networkConfig network.Config) (servicespec.TrackerService, func()) {
+	newNetworkService, err := network.New(networkConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	newLogService := log.New()
+	newLogService.SetRootLogger(kitlog.NewNopLogger())
+
+	newStorageCollection := storagecollection.New()
+	newStorageCollection.SetGeneralService(memorystorage.New())
+
+	newTrackerService, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	collection := servicecollection.New()
+	collection.SetBehaviourService(behaviour.New())
+	collection.SetIDService(id.New())
+	collection.SetInstrumentorService(memoryinstrumentor.New())
+	collection.SetLogService(newLogService)
+	collection.SetNetworkService(newNetworkService)
+	collection.SetRandomService(random.New())
+	collection.SetStorageCollection(newStorageCollection)
+	collection.SetTrackerService(newTrackerService)
+
+	collection.Behaviour().SetServiceCollection(collection)
+	collection.ID().SetServiceCollection(collection)
+	collection.Log().SetServiceCollection(collection)
+	collection.Random().SetServiceCollection(collection)
+	collection.Storage().General().SetServiceCollection(collection)
+	collection.Tracker().SetServiceCollection(collection)
+
+	collection.Storage().General().Boot()
+
+	return collection.Tracker(), func() {
+		collection.Storage().General().Shutdown()
+	}
+}
+
+// testPath registers a behaviour ID for each of the given CLG kinds within the
+// CLG tree identified by the given CLG tree ID and tracks the path patterns
+// of a network payload passing these behaviour IDs in this order, the same way
+// the network tracks each hop.
+func testPath(t *testing.T, newService servicespec.TrackerService, clgTreeID string, kinds ...string) {
+	var path []string
+	for i, k := range kinds {
+		ctx := context.MustNew()
+		ctx.SetCLGTreeID(clgTreeID)
+
+		newNetworkPayloadConfig := networkpayload.DefaultConfig()
+		newNetworkPayloadConfig.Context = ctx
+		newNetworkPayloadConfig.Destination = "destination"
+		newNetworkPayloadConfig.Path = path
+		newNetworkPayloadConfig.Sources = []string{"source"}
+		newNetworkPayload, err := networkpayload.New(newNetworkPayloadConfig)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		err = newService.CLGPatterns(&testCLG{kind: k}, newNetworkPayload)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+
+		behaviourID := fmt.Sprintf("%s-%d", clgTreeID, i)
+		err = newService.Service().Behaviour().Create(behaviourID, k, clgTreeID)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		path = append(path, behaviourID)
+	}
+}
+
+func Test_Tracker_New_Error_PatternLength(t *testing.T) {
+	newConfig := DefaultConfig()
+	newConfig.PatternLength = 1
+	_, err := New(newConfig)
+	if !IsInvalidConfig(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
+
+func Test_Tracker_pathPatterns(t *testing.T) {
+	testCases := []struct {
+		Kinds     []string
+		MaxLength int
+		Expected  []string
+	}{
+		{
+			Kinds:     []string{"input"},
+			MaxLength: 3,
+			Expected:  nil,
+		},
+		{
+			Kinds:     []string{"input", "sum"},
+			MaxLength: 3,
+			Expected:  []string{"input,sum"},
+		},
+		{
+			Kinds:     []string{"input", "sum", "round", "output"},
+			MaxLength: 3,
+			Expected:  []string{"round,output", "sum,round,output"},
+		},
+		{
+			Kinds:     []string{"input", "sum", "round", "output"},
+			MaxLength: 2,
+			Expected:  []string{"round,output"},
+		},
+	}
+
+	for i, testCase := range testCases {
+		output := pathPatterns(testCase.Kinds, testCase.MaxLength)
+		if !reflect.DeepEqual(output, testCase.Expected) {
+			t.Fatal("case", i+1, "expected", testCase.Expected, "got", output)
+		}
+	}
+}
+
+func Test_Tracker_countPattern(t *testing.T) {
+	stats, err := countPattern(map[string]string{}, true)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	stats, err = countPattern(stats, false)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	stats, err = countPattern(stats, true)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	expected := map[string]string{
+		"failure":      "1",
+		"success":      "2",
+		"success-rate": "0.6",
+		"support":      "3",
+	}
+	if !reflect.DeepEqual(stats, expected) {
+		t.Fatal("expected", expected, "got", stats)
+	}
+}
+
+func Test_Tracker_EndCLGTree(t *testing.T) {
+	newService, shutdown := testService(t)
+	defer shutdown()
+
+	// Two successful CLG trees find the output by summing. One failed CLG tree
+	// tries to find the output by dividing. The pattern occurring twice within
+	// the same CLG tree is only counted once.
+	testPath(t, newService, "tree-1", "input", "sum", "output")
+	testPath(t, newService, "tree-1", "input", "sum", "output")
+	testPath(t, newService, "tree-2", "input", "sum", "output")
+	testPath(t, newService, "tree-3", "input", "divide", "output")
+	testPath(t, newService, "tree-3", "input", "sum")
+
+	for _, e := range []struct {
+		CLGTreeID string
+		Success   bool
+	}{
+		{CLGTreeID: "tree-1", Success: true},
+		{CLGTreeID: "tree-2", Success: true},
+		{CLGTreeID: "tree-3", Success: false},
+	} {
+		err := newService.EndCLGTree(e.CLGTreeID, e.Success)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	stats, err := newService.PatternStats("input,sum")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	expected := map[string]string{
+		"failure":      "1",
+		"success":      "2",
+		"success-rate": "0.6",
+		"support":      "3",
+	}
+	if !reflect.DeepEqual(stats, expected) {
+		t.Fatal("expected", expected, "got", stats)
+	}
+	_, err = newService.PatternStats("input,output")
+	if !IsNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	patterns, err := newService.PatternsBySupport(1)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if !reflect.DeepEqual(patterns, []string{"input,sum"}) {
+		t.Fatal("expected", []string{"input,sum"}, "got", patterns)
+	}
+
+	patterns, err = newService.PatternsBySuccessRate(2)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	expectedPatterns := []string{"sum,output", "input,sum,output"}
+	if !reflect.DeepEqual(patterns, expectedPatterns) {
+		t.Fatal("expected", expectedPatterns, "got", patterns)
+	}
+}
+
+func Test_Tracker_PathSuccessRate(t *testing.T) {
+	newService, shutdown := testService(t)
+	defer shutdown()
+
+	testPath(t, newService, "tree-1", "input", "sum", "output")
+	testPath(t, newService, "tree-2", "input", "divide", "output")
+	err := newService.EndCLGTree("tree-1", true)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	err = newService.EndCLGTree("tree-2", false)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	testCases := []struct {
+		Path     []string
+		Kind     string
+		Expected float64
+	}{
+		{
+			Path:     []string{"tree-1-0", "tree-1-1"},
+			Kind:     "output",
+			Expected: 2.0 / 3.0,
+		},
+		{
+			Path:     []string{"tree-2-0", "tree-2-1"},
+			Kind:     "output",
+			Expected: 1.0 / 3.0,
+		},
+		// Path patterns never counted rate the same as path patterns without
+		// support.
+		{
+			Path:     []string{"tree-1-0"},
+			Kind:     "round",
+			Expected: 0.5,
+		},
+		{
+			Path:     nil,
+			Kind:     "input",
+			Expected: 0.5,
+		},
+	}
+
+	for i, testCase := range testCases {
+		newNetworkPayloadConfig := networkpayload.DefaultConfig()
+		newNetworkPayloadConfig.Context = context.MustNew()
+		newNetworkPayloadConfig.Destination = "destination"
+		newNetworkPayloadConfig.Path = testCase.Path
+		newNetworkPayloadConfig.Sources = []string{"source"}
+		newNetworkPayload, err := networkpayload.New(newNetworkPayloadConfig)
+		if err != nil {
+			t.Fatal("case", i+1, "expected", nil, "got", err)
+		}
+
+		successRate, err := newService.PathSuccessRate(&testCLG{kind: testCase.Kind}, newNetworkPayload)
+		if err != nil {
+			t.Fatal("case", i+1, "expected", nil, "got", err)
+		}
+		if successRate != testCase.Expected {
+			t.Fatal("case", i+1, "expected", testCase.Expected, "got", successRate)
+		}
+	}
+}
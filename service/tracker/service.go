@@ -1,22 +1,52 @@
 package tracker
 
 import (
-	"fmt"
+	"sync"
 
-	objectspec "github.com/the-anna-project/spec/object"
-	servicespec "github.com/the-anna-project/spec/service"
+	"github.com/the-anna-project/annad/service/behaviour"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
 )
 
+// Config represents the configuration used to create a new tracker service.
+type Config struct {
+	// Settings.
+
+	// PatternLength is the maximum number of CLG kinds a path pattern is made
+	// of. Path patterns are made of at least two CLG kinds.
+	PatternLength int
+}
+
+// DefaultConfig provides a default configuration to create a new tracker
+// service by best effort.
+func DefaultConfig() Config {
+	newConfig := Config{
+		// Settings.
+		PatternLength: 3,
+	}
+
+	return newConfig
+}
+
 // New creates a new tracker service.
-func New() servicespec.TrackerService {
-	return &service{
+func New(config Config) (servicespec.TrackerService, error) {
+	// Settings.
+	if config.PatternLength < 2 {
+		return nil, maskAnyf(invalidConfigError, "pattern length must be greater than 1")
+	}
+
+	newService := &service{
 		// Dependencies.
 		serviceCollection: nil,
 
 		// Settings.
-		closer:   make(chan struct{}, 1),
-		metadata: map[string]string{},
+		closer:        make(chan struct{}, 1),
+		metadata:      map[string]string{},
+		patternLength: config.PatternLength,
+		patternMutex:  sync.Mutex{},
 	}
+
+	return newService, nil
 }
 
 type service struct {
@@ -26,8 +56,12 @@ type service struct {
 
 	// Settings.
 
-	closer   chan struct{}
-	metadata map[string]string
+	closer        chan struct{}
+	metadata      map[string]string
+	patternLength int
+	// patternMutex guards the statistics of path patterns against concurrent
+	// modifications of CLG trees ending at the same time.
+	patternMutex sync.Mutex
 }
 
 func (s *service) Boot() {
@@ -40,9 +74,51 @@ func (s *service) Boot() {
 		"name": "tracker",
 		"type": "service",
 	}
+
+	// Track asynchronously. The network publishes network events on the bus,
+	// so tracking is kept off the hot path of the network. The subscription is
+	// blocking, because each network event the tracker missed would lose
+	// connections and path patterns for good. Publishers never hold locks while
+	// publishing, so a slow tracker only slows down the publishing goroutine
+	// instead of stalling all event listeners of the network.
+	networkEvents, err := s.Service().Bus().SubscribeBlocking("tracker")
+	if err != nil {
+		panic(err)
+	}
+
+	go func() {
+		// Create a new execute config for the worker service to execute the
+		// subscriber.
+		executeConfig := s.Service().Worker().ExecuteConfig()
+		executeConfig.SetActions([]func(canceler <-chan struct{}) error{
+			func(canceler <-chan struct{}) error {
+				return s.subscriber(canceler, networkEvents)
+			},
+		})
+		executeConfig.SetCanceler(s.closer)
+		executeConfig.SetNumWorkers(1)
+		err := s.Service().Worker().Execute(executeConfig)
+		if err != nil {
+			s.Service().Log().Line("msg", maskAny(err))
+		}
+
+		// Publishers must not wait for the tracker as soon as it stopped
+		// receiving network events.
+		s.Service().Bus().Unsubscribe("tracker")
+	}()
 }
 
 func (s *service) CLGIDs(CLG servicespec.CLGService, networkPayload objectspec.NetworkPayload) error {
+	err := s.clgIDs(CLG.Metadata()["kind"], networkPayload)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
+
+// clgIDs implements CLGIDs for the CLG of the given kind.
+func (s *service) clgIDs(clgKind string, networkPayload objectspec.NetworkPayload) error {
 	destinationID := string(networkPayload.GetDestination())
 	sourceIDs := networkPayload.GetSources()
 
@@ -91,7 +167,17 @@ func (s *service) CLGIDs(CLG servicespec.CLGService, networkPayload objectspec.N
 }
 
 func (s *service) CLGNames(CLG servicespec.CLGService, networkPayload objectspec.NetworkPayload) error {
-	destinationName := CLG.Metadata()["name"]
+	err := s.clgNames(CLG.Metadata()["kind"], networkPayload)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
+
+// clgNames implements CLGNames for the CLG of the given kind.
+func (s *service) clgNames(clgKind string, networkPayload objectspec.NetworkPayload) error {
+	destinationName := clgKind
 	sourceIDs := networkPayload.GetSources()
 
 	// Prepare a queue to synchronise the workload.
@@ -107,14 +193,16 @@ func (s *service) CLGNames(CLG servicespec.CLGService, networkPayload objectspec
 		func(canceler <-chan struct{}) error {
 			sourceID := <-queue
 
-			// Resolve behaviour ID to CLG name.
-			//
-			// TODO handle mapping of CLG ID/Name in separate service
-			behaviourNameKey := fmt.Sprintf("behaviour-id:%s:behaviour-name", sourceID)
-			sourceName, err := s.Service().Storage().General().Get(behaviourNameKey)
-			if err != nil {
+			// Resolve behaviour ID to CLG name. Sources not being registered as
+			// behaviour, like the network itself forwarding the input, are not
+			// tracked.
+			behaviourMetadata, err := s.Service().Behaviour().Search(sourceID)
+			if behaviour.IsNotFound(err) {
+				return nil
+			} else if err != nil {
 				return maskAny(err)
 			}
+			sourceName := behaviourMetadata["clg-kind"]
 
 			// Connect source and destination name of the CLG in the behaviour layer
 			// of the connection space.
@@ -162,10 +250,25 @@ func (s *service) SetServiceCollection(sc servicespec.ServiceCollection) {
 func (s *service) Track(CLG servicespec.CLGService, networkPayload objectspec.NetworkPayload) error {
 	s.Service().Log().Line("func", "Track")
 
+	if s.Service().Network().Frozen() {
+		return nil
+	}
+
+	err := s.track(CLG.Metadata()["kind"], networkPayload)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	return nil
+}
+
+// track implements Track for the CLG of the given kind.
+func (s *service) track(clgKind string, networkPayload objectspec.NetworkPayload) error {
 	// This is the list of lookup functions which is executed seuqentially.
-	lookups := []func(CLG servicespec.CLGService, networkPayload objectspec.NetworkPayload) error{
-		s.CLGIDs,
-		s.CLGNames,
+	lookups := []func(clgKind string, networkPayload objectspec.NetworkPayload) error{
+		s.clgIDs,
+		s.clgNames,
+		s.clgPatterns,
 	}
 
 	// Execute one lookup after another to track connection path patterns.
@@ -173,7 +276,7 @@ func (s *service) Track(CLG servicespec.CLGService, networkPayload objectspec.Ne
 	// TODO execute concurrently
 	var err error
 	for _, l := range lookups {
-		err = l(CLG, networkPayload)
+		err = l(clgKind, networkPayload)
 		if err != nil {
 			return maskAny(err)
 		}
@@ -0,0 +1,56 @@
+package tracker
+
+import (
+	"github.com/the-anna-project/annad/object/networkevent"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+)
+
+// subscriber is a worker pool function which handles the given network events
+// published on the bus until the subscription ends. Successfully calculated
+// network payloads are tracked. Ended CLG trees have their path patterns
+// counted.
+func (s *service) subscriber(canceler <-chan struct{}, networkEvents <-chan objectspec.NetworkEvent) error {
+	for {
+		select {
+		case <-canceler:
+			return nil
+		case networkEvent, ok := <-networkEvents:
+			if !ok {
+				return nil
+			}
+			err := s.handleNetworkEvent(networkEvent)
+			if err != nil {
+				s.Service().Log().Line("msg", maskAny(err))
+			}
+		}
+	}
+}
+
+// handleNetworkEvent tracks the given network event, if it is of interest for
+// the tracker.
+func (s *service) handleNetworkEvent(networkEvent objectspec.NetworkEvent) error {
+	if s.Service().Network().Frozen() {
+		// A frozen network does not learn from its CLG trees.
+		return nil
+	}
+
+	switch networkEvent.GetKind() {
+	case networkevent.KindCalculated:
+		if networkEvent.GetError() != "" || networkEvent.GetNetworkPayload() == nil {
+			return nil
+		}
+		err := s.track(networkEvent.GetCLGName(), networkEvent.GetNetworkPayload())
+		if err != nil {
+			return maskAny(err)
+		}
+	case networkevent.KindTreeFinished:
+		// CLG trees which did not answer carry an error and are considered
+		// failed.
+		err := s.EndCLGTree(networkEvent.GetCLGTreeID(), networkEvent.GetError() == "")
+		if err != nil {
+			return maskAny(err)
+		}
+	}
+
+	return nil
+}
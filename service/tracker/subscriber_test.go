@@ -0,0 +1,67 @@
+package tracker
+
+import (
+	"testing"
+
+	"github.com/the-anna-project/annad/object/networkevent"
+	"github.com/the-anna-project/annad/service/network"
+)
+
+func Test_Tracker_handleNetworkEvent_TreeFinished(t *testing.T) {
+	newService, shutdown := testService(t)
+	defer shutdown()
+
+	testPath(t, newService, "tree-1", "input", "sum")
+	testPath(t, newService, "tree-2", "input", "sum")
+
+	// The CLG tree which did not answer carries an error.
+	for clgTreeID, errorMessage := range map[string]string{"tree-1": "", "tree-2": "no answer within budget"} {
+		newConfig := networkevent.DefaultConfig()
+		newConfig.CLGTreeID = clgTreeID
+		newConfig.Error = errorMessage
+		newConfig.Kind = networkevent.KindTreeFinished
+		newNetworkEvent, err := networkevent.New(newConfig)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+		err = newService.(*service).handleNetworkEvent(newNetworkEvent)
+		if err != nil {
+			t.Fatal("expected", nil, "got", err)
+		}
+	}
+
+	stats, err := newService.PatternStats("input,sum")
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if stats["success"] != "1" || stats["failure"] != "1" {
+		t.Fatal("expected", "1 success and 1 failure", "got", stats)
+	}
+}
+
+func Test_Tracker_handleNetworkEvent_Frozen(t *testing.T) {
+	newNetworkConfig := network.DefaultConfig()
+	newNetworkConfig.Frozen = true
+	newService, shutdown := testServiceWithNetwork(t, newNetworkConfig)
+	defer shutdown()
+
+	testPath(t, newService, "tree-1", "input", "sum")
+
+	newConfig := networkevent.DefaultConfig()
+	newConfig.CLGTreeID = "tree-1"
+	newConfig.Kind = networkevent.KindTreeFinished
+	newNetworkEvent, err := networkevent.New(newConfig)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	err = newService.(*service).handleNetworkEvent(newNetworkEvent)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	// A frozen network neither tracks path patterns nor their outcome.
+	_, err = newService.PatternStats("input,sum")
+	if !IsNotFound(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+}
@@ -0,0 +1,52 @@
+// Package api provides information to communicate with the network API. Client
+// and server implementations make use of this to provide consistent API
+// schemes.
+package api
+
+var (
+	// CodeData represents the API response code of a data response.
+	CodeData = "10001"
+
+	// TextData represents the API response text of a data response.
+	TextData = "data"
+
+	// CodeSuccess represents the API response code of a success response.
+	CodeSuccess = "10002"
+
+	// TextSuccess represents the API response text of a success response.
+	TextSuccess = "success"
+
+	// CodeError represents the API response code of a error response.
+	CodeError = "10003"
+
+	// TextError represents the API response text of a error response.
+	TextError = "error"
+
+	// CodeBudgetExhausted represents the API response code of a response telling
+	// that the neural network exhausted the budget of a request without
+	// answering it.
+	CodeBudgetExhausted = "10004"
+
+	// TextBudgetExhausted represents the API response text of a budget exhausted
+	// response.
+	TextBudgetExhausted = "budget exhausted"
+
+	// CodeNoLearnedPath represents the API response code of a response telling
+	// that a frozen neural network does not know how to answer a request,
+	// because it did not learn any path leading to the output.
+	CodeNoLearnedPath = "10005"
+
+	// TextNoLearnedPath represents the API response text of a no learned path
+	// response.
+	TextNoLearnedPath = "no learned path"
+
+	// CodeRetriesExhausted represents the API response code of a response
+	// telling that the neural network gave up on meeting the expectation of a
+	// request, because it exceeded the retry limit. The response carries the
+	// calculated output coming closest to the expectation.
+	CodeRetriesExhausted = "10006"
+
+	// TextRetriesExhausted represents the API response text of a retries
+	// exhausted response.
+	TextRetriesExhausted = "retries exhausted"
+)
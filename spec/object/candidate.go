@@ -0,0 +1,17 @@
+package object
+
+// Candidate represents one of the outputs a CLG tree calculated for the input
+// it was created for. Multiple branches of a CLG tree may reach the output
+// CLG. The network gathers their outputs and ranks them as candidates.
+type Candidate struct {
+	// BehaviourIDs are the behaviour IDs of the CLGs along the path which
+	// calculated the output, starting with the first behaviour of the CLG tree
+	// and ending with the output CLG.
+	BehaviourIDs []string
+	// Output is the output calculated by the neural network.
+	Output string
+	// Score is the confidence of the neural network in the output, ranging from
+	// 0 to 1. It is derived from the connection weights along the path and from
+	// how successful similar paths were before.
+	Score float64
+}
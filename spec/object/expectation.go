@@ -0,0 +1,25 @@
+package object
+
+// Expectation represents a description of what output is to be expected when
+// requesting calculations by providing some input.
+type Expectation interface {
+	// GetMatcher returns the name of the matcher used to check calculated output
+	// against the expectation. An empty name means the calculated output must
+	// equal the expected output exactly.
+	GetMatcher() string
+	// GetOutput returns the configured output of the expectation. This output
+	// represents the output which is expected to be calculated by the neural
+	// network. Depending on the matcher the output is e.g. a regular expression
+	// or a number.
+	GetOutput() string
+	// GetOutputs returns alternative outputs which are expected as well, if the
+	// matcher supports them.
+	GetOutputs() []string
+	// GetThreshold returns the threshold of the matcher, if the matcher
+	// supports one. E.g. the tolerance of numbers, or the maximum edit
+	// distance.
+	GetThreshold() float64
+	SetMatcher(matcher string)
+	SetOutputs(outputs []string)
+	SetThreshold(threshold float64)
+}
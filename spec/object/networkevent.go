@@ -0,0 +1,79 @@
+package object
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// NetworkEvent represents something that happened within the neural network.
+// Network events are published using the BusService. The following kinds of
+// network events are known.
+//
+//     input-received
+//
+//         input-received is published as soon as the network received input
+//         and created a new CLG tree for it.
+//
+//     activated
+//
+//         activated is published as soon as the interface of a requested CLG
+//         is satisfied.
+//
+//     calculated
+//
+//         calculated is published as soon as a CLG executed its business
+//         logic, successfully or not.
+//
+//     forwarded
+//
+//         forwarded is published as soon as the network payload calculated by
+//         a CLG is forwarded to other CLGs.
+//
+//     output-emitted
+//
+//         output-emitted is published as soon as output was sent to the
+//         client. That is output of the output CLG, or the terminal response
+//         of a CLG tree exhausting its budget. The network payload of the
+//         network event carries the output as its only argument.
+//
+//     tree-finished
+//
+//         tree-finished is published as soon as a CLG tree ended. In case the
+//         CLG tree did not answer, the network event carries an error.
+//
+type NetworkEvent interface {
+	// GetBehaviourID returns the behaviour ID of the CLG the network event is
+	// associated with, if any.
+	GetBehaviourID() string
+
+	// GetCLGName returns the name of the CLG the network event is associated
+	// with, if any.
+	GetCLGName() string
+
+	// GetCLGTreeID returns the ID of the CLG tree the network event belongs to.
+	GetCLGTreeID() string
+
+	// GetDuration returns the duration the stage of the network event took, if
+	// measured.
+	GetDuration() time.Duration
+
+	// GetError returns the error message of the stage of the network event, if
+	// any.
+	GetError() string
+
+	// GetKind returns the kind of the network event, e.g. "calculated".
+	GetKind() string
+
+	// GetNetworkPayload returns the network payload the network event is
+	// associated with, if any.
+	GetNetworkPayload() NetworkPayload
+
+	// GetSessionID returns the ID of the session the network event belongs to,
+	// if any.
+	GetSessionID() string
+
+	// GetTime returns the point in time the network event happened.
+	GetTime() time.Time
+
+	json.Marshaler
+}
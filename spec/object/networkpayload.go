@@ -20,6 +20,11 @@ type NetworkPayload interface {
 	// GetContext returns the context of the current network payload.
 	GetContext() Context
 
+	// GetDepth returns the number of hops the current network payload travelled
+	// within its CLG tree. The network payload created for the input CLG has a
+	// depth of 0.
+	GetDepth() int
+
 	// GetArgs returns the destination of the current network payload, which must
 	// be the ID of a CLG registered within the neural network.
 	GetDestination() string
@@ -27,6 +32,11 @@ type NetworkPayload interface {
 	// GetID returns the object ID of the current network payload.
 	GetID() string
 
+	// GetPath returns the behaviour IDs of the CLGs the current network payload
+	// passed within its CLG tree, ordered from the first to the most recent
+	// one. The path is used to detect cycles within CLG trees.
+	GetPath() []string
+
 	// GetArgs returns the sources of the current network payload, which must be
 	// the ID of a CLG registered within the neural network. One allowed exception
 	// is the very first source of the very first network payload, which is
@@ -0,0 +1,38 @@
+package object
+
+import (
+	"time"
+)
+
+// TextInput represents a streamed request being send to the neural network.
+// This is basically good for requesting calculations from the neural network
+// by providing text input and an optional expectation object.
+type TextInput interface {
+	// Backoff returns the initial delay between the retries of the current text
+	// request. Retries are caused by output not meeting the expectation. The
+	// delay doubles with each retry. A backoff of 0 means the network's default
+	// is used.
+	Backoff() time.Duration
+	// Deadline returns the duration the CLG tree of the current text request is
+	// allowed to calculate an answer. A deadline of 0 means the network's
+	// default is used.
+	Deadline() time.Duration
+	// Echo returns the echo flag of the current text request.
+	Echo() bool
+	// Expectation returns the expectation of the current text request.
+	Expectation() Expectation
+	// Input returns the input of the current text request.
+	Input() string
+	// Retries returns the maximum number of retries of the current text
+	// request. A limit of 0 means the network's default is used. See Backoff.
+	Retries() int
+	// SessionID returns the session ID of the current text request.
+	SessionID() string
+	SetBackoff(backoff time.Duration)
+	SetDeadline(deadline time.Duration)
+	SetEcho(echo bool)
+	SetExpectation(expectation Expectation)
+	SetInput(input string)
+	SetRetries(retries int)
+	SetSessionID(sessionID string)
+}
@@ -0,0 +1,32 @@
+package object
+
+// TextOutput represents a streamed response being send to the client. This
+// is basically good for responding calculated output of the neural network.
+type TextOutput interface {
+	// Attempts returns the number of outputs the neural network calculated
+	// without meeting the expectation of the request, in case it gave up on
+	// meeting it. Otherwise it is 0.
+	Attempts() int
+	// Candidates returns the outputs the CLG tree calculated, ranked by their
+	// score, where the best candidate is the first in the returned list. The
+	// output of the text response is the output of the best candidate. Text
+	// responses not being calculated by the neural network have no candidates.
+	Candidates() []Candidate
+	// CLGTreeID returns the ID of the CLG tree which calculated the output.
+	CLGTreeID() string
+	// Code returns the API response code of the current text response. An empty
+	// code means the output is data calculated by the neural network. See the
+	// codes of the api package.
+	Code() string
+	// Output returns the output of the current text response.
+	Output() string
+	// Score returns how close the output comes to the expectation of the
+	// request, in case the neural network gave up on meeting it. See Attempts.
+	Score() float64
+	SetAttempts(attempts int)
+	SetCandidates(candidates []Candidate)
+	SetCLGTreeID(clgTreeID string)
+	SetCode(code string)
+	SetOutput(output string)
+	SetScore(score float64)
+}
@@ -1,15 +1,15 @@
 package service
 
 import (
-	objectspec "github.com/the-anna-project/spec/object"
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
 // ActivatorService represents an management layer to organize CLG activation rules.
 // The activator obtains network payloads for every single requested CLG of
 // every possible CLG tree.
 type ActivatorService interface {
-	// Activate represents the public interface that bundles the following lookup
-	// functions.
+	// Activate represents the public interface that bundles the configured
+	// lookup strategies. By default these are the following lookup functions.
 	//
 	//     GetNetworkPayload
 	//     New
@@ -47,4 +47,7 @@ type ActivatorService interface {
 	New(clgService CLGService, queue []objectspec.NetworkPayload) (objectspec.NetworkPayload, error)
 	Service() ServiceCollection
 	SetServiceCollection(serviceCollection ServiceCollection)
+	// Shutdown ends all processes of the activator, like sweeping activation
+	// queues.
+	Shutdown()
 }
@@ -0,0 +1,58 @@
+package service
+
+// BehaviourService represents a registry of behaviour IDs. A behaviour ID
+// identifies a specific instance of a CLG within the neural network. For each
+// behaviour ID the registry records the following information.
+//
+//     clg-kind
+//
+//         clg-kind is the kind of CLG the behaviour ID is an instance of, e.g.
+//         "input" or "sum".
+//
+//     clg-tree-id
+//
+//         clg-tree-id is the ID of the CLG tree the behaviour ID was created
+//         in.
+//
+//     created
+//
+//         created is the unix timestamp of the point in time the behaviour ID
+//         was registered.
+//
+// Additionally the activation and forward configuration of each behaviour ID
+// is managed by the registry.
+type BehaviourService interface {
+	// ActivateConfiguration returns the behaviour IDs of the CLGs whose network
+	// payloads are known to satisfy the interface of the CLG identified by the
+	// given behaviour ID. The order of the returned behaviour IDs represents the
+	// input interface of the CLG. In case no activation configuration is
+	// stored, an error is returned.
+	ActivateConfiguration(behaviourID string) ([]string, error)
+	// AddForwardConfiguration adds the given behaviour IDs to the forward
+	// configuration of the CLG identified by the given behaviour ID.
+	AddForwardConfiguration(behaviourID string, behaviourIDs []string) error
+	Boot()
+	// Create registers the given behaviour ID as instance of the given CLG kind
+	// being created within the CLG tree identified by the given CLG tree ID.
+	Create(behaviourID, clgKind, clgTreeID string) error
+	// Delete removes the given behaviour ID and all of its configuration from
+	// the registry. The given behaviour ID is also removed from the forward
+	// configurations of other behaviour IDs, so no references to it dangle.
+	Delete(behaviourID string) error
+	// ForwardConfiguration returns the behaviour IDs of the CLGs the CLG
+	// identified by the given behaviour ID forwards network payloads to. In
+	// case no forward configuration is stored, an error is returned.
+	ForwardConfiguration(behaviourID string) ([]string, error)
+	Metadata() map[string]string
+	// Search returns the information recorded for the given behaviour ID. In
+	// case the behaviour ID is not registered, an error is returned.
+	Search(behaviourID string) (map[string]string, error)
+	// SearchByKind returns all behaviour IDs registered as instances of the
+	// given CLG kind.
+	SearchByKind(clgKind string) ([]string, error)
+	Service() ServiceCollection
+	// SetActivateConfiguration stores the given behaviour IDs as activation
+	// configuration of the CLG identified by the given behaviour ID.
+	SetActivateConfiguration(behaviourID string, behaviourIDs []string) error
+	SetServiceCollection(serviceCollection ServiceCollection)
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	objectspec "github.com/the-anna-project/annad/spec/object"
+)
+
+// BusService represents an internal publish/subscribe bus carrying network
+// events. Each subscriber receives network events through its own bounded
+// buffer. Network events not fitting into the buffer of a subscriber are
+// dropped and counted for this subscriber, so publishers never block. Only
+// blocking subscribers, which must not miss any network event, make
+// publishers wait until their buffer has room again. The bus itself subscribes
+// to emit metrics of the network events, and to optionally log them for tracing.
+type BusService interface {
+	Boot()
+	// Dropped returns the number of network events dropped for the subscriber
+	// identified by the given name, because its buffer was full. Network events
+	// are never dropped for blocking subscribers.
+	Dropped(name string) int64
+	Metadata() map[string]string
+	// Publish delivers the given network event to all current subscribers.
+	Publish(networkEvent objectspec.NetworkEvent)
+	Service() ServiceCollection
+	SetServiceCollection(serviceCollection ServiceCollection)
+	// Shutdown unsubscribes all subscribers.
+	Shutdown()
+	// Subscribe registers a new subscriber identified by the given name. The
+	// returned channel receives all network events published from now on. It is
+	// closed as soon as the subscriber unsubscribes, or the bus shuts down.
+	Subscribe(name string) (<-chan objectspec.NetworkEvent, error)
+	// SubscribeBlocking works like Subscribe, but registers a blocking
+	// subscriber. Publishing to a blocking subscriber whose buffer is full waits
+	// until the subscriber received enough network events, or unsubscribed. A
+	// blocking subscriber must therefore keep receiving until it unsubscribes.
+	// Publishers must not hold locks other publishers wait for, because a slow
+	// blocking subscriber would then stall all of them.
+	SubscribeBlocking(name string) (<-chan objectspec.NetworkEvent, error)
+	// Unsubscribe removes the subscriber identified by the given name and closes
+	// its channel.
+	Unsubscribe(name string)
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	objectspec "github.com/the-anna-project/annad/spec/object"
+)
+
+// CLGService represents the CLGs interacting with each other within the neural
+// network. Each CLG  is registered in the neural network. From there signal are
+// dispatched in a dynamic fashion until some useful calculation took place.
+type CLGService interface {
+	Boot()
+	// Call executes the CLG's calculate function using the given context and
+	// arguments. The arguments are checked against InputTypes before the
+	// calculate function is executed. Arguments not matching cause an error
+	// instead of a panic. The returned outputs match OutputTypes. An error
+	// returned by the calculate function is returned as error.
+	Call(ctx objectspec.Context, args []interface{}) ([]interface{}, error)
+	// GetCalculate returns the CLG's calculate function which implements its
+	// actual business logic.
+	GetCalculate() interface{}
+	// InputTypes returns the type names of the arguments of the CLG's calculate
+	// function, excluding the context being the first argument by convention.
+	InputTypes() []string
+	Metadata() map[string]string
+	// OutputTypes returns the type names of the outputs of the CLG's calculate
+	// function, excluding the error being the last output by convention, if
+	// any.
+	OutputTypes() []string
+	Service() ServiceCollection
+	// SetServiceCollection configures the CLG's factory collection. This is done
+	// for all CLGs, regardless if a CLG is making use of the factory collection
+	// or not.
+	SetServiceCollection(serviceCollection ServiceCollection)
+}
@@ -1,7 +1,7 @@
 package service
 
 import (
-	objectspec "github.com/the-anna-project/spec/object"
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
 // ForwarderService represents an management layer to organize CLG forwarding
@@ -1,7 +1,7 @@
 package service
 
 import (
-	objectspec "github.com/the-anna-project/spec/object"
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
 // InputService provides a communication channel for information sequences.
@@ -3,7 +3,7 @@ package service
 import (
 	"net/http"
 
-	objectspec "github.com/the-anna-project/spec/object"
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
 // InstrumentorService represents an abstraction of instrumentation libraries to
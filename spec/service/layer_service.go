@@ -38,6 +38,9 @@ type LayerService interface {
 	DeletePeer(peer string) (string, error)
 	Kind() string
 	Metadata() map[string]string
+	// PeerKey returns the key of the peer of the current layer representing the
+	// given peer argument. CreatePeer creates peers using this key.
+	PeerKey(peer string) string
 	PeerPosition(peer string) (string, error)
 	Service() ServiceCollection
 	SetServiceCollection(serviceCollection ServiceCollection)
@@ -1,7 +1,7 @@
 package service
 
 import (
-	objectspec "github.com/the-anna-project/spec/object"
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
 // NetworkService provides a neural network based on dynamic and self improving CLG
@@ -77,6 +77,13 @@ type NetworkService interface {
 	//     |-----|     |-----|     |-----|     |-----|     |-----|
 	//
 	Forward(clgService CLGService, networkPayload objectspec.NetworkPayload) error
+	// Frozen returns whether the network is frozen. A frozen network only uses
+	// what it already learned. The activator, the forwarder and the tracker
+	// only look up stored configurations and do neither create new ones, nor
+	// track connections or path patterns. CLG trees of a frozen network for
+	// which no learned path leads to the output end as soon as they run out of
+	// network payloads, without waiting for their budget to be exhausted.
+	Frozen() bool
 	// InputListener is a worker pool function which is executed multiple times
 	// concurrently to listen for network inputs. A network input is qualified by
 	// information sequences sent by clients who request some calculation from the
@@ -86,6 +93,19 @@ type NetworkService interface {
 	// CLG using the incoming text request. InputHandler is called by
 	// InputListener.
 	InputHandler(clgService CLGService, textInput objectspec.TextInput) error
+	// Queue queues the given network payload to be handled as network event.
+	// All network payloads being sent within the neural network are queued
+	// using Queue. That way the network keeps track of the network payloads of
+	// each CLG tree which are still to be handled.
+	Queue(networkPayload objectspec.NetworkPayload) error
+	// Retry queues the given network payload once more to calculate new output
+	// for a CLG tree whose output did not meet its expectation. Retries are
+	// delayed using an exponential backoff and limited per CLG tree. As soon as
+	// the limit is reached, the CLG tree ends and the client receives the
+	// output coming closest to the expectation instead.
+	Retry(networkPayload objectspec.NetworkPayload) error
+	// SetFrozen freezes or unfreezes the network at runtime. See Frozen.
+	SetFrozen(frozen bool)
 	// Shutdown ends all processes of the network like shutting down a machine.
 	// The call to Shutdown blocks until the network is completely shut down, so
 	// you might want to call it in a separate goroutine.
@@ -1,7 +1,7 @@
 package service
 
 import (
-	objectspec "github.com/the-anna-project/spec/object"
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
 // OutputService provides a communication channel to send information sequences.
@@ -5,7 +5,7 @@ import (
 	"math/big"
 	"time"
 
-	objectspec "github.com/the-anna-project/spec/object"
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
 // RandomService creates pseudo random numbers. The service might implement
@@ -5,7 +5,13 @@ package service
 // around.
 type ServiceCollection interface {
 	Activator() ActivatorService
+	// Behaviour returns a behaviour service. It is used to look up information
+	// about behaviour IDs.
+	Behaviour() BehaviourService
 	Boot()
+	// Bus returns a bus service. It is used to publish and subscribe to network
+	// events.
+	Bus() BusService
 	Connection() ConnectionService
 	Endpoint() EndpointCollection
 	Feature() FeatureService
@@ -31,6 +37,8 @@ type ServiceCollection interface {
 	// Random returns a random service. It is used to create random numbers.
 	Random() RandomService
 	SetActivatorService(activatorService ActivatorService)
+	SetBehaviourService(behaviourService BehaviourService)
+	SetBusService(busService BusService)
 	SetConnectionService(connectionService ConnectionService)
 	SetEndpointCollection(endpointCollection EndpointCollection)
 	SetFeatureService(featureService FeatureService)
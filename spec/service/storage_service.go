@@ -3,7 +3,7 @@ package service
 import (
 	"github.com/garyburd/redigo/redis"
 
-	objectspec "github.com/the-anna-project/spec/object"
+	objectspec "github.com/the-anna-project/annad/spec/object"
 )
 
 // StorageService represents a persistency management object. Different storages may be
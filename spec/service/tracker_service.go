@@ -0,0 +1,78 @@
+package service
+
+import (
+	objectspec "github.com/the-anna-project/annad/spec/object"
+)
+
+// TrackerService represents a management object to track connection path
+// patterns. The tracker subscribes to the network events published on the
+// BusService when booting. Calculated network payloads are tracked and ended
+// CLG trees are counted asynchronously that way.
+//
+// Path patterns are sequences of CLG kinds along the paths network payloads
+// travel within CLG trees, e.g. "input,sum,output". The tracker counts the CLG
+// trees each path pattern occurs in, split by successful and failed CLG trees.
+// For each path pattern the following statistics are provided.
+//
+//     support
+//
+//         support is the number of ended CLG trees the path pattern occurred
+//         in.
+//
+//     success
+//
+//         success is the number of successful CLG trees the path pattern
+//         occurred in.
+//
+//     failure
+//
+//         failure is the number of failed CLG trees the path pattern occurred
+//         in.
+//
+//     success-rate
+//
+//         success-rate is the smoothed ratio of successful CLG trees, that is
+//         (success+1)/(support+2). That way path patterns seen only a few times
+//         do not outrank proven ones.
+//
+type TrackerService interface {
+	Boot()
+	// CLGIDs is a lookup function used by Track. It persists the single
+	// connections between the destination and sources provided by networkPayload
+	// in the underlying storage.
+	CLGIDs(clgService CLGService, networkPayload objectspec.NetworkPayload) error
+	// CLGNames is a lookup function used by Track. It resolves the CLG names of
+	// the destination and sources provided by networkPayload and persists the
+	// single connections between them in the underlying storage.
+	CLGNames(clgService CLGService, networkPayload objectspec.NetworkPayload) error
+	// CLGPatterns is a lookup function used by Track. It resolves the CLG kinds
+	// along the path of networkPayload and records the path patterns ending at
+	// the given CLG for the CLG tree networkPayload belongs to. The recorded
+	// path patterns are counted as soon as the CLG tree ends.
+	CLGPatterns(clgService CLGService, networkPayload objectspec.NetworkPayload) error
+	// EndCLGTree counts the path patterns recorded for the CLG tree identified
+	// by the given CLG tree ID as successful or failed, according to success.
+	// EndCLGTree must only be called once per CLG tree.
+	EndCLGTree(clgTreeID string, success bool) error
+	Metadata() map[string]string
+	// PathSuccessRate returns the mean success rate of the path patterns
+	// networkPayload completes when entering the given CLG. That is how
+	// successful CLG trees were before, whose network payloads travelled along
+	// similar paths. Path patterns never counted rate 0.5.
+	PathSuccessRate(clgService CLGService, networkPayload objectspec.NetworkPayload) (float64, error)
+	// PatternStats returns the statistics of the given path pattern. In case
+	// the path pattern was never counted, an error is returned.
+	PatternStats(pattern string) (map[string]string, error)
+	// PatternsBySuccessRate returns up to maxPatterns path patterns having the
+	// highest success rate, where the best path pattern is the first in the
+	// returned list.
+	PatternsBySuccessRate(maxPatterns int) ([]string, error)
+	// PatternsBySupport returns up to maxPatterns path patterns having the
+	// highest support, where the most frequent path pattern is the first in the
+	// returned list.
+	PatternsBySupport(maxPatterns int) ([]string, error)
+	Service() ServiceCollection
+	SetServiceCollection(serviceCollection ServiceCollection)
+	// Track tracks connection path patterns.
+	Track(clgService CLGService, networkPayload objectspec.NetworkPayload) error
+}
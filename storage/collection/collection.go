@@ -5,7 +5,7 @@ package collection
 import (
 	"sync"
 
-	servicespec "github.com/the-anna-project/spec/service"
+	servicespec "github.com/the-anna-project/annad/spec/service"
 )
 
 // New creates a new storage collection.
@@ -1,6 +1,6 @@
 package collection
 
-import "github.com/the-anna-project/storage/service/redis"
+import "github.com/the-anna-project/annad/storage/service/redis"
 
 // IsNotFound combines IsNotFound error matchers of all storage
 // implementations. IsNotFound should thus be used for error handling wherever
@@ -5,7 +5,7 @@ import (
 
 	"github.com/juju/errgo"
 
-	"github.com/the-anna-project/storage/service/redis"
+	"github.com/the-anna-project/annad/storage/service/redis"
 )
 
 var (
@@ -10,9 +10,9 @@ import (
 	"github.com/cenk/backoff"
 	"github.com/garyburd/redigo/redis"
 
-	objectspec "github.com/the-anna-project/spec/object"
-	servicespec "github.com/the-anna-project/spec/service"
-	redisstorage "github.com/the-anna-project/storage/service/redis"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+	redisstorage "github.com/the-anna-project/annad/storage/service/redis"
 )
 
 // New creates a new memory storage service. Therefore it manages an in-memory
@@ -288,6 +288,9 @@ func (s *service) Shutdown() {
 	s.Service().Log().Line("func", "Shutdown")
 
 	s.shutdownOnce.Do(func() {
+		// Shut down the redis storage before the in-memory redis instance, so
+		// pending actions are not retried once the instance went away.
+		s.redisStorage.Shutdown()
 		close(s.closer)
 	})
 }
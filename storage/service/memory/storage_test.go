@@ -10,13 +10,13 @@ import (
 
 	kitlog "github.com/go-kit/kit/log"
 
-	servicecollection "github.com/the-anna-project/collection/collection"
-	"github.com/the-anna-project/id"
-	memoryinstrumentor "github.com/the-anna-project/instrumentor/memory"
-	"github.com/the-anna-project/log"
-	"github.com/the-anna-project/random"
-	servicespec "github.com/the-anna-project/spec/service"
-	storagecollection "github.com/the-anna-project/storage/collection"
+	servicecollection "github.com/the-anna-project/annad/collection/collection"
+	"github.com/the-anna-project/annad/id"
+	memoryinstrumentor "github.com/the-anna-project/annad/instrumentor/memory"
+	"github.com/the-anna-project/annad/log"
+	"github.com/the-anna-project/annad/random"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+	storagecollection "github.com/the-anna-project/annad/storage/collection"
 )
 
 func testNewStorage() servicespec.StorageService {
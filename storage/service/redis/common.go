@@ -0,0 +1,65 @@
+package redis
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/cenk/backoff"
+
+	objectspec "github.com/the-anna-project/annad/spec/object"
+)
+
+// closableBackoff wraps a spec.Backoff. Once the given closer is closed, no
+// more retries are attempted.
+type closableBackoff struct {
+	objectspec.Backoff
+
+	closer <-chan struct{}
+}
+
+func (b *closableBackoff) NextBackOff() time.Duration {
+	select {
+	case <-b.closer:
+		return backoff.Stop
+	default:
+		return b.Backoff.NextBackOff()
+	}
+}
+
+// newBackoff creates a new spec.Backoff using the configured backoff factory.
+// The returned backoff stops retrying as soon as the storage is shut down.
+// Otherwise actions failing because of the shut down would be retried until
+// the backoff gives up.
+func (s *service) newBackoff() objectspec.Backoff {
+	return &closableBackoff{
+		Backoff: s.backoffFactory(),
+		closer:  s.closer,
+	}
+}
+
+func (s *service) retryErrorLogger(err error, d time.Duration) {
+	s.Service().Log().Line("msg", "retry error", maskAny(err))
+}
+
+func (s *service) withPrefix(keys ...string) string {
+	newKey := s.prefix
+
+	for _, k := range keys {
+		newKey += ":" + k
+	}
+
+	return newKey
+}
+
+func parseMultiBulkReply(reply []interface{}) (int64, []string, error) {
+	cursor, err := strconv.ParseInt(string(reply[0].([]uint8)), 10, 64)
+	if err != nil {
+		return 0, nil, maskAny(err)
+	}
+	var values []string
+	for _, v := range reply[1].([]interface{}) {
+		values = append(values, string(v.([]uint8)))
+	}
+
+	return cursor, values, nil
+}
@@ -4,9 +4,9 @@ import (
 	"strings"
 	"testing"
 
-	servicecollection "github.com/the-anna-project/collection/collection"
-	"github.com/the-anna-project/log"
-	servicespec "github.com/the-anna-project/spec/service"
+	servicecollection "github.com/the-anna-project/annad/collection/collection"
+	"github.com/the-anna-project/annad/log"
+	servicespec "github.com/the-anna-project/annad/spec/service"
 )
 
 // rootLogger implements spec.RootLogger and is used to capture log messages.
@@ -9,8 +9,8 @@ import (
 	"github.com/cenk/backoff"
 	"github.com/garyburd/redigo/redis"
 
-	objectspec "github.com/the-anna-project/spec/object"
-	servicespec "github.com/the-anna-project/spec/service"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
 )
 
 // New creates a new redis storage service.
@@ -29,6 +29,7 @@ func New() servicespec.StorageService {
 		backoffFactory: func() objectspec.Backoff {
 			return &backoff.StopBackOff{}
 		},
+		closer:       make(chan struct{}),
 		pool:         newPool,
 		prefix:       "prefix",
 		metadata:     map[string]string{},
@@ -46,10 +47,13 @@ type service struct {
 	// backoffFactory is supposed to be able to create a new spec.Backoff. Retry
 	// implementations can make use of this to decide when to retry.
 	backoffFactory func() objectspec.Backoff
-	metadata       map[string]string
-	pool           *redis.Pool
-	prefix         string
-	shutdownOnce   sync.Once
+	// closer is closed as soon as the storage is shut down. Then failing actions
+	// are not retried anymore.
+	closer       chan struct{}
+	metadata     map[string]string
+	pool         *redis.Pool
+	prefix       string
+	shutdownOnce sync.Once
 }
 
 func (s *service) Boot() {
@@ -91,7 +95,7 @@ func (s *service) Get(key string) (string, error) {
 		return nil
 	}
 
-	err := backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("Get", action), s.backoffFactory(), s.retryErrorLogger)
+	err := backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("Get", action), s.newBackoff(), s.retryErrorLogger)
 	if err != nil {
 		return "", maskAny(err)
 	}
@@ -128,7 +132,7 @@ func (s *service) GetAllFromSet(key string) ([]string, error) {
 		return nil
 	}
 
-	err := backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("GetAllFromSet", action), s.backoffFactory(), s.retryErrorLogger)
+	err := backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("GetAllFromSet", action), s.newBackoff(), s.retryErrorLogger)
 	if err != nil {
 		return nil, maskAny(err)
 	}
@@ -153,7 +157,7 @@ func (s *service) GetElementsByScore(key string, score float64, maxElements int)
 		return nil
 	}
 
-	err = backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("GetElementsByScore", action), s.backoffFactory(), s.retryErrorLogger)
+	err = backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("GetElementsByScore", action), s.newBackoff(), s.retryErrorLogger)
 	if err != nil {
 		return nil, maskAny(err)
 	}
@@ -178,7 +182,7 @@ func (s *service) GetHighestScoredElements(key string, maxElements int) ([]strin
 		return nil
 	}
 
-	err = backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("GetHighestScoredElements", action), s.backoffFactory(), s.retryErrorLogger)
+	err = backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("GetHighestScoredElements", action), s.newBackoff(), s.retryErrorLogger)
 	if err != nil {
 		return nil, maskAny(err)
 	}
@@ -203,7 +207,7 @@ func (s *service) GetRandom() (string, error) {
 		return nil
 	}
 
-	err := backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("GetRandom", action), s.backoffFactory(), s.retryErrorLogger)
+	err := backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("GetRandom", action), s.newBackoff(), s.retryErrorLogger)
 	if err != nil {
 		return "", maskAny(err)
 	}
@@ -228,7 +232,7 @@ func (s *service) GetStringMap(key string) (map[string]string, error) {
 		return nil
 	}
 
-	err = backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("GetStringMap", action), s.backoffFactory(), s.retryErrorLogger)
+	err = backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("GetStringMap", action), s.newBackoff(), s.retryErrorLogger)
 	if err != nil {
 		return nil, maskAny(err)
 	}
@@ -261,7 +265,7 @@ func (s *service) PopFromList(key string) (string, error) {
 		return nil
 	}
 
-	err := backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("PopFromList", action), s.backoffFactory(), s.retryErrorLogger)
+	err := backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("PopFromList", action), s.newBackoff(), s.retryErrorLogger)
 	if err != nil {
 		return "", maskAny(err)
 	}
@@ -284,7 +288,7 @@ func (s *service) PushToList(key string, element string) error {
 		return nil
 	}
 
-	err := backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("PushToList", action), s.backoffFactory(), s.retryErrorLogger)
+	err := backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("PushToList", action), s.newBackoff(), s.retryErrorLogger)
 	if err != nil {
 		return maskAny(err)
 	}
@@ -307,7 +311,7 @@ func (s *service) PushToSet(key string, element string) error {
 		return nil
 	}
 
-	err := backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("PushToSet", action), s.backoffFactory(), s.retryErrorLogger)
+	err := backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("PushToSet", action), s.newBackoff(), s.retryErrorLogger)
 	if err != nil {
 		return maskAny(err)
 	}
@@ -330,7 +334,7 @@ func (s *service) Remove(key string) error {
 		return nil
 	}
 
-	err := backoff.Retry(s.Service().Instrumentor().WrapFunc("Remove", action), s.backoffFactory())
+	err := backoff.Retry(s.Service().Instrumentor().WrapFunc("Remove", action), s.newBackoff())
 	if err != nil {
 		return maskAny(err)
 	}
@@ -353,7 +357,7 @@ func (s *service) RemoveFromSet(key string, element string) error {
 		return nil
 	}
 
-	err := backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("RemoveFromSet", action), s.backoffFactory(), s.retryErrorLogger)
+	err := backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("RemoveFromSet", action), s.newBackoff(), s.retryErrorLogger)
 	if err != nil {
 		return maskAny(err)
 	}
@@ -376,7 +380,7 @@ func (s *service) RemoveScoredElement(key string, element string) error {
 		return nil
 	}
 
-	err := backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("RemoveScoredElement", action), s.backoffFactory(), s.retryErrorLogger)
+	err := backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("RemoveScoredElement", action), s.newBackoff(), s.retryErrorLogger)
 	if err != nil {
 		return maskAny(err)
 	}
@@ -407,7 +411,7 @@ func (s *service) Set(key, value string) error {
 		return nil
 	}
 
-	err := backoff.Retry(s.Service().Instrumentor().WrapFunc("Set", action), s.backoffFactory())
+	err := backoff.Retry(s.Service().Instrumentor().WrapFunc("Set", action), s.newBackoff())
 	if err != nil {
 		return maskAny(err)
 	}
@@ -434,7 +438,7 @@ func (s *service) SetElementByScore(key, element string, score float64) error {
 		return nil
 	}
 
-	err := backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("SetElementByScore", action), s.backoffFactory(), s.retryErrorLogger)
+	err := backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("SetElementByScore", action), s.newBackoff(), s.retryErrorLogger)
 	if err != nil {
 		return maskAny(err)
 	}
@@ -473,7 +477,7 @@ func (s *service) SetStringMap(key string, stringMap map[string]string) error {
 		return nil
 	}
 
-	err := backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("SetStringMap", action), s.backoffFactory(), s.retryErrorLogger)
+	err := backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("SetStringMap", action), s.newBackoff(), s.retryErrorLogger)
 	if err != nil {
 		return maskAny(err)
 	}
@@ -485,6 +489,7 @@ func (s *service) Shutdown() {
 	s.Service().Log().Line("func", "Shutdown")
 
 	s.shutdownOnce.Do(func() {
+		close(s.closer)
 		s.pool.Close()
 	})
 }
@@ -540,7 +545,7 @@ func (s *service) WalkKeys(glob string, closer <-chan struct{}, cb func(key stri
 		return nil
 	}
 
-	err := backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("WalkKeys", action), s.backoffFactory(), s.retryErrorLogger)
+	err := backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("WalkKeys", action), s.newBackoff(), s.retryErrorLogger)
 	if err != nil {
 		return maskAny(err)
 	}
@@ -607,7 +612,7 @@ func (s *service) WalkScoredSet(key string, closer <-chan struct{}, cb func(elem
 		return nil
 	}
 
-	err := backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("WalkScoredSet", action), s.backoffFactory(), s.retryErrorLogger)
+	err := backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("WalkScoredSet", action), s.newBackoff(), s.retryErrorLogger)
 	if err != nil {
 		return maskAny(err)
 	}
@@ -666,7 +671,7 @@ func (s *service) WalkSet(key string, closer <-chan struct{}, cb func(element st
 		return nil
 	}
 
-	err := backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("WalkSet", action), s.backoffFactory(), s.retryErrorLogger)
+	err := backoff.RetryNotify(s.Service().Instrumentor().WrapFunc("WalkSet", action), s.newBackoff(), s.retryErrorLogger)
 	if err != nil {
 		return maskAny(err)
 	}
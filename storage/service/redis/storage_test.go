@@ -9,12 +9,12 @@ import (
 	kitlog "github.com/go-kit/kit/log"
 	"github.com/rafaeljusto/redigomock"
 
-	servicecollection "github.com/the-anna-project/collection/collection"
-	"github.com/the-anna-project/id"
-	memoryinstrumentor "github.com/the-anna-project/instrumentor/memory"
-	"github.com/the-anna-project/log"
-	"github.com/the-anna-project/random"
-	servicespec "github.com/the-anna-project/spec/service"
+	servicecollection "github.com/the-anna-project/annad/collection/collection"
+	"github.com/the-anna-project/annad/id"
+	memoryinstrumentor "github.com/the-anna-project/annad/instrumentor/memory"
+	"github.com/the-anna-project/annad/log"
+	"github.com/the-anna-project/annad/random"
+	servicespec "github.com/the-anna-project/annad/spec/service"
 )
 
 func testMustNewStorageWithConn(t *testing.T, c redis.Conn) servicespec.StorageService {
@@ -1,4 +1,4 @@
-package text
+package testkit
 
 import (
 	"fmt"
@@ -21,3 +21,10 @@ func maskAnyf(err error, f string, v ...interface{}) error {
 
 	return newErr
 }
+
+var timeoutError = errgo.New("timeout")
+
+// IsTimeout asserts timeoutError.
+func IsTimeout(err error) bool {
+	return errgo.Cause(err) == timeoutError
+}
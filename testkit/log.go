@@ -0,0 +1,37 @@
+package testkit
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+
+	kitlog "github.com/go-kit/kit/log"
+)
+
+// logRecorder implements servicespec.RootLogger and captures all log lines in
+// logfmt format, so tests are able to inspect what services logged.
+type logRecorder struct {
+	lines []string
+	mutex sync.Mutex
+}
+
+func (r *logRecorder) Lines() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]string(nil), r.lines...)
+}
+
+func (r *logRecorder) Log(v ...interface{}) error {
+	var buf bytes.Buffer
+	err := kitlog.NewLogfmtLogger(&buf).Log(v...)
+	if err != nil {
+		return maskAny(err)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.lines = append(r.lines, strings.TrimSuffix(buf.String(), "\n"))
+
+	return nil
+}
@@ -0,0 +1,382 @@
+// Package testkit provides a fully wired service collection for tests. All
+// services are real implementations working in memory. Storage is backed by
+// memory storage, IDs and random numbers are reproducible using a seed and
+// log lines are captured instead of being printed. Single services can be
+// replaced using the kit's config.
+//
+// Writing a test for a CLG might look as follows.
+//
+//     newKit, err := testkit.New(testkit.DefaultConfig())
+//     if err != nil {
+//         t.Fatal("expected", nil, "got", err)
+//     }
+//     newKit.Boot()
+//     defer newKit.Shutdown()
+//
+//     newCLG := newKit.BootCLG(sum.New())
+//     outputs, err := newCLG.Call(context.MustNew(), []interface{}{3.0, 4.0})
+//
+// Note that the endpoint collection is not part of the kit, because endpoints
+// listen on network addresses.
+package testkit
+
+import (
+	"time"
+
+	servicecollection "github.com/the-anna-project/annad/collection/collection"
+	connectionservice "github.com/the-anna-project/annad/connection/service"
+	memoryfs "github.com/the-anna-project/annad/fs/memory"
+	inputcollection "github.com/the-anna-project/annad/input/collection"
+	textinputobject "github.com/the-anna-project/annad/input/object/text"
+	textinputservice "github.com/the-anna-project/annad/input/service/text"
+	memoryinstrumentor "github.com/the-anna-project/annad/instrumentor/memory"
+	layercollection "github.com/the-anna-project/annad/layer/collection"
+	layerservice "github.com/the-anna-project/annad/layer/service"
+	"github.com/the-anna-project/annad/log"
+	outputcollection "github.com/the-anna-project/annad/output/collection"
+	textoutputservice "github.com/the-anna-project/annad/output/service/text"
+	peerservice "github.com/the-anna-project/annad/peer/service"
+	"github.com/the-anna-project/annad/permutation/service"
+	positionservice "github.com/the-anna-project/annad/position/service"
+	"github.com/the-anna-project/annad/service/activator"
+	"github.com/the-anna-project/annad/service/behaviour"
+	"github.com/the-anna-project/annad/service/bus"
+	"github.com/the-anna-project/annad/service/feature"
+	"github.com/the-anna-project/annad/service/forwarder"
+	"github.com/the-anna-project/annad/service/id"
+	"github.com/the-anna-project/annad/service/network"
+	"github.com/the-anna-project/annad/service/random"
+	"github.com/the-anna-project/annad/service/tracker"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
+	storagecollection "github.com/the-anna-project/annad/storage/collection"
+	memorystorage "github.com/the-anna-project/annad/storage/service/memory"
+	workerservice "github.com/the-anna-project/annad/worker/service"
+)
+
+const (
+	// SessionID is the session ID of text inputs created by NewTextInput.
+	SessionID = "testkit-session"
+)
+
+// Config represents the configuration used to create a new test kit.
+type Config struct {
+	// Dependencies.
+
+	// Each of the following services replaces the corresponding default
+	// service of the test kit, if not nil.
+
+	ActivatorService    servicespec.ActivatorService
+	BehaviourService    servicespec.BehaviourService
+	BusService          servicespec.BusService
+	FeatureService      servicespec.FeatureService
+	ForwarderService    servicespec.ForwarderService
+	IDService           servicespec.IDService
+	InstrumentorService servicespec.InstrumentorService
+	NetworkService      servicespec.NetworkService
+	RandomService       servicespec.RandomService
+	StorageCollection   servicespec.StorageCollection
+	TrackerService      servicespec.TrackerService
+
+	// Settings.
+
+	// Seed is used to create the pseudo random numbers and IDs of the default
+	// random and ID services. Test kits using the same seed create the same
+	// random numbers and IDs, as long as the services are used in the same
+	// order.
+	Seed int64
+}
+
+// DefaultConfig provides a default configuration to create a new test kit by
+// best effort.
+func DefaultConfig() Config {
+	newConfig := Config{
+		// Dependencies.
+		ActivatorService:    nil,
+		BehaviourService:    nil,
+		BusService:          nil,
+		FeatureService:      nil,
+		ForwarderService:    nil,
+		IDService:           nil,
+		InstrumentorService: nil,
+		NetworkService:      nil,
+		RandomService:       nil,
+		StorageCollection:   nil,
+		TrackerService:      nil,
+
+		// Settings.
+		Seed: 1,
+	}
+
+	return newConfig
+}
+
+// New creates a new test kit. All services are wired with each other, but not
+// booted. See Kit.Boot.
+func New(config Config) (*Kit, error) {
+	var err error
+
+	newLogRecorder := &logRecorder{}
+	logService := log.New()
+	logService.SetRootLogger(newLogRecorder)
+
+	if config.ActivatorService == nil {
+		config.ActivatorService, err = activator.New(activator.DefaultConfig())
+		if err != nil {
+			return nil, maskAny(err)
+		}
+	}
+	if config.BehaviourService == nil {
+		config.BehaviourService = behaviour.New()
+	}
+	if config.BusService == nil {
+		config.BusService, err = bus.New(bus.DefaultConfig())
+		if err != nil {
+			return nil, maskAny(err)
+		}
+	}
+	if config.FeatureService == nil {
+		config.FeatureService = feature.New()
+	}
+	if config.ForwarderService == nil {
+		config.ForwarderService = forwarder.New()
+	}
+	if config.IDService == nil {
+		newIDConfig := id.DefaultConfig()
+		newIDConfig.Seed = config.Seed
+		config.IDService, err = id.New(newIDConfig)
+		if err != nil {
+			return nil, maskAny(err)
+		}
+	}
+	if config.InstrumentorService == nil {
+		config.InstrumentorService = memoryinstrumentor.New()
+	}
+	if config.NetworkService == nil {
+		config.NetworkService, err = network.New(network.DefaultConfig())
+		if err != nil {
+			return nil, maskAny(err)
+		}
+	}
+	if config.RandomService == nil {
+		newRandomConfig := random.DefaultConfig()
+		newRandomConfig.Seed = config.Seed
+		config.RandomService, err = random.New(newRandomConfig)
+		if err != nil {
+			return nil, maskAny(err)
+		}
+	}
+	if config.StorageCollection == nil {
+		config.StorageCollection = storagecollection.New()
+		config.StorageCollection.SetConnectionService(memorystorage.New())
+		config.StorageCollection.SetFeatureService(memorystorage.New())
+		config.StorageCollection.SetGeneralService(memorystorage.New())
+		config.StorageCollection.SetPeerService(memorystorage.New())
+	}
+	if config.TrackerService == nil {
+		config.TrackerService, err = tracker.New(tracker.DefaultConfig())
+		if err != nil {
+			return nil, maskAny(err)
+		}
+	}
+
+	connectionConfig := connectionservice.DefaultConfig()
+	connectionConfig.Weight = 1
+	connectionService, err := connectionservice.New(connectionConfig)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	newInputCollection := inputcollection.New()
+	newInputCollection.SetTextService(textinputservice.New())
+
+	newLayerCollection := layercollection.New()
+	for _, kind := range []string{layerservice.KindBehaviour, layerservice.KindInformation, layerservice.KindPosition} {
+		layerConfig := layerservice.DefaultConfig()
+		layerConfig.Kind = kind
+		layerService, err := layerservice.New(layerConfig)
+		if err != nil {
+			return nil, maskAny(err)
+		}
+		switch kind {
+		case layerservice.KindBehaviour:
+			newLayerCollection.SetBehaviourService(layerService)
+		case layerservice.KindInformation:
+			newLayerCollection.SetInformationService(layerService)
+		case layerservice.KindPosition:
+			newLayerCollection.SetPositionService(layerService)
+		}
+	}
+
+	newOutputCollection := outputcollection.New()
+	newOutputCollection.SetTextService(textoutputservice.New())
+
+	positionConfig := positionservice.DefaultConfig()
+	positionConfig.DimensionCount = 3
+	positionConfig.DimensionDepth = 1000000
+	positionService, err := positionservice.New(positionConfig)
+	if err != nil {
+		return nil, maskAny(err)
+	}
+
+	collection := servicecollection.New()
+
+	collection.SetActivatorService(config.ActivatorService)
+	collection.SetBehaviourService(config.BehaviourService)
+	collection.SetBusService(config.BusService)
+	collection.SetConnectionService(connectionService)
+	collection.SetFeatureService(config.FeatureService)
+	collection.SetForwarderService(config.ForwarderService)
+	collection.SetFSService(memoryfs.New())
+	collection.SetIDService(config.IDService)
+	collection.SetInputCollection(newInputCollection)
+	collection.SetInstrumentorService(config.InstrumentorService)
+	collection.SetLayerCollection(newLayerCollection)
+	collection.SetLogService(logService)
+	collection.SetNetworkService(config.NetworkService)
+	collection.SetOutputCollection(newOutputCollection)
+	collection.SetPeerService(peerservice.New())
+	collection.SetPermutationService(permutation.New())
+	collection.SetPositionService(positionService)
+	collection.SetRandomService(config.RandomService)
+	collection.SetStorageCollection(config.StorageCollection)
+	collection.SetTrackerService(config.TrackerService)
+	collection.SetWorkerService(workerservice.New())
+
+	collection.Activator().SetServiceCollection(collection)
+	collection.Behaviour().SetServiceCollection(collection)
+	collection.Bus().SetServiceCollection(collection)
+	collection.Connection().SetServiceCollection(collection)
+	collection.Feature().SetServiceCollection(collection)
+	collection.Forwarder().SetServiceCollection(collection)
+	collection.FS().SetServiceCollection(collection)
+	collection.ID().SetServiceCollection(collection)
+	collection.Input().Text().SetServiceCollection(collection)
+	collection.Instrumentor().SetServiceCollection(collection)
+	collection.Layer().Behaviour().SetServiceCollection(collection)
+	collection.Layer().Information().SetServiceCollection(collection)
+	collection.Layer().Position().SetServiceCollection(collection)
+	collection.Log().SetServiceCollection(collection)
+	collection.Network().SetServiceCollection(collection)
+	collection.Output().Text().SetServiceCollection(collection)
+	collection.Peer().SetServiceCollection(collection)
+	collection.Permutation().SetServiceCollection(collection)
+	collection.Position().SetServiceCollection(collection)
+	collection.Random().SetServiceCollection(collection)
+	collection.Storage().Connection().SetServiceCollection(collection)
+	collection.Storage().Feature().SetServiceCollection(collection)
+	collection.Storage().General().SetServiceCollection(collection)
+	collection.Storage().Peer().SetServiceCollection(collection)
+	collection.Tracker().SetServiceCollection(collection)
+	collection.Worker().SetServiceCollection(collection)
+
+	newKit := &Kit{
+		logRecorder:       newLogRecorder,
+		serviceCollection: collection,
+	}
+
+	return newKit, nil
+}
+
+// Kit provides a fully wired service collection for tests.
+type Kit struct {
+	logRecorder       *logRecorder
+	serviceCollection servicespec.ServiceCollection
+}
+
+// Boot boots all services of the kit's service collection. Other than
+// servicespec.ServiceCollection.Boot, services are booted synchronously in the
+// order of their dependencies. So all services are ready to be used as soon as
+// Boot returns.
+func (k *Kit) Boot() {
+	c := k.serviceCollection
+
+	c.Random().Boot()
+	c.ID().Boot()
+	c.Log().Boot()
+	c.Instrumentor().Boot()
+	c.Storage().Connection().Boot()
+	c.Storage().Feature().Boot()
+	c.Storage().General().Boot()
+	c.Storage().Peer().Boot()
+	c.FS().Boot()
+	c.Worker().Boot()
+	c.Input().Text().Boot()
+	c.Output().Text().Boot()
+	c.Layer().Behaviour().Boot()
+	c.Layer().Information().Boot()
+	c.Layer().Position().Boot()
+	c.Connection().Boot()
+	c.Peer().Boot()
+	c.Permutation().Boot()
+	c.Position().Boot()
+	c.Behaviour().Boot()
+	c.Bus().Boot()
+	c.Feature().Boot()
+	c.Forwarder().Boot()
+	c.Tracker().Boot()
+	c.Activator().Boot()
+	c.Network().Boot()
+}
+
+// BootCLG wires the given CLG with the kit's service collection and boots it.
+// The given CLG is returned for convenience.
+func (k *Kit) BootCLG(CLG servicespec.CLGService) servicespec.CLGService {
+	CLG.SetServiceCollection(k.serviceCollection)
+	CLG.Boot()
+
+	return CLG
+}
+
+// LogLines returns all lines logged using the kit's log service so far. Each
+// line is formatted using logfmt.
+func (k *Kit) LogLines() []string {
+	return k.logRecorder.Lines()
+}
+
+// PushInput sends the given text input to the network, the same way the text
+// endpoint does. See NewTextInput.
+func (k *Kit) PushInput(textInput objectspec.TextInput) {
+	k.serviceCollection.Input().Text().Channel() <- textInput
+}
+
+// Service returns the kit's service collection.
+func (k *Kit) Service() servicespec.ServiceCollection {
+	return k.serviceCollection
+}
+
+// Shutdown shuts down all services of the kit's service collection having to
+// be shut down.
+func (k *Kit) Shutdown() {
+	c := k.serviceCollection
+
+	c.Network().Shutdown()
+	c.Activator().Shutdown()
+	c.Bus().Shutdown()
+	c.Peer().Shutdown()
+	c.Storage().Shutdown()
+}
+
+// WaitForOutput returns the next output the network sent back. In case there
+// is no output within the given timeout, timeoutError is returned.
+func (k *Kit) WaitForOutput(timeout time.Duration) (string, error) {
+	select {
+	case textOutput := <-k.serviceCollection.Output().Text().Channel():
+		return textOutput.Output(), nil
+	case <-time.After(timeout):
+		return "", maskAnyf(timeoutError, "no output within %s", timeout)
+	}
+}
+
+// NewTextInput creates a new text input for the given input, belonging to the
+// session identified by SessionID. In case echo is true, the network responds
+// with the given input directly. This is useful to test the round trip
+// through the network without depending on any learned behaviour.
+func NewTextInput(input string, echo bool) objectspec.TextInput {
+	newTextInput := textinputobject.New()
+	newTextInput.SetEcho(echo)
+	newTextInput.SetInput(input)
+	newTextInput.SetSessionID(SessionID)
+
+	return newTextInput
+}
@@ -0,0 +1,110 @@
+package testkit
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/the-anna-project/annad/object/context"
+	"github.com/the-anna-project/annad/service/clg/sum"
+	"github.com/the-anna-project/annad/service/tracker"
+)
+
+func testKit(t *testing.T, config Config) *Kit {
+	newKit, err := New(config)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	newKit.Boot()
+
+	return newKit
+}
+
+func Test_Testkit_Seed(t *testing.T) {
+	newIDs := func(seed int64) []string {
+		newConfig := DefaultConfig()
+		newConfig.Seed = seed
+		newKit := testKit(t, newConfig)
+		defer newKit.Shutdown()
+
+		var IDs []string
+		for i := 0; i < 3; i++ {
+			ID, err := newKit.Service().ID().New()
+			if err != nil {
+				t.Fatal("expected", nil, "got", err)
+			}
+			IDs = append(IDs, ID)
+		}
+
+		return IDs
+	}
+
+	IDs1 := newIDs(1)
+	if !reflect.DeepEqual(IDs1, newIDs(1)) {
+		t.Fatal("expected", "same IDs", "got", "different IDs")
+	}
+	if reflect.DeepEqual(IDs1, newIDs(2)) {
+		t.Fatal("expected", "different IDs", "got", "same IDs")
+	}
+}
+
+func Test_Testkit_Config_Override(t *testing.T) {
+	newTrackerService, err := tracker.New(tracker.DefaultConfig())
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+
+	newConfig := DefaultConfig()
+	newConfig.TrackerService = newTrackerService
+	newKit := testKit(t, newConfig)
+	defer newKit.Shutdown()
+
+	if newKit.Service().Tracker() != newTrackerService {
+		t.Fatal("expected", newTrackerService, "got", newKit.Service().Tracker())
+	}
+	if newTrackerService.Service() != newKit.Service() {
+		t.Fatal("expected", "tracker wired with kit", "got", "tracker not wired")
+	}
+}
+
+func Test_Testkit_BootCLG(t *testing.T) {
+	newKit := testKit(t, DefaultConfig())
+	defer newKit.Shutdown()
+
+	newCLG := newKit.BootCLG(sum.New())
+	if newCLG.Metadata()["kind"] != "sum" {
+		t.Fatal("expected", "sum", "got", newCLG.Metadata()["kind"])
+	}
+
+	outputs, err := newCLG.Call(context.MustNew(), []interface{}{3.0, 4.0})
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if !reflect.DeepEqual(outputs, []interface{}{7.0}) {
+		t.Fatal("expected", []interface{}{7.0}, "got", outputs)
+	}
+}
+
+func Test_Testkit_PushInput_WaitForOutput(t *testing.T) {
+	newKit := testKit(t, DefaultConfig())
+	defer newKit.Shutdown()
+
+	newKit.PushInput(NewTextInput("hello world", true))
+
+	output, err := newKit.WaitForOutput(5 * time.Second)
+	if err != nil {
+		t.Fatal("expected", nil, "got", err)
+	}
+	if output != "hello world" {
+		t.Fatal("expected", "hello world", "got", output)
+	}
+
+	_, err = newKit.WaitForOutput(10 * time.Millisecond)
+	if !IsTimeout(err) {
+		t.Fatal("expected", true, "got", false)
+	}
+
+	if len(newKit.LogLines()) == 0 {
+		t.Fatal("expected", "log lines", "got", nil)
+	}
+}
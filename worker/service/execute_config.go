@@ -1,6 +1,6 @@
 package service
 
-import objectspec "github.com/the-anna-project/spec/object"
+import objectspec "github.com/the-anna-project/annad/spec/object"
 
 func newExecuteConfig() objectspec.WorkerExecuteConfig {
 	return &executeConfig{
@@ -4,8 +4,8 @@ package service
 import (
 	"sync"
 
-	objectspec "github.com/the-anna-project/spec/object"
-	servicespec "github.com/the-anna-project/spec/service"
+	objectspec "github.com/the-anna-project/annad/spec/object"
+	servicespec "github.com/the-anna-project/annad/spec/service"
 )
 
 // New creates a new worker service.
@@ -54,9 +54,19 @@ func (s *service) Execute(config objectspec.WorkerExecuteConfig) error {
 	canceler := make(chan struct{}, 1)
 	errors := make(chan error, 1)
 
+	// done is closed as soon as Execute returns. That way the goroutine waiting
+	// for the global canceler below ends as well, even if the global canceler is
+	// never closed.
+	done := make(chan struct{})
+	defer close(done)
+
 	if config.Canceler() != nil {
 		go func() {
-			<-config.Canceler()
+			select {
+			case <-done:
+				return
+			case <-config.Canceler():
+			}
 			// Receiving a signal from the global canceler will forward the
 			// cancelation to all workers. Simply closing the workers canceler wil
 			// broadcast the signal to each listener. Here we also make sure we do
@@ -67,24 +77,35 @@ func (s *service) Execute(config objectspec.WorkerExecuteConfig) error {
 		}()
 	}
 
+	// All actions of all workers are added to the wait group upfront. Adding
+	// them within the goroutines below might happen after wg.Wait was called,
+	// which would make Execute return before the workers finished.
+	wg.Add(config.NumWorkers() * len(config.Actions()))
+
 	for n := 0; n < config.NumWorkers(); n++ {
 		go func() {
 			for _, action := range config.Actions() {
-				wg.Add(1)
 				go func() {
 					defer wg.Done()
 
 					err := action(canceler)
 					if err != nil {
-						if config.CancelOnError() && config.Canceler() != nil {
-							// Closing the canceler channel acts as broadcast to all workers that
-							// should listen to the canceler. Here we also make sure we do not
-							// close on a closed channel by only closing once.
+						if config.CancelOnError() {
+							// Closing the workers canceler acts as broadcast to all workers of
+							// the pool. The global canceler is owned by the caller, who might
+							// close it at any time, so it must not be closed here. Here we
+							// also make sure we do not close on a closed channel by only
+							// closing once.
 							once.Do(func() {
-								close(config.Canceler())
+								close(canceler)
 							})
 						}
-						errors <- err
+						// Only the first error is returned. Others are dropped so no
+						// worker blocks forever.
+						select {
+						case errors <- err:
+						default:
+						}
 					}
 				}()
 			}